@@ -2,6 +2,8 @@ package platforms
 
 import (
 	"context"
+	"net/http"
+	"strings"
 
 	"gitee.com/openeuler/go-gitee/gitee"
 	"github.com/antihax/optional"
@@ -12,6 +14,7 @@ type giteeClient struct {
 	accessToken  string
 	refreshToken string
 	c            *gitee.APIClient
+	httpClient   *http.Client
 }
 
 func newGiteeClient(accessToken, refreshToken string) *giteeClient {
@@ -22,7 +25,12 @@ func newGiteeClient(accessToken, refreshToken string) *giteeClient {
 
 	cli := gitee.NewAPIClient(conf)
 
-	return &giteeClient{refreshToken: refreshToken, accessToken: accessToken, c: cli}
+	return &giteeClient{
+		refreshToken: refreshToken,
+		accessToken:  accessToken,
+		c:            cli,
+		httpClient:   conf.HTTPClient,
+	}
 }
 
 func (this *giteeClient) GetUser() (string, error) {
@@ -58,3 +66,116 @@ func (this *giteeClient) ListOrg() ([]string, error) {
 
 	return r, nil
 }
+
+func (this *giteeClient) setEndpoint(endpoint string) {
+	this.c = gitee.NewAPIClient(&gitee.Configuration{
+		BasePath:   strings.TrimSuffix(endpoint, "/"),
+		HTTPClient: this.httpClient,
+	})
+}
+
+func (this *giteeClient) ListPRCommitAuthors(org, repo string, number int) ([]string, error) {
+	v, _, err := this.c.PullRequestsApi.GetV5ReposOwnerRepoPullsNumberCommits(
+		context.Background(), org, repo, int32(number), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]string, 0, len(v))
+	for _, item := range v {
+		if item.Commit != nil && item.Commit.Author != nil {
+			r = append(r, item.Commit.Author.Email)
+		}
+	}
+	return distinct(r), nil
+}
+
+func (this *giteeClient) AddPRLabel(org, repo string, number int, label string) error {
+	_, _, err := this.c.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberLabels(
+		context.Background(), org, repo, int32(number),
+		gitee.PullRequestLabelPostParam{Body: []string{label}},
+	)
+	return err
+}
+
+func (this *giteeClient) RemovePRLabel(org, repo string, number int, label string) error {
+	// the label, such as cla/yes, is a part of the url path
+	_, err := this.c.PullRequestsApi.DeleteV5ReposOwnerRepoPullsLabel(
+		context.Background(), org, repo, int32(number),
+		strings.Replace(label, "/", "%2F", -1), nil,
+	)
+	return err
+}
+
+func (this *giteeClient) CreatePRComment(org, repo string, number int, comment string) error {
+	_, _, err := this.c.PullRequestsApi.PostV5ReposOwnerRepoPullsNumberComments(
+		context.Background(), org, repo, int32(number),
+		gitee.PullRequestCommentPostParam{Body: comment},
+	)
+	return err
+}
+
+func (this *giteeClient) ListRepos(org string) ([]string, error) {
+	var r []string
+
+	p := int32(1)
+	opt := gitee.GetV5OrgsOrgReposOpts{}
+	for {
+		opt.Page = optional.NewInt32(p)
+		ls, _, err := this.c.RepositoriesApi.GetV5OrgsOrgRepos(context.Background(), org, &opt)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ls) == 0 {
+			break
+		}
+
+		p += 1
+
+		for _, v := range ls {
+			r = append(r, v.Path)
+		}
+	}
+
+	return r, nil
+}
+
+func (this *giteeClient) ListOpenPRs(org, repo string) ([]PullRequest, error) {
+	var r []PullRequest
+
+	p := int32(1)
+	opt := gitee.GetV5ReposOwnerRepoPullsOpts{State: optional.NewString("open")}
+	for {
+		opt.Page = optional.NewInt32(p)
+		ls, _, err := this.c.PullRequestsApi.GetV5ReposOwnerRepoPulls(context.Background(), org, repo, &opt)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ls) == 0 {
+			break
+		}
+
+		p += 1
+
+		for _, v := range ls {
+			pr := PullRequest{Number: int(v.Number)}
+			if v.Head != nil {
+				pr.HeadSHA = v.Head.Sha
+			}
+			for _, l := range v.Labels {
+				pr.Labels = append(pr.Labels, l.Name)
+			}
+			r = append(r, pr)
+		}
+	}
+
+	return r, nil
+}
+
+// CreateCommitStatus is not supported, because Gitee has no api of commit status.
+// The cla status of pull request is reported by labels instead.
+func (this *giteeClient) CreateCommitStatus(org, repo, sha string, status CommitStatus) error {
+	return ErrUnsupported
+}
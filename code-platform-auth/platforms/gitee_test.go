@@ -0,0 +1,115 @@
+package platforms
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func newTestGiteeClient(t *testing.T, mux *http.ServeMux) Platform {
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	c, err := NewPlatformWithEndpoint("token", "gitee", s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestGiteeListPRCommitAuthors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v5/repos/org/repo/pulls/1/commits", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `[
+			{"sha":"1","commit":{"author":{"email":"a@example.com"}}},
+			{"sha":"2","commit":{"author":{"email":"b@example.com"}}},
+			{"sha":"3","commit":{"author":{"email":"a@example.com"}}}
+		]`)
+	})
+
+	emails, err := newTestGiteeClient(t, mux).ListPRCommitAuthors("org", "repo", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{"a@example.com", "b@example.com"}
+	if !reflect.DeepEqual(emails, expect) {
+		t.Errorf("expect emails: %v, got: %v", expect, emails)
+	}
+}
+
+func TestGiteePRLabel(t *testing.T) {
+	var added []string
+	var removed string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v5/repos/org/repo/pulls/1/labels", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expect method: POST, got: %s", r.Method)
+		}
+
+		var body struct {
+			Body []string `json:"body"`
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(b, &body); err != nil {
+			t.Error(err)
+		}
+		added = body.Body
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/v5/repos/org/repo/pulls/1/labels/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("expect method: DELETE, got: %s", r.Method)
+		}
+		removed = r.URL.EscapedPath()
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	c := newTestGiteeClient(t, mux)
+	if err := c.AddPRLabel("org", "repo", 1, "cla/yes"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RemovePRLabel("org", "repo", 1, "cla/no"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(added, []string{"cla/yes"}) {
+		t.Errorf("expect added labels: [cla/yes], got: %v", added)
+	}
+	if expect := "/v5/repos/org/repo/pulls/1/labels/cla%2Fno"; removed != expect {
+		t.Errorf("expect path: %s, got: %s", expect, removed)
+	}
+}
+
+func TestGiteeCreatePRComment(t *testing.T) {
+	var comment string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v5/repos/org/repo/pulls/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Body string `json:"body"`
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(b, &body); err != nil {
+			t.Error(err)
+		}
+		comment = body.Body
+		fmt.Fprint(w, `{"id":1}`)
+	})
+
+	if err := newTestGiteeClient(t, mux).CreatePRComment("org", "repo", 1, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if comment != "hello" {
+		t.Errorf("expect comment: hello, got: %s", comment)
+	}
+}
@@ -0,0 +1,180 @@
+package platforms
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"golang.org/x/oauth2"
+)
+
+const githubRoleAdmin = "admin"
+
+type githubClient struct {
+	accessToken  string
+	refreshToken string
+	c            *github.Client
+}
+
+func newGithubClient(accessToken, refreshToken string) *githubClient {
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken})
+
+	cli := github.NewClient(oauth2.NewClient(context.Background(), ts))
+
+	return &githubClient{refreshToken: refreshToken, accessToken: accessToken, c: cli}
+}
+
+func (this *githubClient) GetUser() (string, error) {
+	u, _, err := this.c.Users.Get(context.Background(), "")
+	if err != nil {
+		return "", err
+	}
+	return u.GetLogin(), nil
+}
+
+// ListOrg returns the orgs which the user administers
+func (this *githubClient) ListOrg() ([]string, error) {
+	var r []string
+
+	opt := github.ListOrgMembershipsOptions{State: "active"}
+	opt.Page = 1
+	for {
+		ls, resp, err := this.c.Organizations.ListOrgMemberships(context.Background(), &opt)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range ls {
+			if v.GetRole() == githubRoleAdmin {
+				r = append(r, v.GetOrganization().GetLogin())
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return r, nil
+}
+
+func (this *githubClient) setEndpoint(endpoint string) error {
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	this.c.BaseURL = u
+	return nil
+}
+
+func (this *githubClient) ListPRCommitAuthors(org, repo string, number int) ([]string, error) {
+	var r []string
+
+	opt := github.ListOptions{Page: 1}
+	for {
+		ls, resp, err := this.c.PullRequests.ListCommits(context.Background(), org, repo, number, &opt)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range ls {
+			r = append(r, v.GetCommit().GetAuthor().GetEmail())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return distinct(r), nil
+}
+
+func (this *githubClient) AddPRLabel(org, repo string, number int, label string) error {
+	_, _, err := this.c.Issues.AddLabelsToIssue(context.Background(), org, repo, number, []string{label})
+	return err
+}
+
+func (this *githubClient) RemovePRLabel(org, repo string, number int, label string) error {
+	_, err := this.c.Issues.RemoveLabelForIssue(context.Background(), org, repo, number, label)
+	return err
+}
+
+func (this *githubClient) CreatePRComment(org, repo string, number int, comment string) error {
+	_, _, err := this.c.Issues.CreateComment(
+		context.Background(), org, repo, number, &github.IssueComment{Body: &comment},
+	)
+	return err
+}
+
+func (this *githubClient) ListRepos(org string) ([]string, error) {
+	var r []string
+
+	opt := github.RepositoryListByOrgOptions{}
+	opt.Page = 1
+	for {
+		ls, resp, err := this.c.Repositories.ListByOrg(context.Background(), org, &opt)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range ls {
+			r = append(r, v.GetName())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return r, nil
+}
+
+func (this *githubClient) ListOpenPRs(org, repo string) ([]PullRequest, error) {
+	var r []PullRequest
+
+	opt := github.PullRequestListOptions{State: "open"}
+	opt.Page = 1
+	for {
+		ls, resp, err := this.c.PullRequests.List(context.Background(), org, repo, &opt)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range ls {
+			pr := PullRequest{Number: v.GetNumber(), HeadSHA: v.GetHead().GetSHA()}
+			for _, l := range v.Labels {
+				pr.Labels = append(pr.Labels, l.GetName())
+			}
+			r = append(r, pr)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return r, nil
+}
+
+func (this *githubClient) CreateCommitStatus(org, repo, sha string, status CommitStatus) error {
+	s := github.RepoStatus{
+		State:       &status.State,
+		Context:     &status.Context,
+		Description: &status.Description,
+	}
+	if status.TargetURL != "" {
+		s.TargetURL = &status.TargetURL
+	}
+
+	_, _, err := this.c.Repositories.CreateStatus(context.Background(), org, repo, sha, &s)
+	return err
+}
@@ -0,0 +1,73 @@
+package platforms
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func newTestGithubClient(t *testing.T, mux *http.ServeMux) *githubClient {
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	c := newGithubClient("token", "")
+
+	u, err := url.Parse(s.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.c.BaseURL = u
+	return c
+}
+
+func TestGithubGetUser(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"login":"octocat"}`)
+	})
+
+	user, err := newTestGithubClient(t, mux).GetUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user != "octocat" {
+		t.Errorf("expect user: octocat, got: %s", user)
+	}
+}
+
+func TestGithubListOrg(t *testing.T) {
+	pages := map[string]string{
+		"1": `[{"role":"admin","organization":{"login":"org1"}},{"role":"member","organization":{"login":"org2"}}]`,
+		"2": `[{"role":"admin","organization":{"login":"org3"}}]`,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/user/memberships/orgs", func(w http.ResponseWriter, r *http.Request) {
+		if v := r.URL.Query().Get("state"); v != "active" {
+			t.Errorf("expect state: active, got: %s", v)
+		}
+
+		p := r.URL.Query().Get("page")
+		if p == "1" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=2>; rel="next"`, r.URL.Path))
+		}
+		fmt.Fprint(w, pages[p])
+	})
+
+	orgs, err := newTestGithubClient(t, mux).ListOrg()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{"org1", "org3"}
+	if !reflect.DeepEqual(orgs, expect) {
+		t.Errorf("expect orgs: %v, got: %v", expect, orgs)
+	}
+}
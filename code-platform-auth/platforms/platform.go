@@ -1,18 +1,83 @@
 package platforms
 
 import (
+	"errors"
 	"fmt"
 )
 
+// ErrUnsupported is returned when the operation is not supported by the platform
+var ErrUnsupported = errors.New("the operation is not supported by the platform")
+
+const (
+	CommitStatusSuccess = "success"
+	CommitStatusFailure = "failure"
+)
+
+type PullRequest struct {
+	Number  int
+	HeadSHA string
+	Labels  []string
+}
+
+type CommitStatus struct {
+	// State is the state of status, such as success and failure
+	State       string
+	Context     string
+	Description string
+	TargetURL   string
+}
+
 type Platform interface {
 	GetUser() (string, error)
 	ListOrg() ([]string, error)
+
+	// ListPRCommitAuthors returns the distinct emails of the commit authors of pull request
+	ListPRCommitAuthors(org, repo string, number int) ([]string, error)
+	AddPRLabel(org, repo string, number int, label string) error
+	RemovePRLabel(org, repo string, number int, label string) error
+	CreatePRComment(org, repo string, number int, comment string) error
+
+	ListRepos(org string) ([]string, error)
+	ListOpenPRs(org, repo string) ([]PullRequest, error)
+	CreateCommitStatus(org, repo, sha string, status CommitStatus) error
 }
 
 func NewPlatform(accessToken, refreshToken, platform string) (Platform, error) {
 	switch platform {
 	case "gitee":
 		return newGiteeClient(accessToken, refreshToken), nil
+	case "github":
+		return newGithubClient(accessToken, refreshToken), nil
 	}
 	return nil, fmt.Errorf("unknown platform:%s", platform)
 }
+
+// NewPlatformWithEndpoint is same as NewPlatform except that the api of platform
+// is accessed at endpoint, such as the one of a self-hosted platform.
+func NewPlatformWithEndpoint(accessToken, platform, endpoint string) (Platform, error) {
+	switch platform {
+	case "gitee":
+		c := newGiteeClient(accessToken, "")
+		c.setEndpoint(endpoint)
+		return c, nil
+	case "github":
+		c := newGithubClient(accessToken, "")
+		if err := c.setEndpoint(endpoint); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	return nil, fmt.Errorf("unknown platform:%s", platform)
+}
+
+func distinct(v []string) []string {
+	r := make([]string, 0, len(v))
+	m := make(map[string]bool, len(v))
+	for _, item := range v {
+		if item != "" && !m[item] {
+			m[item] = true
+			r = append(r, item)
+		}
+	}
+	return r
+}
@@ -14,16 +14,33 @@ const (
 	PermissionIndividualSigner = "individual signer"
 	PermissionCorporAdmin      = "corporation administrator"
 	PermissionEmployeeManager  = "employee manager"
+
+	// PermissionPasswordChanger is granted to the corporation manager who
+	// logins with the initial password, and it can only change the password.
+	PermissionPasswordChanger = "password changer"
 )
 
+var allPermissions = []string{
+	PermissionOwnerOfOrg,
+	PermissionIndividualSigner,
+	PermissionCorporAdmin,
+	PermissionEmployeeManager,
+	PermissionPasswordChanger,
+}
+
 type accessControler struct {
 	Expiry     int64  `json:"expiry"`
 	User       string `json:"user"`
 	Permission string `json:"permission"`
+
+	// SessionID is the id of refresh token which issues this token.
+	// The token is invalid once the session is revoked.
+	SessionID string `json:"session_id"`
 }
 
+// CreateToken creates the token which expires at expiry
 func (this *accessControler) CreateToken(expiry int64, secret string) (string, error) {
-	this.Expiry = time.Now().Add(time.Second * time.Duration(expiry)).Unix()
+	this.Expiry = expiry
 
 	body, err := golangsdk.BuildRequestBody(this, "")
 	if err != nil {
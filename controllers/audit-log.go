@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/astaxie/beego"
+
+	"github.com/zengchen1024/cla-server/models"
+	"github.com/zengchen1024/cla-server/util"
+)
+
+type AuditLogController struct {
+	beego.Controller
+}
+
+func (this *AuditLogController) Prepare() {
+	apiPrepare(&this.Controller, []string{PermissionOwnerOfOrg, PermissionCorporAdmin, PermissionEmployeeManager})
+}
+
+// @Title GetAll
+// @Description list the audit logs. The org owner can list the logs of binding, and
+// @Description the corporation manager can only list the logs of his/her corporation.
+// @Param	cla_org_id		query 	string	false		"The id of binding. The org owner will get his/her own actions if it is empty"
+// @Param	corporation_email		query 	string	false		"The email of corporation, it is ignored for corporation manager"
+// @Param	actor		query 	string	false		"The one who did the action"
+// @Param	action		query 	string	false		"The action"
+// @Param	from		query 	string	false		"The first date of action, such as 2020-01-01"
+// @Param	to		query 	string	false		"The last date of action, such as 2020-12-31"
+// @Param	cursor		query 	string	false		"The next_cursor of previous page"
+// @Param	limit		query 	int	false		"The max number of items per page"
+// @Param	sort		query 	string	false		"The field to sort by, created_at"
+// @Param	order		query 	string	false		"The sort order, asc or desc"
+// @Success 200 {object} controllers.pageResponse
+// @router / [get]
+func (this *AuditLogController) GetAll() {
+	var statusCode = 200
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	opt, err := this.parseListOption()
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	user, err := getApiAccessUser(&this.Controller)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if getApiAccessPermission(&this.Controller) == PermissionOwnerOfOrg {
+		// the org owner can only list his/her own actions which
+		// don't belong to any binding, such as creating cla.
+		if opt.CLAOrgID == "" {
+			opt.Actor = user
+		} else if _, err := checkOrgOwnershipOfBinding(&this.Controller, opt.CLAOrgID); err != nil {
+			reason = err
+			statusCode = 400
+			return
+		}
+	} else {
+		opt.CorporationID = util.EmailSuffixToKey(user)
+	}
+
+	r, err := opt.List()
+	if err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = pageResponse{PageInfo: r.PageInfo, Items: r.Logs}
+}
+
+func (this *AuditLogController) parseListOption() (models.AuditLogListOption, error) {
+	opt := models.AuditLogListOption{
+		CLAOrgID: this.GetString("cla_org_id"),
+		Actor:    this.GetString("actor"),
+		Action:   this.GetString("action"),
+	}
+
+	if v := this.GetString("corporation_email"); v != "" {
+		opt.CorporationID = util.EmailSuffixToKey(v)
+	}
+
+	var err error
+	if opt.Page, err = getPageOption(&this.Controller); err != nil {
+		return opt, err
+	}
+
+	if v := this.GetString("from"); v != "" {
+		t, err := time.ParseInLocation(dateLayout, v, time.Local)
+		if err != nil {
+			return opt, fmt.Errorf("invalid from: %s", err.Error())
+		}
+		opt.From = t.Unix()
+	}
+
+	if v := this.GetString("to"); v != "" {
+		t, err := time.ParseInLocation(dateLayout, v, time.Local)
+		if err != nil {
+			return opt, fmt.Errorf("invalid to: %s", err.Error())
+		}
+		// include the whole day
+		opt.To = t.AddDate(0, 0, 1).Unix() - 1
+	}
+
+	return opt, opt.Validate()
+}
+
+// addAuditLog records the action done by the user of token, or by log.Actor
+// if the api can be accessed without token or the token is not parsed by apiPrepare. The action has been done, so it
+// only logs the failure of recording.
+func addAuditLog(c *beego.Controller, log models.AuditLog, before, after interface{}) {
+	if log.Actor == "" {
+		log.Actor, _ = getApiAccessUser(c)
+	}
+	if log.Permission == "" {
+		log.Permission = getApiAccessPermission(c)
+	}
+	log.Before = auditValue(before)
+	log.After = auditValue(after)
+
+	if err := (&log).Create(); err != nil {
+		beego.Info(fmt.Sprintf("Failed to add audit log of %s by %s: %s", log.Action, log.Actor, err.Error()))
+	}
+}
+
+func auditValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
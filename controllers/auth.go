@@ -1,12 +1,15 @@
 package controllers
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/astaxie/beego"
 
 	platformAuth "github.com/zengchen1024/cla-server/code-platform-auth"
+	"github.com/zengchen1024/cla-server/models"
 )
 
 type AuthController struct {
@@ -62,17 +65,27 @@ func (this *AuthController) Auth() {
 		return
 	}
 
-	at, err := createApiAccessToken(
-		fmt.Sprintf("%s/%s", platform, user),
-		actionToPermission(purpose),
-	)
+	apiUser := fmt.Sprintf("%s/%s", platform, user)
+	permission := actionToPermission(purpose)
+
+	// The platform token of org owner is kept on the server and looked up by the
+	// user of session to check the ownership of org later.
+	if permission == PermissionOwnerOfOrg {
+		pt := models.PlatformToken{User: apiUser, Token: token}
+		if err := pt.Save(); err != nil {
+			sendResponse(&this.Controller, 500, err, nil)
+			return
+		}
+	}
+
+	at, rt, err := createApiAccessToken(apiUser, permission)
 	if err != nil {
 		sendResponse(&this.Controller, 500, err, nil)
 		return
 	}
 
 	this.Ctx.SetCookie("access_token", at, "3600", "/")
-	this.Ctx.SetCookie("platform_token", token, "3600", "/")
+	this.Ctx.SetCookie("refresh_token", rt, strconv.FormatInt(refreshTokenExpiry(), 10), "/")
 
 	http.Redirect(this.Ctx.ResponseWriter, this.Ctx.Request, cp.WebRedirectDir(), http.StatusFound)
 }
@@ -116,3 +129,76 @@ func (this *AuthController) Get() {
 		"url": cp.GetAuthCodeURL(authURLState),
 	}
 }
+
+type refreshTokenInfo struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// @Title Refresh
+// @Description get a new access token by the refresh token
+// @Param	body		body 	refreshTokenInfo	true		"body for refresh token"
+// @Success 201 {object}
+// @router /refresh [post]
+func (this *AuthController) Refresh() {
+	var statusCode = 201
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	var info refreshTokenInfo
+	if err := json.Unmarshal(this.Ctx.Input.RequestBody, &info); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	session := &models.RefreshToken{}
+	if err := session.Check(info.RefreshToken); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	at, err := refreshApiAccessToken(session)
+	if err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = map[string]string{
+		"token": at,
+	}
+}
+
+// @Title Logout
+// @Description revoke the session of current access token
+// @Success 202 {string} logout successfully
+// @router /logout [post]
+func (this *AuthController) Logout() {
+	var statusCode = 202
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	ac, err := parseApiAccessToken(&this.Controller, allPermissions)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := models.RevokeSession(ac.SessionID, ac.Expiry); err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = "logout successfully"
+}
@@ -45,6 +45,12 @@ func (this *CLAMetadataController) Post() {
 	}
 
 	body = data
+
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{Action: models.AuditActionCreateCLAMetadata, Actor: submitter, Target: data.ID},
+		nil, claMetadataAuditValue(&data),
+	)
 }
 
 // @Title Delete CLAMetadata
@@ -70,6 +76,11 @@ func (this *CLAMetadataController) Delete() {
 	}
 
 	data := models.CLAMetadata{ID: uid}
+	if err := (&data).Get(); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
 
 	if err := (&data).Delete(); err != nil {
 		reason = err
@@ -78,6 +89,26 @@ func (this *CLAMetadataController) Delete() {
 	}
 
 	body = "delete cla metadata successfully"
+
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{
+			Action: models.AuditActionDeleteCLAMetadata,
+			Actor:  getHeader(&this.Controller, headerUser),
+			Target: uid,
+		},
+		claMetadataAuditValue(&data), nil,
+	)
+}
+
+// claMetadataAuditValue returns the fields of cla metadata recorded by audit
+// log. The text is too long to be recorded.
+func claMetadataAuditValue(data *models.CLAMetadata) map[string]string {
+	return map[string]string{
+		"name":      data.Name,
+		"language":  data.Language,
+		"submitter": data.Submitter,
+	}
 }
 
 // @Title Get
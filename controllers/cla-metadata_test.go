@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/astaxie/beego"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/memorydb"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+func newCLAMetadataHandler() *beego.ControllerRegister {
+	db := memorydb.NewDatabase()
+	models.RegisterDB(db)
+	dbmodels.RegisterDB(db)
+
+	h := beego.NewControllerRegister()
+	h.Add("/v1/cla-metadata", &CLAMetadataController{}, "post:Post;get:GetAll")
+	h.Add("/v1/cla-metadata/:uid", &CLAMetadataController{}, "get:Get;delete:Delete")
+	return h
+}
+
+func serveRequest(t *testing.T, h http.Handler, method, url, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	r.Header.Set(headerUser, "github/alice")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestCLAMetadata(t *testing.T) {
+	beego.BConfig.CopyRequestBody = true
+	h := newCLAMetadataHandler()
+
+	w := serveRequest(t, h, "POST", "/v1/cla-metadata", `{"name":"cla","text":"text","language":"english"}`)
+	if w.Code != 201 {
+		t.Fatalf("create cla metadata: expect 201, but got %d: %s", w.Code, w.Body.String())
+	}
+
+	var data models.CLAMetadata
+	if err := json.Unmarshal(w.Body.Bytes(), &data); err != nil {
+		t.Fatalf("create cla metadata: invalid response: %v", err)
+	}
+	if data.ID == "" || data.Submitter != "github/alice" {
+		t.Fatalf("create cla metadata: unexpected response: %+v", data)
+	}
+
+	w = serveRequest(t, h, "GET", "/v1/cla-metadata", "")
+	var all []models.CLAMetadata
+	if err := json.Unmarshal(w.Body.Bytes(), &all); err != nil || len(all) != 1 || all[0].ID != data.ID {
+		t.Errorf("list cla metadata: unexpected response(%d): %s", w.Code, w.Body.String())
+	}
+
+	if w = serveRequest(t, h, "DELETE", "/v1/cla-metadata/"+data.ID, ""); w.Code != 204 {
+		t.Errorf("delete cla metadata: expect 204, but got %d: %s", w.Code, w.Body.String())
+	}
+	if w = serveRequest(t, h, "GET", "/v1/cla-metadata/"+data.ID, ""); w.Code == 200 {
+		t.Errorf("get deleted cla metadata: expect failure, but got %s", w.Body.String())
+	}
+	if w = serveRequest(t, h, "DELETE", "/v1/cla-metadata/"+data.ID, ""); w.Code != 400 {
+		t.Errorf("delete cla metadata not exist: expect 400, but got %d", w.Code)
+	}
+
+	logs, err := models.AuditLogListOption{Actor: "github/alice"}.List()
+	if err != nil {
+		t.Fatalf("list audit logs: %v", err)
+	}
+
+	actions := map[string]bool{}
+	for _, item := range logs.Logs {
+		actions[item.Action] = item.Target == data.ID
+	}
+	if !actions[models.AuditActionCreateCLAMetadata] || !actions[models.AuditActionDeleteCLAMetadata] {
+		t.Errorf("audit logs: expect creating and deleting of %s, but got %+v", data.ID, logs.Logs)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/astaxie/beego"
 
+	"github.com/zengchen1024/cla-server/code-platform-auth/platforms"
 	"github.com/zengchen1024/cla-server/models"
 )
 
@@ -44,6 +45,12 @@ func (this *CLAOrgController) Post() {
 		return
 	}
 
+	if err := checkOrgOwnership(&this.Controller, claOrg.Platform, claOrg.OrgID); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
 	cla := &models.CLA{ID: claOrg.CLAID}
 
 	if err := cla.Get(); err != nil {
@@ -65,6 +72,7 @@ func (this *CLAOrgController) Post() {
 	}
 
 	claOrg.CLALanguage = cla.Language
+	claOrg.CLAVersion = cla.Version
 	claOrg.ApplyTo = cla.ApplyTo
 
 	if err := (&claOrg).Create(); err != nil {
@@ -74,6 +82,12 @@ func (this *CLAOrgController) Post() {
 	}
 
 	body = claOrg
+
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{Action: models.AuditActionBindCLA, CLAOrgID: claOrg.ID, Target: claOrg.CLAID},
+		nil, claOrg,
+	)
 }
 
 // @Title Unbind CLA from Org/Repo
@@ -98,7 +112,12 @@ func (this *CLAOrgController) Delete() {
 		return
 	}
 
-	claOrg := models.CLAOrg{ID: uid}
+	claOrg, err := checkOrgOwnershipOfBinding(&this.Controller, uid)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
 
 	if err := claOrg.Delete(); err != nil {
 		reason = err
@@ -107,11 +126,230 @@ func (this *CLAOrgController) Delete() {
 	}
 
 	body = "unbinding successfully"
+
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{Action: models.AuditActionUnbindCLA, CLAOrgID: uid, Target: claOrg.CLAID},
+		claOrg, nil,
+	)
+}
+
+// @Title SetBotToken
+// @Description set the token used by bot to label pull requests and report the commit status
+// @Param	uid		path 	string	true		"The uid of binding"
+// @Param	body		body 	models.BotToken	true		"body for bot token"
+// @Success 202 {string} set bot token successfully
+// @router /:uid/bot-token [put]
+func (this *CLAOrgController) SetBotToken() {
+	var statusCode = 202
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	uid := this.GetString(":uid")
+	claOrg, err := checkOrgOwnershipOfBinding(&this.Controller, uid)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	var info models.BotToken
+	if err := json.Unmarshal(this.Ctx.Input.RequestBody, &info); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if info.Token == "" {
+		reason = fmt.Errorf("missing token")
+		statusCode = 400
+		return
+	}
+
+	p, err := platforms.NewPlatform(info.Token, "", claOrg.Platform)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if _, err := p.GetUser(); err != nil {
+		reason = fmt.Errorf("invalid bot token: %s", err.Error())
+		statusCode = 400
+		return
+	}
+
+	if err := info.Set(uid); err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = "set bot token successfully"
+
+	// the token is secret and must not be recorded
+	addAuditLog(&this.Controller, models.AuditLog{Action: models.AuditActionSetBotToken, CLAOrgID: uid}, nil, nil)
+}
+
+// @Title UpdateCLAVersion
+// @Description change the version of cla which the binding points to
+// @Param	uid		path 	string	true		"The uid of binding"
+// @Param	body		body 	controllers.claVersionOfBinding	true		"body for cla version"
+// @Success 202 {string} update cla version successfully
+// @router /:uid/cla-version [put]
+func (this *CLAOrgController) UpdateCLAVersion() {
+	var statusCode = 202
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	uid := this.GetString(":uid")
+	claOrg, err := checkOrgOwnershipOfBinding(&this.Controller, uid)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	var info claVersionOfBinding
+	if err := json.Unmarshal(this.Ctx.Input.RequestBody, &info); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	cla := &models.CLA{ID: claOrg.CLAID}
+	if err := cla.Get(); err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	if info.Version < 1 || info.Version > cla.Version {
+		reason = fmt.Errorf("the cla(id:%s) has no version %d", cla.ID, info.Version)
+		statusCode = 400
+		return
+	}
+
+	if err := claOrg.UpdateCLAVersion(info.Version); err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = "update cla version successfully"
+
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{Action: models.AuditActionUpdateBindingCLA, CLAOrgID: uid, Target: cla.ID},
+		claVersionOfBinding{Version: claOrg.CLAVersion}, info,
+	)
+}
+
+type claVersionOfBinding struct {
+	Version int `json:"version"`
+}
+
+// @Title SetDomainVerification
+// @Description set whether the corporation must verify its domain by DNS TXT record before being enabled
+// @Param	uid		path 	string	true		"The uid of binding"
+// @Param	body		body 	controllers.domainVerificationOfBinding	true		"body for domain verification"
+// @Success 202 {string} set domain verification successfully
+// @router /:uid/domain-verification [put]
+func (this *CLAOrgController) SetDomainVerification() {
+	var statusCode = 202
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	uid := this.GetString(":uid")
+	claOrg, err := checkOrgOwnershipOfBinding(&this.Controller, uid)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if claOrg.ApplyTo != models.ApplyToCorporation {
+		reason = fmt.Errorf("the binding is not for corporation")
+		statusCode = 400
+		return
+	}
+
+	var info domainVerificationOfBinding
+	if err := json.Unmarshal(this.Ctx.Input.RequestBody, &info); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := claOrg.UpdateDomainVerification(info.Required); err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = "set domain verification successfully"
+
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{Action: models.AuditActionSetDomainVerification, CLAOrgID: uid},
+		domainVerificationOfBinding{Required: claOrg.DomainVerificationRequired}, info,
+	)
+}
+
+type domainVerificationOfBinding struct {
+	Required bool `json:"required"`
+}
+
+// @Title ListRevokedSigning
+// @Description list the tombstones of revoked individual and employee signings
+// @Param	uid		path 	string	true		"The uid of binding"
+// @Success 200 {object} dbmodels.RevokedSigning
+// @router /:uid/revoked-signings [get]
+func (this *CLAOrgController) ListRevokedSigning() {
+	var statusCode = 200
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	uid := this.GetString(":uid")
+	if _, err := checkOrgOwnershipOfBinding(&this.Controller, uid); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	r, err := models.ListRevokedSigning(uid)
+	if err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = r
 }
 
 // @Title GetAll
 // @Description get all bindings
-// @Success 200 {object} models.CLAOrg
+// @Param	cursor		query 	string	false		"The next_cursor of previous page"
+// @Param	limit		query 	int	false		"The max number of items per page"
+// @Param	sort		query 	string	false		"The field to sort by, repo_id or cla_language"
+// @Param	order		query 	string	false		"The sort order, asc or desc"
+// @Success 200 {object} controllers.pageResponse
 // @router / [get]
 func (this *CLAOrgController) GetAll() {
 	var statusCode = 200
@@ -122,11 +360,44 @@ func (this *CLAOrgController) GetAll() {
 		sendResponse(&this.Controller, statusCode, reason, body)
 	}()
 
+	page, err := getPageOption(&this.Controller)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
 	opt := models.CLAOrgListOption{
 		Platform: this.GetString("platform"),
 		OrgID:    this.GetString("org_id"),
 		RepoID:   this.GetString("repo_id"),
 		ApplyTo:  this.GetString("apply_to"),
+		Page:     page,
+	}
+	if err := opt.Validate(); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	platform, orgs, err := listOrgsOfUser(&this.Controller)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+	if opt.Platform != platform || (opt.OrgID != "" && !orgs[opt.OrgID]) {
+		reason = fmt.Errorf("the user is not the owner of org: %s/%s", opt.Platform, opt.OrgID)
+		statusCode = 400
+		return
+	}
+
+	if opt.OrgID == "" {
+		// only the bindings of orgs owned by the user are listed
+		opt.OrgIDs = make([]string, 0, len(orgs))
+		for k := range orgs {
+			opt.OrgIDs = append(opt.OrgIDs, k)
+		}
 	}
 
 	r, err := opt.List()
@@ -136,7 +407,7 @@ func (this *CLAOrgController) GetAll() {
 		return
 	}
 
-	body = r
+	body = pageResponse{PageInfo: r.PageInfo, Items: r.Bindings}
 }
 
 // @Title GetSigningPageInfo
@@ -167,42 +438,34 @@ func (this *CLAOrgController) GetSigningPageInfo() {
 		ApplyTo:  this.GetString(":apply_to"),
 	}
 
-	claOrgs, err := opt.List()
+	r, err := opt.List()
 	if err != nil {
 		reason = err
 		statusCode = 500
 		return
 	}
+
+	claOrgs := r.Bindings
 	if len(claOrgs) == 0 {
 		reason = fmt.Errorf("this org has no bound cla")
 		statusCode = 500
 		return
 	}
 
-	ids := make([]string, 0, len(claOrgs))
-	m := map[string]string{}
 	for _, i := range claOrgs {
 		if i.ApplyTo == models.ApplyToCorporation && !i.OrgSignatureUploaded {
 			reason = fmt.Errorf("this org is not ready to sign cla")
 			statusCode = 500
 			return
 		}
-
-		ids = append(ids, i.CLAID)
-		m[i.CLAID] = i.ID
 	}
 
-	clas, err := models.ListCLAByIDs(ids)
+	clas, err := models.ListCLAOfBindings(claOrgs)
 	if err != nil {
 		reason = err
 		statusCode = 500
 		return
 	}
 
-	result := map[string]interface{}{}
-	for _, i := range clas {
-		result[m[i.ID]] = i
-	}
-
-	body = result
+	body = clas
 }
@@ -39,6 +39,12 @@ func (this *CLAController) Post() {
 		return
 	}
 
+	if err := (&cla).Validate(); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
 	user, err := getApiAccessUser(&this.Controller)
 	if err != nil {
 		reason = err
@@ -54,6 +60,83 @@ func (this *CLAController) Post() {
 	}
 
 	body = cla
+
+	addAuditLog(&this.Controller, models.AuditLog{Action: models.AuditActionCreateCLA, Target: cla.ID}, nil, claAuditValue(&cla))
+}
+
+// @Title PublishVersion
+// @Description publish a new version of cla
+// @Param	uid		path 	string	true		"cla id"
+// @Param	body		body 	controllers.claVersionCreateOption	true		"body for cla version"
+// @Success 201 {int} controllers.claVersionCreateOption
+// @router /:uid/versions [post]
+func (this *CLAController) PublishVersion() {
+	var statusCode = 201
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	var info claVersionCreateOption
+	if err := json.Unmarshal(this.Ctx.Input.RequestBody, &info); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if info.Text == "" {
+		reason = fmt.Errorf("missing text of cla")
+		statusCode = 400
+		return
+	}
+
+	user, err := getApiAccessUser(&this.Controller)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	cla := &models.CLA{ID: this.GetString(":uid")}
+	if err := cla.Get(); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if cla.Submitter != user {
+		reason = fmt.Errorf("only the submitter of cla can publish new version")
+		statusCode = 400
+		return
+	}
+
+	v, err := cla.PublishVersion(info.Text, info.RequireResign)
+	if err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	info.Version = v
+	body = info
+
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{Action: models.AuditActionPublishCLAVersion, Target: cla.ID},
+		map[string]int{"version": cla.Version}, map[string]interface{}{
+			"version": v, "require_resign": info.RequireResign,
+		},
+	)
+}
+
+// claVersionCreateOption is the new version of cla. The binding will not point
+// to it until its cla version is updated.
+type claVersionCreateOption struct {
+	Version       int    `json:"version"`
+	Text          string `json:"text"`
+	RequireResign bool   `json:"require_resign"`
 }
 
 // @Title Delete CLA
@@ -79,6 +162,11 @@ func (this *CLAController) Delete() {
 	}
 
 	cla := models.CLA{ID: uid}
+	if err := (&cla).Get(); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
 
 	if err := (&cla).Delete(); err != nil {
 		reason = err
@@ -87,6 +175,18 @@ func (this *CLAController) Delete() {
 	}
 
 	body = "delete cla successfully"
+
+	addAuditLog(&this.Controller, models.AuditLog{Action: models.AuditActionDeleteCLA, Target: uid}, claAuditValue(&cla), nil)
+}
+
+// claAuditValue returns the fields of cla recorded by audit log. The text is
+// too long to be recorded.
+func claAuditValue(cla *models.CLA) map[string]string {
+	return map[string]string{
+		"name":     cla.Name,
+		"language": cla.Language,
+		"apply_to": cla.ApplyTo,
+	}
 }
 
 // @Title Get
@@ -124,7 +224,11 @@ func (this *CLAController) Get() {
 
 // @Title GetAllCLA
 // @Description get all clas
-// @Success 200 {object} models.CLA
+// @Param	cursor		query 	string	false		"The next_cursor of previous page"
+// @Param	limit		query 	int	false		"The max number of items per page"
+// @Param	sort		query 	string	false		"The field to sort by, name or language"
+// @Param	order		query 	string	false		"The sort order, asc or desc"
+// @Success 200 {object} controllers.pageResponse
 // @router / [get]
 func (this *CLAController) GetAll() {
 	var statusCode = 200
@@ -142,11 +246,24 @@ func (this *CLAController) GetAll() {
 		return
 	}
 
+	page, err := getPageOption(&this.Controller)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
 	clas := models.CLAListOptions{
 		Submitter: user,
 		Name:      this.GetString("name"),
 		ApplyTo:   this.GetString("apply_to"),
 		Language:  this.GetString("language"),
+		Page:      page,
+	}
+	if err := clas.Validate(); err != nil {
+		reason = err
+		statusCode = 400
+		return
 	}
 
 	r, err := clas.Get()
@@ -156,5 +273,5 @@ func (this *CLAController) GetAll() {
 		return
 	}
 
-	body = r
+	body = pageResponse{PageInfo: r.PageInfo, Items: r.CLAs}
 }
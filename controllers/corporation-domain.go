@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/astaxie/beego"
+
+	"github.com/zengchen1024/cla-server/models"
+	"github.com/zengchen1024/cla-server/util"
+)
+
+// dnsResolver looks up the TXT record when verifying the domain of corporation.
+// It can be replaced in tests.
+var dnsResolver = models.DefaultDNSResolver()
+
+type CorporationDomainController struct {
+	beego.Controller
+}
+
+func (this *CorporationDomainController) Prepare() {
+	apiPrepare(&this.Controller, []string{PermissionCorporAdmin})
+}
+
+// @Title GetAll
+// @Description list the domains claimed by the corporation
+// @Param	cla_org_id		query 	string	true		"The id of binding"
+// @Success 200 {object} models.CorporationDomain
+// @router / [get]
+func (this *CorporationDomainController) GetAll() {
+	var statusCode = 200
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	claOrgID := this.GetString("cla_org_id")
+	if claOrgID == "" {
+		reason = fmt.Errorf("missing cla_org_id")
+		statusCode = 400
+		return
+	}
+
+	user, err := getApiAccessUser(&this.Controller)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	r, err := models.ListCorporationDomain(claOrgID, user)
+	if err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = r
+}
+
+// @Title Post
+// @Description claim a domain for the corporation. The TXT record returned must be
+// @Description added to the DNS of domain before verifying it.
+// @Param	body		body 	models.CorporationDomainOption	true		"body for domain"
+// @Success 201 {object} models.CorporationDomain
+// @router / [post]
+func (this *CorporationDomainController) Post() {
+	var statusCode = 201
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	info, user, err := this.parseDomainOption()
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	r, err := info.Add(user)
+	if err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = r
+
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{
+			Action:        models.AuditActionAddCorporationDomain,
+			CLAOrgID:      info.CLAOrgID,
+			CorporationID: util.EmailSuffixToKey(user),
+			Target:        info.Domain,
+		},
+		nil, nil,
+	)
+}
+
+// @Title Verify
+// @Description verify the domain by its DNS TXT record
+// @Param	body		body 	models.CorporationDomainOption	true		"body for domain"
+// @Success 202 {string} verify domain successfully
+// @router / [put]
+func (this *CorporationDomainController) Verify() {
+	var statusCode = 202
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	info, user, err := this.parseDomainOption()
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := info.Verify(user, dnsResolver); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	body = "verify domain successfully"
+
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{
+			Action:        models.AuditActionVerifyCorporationDomain,
+			CLAOrgID:      info.CLAOrgID,
+			CorporationID: util.EmailSuffixToKey(user),
+			Target:        info.Domain,
+		},
+		nil, map[string]bool{"verified": true},
+	)
+}
+
+func (this *CorporationDomainController) parseDomainOption() (models.CorporationDomainOption, string, error) {
+	var info models.CorporationDomainOption
+	if err := json.Unmarshal(this.Ctx.Input.RequestBody, &info); err != nil {
+		return info, "", err
+	}
+
+	if err := (&info).Validate(); err != nil {
+		return info, "", err
+	}
+
+	user, err := getApiAccessUser(&this.Controller)
+	return info, user, err
+}
@@ -8,7 +8,9 @@ import (
 	"github.com/astaxie/beego"
 	"github.com/huaweicloud/golangsdk"
 
+	"github.com/zengchen1024/cla-server/email"
 	"github.com/zengchen1024/cla-server/models"
+	"github.com/zengchen1024/cla-server/util"
 )
 
 type CorporationManagerController struct {
@@ -19,10 +21,15 @@ func (this *CorporationManagerController) Prepare() {
 	method := this.Ctx.Request.Method
 
 	if method == http.MethodPost {
-		if getRouterPattern(&this.Controller) == "/v1/corporation-manager/auth" {
+		switch getRouterPattern(&this.Controller) {
+		case "/v1/corporation-manager/auth",
+			"/v1/corporation-manager/password-retrieval",
+			"/v1/corporation-manager/password-retrieval/reset":
 			return
 		}
 		apiPrepare(&this.Controller, []string{PermissionOwnerOfOrg})
+	} else if method == http.MethodPut {
+		apiPrepare(&this.Controller, []string{PermissionCorporAdmin, PermissionEmployeeManager, PermissionPasswordChanger})
 	} else {
 		apiPrepare(&this.Controller, []string{PermissionCorporAdmin, PermissionEmployeeManager})
 	}
@@ -50,13 +57,27 @@ func (this *CorporationManagerController) Post() {
 		return
 	}
 
-	if err := (&info).Create(); err != nil {
+	pw, err := (&info).Create()
+	if err != nil {
 		reason = err
 		statusCode = 500
 		return
 	}
 
 	body = "add manager successfully"
+
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{
+			Action:        models.AuditActionAddCorporationManager,
+			CLAOrgID:      info.CLAOrgID,
+			CorporationID: util.EmailSuffixToKey(info.Email),
+			Target:        info.Email,
+		},
+		nil, nil,
+	)
+
+	notifyManagers(info.CLAOrgID, map[string]string{info.Email: pw})
 }
 
 // @Title authenticate corporation manager
@@ -95,7 +116,12 @@ func (this *CorporationManagerController) Auth() {
 
 	result := make([]map[string]interface{}, 0, len(v))
 	for _, item := range v {
-		token, err := createApiAccessToken(item.Email, corporRoleToPermission(item.Role))
+		permission := corporRoleToPermission(item.Role)
+		if item.MustChangePassword {
+			permission = PermissionPasswordChanger
+		}
+
+		token, refreshToken, err := createApiAccessToken(item.Email, permission)
 		if err != nil {
 			continue
 		}
@@ -106,6 +132,7 @@ func (this *CorporationManagerController) Auth() {
 		}
 
 		m["token"] = token
+		m["refresh_token"] = refreshToken
 		result = append(result, m)
 	}
 	body = result
@@ -133,6 +160,12 @@ func (this *CorporationManagerController) Update() {
 		return
 	}
 
+	if err := (&info).Validate(); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
 	if err := (&info).Reset(); err != nil {
 		reason = err
 		statusCode = 500
@@ -140,4 +173,126 @@ func (this *CorporationManagerController) Update() {
 	}
 
 	body = "reset password successfully"
+
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{
+			Action:        models.AuditActionChangePassword,
+			CLAOrgID:      info.CLAOrgID,
+			CorporationID: util.EmailSuffixToKey(info.Email),
+			Target:        info.Email,
+		},
+		nil, nil,
+	)
+}
+
+// @Title Retrieve password
+// @Description send the token to reset password by email
+// @Param	body		body 	models.CorporationManagerPasswordRetrieval	true		"body for retrieving password"
+// @Success 201 {int} map
+// @Failure 403 body is empty
+// @router /password-retrieval [post]
+func (this *CorporationManagerController) RetrievePassword() {
+	var statusCode = 201
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	var info models.CorporationManagerPasswordRetrieval
+	if err := json.Unmarshal(this.Ctx.Input.RequestBody, &info); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	claOrg := &models.CLAOrg{ID: info.CLAOrgID}
+	if err := claOrg.Get(); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	ip := clientIP(&this.Controller)
+	if err := info.CheckFrequency(ip, verifiCodeResendInterval()); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	expiry := beego.AppConfig.DefaultInt64("password_retrieval_token_expiry", defaultPasswordRetrievalExpiry)
+	token, err := info.Create(ip, expiry)
+	if err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	// don't tell whether the manager exists
+	body = "the token to reset password has been sent if the manager exists"
+
+	if token == "" {
+		return
+	}
+
+	data := email.EmailTemplateData{
+		CLAOrg: claOrg,
+		Email:  info.Email,
+		Code:   token,
+	}
+	// the failure is only logged, otherwise it tells the manager exists
+	if err := sendEmail(models.EmailTemplatePasswordRetrieval, &data); err != nil {
+		beego.Info(fmt.Sprintf("Failed to send the token to reset password by email: %s", err.Error()))
+	}
+}
+
+// @Title Reset retrieved password
+// @Description reset password with the token sent by email
+// @Param	body		body 	models.CorporationManagerPasswordRetrievalReset	true		"body for resetting password"
+// @Success 201 {int} map
+// @Failure 403 body is empty
+// @router /password-retrieval/reset [post]
+func (this *CorporationManagerController) ResetRetrievedPassword() {
+	var statusCode = 201
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	var info models.CorporationManagerPasswordRetrievalReset
+	if err := json.Unmarshal(this.Ctx.Input.RequestBody, &info); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := info.Validate(verifiCodeMaxAttempts()); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := info.Reset(); err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = "reset password successfully"
+
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{
+			Actor:         info.Email,
+			Action:        models.AuditActionResetPassword,
+			CLAOrgID:      info.CLAOrgID,
+			CorporationID: util.EmailSuffixToKey(info.Email),
+			Target:        info.Email,
+		},
+		nil, nil,
+	)
 }
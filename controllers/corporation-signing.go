@@ -9,6 +9,7 @@ import (
 
 	"github.com/zengchen1024/cla-server/email"
 	"github.com/zengchen1024/cla-server/models"
+	"github.com/zengchen1024/cla-server/util"
 	"github.com/zengchen1024/cla-server/worker"
 )
 
@@ -46,7 +47,10 @@ func (this *CorporationSigningController) Post() {
 		return
 	}
 
-	if err := (&info).Validate(); err != nil {
+	if err := (&info).Validate(verifiCodeMaxAttempts()); err != nil {
+		reason = err
+		statusCode = 400
+		return
 	}
 
 	claOrg := &models.CLAOrg{ID: info.CLAOrgID}
@@ -70,7 +74,7 @@ func (this *CorporationSigningController) Post() {
 		return
 	}
 
-	if err := (&info).Create(); err != nil {
+	if err := (&info).Create(signingMetadata(&this.Controller)); err != nil {
 		reason = err
 		statusCode = 500
 		return
@@ -78,12 +82,18 @@ func (this *CorporationSigningController) Post() {
 
 	body = "sign successfully"
 
-	worker.GetEmailWorker().GenCLAPDFForCorporationAndSendIt(claOrg, &info.CorporationSigning, cla, emailInfo)
+	if err := worker.GetEmailWorker().GenCLAPDFForCorporationAndSendIt(claOrg, &info.CorporationSigning); err != nil {
+		beego.Info(err)
+	}
 }
 
 // @Title GetAll
 // @Description get all the corporations which have signed to a org
-// @Success 200 {object} dbmodels.CorporationSigningInfo
+// @Param	cursor		query 	string	false		"The next_cursor of previous page"
+// @Param	limit		query 	int	false		"The max number of items per page"
+// @Param	sort		query 	string	false		"The field to sort by, signed_at, corporation_name or admin_email"
+// @Param	order		query 	string	false		"The sort order, asc or desc"
+// @Success 200 {object} controllers.pageResponse
 // @router / [get]
 func (this *CorporationSigningController) GetAll() {
 	var statusCode = 200
@@ -94,11 +104,30 @@ func (this *CorporationSigningController) GetAll() {
 		sendResponse(&this.Controller, statusCode, reason, body)
 	}()
 
+	page, err := getPageOption(&this.Controller)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
 	opt := models.CorporationSigningListOption{
 		Platform:    this.GetString("platform"),
 		OrgID:       this.GetString("org_id"),
 		RepoID:      this.GetString("repo_id"),
 		CLALanguage: this.GetString("cla_language"),
+		Page:        page,
+	}
+	if err := opt.Validate(); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := checkOrgOwnership(&this.Controller, opt.Platform, opt.OrgID); err != nil {
+		reason = err
+		statusCode = 400
+		return
 	}
 
 	r, err := opt.List()
@@ -108,7 +137,7 @@ func (this *CorporationSigningController) GetAll() {
 		return
 	}
 
-	body = r
+	body = pageResponse{PageInfo: r.PageInfo, Items: r.Signings}
 }
 
 // @Title Enable corporation signing
@@ -133,6 +162,18 @@ func (this *CorporationSigningController) Update() {
 		return
 	}
 
+	if _, err := checkOrgOwnershipOfBinding(&this.Controller, info.CLAOrgID); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := (&info).CheckEnabling(); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
 	if err := (&info).Update(); err != nil {
 		reason = err
 		statusCode = 500
@@ -140,6 +181,21 @@ func (this *CorporationSigningController) Update() {
 	}
 
 	body = "enabled corporation successfully"
+
+	action := models.AuditActionDisableCorporation
+	if info.Enabled {
+		action = models.AuditActionEnableCorporation
+	}
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{
+			Action:        action,
+			CLAOrgID:      info.CLAOrgID,
+			CorporationID: util.EmailSuffixToKey(info.AdminEmail),
+			Target:        info.CorporationName,
+		},
+		map[string]bool{"enabled": !info.Enabled}, map[string]bool{"enabled": info.Enabled},
+	)
 }
 
 // @Title send verification code when signing as Corporation
@@ -164,47 +220,46 @@ func (this *CorporationSigningController) SendVerifiCode() {
 		return
 	}
 
-	claOrg := &models.CLAOrg{ID: info.CLAOrgID}
-	if err := claOrg.Get(); err != nil {
+	if err := info.Validate(); err != nil {
 		reason = err
 		statusCode = 400
 		return
 	}
 
-	emailCfg := &models.OrgEmail{Email: claOrg.OrgEmail}
-	if err := emailCfg.Get(); err != nil {
+	claOrg := &models.CLAOrg{ID: info.CLAOrgID}
+	if err := claOrg.Get(); err != nil {
 		reason = err
 		statusCode = 400
 		return
 	}
 
-	ec, err := email.GetEmailClient(emailCfg.Platform)
+	expiry, err := beego.AppConfig.Int64("verification_vode_expiry")
 	if err != nil {
-		reason = fmt.Errorf("Failtd to get email client: %s", err.Error())
-		statusCode = 500
+		reason = err
+		statusCode = 400
 		return
 	}
 
-	expiry, err := beego.AppConfig.Int64("verification_vode_expiry")
-	if err != nil {
+	ip := clientIP(&this.Controller)
+	if err := info.CheckFrequency(ip, verifiCodeResendInterval()); err != nil {
 		reason = err
 		statusCode = 400
 		return
 	}
 
-	code, err := info.Create(expiry)
+	code, err := info.Create(ip, expiry)
 	if err != nil {
 		reason = err
 		statusCode = 500
 		return
 	}
 
-	msg := email.EmailMessage{
-		To:      info.Email,
-		Content: code,
-		Subject: "verification code",
+	data := email.EmailTemplateData{
+		CLAOrg: claOrg,
+		Email:  info.Email,
+		Code:   code,
 	}
-	if err := ec.SendEmail(*emailCfg.Token, msg); err != nil {
+	if err := sendEmail(models.EmailTemplateVerificationCode, &data); err != nil {
 		reason = fmt.Errorf("Failed to send verification code by email: %s", err.Error())
 		statusCode = 500
 		return
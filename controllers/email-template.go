@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/astaxie/beego"
+
+	"github.com/zengchen1024/cla-server/email"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+type EmailTemplateController struct {
+	beego.Controller
+}
+
+func (this *EmailTemplateController) Prepare() {
+	apiPrepare(&this.Controller, []string{PermissionOwnerOfOrg})
+}
+
+type emailTemplateDetail struct {
+	models.EmailTemplate
+
+	// Customized tells whether the template is customized for the binding or the default one
+	Customized bool `json:"customized"`
+}
+
+// @Title GetAll
+// @Description get the email templates used by the binding
+// @Param	cla_org_id		path 	string	true		"The id of binding between cla and org"
+// @Success 200 {object} emailTemplateDetail
+// @router /:cla_org_id [get]
+func (this *EmailTemplateController) GetAll() {
+	var statusCode = 200
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	claOrgID := this.GetString(":cla_org_id")
+	claOrg, err := checkOrgOwnershipOfBinding(&this.Controller, claOrgID)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	v, err := models.ListEmailTemplate(claOrgID)
+	if err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	r := make(map[string]emailTemplateDetail, len(models.EmailTemplateKinds))
+	for _, kind := range models.EmailTemplateKinds {
+		if t, ok := v[kind]; ok {
+			r[kind] = emailTemplateDetail{EmailTemplate: t, Customized: true}
+			continue
+		}
+
+		t, err := email.DefaultEmailTemplate(kind, claOrg.CLALanguage)
+		if err != nil {
+			reason = err
+			statusCode = 500
+			return
+		}
+		r[kind] = emailTemplateDetail{EmailTemplate: t}
+	}
+
+	body = r
+}
+
+// @Title Update
+// @Description customize the email template of binding
+// @Param	cla_org_id		path 	string	true		"The id of binding between cla and org"
+// @Param	kind		path 	string	true		"The kind of email template"
+// @Param	body		body 	models.EmailTemplate	true		"body for email template"
+// @Success 202 {string} update email template successfully
+// @router /:cla_org_id/:kind [put]
+func (this *EmailTemplateController) Update() {
+	var statusCode = 202
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	claOrgID, kind, err := this.checkParameter()
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	var info models.EmailTemplate
+	if err := json.Unmarshal(this.Ctx.Input.RequestBody, &info); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := email.ValidateEmailTemplate(&info); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	before, err := models.ListEmailTemplate(claOrgID)
+	if err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	if err := info.Set(claOrgID, kind); err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = "update email template successfully"
+
+	var old interface{}
+	if t, ok := before[kind]; ok {
+		old = t
+	}
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{Action: models.AuditActionSetEmailTemplate, CLAOrgID: claOrgID, Target: kind},
+		old, info,
+	)
+}
+
+// @Title Delete
+// @Description delete the customized email template and the default one will be used
+// @Param	cla_org_id		path 	string	true		"The id of binding between cla and org"
+// @Param	kind		path 	string	true		"The kind of email template"
+// @Success 204 {string} delete success!
+// @router /:cla_org_id/:kind [delete]
+func (this *EmailTemplateController) Delete() {
+	var statusCode = 204
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	claOrgID, kind, err := this.checkParameter()
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	before, err := models.ListEmailTemplate(claOrgID)
+	if err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	if err := models.DeleteEmailTemplate(claOrgID, kind); err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = "delete email template successfully"
+
+	var old interface{}
+	if t, ok := before[kind]; ok {
+		old = t
+	}
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{Action: models.AuditActionDeleteEmailTemplate, CLAOrgID: claOrgID, Target: kind},
+		old, nil,
+	)
+}
+
+func (this *EmailTemplateController) checkParameter() (string, string, error) {
+	claOrgID := this.GetString(":cla_org_id")
+	kind := this.GetString(":kind")
+
+	if !models.IsValidEmailTemplateKind(kind) {
+		return "", "", fmt.Errorf("unknown kind of email template: %s", kind)
+	}
+
+	_, err := checkOrgOwnershipOfBinding(&this.Controller, claOrgID)
+	return claOrgID, kind, err
+}
@@ -52,13 +52,20 @@ func (this *EmailController) Post() {
 		return
 	}
 
-	if err := e.SendEmail(*cfg.Token, msg); err != nil {
+	if err := e.SendEmail(cfg, msg); err != nil {
 		reason = fmt.Errorf("Failed to send email: %s", err.Error())
 		statusCode = 500
 		return
 	}
 
 	body = "send email successfully"
+
+	// the content may be private and is not recorded
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{Action: models.AuditActionSendEmail, Actor: msg.From, Target: msg.To},
+		nil, map[string]string{"from": msg.From, "to": msg.To, "subject": msg.Subject},
+	)
 }
 
 // @Title Get
@@ -106,6 +113,8 @@ func (this *EmailController) Auth() {
 		return
 	}
 	opt.Platform = platform
+	// the email is authorized by itself
+	opt.Submitter = opt.Email
 
 	if err = opt.Create(); err != nil {
 		sendResponse(&this.Controller, 500, err, nil)
@@ -117,6 +126,78 @@ func (this *EmailController) Auth() {
 	http.Redirect(this.Ctx.ResponseWriter, this.Ctx.Request, e.WebRedirectDir(), http.StatusFound)
 }
 
+// @Title Add SMTP email
+// @Description add the org email which sends email by SMTP server
+// @Param	body		body 	models.OrgEmail	true		"body for smtp email"
+// @Success 201 {string} add email successfully
+// @router /smtp [post]
+func (this *EmailController) AddSMTPEmail() {
+	var statusCode = 201
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	user, err := checkApiAccessToken(&this.Controller, []string{PermissionOwnerOfOrg})
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	var info models.OrgEmail
+	if err := json.Unmarshal(this.Ctx.Input.RequestBody, &info); err != nil {
+		reason = fmt.Errorf("Parse email parameter failed: %s", err.Error())
+		statusCode = 400
+		return
+	}
+
+	if info.Email == "" || info.SMTP == nil {
+		reason = fmt.Errorf("missing email or smtp credential")
+		statusCode = 400
+		return
+	}
+	info.Platform = models.EmailPlatformSMTP
+	info.Token = nil
+	info.Submitter = user
+
+	// the credential of email can only be updated by who added it
+	existing := models.OrgEmail{Email: info.Email}
+	if err := existing.Get(); err == nil && existing.Submitter != user {
+		reason = fmt.Errorf("the email has been added by others")
+		statusCode = 403
+		return
+	}
+
+	if err := email.VerifySMTPCredential(info.SMTP); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := (&info).Create(); err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = "add email successfully"
+
+	// the credential is secret and must not be recorded
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{
+			Actor:      user,
+			Permission: PermissionOwnerOfOrg,
+			Action:     models.AuditActionAddOrgEmail,
+			Target:     info.Email,
+		},
+		nil, nil,
+	)
+}
+
 // @Title Get
 // @Description get auth code url
 // @Param	platform		path 	string	true		"The email platform"
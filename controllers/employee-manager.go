@@ -6,6 +6,7 @@ import (
 	"github.com/astaxie/beego"
 
 	"github.com/zengchen1024/cla-server/models"
+	"github.com/zengchen1024/cla-server/util"
 )
 
 type EmployeeManagerController struct {
@@ -44,18 +45,27 @@ func (this *EmployeeManagerController) Post() {
 		return
 	}
 
-	if err := (&info).Create(); err != nil {
+	pws, err := (&info).Create()
+	if err != nil {
 		reason = err
 		statusCode = 500
 		return
 	}
 
 	body = "add employee manager successfully"
+
+	this.addAuditLog(models.AuditActionAddEmployeeManager, &info)
+
+	notifyManagers(info.CLAOrgID, pws)
 }
 
 // @Title GetAll
 // @Description get all employee managers
-// @Success 200 {object} dbmodels.CorporationManagerListResult
+// @Param	cursor		query 	string	false		"The next_cursor of previous page"
+// @Param	limit		query 	int	false		"The max number of items per page"
+// @Param	sort		query 	string	false		"The field to sort by, email"
+// @Param	order		query 	string	false		"The sort order, asc or desc"
+// @Success 200 {object} controllers.pageResponse
 // @router / [get]
 func (this *EmployeeManagerController) GetAll() {
 	var statusCode = 200
@@ -66,10 +76,23 @@ func (this *EmployeeManagerController) GetAll() {
 		sendResponse(&this.Controller, statusCode, reason, body)
 	}()
 
+	page, err := getPageOption(&this.Controller)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
 	opt := models.CorporationManagerListOption{
 		CLAOrgID: this.GetString("cla_org_id"),
 		Email:    this.GetString("email"),
 		Role:     models.RoleManager,
+		Page:     page,
+	}
+	if err := opt.Validate(); err != nil {
+		reason = err
+		statusCode = 400
+		return
 	}
 
 	r, err := opt.List()
@@ -79,7 +102,7 @@ func (this *EmployeeManagerController) GetAll() {
 		return
 	}
 
-	body = r
+	body = pageResponse{PageInfo: r.PageInfo, Items: r.Managers}
 }
 
 // @Title Delete
@@ -117,4 +140,21 @@ func (this *EmployeeManagerController) Delete() {
 	}
 
 	body = "delete cla successfully"
+
+	this.addAuditLog(models.AuditActionDeleteEmployeeManager, &info)
+}
+
+func (this *EmployeeManagerController) addAuditLog(action string, info *models.EmployeeManagerCreateOption) {
+	for _, item := range info.Emails {
+		addAuditLog(
+			&this.Controller,
+			models.AuditLog{
+				Action:        action,
+				CLAOrgID:      info.CLAOrgID,
+				CorporationID: util.EmailSuffixToKey(item),
+				Target:        item,
+			},
+			nil, nil,
+		)
+	}
 }
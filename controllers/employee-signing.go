@@ -7,7 +7,9 @@ import (
 
 	"github.com/astaxie/beego"
 
+	"github.com/zengchen1024/cla-server/email"
 	"github.com/zengchen1024/cla-server/models"
+	"github.com/zengchen1024/cla-server/util"
 )
 
 type EmployeeSigningController struct {
@@ -15,9 +17,16 @@ type EmployeeSigningController struct {
 }
 
 func (this *EmployeeSigningController) Prepare() {
-	if this.Ctx.Request.Method == http.MethodPost {
+	switch this.Ctx.Request.Method {
+	case http.MethodPost:
 		apiPrepare(&this.Controller, []string{PermissionIndividualSigner})
-	} else {
+	case http.MethodGet:
+		apiPrepare(&this.Controller, []string{PermissionEmployeeManager, PermissionOwnerOfOrg})
+	case http.MethodDelete:
+		apiPrepare(&this.Controller, []string{
+			PermissionIndividualSigner, PermissionEmployeeManager, PermissionOwnerOfOrg,
+		})
+	default:
 		apiPrepare(&this.Controller, []string{PermissionEmployeeManager})
 	}
 }
@@ -44,6 +53,12 @@ func (this *EmployeeSigningController) Post() {
 		return
 	}
 
+	if err := (&info).Validate(); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
 	claOrg := &models.CLAOrg{ID: info.CLAOrgID}
 	if err := claOrg.Get(); err != nil {
 		reason = err
@@ -57,12 +72,14 @@ func (this *EmployeeSigningController) Post() {
 		RepoID:   claOrg.RepoID,
 		ApplyTo:  models.ApplyToCorporation,
 	}
-	claOrgs, err := opt.List()
+	r, err := opt.List()
 	if err != nil {
 		reason = err
 		statusCode = 500
 		return
 	}
+
+	claOrgs := r.Bindings
 	if len(claOrgs) == 0 {
 		reason = fmt.Errorf("this org has not been bound any cla to be signed as corporation")
 		statusCode = 400
@@ -85,7 +102,7 @@ func (this *EmployeeSigningController) Post() {
 		return
 	}
 
-	if err := (&info).Create(); err != nil {
+	if err := (&info).Create(signingMetadata(&this.Controller)); err != nil {
 		reason = err
 		statusCode = 500
 		return
@@ -96,7 +113,11 @@ func (this *EmployeeSigningController) Post() {
 
 // @Title GetAll
 // @Description get all the employees
-// @Success 200 {object} models.EmployeeSigning
+// @Param	cursor		query 	string	false		"The next_cursor of previous page"
+// @Param	limit		query 	int	false		"The max number of items per page"
+// @Param	sort		query 	string	false		"The field to sort by, signed_at, email or name"
+// @Param	order		query 	string	false		"The sort order, asc or desc"
+// @Success 200 {object} controllers.pageResponse
 // @router / [get]
 func (this *EmployeeSigningController) GetAll() {
 	var statusCode = 200
@@ -107,12 +128,43 @@ func (this *EmployeeSigningController) GetAll() {
 		sendResponse(&this.Controller, statusCode, reason, body)
 	}()
 
+	page, err := getPageOption(&this.Controller)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
 	opt := models.EmployeeSigningListOption{
 		Platform:         this.GetString("platform"),
 		OrgID:            this.GetString("org_id"),
 		RepoID:           this.GetString("repo_id"),
 		CLALanguage:      this.GetString("cla_language"),
 		CorporationEmail: this.GetString("corporation_email"),
+		Page:             page,
+	}
+	if err := opt.Validate(); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	// the org owner can list the employees of its orgs, and the manager
+	// can only list the ones of its corporation.
+	if getApiAccessPermission(&this.Controller) == PermissionOwnerOfOrg {
+		if err := checkOrgOwnership(&this.Controller, opt.Platform, opt.OrgID); err != nil {
+			reason = err
+			statusCode = 400
+			return
+		}
+	} else {
+		user, err := getApiAccessUser(&this.Controller)
+		if err != nil {
+			reason = err
+			statusCode = 400
+			return
+		}
+		opt.CorporationEmail = user
 	}
 
 	r, err := opt.List()
@@ -122,7 +174,7 @@ func (this *EmployeeSigningController) GetAll() {
 		return
 	}
 
-	body = r
+	body = pageResponse{PageInfo: r.PageInfo, Items: r.Signings}
 }
 
 // @Title Enable employee signing
@@ -154,4 +206,84 @@ func (this *EmployeeSigningController) Update() {
 	}
 
 	body = "enabled employee successfully"
+
+	action := models.AuditActionDisableEmployee
+	if info.Enabled {
+		action = models.AuditActionEnableEmployee
+	}
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{
+			Action:        action,
+			CLAOrgID:      info.CLAOrgID,
+			CorporationID: util.EmailSuffixToKey(info.Email),
+			Target:        info.Email,
+		},
+		map[string]bool{"enabled": !info.Enabled}, map[string]bool{"enabled": info.Enabled},
+	)
+
+	if info.Enabled {
+		recheckPRs(info.CLAOrgID, info.Email)
+
+		claOrg := &models.CLAOrg{ID: info.CLAOrgID}
+		if err := claOrg.Get(); err != nil {
+			beego.Info(err)
+			return
+		}
+
+		data := email.EmailTemplateData{CLAOrg: claOrg, Email: info.Email}
+		if err := sendEmail(models.EmailTemplateEmployeeEnabled, &data); err != nil {
+			beego.Info(fmt.Sprintf("Failed to notify employee(%s): %s", info.Email, err.Error()))
+		}
+	}
+}
+
+// @Title Revoke employee signing
+// @Description withdraw the signing by the employee, or revoke it by the employee manager or org owner
+// @Param	body		body 	models.SigningRevocation	true		"body for revoking signing"
+// @Success 204 {string} delete success!
+// @router / [delete]
+func (this *EmployeeSigningController) Delete() {
+	var statusCode = 204
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	var info models.SigningRevocation
+	if err := json.Unmarshal(this.Ctx.Input.RequestBody, &info); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	revokedBy, err := checkSigningRevocation(&this.Controller, &info, true)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := (&info).RevokeEmployee(revokedBy); err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = "revoke signing successfully"
+
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{
+			Action:        models.AuditActionRevokeEmployee,
+			CLAOrgID:      info.CLAOrgID,
+			CorporationID: util.EmailSuffixToKey(info.Email),
+			Target:        info.Email,
+		},
+		nil, map[string]string{"reason": info.Reason},
+	)
+
+	notifySigningRevoked(&info)
 }
@@ -2,18 +2,35 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/astaxie/beego"
 
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/email"
 	"github.com/zengchen1024/cla-server/models"
 )
 
+const (
+	dateLayout = "2006-01-02"
+)
+
 type IndividualSigningController struct {
 	beego.Controller
 }
 
 func (this *IndividualSigningController) Prepare() {
-	apiPrepare(&this.Controller, []string{PermissionIndividualSigner})
+	switch this.Ctx.Request.Method {
+	case http.MethodGet:
+		apiPrepare(&this.Controller, []string{PermissionOwnerOfOrg})
+	case http.MethodDelete:
+		apiPrepare(&this.Controller, []string{PermissionIndividualSigner, PermissionOwnerOfOrg})
+	default:
+		apiPrepare(&this.Controller, []string{PermissionIndividualSigner})
+	}
 }
 
 // @Title Individual signing
@@ -38,11 +55,367 @@ func (this *IndividualSigningController) Post() {
 		return
 	}
 
-	if err := (&info).Create(); err != nil {
+	if err := (&info).Validate(); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := (&info).Create(signingMetadata(&this.Controller)); err != nil {
 		reason = err
 		statusCode = 500
 		return
 	}
 
 	body = "sign successfully"
+
+	recheckPRs(info.CLAOrgID, info.Email)
+}
+
+// @Title Revoke individual signing
+// @Description withdraw the signing by the signer or revoke it by the org owner
+// @Param	body		body 	models.SigningRevocation	true		"body for revoking signing"
+// @Success 204 {string} delete success!
+// @router / [delete]
+func (this *IndividualSigningController) Delete() {
+	var statusCode = 204
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	var info models.SigningRevocation
+	if err := json.Unmarshal(this.Ctx.Input.RequestBody, &info); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	revokedBy, err := checkSigningRevocation(&this.Controller, &info, false)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := (&info).RevokeIndividual(revokedBy); err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = "revoke signing successfully"
+
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{Action: models.AuditActionRevokeIndividual, CLAOrgID: info.CLAOrgID, Target: info.Email},
+		nil, map[string]string{"reason": info.Reason},
+	)
+
+	notifySigningRevoked(&info)
+}
+
+// @Title send verification code when withdrawing signing
+// @Description send verification code to the signer of individual or employee signing
+// @Param	body		body 	models.SigningWithdrawalVerifCode	true		"body for sending verification code"
+// @Success 201 {int} map
+// @router /withdrawal/verifi-code [post]
+func (this *IndividualSigningController) SendWithdrawalVerifiCode() {
+	var statusCode = 201
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	var info models.SigningWithdrawalVerifCode
+	if err := json.Unmarshal(this.Ctx.Input.RequestBody, &info); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	claOrg := &models.CLAOrg{ID: info.CLAOrgID}
+	if err := claOrg.Get(); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	expiry, err := beego.AppConfig.Int64("verification_vode_expiry")
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	ip := clientIP(&this.Controller)
+	if err := info.CheckFrequency(ip, verifiCodeResendInterval()); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	code, err := info.Create(ip, expiry)
+	if err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	data := email.EmailTemplateData{
+		CLAOrg: claOrg,
+		Email:  info.Email,
+		Code:   code,
+	}
+	if err := sendEmail(models.EmailTemplateSigningWithdrawal, &data); err != nil {
+		reason = fmt.Errorf("Failed to send verification code by email: %s", err.Error())
+		statusCode = 500
+		return
+	}
+
+	body = "verification code has been sent successfully"
+}
+
+// @Title GetAll
+// @Description list the individual signings of org. All the matched signings will be exported if format is csv.
+// @Param	platform		query 	string	true		"The code platform"
+// @Param	org_id		query 	string	true		"The org"
+// @Param	repo_id		query 	string	false		"The repo"
+// @Param	cla_language		query 	string	false		"The language of cla"
+// @Param	email		query 	string	false		"The substring of email"
+// @Param	signed_from		query 	string	false		"The first date of signing, such as 2020-01-01"
+// @Param	signed_to		query 	string	false		"The last date of signing, such as 2020-12-31"
+// @Param	cursor		query 	string	false		"The next_cursor of previous page"
+// @Param	limit		query 	int	false		"The max number of items per page"
+// @Param	sort		query 	string	false		"The field to sort by, signed_at or email"
+// @Param	order		query 	string	false		"The sort order, asc or desc"
+// @Param	format		query 	string	false		"The output format, json or csv"
+// @Success 200 {object} controllers.pageResponse
+// @router / [get]
+func (this *IndividualSigningController) GetAll() {
+	var statusCode = 200
+	var reason error
+	var body interface{}
+	var exported bool
+
+	defer func() {
+		if reason == nil && exported {
+			return
+		}
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	opt, err := this.parseListOption()
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := checkOrgOwnership(&this.Controller, opt.Platform, opt.OrgID); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	format := this.GetString("format", "json")
+	if format != "json" && format != "csv" {
+		reason = fmt.Errorf("unknown format: %s", format)
+		statusCode = 400
+		return
+	}
+
+	if format == "csv" {
+		if err := this.exportCSV(opt); err != nil {
+			reason = err
+			statusCode = 500
+			return
+		}
+		exported = true
+		return
+	}
+
+	r, err := opt.List()
+	if err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = pageResponse{PageInfo: r.PageInfo, Items: r.Signings}
+}
+
+func (this *IndividualSigningController) parseListOption() (models.IndividualSigningListOption, error) {
+	opt := models.IndividualSigningListOption{
+		Platform:      this.GetString("platform"),
+		OrgID:         this.GetString("org_id"),
+		RepoID:        this.GetString("repo_id"),
+		CLALanguage:   this.GetString("cla_language"),
+		EmailContains: this.GetString("email"),
+	}
+	if opt.Platform == "" || opt.OrgID == "" {
+		return opt, fmt.Errorf("missing platform or org_id")
+	}
+
+	var err error
+	if opt.Page, err = getPageOption(&this.Controller); err != nil {
+		return opt, err
+	}
+
+	if v := this.GetString("signed_from"); v != "" {
+		t, err := time.ParseInLocation(dateLayout, v, time.Local)
+		if err != nil {
+			return opt, fmt.Errorf("invalid signed_from: %s", err.Error())
+		}
+		opt.SignedFrom = t.Unix()
+	}
+
+	if v := this.GetString("signed_to"); v != "" {
+		t, err := time.ParseInLocation(dateLayout, v, time.Local)
+		if err != nil {
+			return opt, fmt.Errorf("invalid signed_to: %s", err.Error())
+		}
+		// include the whole day
+		opt.SignedTo = t.AddDate(0, 0, 1).Unix() - 1
+	}
+
+	return opt, opt.Validate()
+}
+
+// exportCSV writes the matched signings to csv page by page and flushes
+// each page, so that all of them are never held in memory at once. The
+// error is returned only if nothing has been sent.
+func (this *IndividualSigningController) exportCSV(opt models.IndividualSigningListOption) error {
+	fields, err := individualSigningFields(opt)
+	if err != nil {
+		return err
+	}
+
+	opt.Page.Cursor = ""
+	opt.Page.Limit = maxPageLimit
+	r, err := opt.List()
+	if err != nil {
+		return err
+	}
+
+	w := newCSVWriter(&this.Controller, "individual-signings.csv")
+	if err := w.Write(individualSigningCSVHeader(fields)); err != nil {
+		beego.Info(fmt.Sprintf("Failed to write csv: %s", err.Error()))
+		return nil
+	}
+
+	for {
+		for i := range r.Signings {
+			if err := w.Write(individualSigningToCSV(&r.Signings[i], fields)); err != nil {
+				beego.Info(fmt.Sprintf("Failed to write csv: %s", err.Error()))
+				return nil
+			}
+		}
+
+		w.Flush()
+		if err := w.Error(); err != nil {
+			beego.Info(fmt.Sprintf("Failed to write csv: %s", err.Error()))
+			return nil
+		}
+
+		if r.NextCursor == "" {
+			return nil
+		}
+
+		opt.Page.Cursor = r.NextCursor
+		if r, err = opt.List(); err != nil {
+			beego.Info(fmt.Sprintf("Failed to export individual signings: %s", err.Error()))
+			return nil
+		}
+	}
+}
+
+// individualSigningFields returns the ids of fields defined by the clas of
+// bindings which the signings may belong to.
+func individualSigningFields(opt models.IndividualSigningListOption) ([]string, error) {
+	bindings, err := models.CLAOrgListOption{
+		Platform: opt.Platform,
+		OrgID:    opt.OrgID,
+		RepoID:   opt.RepoID,
+		ApplyTo:  models.ApplyToIndividual,
+	}.List()
+	if err != nil {
+		return nil, err
+	}
+
+	clas := map[string]bool{}
+	done := map[string]bool{}
+	fields := []string{}
+	for _, item := range bindings.Bindings {
+		if clas[item.CLAID] || (opt.CLALanguage != "" && item.CLALanguage != opt.CLALanguage) {
+			continue
+		}
+		clas[item.CLAID] = true
+
+		cla := models.CLA{ID: item.CLAID}
+		if err := cla.Get(); err != nil {
+			return nil, err
+		}
+
+		for _, f := range cla.Fields {
+			if !done[f.ID] {
+				done[f.ID] = true
+				fields = append(fields, f.ID)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// individualSigningCSVHeader returns the header of csv. The fields filled by
+// signers are the columns after the fixed ones, and the values not belonging
+// to them are put in the last column as json.
+func individualSigningCSVHeader(fields []string) []string {
+	header := []string{
+		"email", "signed_at", "cla_version", "cla_language", "repo_id", "cla_org_id",
+		"signed_by", "ip", "user_agent", "cla_hash",
+	}
+	for _, k := range fields {
+		header = append(header, csvCell(k))
+	}
+	return append(header, "other_info")
+}
+
+func individualSigningToCSV(item *dbmodels.IndividualSigningDetail, fields []string) []string {
+	signedAt := ""
+	if item.SignedAt > 0 {
+		signedAt = time.Unix(item.SignedAt, 0).Format(time.RFC3339)
+	}
+
+	row := []string{
+		csvCell(item.Email), signedAt, strconv.Itoa(item.CLAVersion),
+		item.CLALanguage, item.RepoID, item.CLAOrgID,
+	}
+
+	if m := item.Metadata; m != nil {
+		row = append(row, csvCell(m.SignedBy), m.IP, csvCell(m.UserAgent), m.CLAHash)
+	} else {
+		row = append(row, "", "", "", "")
+	}
+
+	others := map[string]string{}
+	for k, v := range item.Info {
+		others[k] = v
+	}
+	for _, k := range fields {
+		row = append(row, csvCell(item.Info[k]))
+		delete(others, k)
+	}
+
+	other := ""
+	if len(others) > 0 {
+		if v, err := json.Marshal(others); err == nil {
+			other = csvCell(string(v))
+		}
+	}
+	return append(row, other)
 }
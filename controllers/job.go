@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/astaxie/beego"
+
+	"github.com/zengchen1024/cla-server/models"
+	"github.com/zengchen1024/cla-server/worker"
+)
+
+type JobController struct {
+	beego.Controller
+}
+
+func (this *JobController) Prepare() {
+	apiPrepare(&this.Controller, []string{PermissionOwnerOfOrg})
+}
+
+// @Title GetAll
+// @Description get all the background jobs of a binding
+// @Param	cla_org_id		query 	string	true		"The id of binding between cla and org"
+// @Param	status		query 	string	false		"The status of job"
+// @Param	corporation_email		query 	string	false		"The email of corporation"
+// @Param	cursor		query 	string	false		"The next_cursor of previous page"
+// @Param	limit		query 	int	false		"The max number of items per page"
+// @Param	sort		query 	string	false		"The field to sort by, created_at"
+// @Param	order		query 	string	false		"The sort order, asc or desc"
+// @Success 200 {object} controllers.pageResponse
+// @router / [get]
+func (this *JobController) GetAll() {
+	var statusCode = 200
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	page, err := getPageOption(&this.Controller)
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	opt := models.JobListOption{
+		CLAOrgID:         this.GetString("cla_org_id"),
+		Status:           this.GetString("status"),
+		CorporationEmail: this.GetString("corporation_email"),
+		Page:             page,
+	}
+	if opt.CLAOrgID == "" {
+		reason = fmt.Errorf("missing cla_org_id")
+		statusCode = 400
+		return
+	}
+	if err := opt.Validate(); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if _, err := checkOrgOwnershipOfBinding(&this.Controller, opt.CLAOrgID); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	r, err := worker.GetEmailWorker().ListJobs(opt)
+	if err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = pageResponse{PageInfo: r.PageInfo, Items: r.Jobs}
+}
+
+// @Title Retry
+// @Description retry the dead or cancelled job
+// @Param	job_id		path 	string	true		"The id of job"
+// @Success 202 {string} retry successfully
+// @router /:job_id/retry [put]
+func (this *JobController) Retry() {
+	var statusCode = 202
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	job, err := this.checkJob()
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := worker.GetEmailWorker().RetryJob(job.ID); err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = "retry job successfully"
+
+	this.addAuditLog(models.AuditActionRetryJob, job)
+}
+
+// @Title Cancel
+// @Description cancel the job which is waiting to run
+// @Param	job_id		path 	string	true		"The id of job"
+// @Success 202 {string} cancel successfully
+// @router /:job_id/cancel [put]
+func (this *JobController) Cancel() {
+	var statusCode = 202
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	job, err := this.checkJob()
+	if err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := worker.GetEmailWorker().CancelJob(job.ID); err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = "cancel job successfully"
+
+	this.addAuditLog(models.AuditActionCancelJob, job)
+}
+
+// checkJob checks whether the user is the owner of org which the job belongs to
+func (this *JobController) checkJob() (*models.Job, error) {
+	jobID := this.GetString(":job_id")
+	if jobID == "" {
+		return nil, fmt.Errorf("missing job_id")
+	}
+
+	job := &models.Job{ID: jobID}
+	if err := job.Get(); err != nil {
+		return nil, err
+	}
+
+	_, err := checkOrgOwnershipOfBinding(&this.Controller, job.CLAOrgID)
+	return job, err
+}
+
+func (this *JobController) addAuditLog(action string, job *models.Job) {
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{
+			Action:        action,
+			CLAOrgID:      job.CLAOrgID,
+			CorporationID: job.CorporationID,
+			Target:        job.ID,
+		},
+		map[string]string{"status": job.Status}, nil,
+	)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -40,6 +41,12 @@ func (this *OrgSignatureController) Post() {
 		return
 	}
 
+	if _, err := checkOrgOwnershipOfBinding(&this.Controller, claOrgID); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
 	f, _, err := this.GetFile("signature_page")
 	if err != nil {
 		reason = err
@@ -76,6 +83,12 @@ func (this *OrgSignatureController) Post() {
 	}
 
 	body = "upload pdf of signature page successfully"
+
+	addAuditLog(
+		&this.Controller,
+		models.AuditLog{Action: models.AuditActionUploadOrgSignature, CLAOrgID: claOrgID},
+		nil, map[string]string{"sha256": fmt.Sprintf("%x", sha256.Sum256(data))},
+	)
 }
 
 // @Title Get
@@ -99,6 +112,12 @@ func (this *OrgSignatureController) Get() {
 		return
 	}
 
+	if _, err := checkOrgOwnershipOfBinding(&this.Controller, claOrgID); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
 	pdf, err := models.DownloadOrgSignature(claOrgID)
 	if err != nil {
 		reason = err
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/astaxie/beego"
+
+	"github.com/zengchen1024/cla-server/models"
+)
+
+// SigningCheckController is public and used by bots or CI to check whether
+// the contributor has signed the cla.
+type SigningCheckController struct {
+	beego.Controller
+}
+
+// @Title Check
+// @Description check whether the contributor has signed cla for the org/repo
+// @Param	platform		query 	string	true		"The code platform"
+// @Param	org_id		query 	string	true		"The org"
+// @Param	repo_id		query 	string	false		"The repo"
+// @Param	email		query 	string	true		"The email of contributor"
+// @Success 200 {object} models.SigningCheckResult
+// @router / [get]
+func (this *SigningCheckController) Check() {
+	var statusCode = 200
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	opt := models.SigningCheckOption{
+		Platform: this.GetString("platform"),
+		OrgID:    this.GetString("org_id"),
+		RepoID:   this.GetString("repo_id"),
+		Email:    this.GetString("email"),
+	}
+	if opt.Platform == "" || opt.OrgID == "" || opt.Email == "" {
+		reason = fmt.Errorf("missing platform, org_id or email")
+		statusCode = 400
+		return
+	}
+
+	r, err := opt.Check()
+	if err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = r
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/astaxie/beego"
+
+	"github.com/zengchen1024/cla-server/email"
+	"github.com/zengchen1024/cla-server/models"
+	"github.com/zengchen1024/cla-server/util"
+)
+
+const reasonOfWithdrawal = "withdrawn by the signer"
+
+// checkSigningRevocation checks whether the user can revoke the signing and returns who revokes it.
+// The signer can withdraw the signing with the verification code sent to the email.
+// The org owner can revoke any signing of the binding, and the employee manager can
+// revoke the signing of employee of its corporation if managerAllowed is true.
+func checkSigningRevocation(c *beego.Controller, info *models.SigningRevocation, managerAllowed bool) (string, error) {
+	if info.CLAOrgID == "" || !strings.Contains(info.Email, "@") {
+		return "", fmt.Errorf("missing cla_org_id or invalid email")
+	}
+
+	user, err := getApiAccessUser(c)
+	if err != nil {
+		return "", err
+	}
+
+	switch getApiAccessPermission(c) {
+	case PermissionIndividualSigner:
+		if err := info.ValidateWithdrawal(verifiCodeMaxAttempts()); err != nil {
+			return "", err
+		}
+		if info.Reason == "" {
+			info.Reason = reasonOfWithdrawal
+		}
+		return info.Email, nil
+
+	case PermissionOwnerOfOrg:
+		if _, err := checkOrgOwnershipOfBinding(c, info.CLAOrgID); err != nil {
+			return "", err
+		}
+
+	case PermissionEmployeeManager:
+		if !managerAllowed {
+			return "", fmt.Errorf("not allowed to revoke the signing of %s", info.Email)
+		}
+
+		corporationID, err := info.CorporationIDOfEmployee()
+		if err != nil {
+			return "", err
+		}
+		if util.EmailSuffixToKey(user) != corporationID {
+			return "", fmt.Errorf("not allowed to revoke the signing of %s", info.Email)
+		}
+
+	default:
+		return "", fmt.Errorf("not allowed to revoke the signing")
+	}
+
+	if info.Reason == "" {
+		return "", fmt.Errorf("missing the reason of revocation")
+	}
+	return user, nil
+}
+
+// notifySigningRevoked notifies the signer and updates the cla status of its pull requests
+func notifySigningRevoked(info *models.SigningRevocation) {
+	recheckPRs(info.CLAOrgID, info.Email)
+
+	claOrg := &models.CLAOrg{ID: info.CLAOrgID}
+	if err := claOrg.Get(); err != nil {
+		beego.Info(err)
+		return
+	}
+
+	data := email.EmailTemplateData{CLAOrg: claOrg, Email: info.Email, Reason: info.Reason}
+	if err := sendEmail(models.EmailTemplateSigningRevoked, &data); err != nil {
+		beego.Info(fmt.Sprintf("Failed to notify the signer(%s) of revocation: %s", info.Email, err.Error()))
+	}
+}
@@ -1,12 +1,20 @@
 package controllers
 
 import (
+	"encoding/csv"
 	"fmt"
+	"net"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/astaxie/beego"
 
+	"github.com/zengchen1024/cla-server/code-platform-auth/platforms"
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/email"
 	"github.com/zengchen1024/cla-server/models"
+	"github.com/zengchen1024/cla-server/webhook"
 )
 
 const (
@@ -15,13 +23,26 @@ const (
 	headerUser         = "User"
 	headerToken        = "Token"
 	apiAccessUser      = "access_user"
+	apiAccessPerm      = "access_permission"
+
+	defaultVerifiCodeMaxAttempts    = 5
+	defaultVerifiCodeResendInterval = 60
+	defaultPasswordRetrievalExpiry  = 1800
+	defaultRefreshTokenExpiry       = 7 * 24 * 3600
+
+	defaultPageLimit = 20
+	maxPageLimit     = 100
 )
 
 func sendResponse(c *beego.Controller, statusCode int, reason error, body interface{}) {
 	c.Ctx.ResponseWriter.WriteHeader(statusCode)
 
 	if reason != nil {
-		c.Data["json"] = reason.Error()
+		if v, ok := reason.(models.FieldErrors); ok {
+			c.Data["json"] = fieldErrorsResponse{Message: reason.Error(), Fields: v}
+		} else {
+			c.Data["json"] = reason.Error()
+		}
 	} else {
 		if body != nil {
 			c.Data["json"] = body
@@ -31,6 +52,63 @@ func sendResponse(c *beego.Controller, statusCode int, reason error, body interf
 	c.ServeJSON()
 }
 
+// fieldErrorsResponse tells the client which fields are invalid and why
+type fieldErrorsResponse struct {
+	Message string             `json:"message"`
+	Fields  models.FieldErrors `json:"fields"`
+}
+
+// pageResponse is the response of the list which is paginated
+type pageResponse struct {
+	dbmodels.PageInfo
+
+	Items interface{} `json:"items"`
+}
+
+// getPageOption parses the cursor, limit, sort and order of list in the query
+func getPageOption(c *beego.Controller) (dbmodels.PageOption, error) {
+	opt := dbmodels.PageOption{
+		Cursor: c.GetString("cursor"),
+		SortBy: c.GetString("sort"),
+	}
+
+	var err error
+	opt.Limit, err = c.GetInt("limit", defaultPageLimit)
+	if err != nil || opt.Limit < 1 || opt.Limit > maxPageLimit {
+		return opt, fmt.Errorf("invalid limit, it should be between 1 and %d", maxPageLimit)
+	}
+
+	switch c.GetString("order") {
+	case "", "asc":
+	case "desc":
+		opt.Desc = true
+	default:
+		return opt, fmt.Errorf("invalid order, it should be asc or desc")
+	}
+	return opt, nil
+}
+
+// newCSVWriter starts sending a csv file to be downloaded and returns the
+// writer of it. The caller should flush the writer.
+func newCSVWriter(c *beego.Controller, filename string) *csv.Writer {
+	c.Ctx.Output.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Ctx.Output.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Ctx.ResponseWriter.WriteHeader(200)
+
+	return csv.NewWriter(c.Ctx.ResponseWriter)
+}
+
+// csvCell escapes the value filled by user, so that it will not be
+// treated as a formula by the spreadsheet software. The leading tab and
+// carriage return are escaped too, because some software skips them
+// before checking the formula.
+func csvCell(v string) string {
+	if v != "" && strings.ContainsAny(v[:1], "=+-@\t\r") {
+		return "'" + v
+	}
+	return v
+}
+
 func getHeader(c *beego.Controller, h string) string {
 	return c.Ctx.Input.Header(h)
 }
@@ -57,30 +135,81 @@ func parseHeader(c *beego.Controller) requestHeader {
 	return h
 }
 
-func createApiAccessToken(user, permission string) (string, error) {
+// createApiAccessToken starts a session of user and returns the short-lived
+// access token and the refresh token of session.
+func createApiAccessToken(user, permission string) (string, string, error) {
+	session := &models.RefreshToken{
+		User:       user,
+		Permission: permission,
+	}
+	rt, err := session.Create(refreshTokenExpiry())
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to create refresh token: %s", err.Error())
+	}
+
+	at, err := refreshApiAccessToken(session)
+	if err != nil {
+		return "", "", err
+	}
+	return at, rt, nil
+}
+
+// refreshTokenExpiry returns how long the refresh token lives in seconds
+func refreshTokenExpiry() int64 {
+	return beego.AppConfig.DefaultInt64("api_refresh_token_expiry", defaultRefreshTokenExpiry)
+}
+
+// refreshApiAccessToken creates a new access token for the session. The token
+// never outlives the session.
+func refreshApiAccessToken(session *models.RefreshToken) (string, error) {
 	expiry, err := beego.AppConfig.Int64("api_token_expiry")
 	if err != nil {
 		return "", fmt.Errorf("Failed to create access token: parsing token expiry was failed")
 	}
+
+	e := time.Now().Unix() + expiry
+	if e > session.Expiry {
+		e = session.Expiry
+	}
+
 	ac := &accessControler{
-		User:       user,
-		Permission: permission,
+		User:       session.User,
+		Permission: session.Permission,
+		SessionID:  session.ID,
 	}
-	return ac.CreateToken(
-		expiry,
-		beego.AppConfig.String("api_token_key"),
-	)
+	return ac.CreateToken(e, beego.AppConfig.String("api_token_key"))
 }
 
-func checkApiAccessToken(c *beego.Controller, permission []string) (string, error) {
+func parseApiAccessToken(c *beego.Controller, permission []string) (*accessControler, error) {
 	token := getHeader(c, headerToken)
 	if token == "" {
-		return "", fmt.Errorf("no token passed")
+		return nil, fmt.Errorf("no token passed")
 	}
 
 	ac := &accessControler{}
 
 	err := ac.CheckToken(token, beego.AppConfig.String("api_token_key"), permission)
+	if err != nil {
+		return nil, err
+	}
+
+	if ac.SessionID == "" {
+		return nil, fmt.Errorf("Not a valid token")
+	}
+
+	revoked, err := models.IsSessionRevoked(ac.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, fmt.Errorf("token is revoked")
+	}
+
+	return ac, nil
+}
+
+func checkApiAccessToken(c *beego.Controller, permission []string) (string, error) {
+	ac, err := parseApiAccessToken(c, permission)
 	if err != nil {
 		return "", err
 	}
@@ -89,13 +218,14 @@ func checkApiAccessToken(c *beego.Controller, permission []string) (string, erro
 }
 
 func apiPrepare(c *beego.Controller, permission []string) {
-	user, err := checkApiAccessToken(c, permission)
+	ac, err := parseApiAccessToken(c, permission)
 	if err != nil {
 		sendResponse(c, 400, err, nil)
 		c.StopRun()
 	}
 
-	c.Data[apiAccessUser] = user
+	c.Data[apiAccessUser] = ac.User
+	c.Data[apiAccessPerm] = ac.Permission
 }
 
 func getApiAccessUser(c *beego.Controller) (string, error) {
@@ -106,6 +236,78 @@ func getApiAccessUser(c *beego.Controller) (string, error) {
 	return user, nil
 }
 
+// clientIP returns the ip of client. The headers set by proxy, such as
+// X-Forwarded-For, are trusted only if the request comes from one of the
+// proxies configured by trusted_proxies, otherwise the client can fake its ip
+// to escape the limit of frequency.
+func clientIP(c *beego.Controller) string {
+	remote := c.Ctx.Request.RemoteAddr
+	if ip, _, err := net.SplitHostPort(remote); err == nil {
+		remote = ip
+	}
+
+	proxies := beego.AppConfig.Strings("trusted_proxies")
+	if !isTrustedProxy(remote, proxies) {
+		return remote
+	}
+
+	// the proxies append the address to the end, so the last untrusted one is the client.
+	v := strings.Split(c.Ctx.Input.Header("X-Forwarded-For"), ",")
+	for i := len(v) - 1; i >= 0; i-- {
+		if ip := strings.TrimSpace(v[i]); ip != "" && !isTrustedProxy(ip, proxies) {
+			return ip
+		}
+	}
+
+	if ip := c.Ctx.Input.Header("X-Real-Ip"); ip != "" {
+		return ip
+	}
+	return remote
+}
+
+// isTrustedProxy checks whether ip is one of proxies which can be ip or cidr.
+func isTrustedProxy(ip string, proxies []string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+
+	for _, item := range proxies {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		if _, n, err := net.ParseCIDR(item); err == nil {
+			if n.Contains(addr) {
+				return true
+			}
+		} else if p := net.ParseIP(item); p != nil && p.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// signingMetadata returns the metadata of signing request. The signer is
+// unknown if the api can be accessed without token, such as corporation signing.
+func signingMetadata(c *beego.Controller) models.SigningMetadata {
+	user, _ := getApiAccessUser(c)
+
+	return models.SigningMetadata{
+		SignedBy:  user,
+		IP:        clientIP(c),
+		UserAgent: c.Ctx.Input.UserAgent(),
+	}
+}
+
+// getApiAccessPermission returns the permission of token, which is useful
+// when the api can be accessed with several permissions.
+func getApiAccessPermission(c *beego.Controller) string {
+	v, _ := c.Data[apiAccessPerm].(string)
+	return v
+}
+
 func corporRoleToPermission(role string) string {
 	switch role {
 	case models.RoleAdmin:
@@ -133,3 +335,190 @@ func getRouterPattern(c *beego.Controller) string {
 	}
 	return ""
 }
+
+// listOrgsOfUser returns the platform of the logged-in user and the orgs which he/she administers
+func listOrgsOfUser(c *beego.Controller) (string, map[string]bool, error) {
+	user, err := getApiAccessUser(c)
+	if err != nil {
+		return "", nil, err
+	}
+
+	v := strings.Split(user, "/")
+	if len(v) != 2 {
+		return "", nil, fmt.Errorf("invalid user: %s", user)
+	}
+	platform := v[0]
+
+	pt := models.PlatformToken{User: user}
+	if err := pt.Get(); err != nil {
+		return "", nil, fmt.Errorf("Failed to get the platform token of user: %s", err.Error())
+	}
+
+	p, err := platforms.NewPlatform(pt.Token, "", platform)
+	if err != nil {
+		return "", nil, err
+	}
+
+	orgs, err := p.ListOrg()
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to list orgs of user: %s", err.Error())
+	}
+
+	m := make(map[string]bool, len(orgs))
+	for _, item := range orgs {
+		m[item] = true
+	}
+	return platform, m, nil
+}
+
+func checkOrgOwnership(c *beego.Controller, platform, orgID string) error {
+	p, orgs, err := listOrgsOfUser(c)
+	if err != nil {
+		return err
+	}
+
+	if p != platform || !orgs[orgID] {
+		return fmt.Errorf("the user is not the owner of org: %s/%s", platform, orgID)
+	}
+	return nil
+}
+
+func checkOrgOwnershipOfBinding(c *beego.Controller, claOrgID string) (*models.CLAOrg, error) {
+	claOrg := &models.CLAOrg{ID: claOrgID}
+	if err := claOrg.Get(); err != nil {
+		return nil, err
+	}
+
+	return claOrg, checkOrgOwnership(c, claOrg.Platform, claOrg.OrgID)
+}
+
+// sendEmail renders the email template of kind and sends it by the org email of binding
+func sendEmail(kind string, data *email.EmailTemplateData) error {
+	claOrg := data.CLAOrg
+
+	if data.CLA == nil {
+		cla := &models.CLA{ID: claOrg.CLAID}
+		if err := cla.Get(); err != nil {
+			return err
+		}
+		data.CLA = cla
+	}
+
+	emailCfg := &models.OrgEmail{Email: claOrg.OrgEmail}
+	if err := emailCfg.Get(); err != nil {
+		return err
+	}
+
+	ec, err := email.GetEmailClient(emailCfg.Platform)
+	if err != nil {
+		return fmt.Errorf("Failtd to get email client: %s", err.Error())
+	}
+
+	msg, err := email.GenEmailMessage(kind, data)
+	if err != nil {
+		return err
+	}
+
+	if err := ec.SendEmail(emailCfg, msg); err != nil {
+		return fmt.Errorf("Failed to send email: %s", err.Error())
+	}
+	return nil
+}
+
+// notifyManagers sends the initial passwords to the managers whose accounts have been created.
+// key of passwords is the email of manager.
+func notifyManagers(claOrgID string, passwords map[string]string) {
+	claOrg := &models.CLAOrg{ID: claOrgID}
+	if err := claOrg.Get(); err != nil {
+		beego.Info(err)
+		return
+	}
+
+	for item, pw := range passwords {
+		data := email.EmailTemplateData{CLAOrg: claOrg, Email: item, Password: pw}
+		if err := sendEmail(models.EmailTemplateManagerAccount, &data); err != nil {
+			beego.Info(fmt.Sprintf("Failed to notify manager(%s): %s", item, err.Error()))
+		}
+	}
+}
+
+// verifiCodeMaxAttempts returns the times a verification code can be tried
+func verifiCodeMaxAttempts() int {
+	return beego.AppConfig.DefaultInt("verification_code_max_attempts", defaultVerifiCodeMaxAttempts)
+}
+
+// verifiCodeResendInterval returns the seconds to wait before sending code
+// to the same email or from the same ip again
+func verifiCodeResendInterval() int64 {
+	return beego.AppConfig.DefaultInt64("verification_code_resend_interval", defaultVerifiCodeResendInterval)
+}
+
+// prRecheckQueueSize is the max number of rechecks waiting to run. The new one
+// is dropped when the queue is full, and the pull requests can still be
+// checked again by commenting on them.
+const prRecheckQueueSize = 100
+
+type prRecheck struct {
+	claOrgID string
+	email    string
+}
+
+var (
+	prRecheckOnce    sync.Once
+	prRecheckQueue   chan prRecheck
+	prRecheckLock    sync.Mutex
+	prRecheckPending = map[prRecheck]bool{}
+)
+
+// recheckPRs updates the cla status of the open pull requests which contain
+// the commits of the contributor who has just signed the cla of binding.
+// The rechecks run one by one in the background, so that a burst of signings
+// can't start unbounded requests to the code platform.
+func recheckPRs(claOrgID, email string) {
+	checker := webhook.GetCLAChecker()
+	if checker == nil {
+		return
+	}
+
+	prRecheckOnce.Do(func() {
+		prRecheckQueue = make(chan prRecheck, prRecheckQueueSize)
+		go runPRRechecks(checker)
+	})
+
+	task := prRecheck{claOrgID: claOrgID, email: email}
+
+	prRecheckLock.Lock()
+	defer prRecheckLock.Unlock()
+
+	// the waiting one will see the latest signing too
+	if prRecheckPending[task] {
+		return
+	}
+
+	select {
+	case prRecheckQueue <- task:
+		prRecheckPending[task] = true
+	default:
+		beego.Info(fmt.Sprintf("Failed to recheck pull requests of %s: too many rechecks are waiting", email))
+	}
+}
+
+func runPRRechecks(checker *webhook.CLAChecker) {
+	for task := range prRecheckQueue {
+		prRecheckLock.Lock()
+		delete(prRecheckPending, task)
+		prRecheckLock.Unlock()
+
+		claOrg := &models.CLAOrg{ID: task.claOrgID}
+		if err := claOrg.Get(); err != nil {
+			beego.Info(err)
+			continue
+		}
+
+		// only the pull requests containing the commits of the email are checked
+		err := checker.RecheckAuthor(claOrg.Platform, claOrg.OrgID, claOrg.RepoID, task.email)
+		if err != nil {
+			beego.Info(fmt.Sprintf("Failed to recheck pull requests of %s: %s", task.email, err.Error()))
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/astaxie/beego"
+
+	"github.com/zengchen1024/cla-server/webhook"
+)
+
+// WebhookController receives the events of code platforms. It is authenticated by
+// the signature of webhook instead of the access token.
+type WebhookController struct {
+	beego.Controller
+}
+
+// @Title Gitee
+// @Description handle the webhook events of Gitee and label the pull request by cla status
+// @Success 202 {string} handle event successfully
+// @router /gitee [post]
+func (this *WebhookController) Gitee() {
+	var statusCode = 202
+	var reason error
+	var body interface{}
+
+	defer func() {
+		sendResponse(&this.Controller, statusCode, reason, body)
+	}()
+
+	w := webhook.GetGiteeWebhook()
+	if w == nil {
+		reason = fmt.Errorf("the webhook of gitee is not enabled")
+		statusCode = 400
+		return
+	}
+
+	if err := w.VerifySignature(this.Ctx.Request.Header); err != nil {
+		reason = err
+		statusCode = 400
+		return
+	}
+
+	if err := w.Handle(this.Ctx.Input.Header("X-Gitee-Event"), this.Ctx.Input.RequestBody); err != nil {
+		reason = err
+		statusCode = 500
+		return
+	}
+
+	body = "handle event successfully"
+}
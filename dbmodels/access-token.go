@@ -0,0 +1,20 @@
+package dbmodels
+
+// RefreshToken is the server-side session of user which can be used to
+// refresh the short-lived access token.
+type RefreshToken struct {
+	// ID is the session id which is also put in the access token
+	ID string `json:"id" required:"true"`
+
+	// Token is the hash of refresh token
+	Token      string `json:"token" required:"true"`
+	User       string `json:"user" required:"true"`
+	Permission string `json:"permission" required:"true"`
+	Expiry     int64  `json:"expiry" required:"true"`
+}
+
+// RevokedSession is the session whose access tokens are revoked until expiry
+type RevokedSession struct {
+	ID     string `json:"id" required:"true"`
+	Expiry int64  `json:"expiry" required:"true"`
+}
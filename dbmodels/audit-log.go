@@ -0,0 +1,37 @@
+package dbmodels
+
+type AuditLog struct {
+	ID            string `json:"id,omitempty"`
+	Actor         string `json:"actor" required:"true"`
+	Permission    string `json:"permission,omitempty"`
+	Action        string `json:"action" required:"true"`
+	CLAOrgID      string `json:"cla_org_id,omitempty"`
+	CorporationID string `json:"corporation_id,omitempty"`
+	Target        string `json:"target,omitempty"`
+
+	// Before and After are the values of target in json before and after the action
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+
+	CreatedAt int64 `json:"created_at" required:"true"`
+}
+
+type AuditLogListOption struct {
+	CLAOrgID      string
+	CorporationID string
+	Actor         string
+	Action        string
+
+	// From and To are the range of time when the action was done, zero means unlimited
+	From int64
+	To   int64
+
+	// Page is sorted by one of AuditLogSortFields
+	Page PageOption
+}
+
+type AuditLogPage struct {
+	PageInfo
+
+	Logs []AuditLog `json:"logs"`
+}
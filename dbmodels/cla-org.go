@@ -7,11 +7,16 @@ type CLAOrg struct {
 	RepoID               string `json:"repo_id" required:"true"`
 	CLAID                string `json:"cla_id" required:"true"`
 	CLALanguage          string `json:"cla_language" required:"true"`
+	CLAVersion           int    `json:"cla_version,omitempty"`
 	ApplyTo              string `json:"apply_to" required:"true"`
 	OrgEmail             string `json:"org_email" required:"true"`
 	Enabled              bool   `json:"enabled"`
 	Submitter            string `json:"submitter" required:"true"`
 	OrgSignatureUploaded bool   `json:"org_signature_uploaded"`
+
+	// DomainVerificationRequired means the corporation must verify the domain
+	// of its email by DNS TXT record before being enabled
+	DomainVerificationRequired bool `json:"domain_verification_required,omitempty"`
 }
 
 type CLAOrgListOption struct {
@@ -19,4 +24,16 @@ type CLAOrgListOption struct {
 	OrgID    string `json:"org_id,omitempty"`
 	RepoID   string `json:"-"`
 	ApplyTo  string `json:"apply_to,omitempty"`
+
+	// OrgIDs restricts the orgs of bindings if it is not nil
+	OrgIDs []string `json:"-"`
+
+	// Page is sorted by one of CLAOrgSortFields
+	Page PageOption `json:"-"`
+}
+
+type CLAOrgPage struct {
+	PageInfo
+
+	Bindings []CLAOrg `json:"bindings"`
 }
@@ -8,6 +8,20 @@ type CLA struct {
 	Submitter string  `json:"submitter" required:"true"`
 	ApplyTo   string  `json:"apply_to" required:"true"`
 	Fields    []Field `json:"fields,omitempty"`
+
+	// Version is the latest version of cla, and Text is the text of it.
+	Version  int          `json:"version,omitempty"`
+	Versions []CLAVersion `json:"versions,omitempty"`
+}
+
+type CLAVersion struct {
+	Version int    `json:"version" required:"true"`
+	Text    string `json:"text" required:"true"`
+
+	// RequireResign means the contributors who signed the previous versions
+	// must sign this version again.
+	RequireResign bool  `json:"require_resign"`
+	PublishedAt   int64 `json:"published_at"`
 }
 
 type Field struct {
@@ -23,4 +37,13 @@ type CLAListOptions struct {
 	Name      string `json:"name,omitempty"`
 	Language  string `json:"language,omitempty"`
 	ApplyTo   string `json:"apply_to,omitempty"`
+
+	// Page is sorted by one of CLASortFields
+	Page PageOption `json:"-"`
+}
+
+type CLAPage struct {
+	PageInfo
+
+	CLAs []CLA `json:"clas"`
 }
@@ -0,0 +1,375 @@
+// Package conformance is the test suite which every backend of database must pass,
+// so that they behave the same.
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+const (
+	platform = "github"
+	orgID    = "org"
+	repoID   = "repo"
+
+	corpAdmin = "admin@example.com"
+	corpID    = "example_com"
+)
+
+// DB is the backend of database to be tested
+type DB interface {
+	dbmodels.IDB
+	models.IDB
+}
+
+// Run runs the whole suite. newDB must return an empty database for each case.
+func Run(t *testing.T, newDB func(t *testing.T) DB) {
+	cases := []struct {
+		name string
+		f    func(*testing.T, DB)
+	}{
+		{"CLA", testCLA},
+		{"CLAMetadata", testCLAMetadata},
+		{"Binding", testBinding},
+		{"IndividualSigning", testIndividualSigning},
+		{"CorporationSigning", testCorporationSigning},
+		{"CorporationManager", testCorporationManager},
+		{"EmployeeSigning", testEmployeeSigning},
+		{"CorporationDomain", testCorporationDomain},
+		{"VerificationCode", testVerificationCode},
+		{"Job", testJob},
+		{"AccessToken", testAccessToken},
+		{"AuditLog", testAuditLog},
+		{"Misc", testMisc},
+	}
+
+	for _, c := range cases {
+		f := c.f
+		t.Run(c.name, func(t *testing.T) {
+			f(t, newDB(t))
+		})
+	}
+}
+
+func mustNil(t *testing.T, err error, action string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %v", action, err)
+	}
+}
+
+func mustFail(t *testing.T, err error, action string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("%s: expect error, but got nil", action)
+	}
+}
+
+func createCLA(t *testing.T, db DB, name, applyTo string) string {
+	t.Helper()
+
+	id, err := db.CreateCLA(dbmodels.CLA{
+		Name:      name,
+		Text:      "text of " + name,
+		Language:  "english",
+		Submitter: "owner",
+		ApplyTo:   applyTo,
+		Version:   1,
+		Versions:  []dbmodels.CLAVersion{{Version: 1, Text: "text of " + name, PublishedAt: 1}},
+	})
+	mustNil(t, err, "create cla")
+	return id
+}
+
+func createBinding(t *testing.T, db DB, applyTo, repo string) string {
+	t.Helper()
+
+	claID := createCLA(t, db, applyTo+repo, applyTo)
+
+	id, err := db.CreateBindingBetweenCLAAndOrg(dbmodels.CLAOrg{
+		Platform:    platform,
+		OrgID:       orgID,
+		RepoID:      repo,
+		CLAID:       claID,
+		CLALanguage: "english",
+		CLAVersion:  1,
+		ApplyTo:     applyTo,
+		OrgEmail:    "org@example.org",
+		Enabled:     true,
+		Submitter:   "owner",
+	})
+	mustNil(t, err, "create binding")
+	return id
+}
+
+func signAsCorporation(t *testing.T, db DB, claOrgID string) {
+	t.Helper()
+
+	err := db.SignAsCorporation(claOrgID, dbmodels.CorporationSigningInfo{
+		AdminEmail:      corpAdmin,
+		AdminName:       "admin",
+		CorporationName: "example",
+		CorporationID:   corpID,
+		Info:            dbmodels.TypeSigningInfo{"1": "example"},
+		CLAVersion:      1,
+		SignedAt:        time.Now().Unix(),
+	})
+	mustNil(t, err, "sign as corporation")
+}
+
+func testCLA(t *testing.T, db DB) {
+	id := createCLA(t, db, "cla", models.ApplyToIndividual)
+
+	_, err := db.CreateCLA(dbmodels.CLA{
+		Name: "cla", Text: "t", Language: "english", Submitter: "owner", ApplyTo: models.ApplyToIndividual,
+	})
+	mustFail(t, err, "create cla with the same name")
+
+	cla, err := db.GetCLA(id)
+	mustNil(t, err, "get cla")
+	if cla.Name != "cla" || cla.Version != 1 || len(cla.Versions) != 1 {
+		t.Errorf("get cla: unexpected cla: %+v", cla)
+	}
+
+	_, err = db.GetCLA("000000000000000000000fff")
+	mustFail(t, err, "get cla not exist")
+
+	_, err = db.GetCLA("invalid")
+	mustFail(t, err, "get cla with invalid id")
+
+	page, err := db.ListCLA(dbmodels.CLAListOptions{Submitter: "owner"})
+	mustNil(t, err, "list cla")
+	if page.Total != 1 || len(page.CLAs) != 1 || page.CLAs[0].ID != id {
+		t.Errorf("list cla: unexpected clas: %+v", page)
+	}
+
+	page, err = db.ListCLA(dbmodels.CLAListOptions{Submitter: "other"})
+	mustNil(t, err, "list cla of other")
+	if page.Total != 0 || len(page.CLAs) != 0 {
+		t.Errorf("list cla of other: expect none, but got %d", len(page.CLAs))
+	}
+
+	v, err := db.ListCLAByIDs([]string{id})
+	mustNil(t, err, "list cla by ids")
+	if len(v) != 1 {
+		t.Errorf("list cla by ids: expect 1, but got %d", len(v))
+	}
+
+	mustFail(t, db.AddCLAVersion(id, dbmodels.CLAVersion{Version: 3, Text: "v3"}), "add version skipped")
+	mustNil(t, db.AddCLAVersion(id, dbmodels.CLAVersion{Version: 2, Text: "v2", RequireResign: true}), "add version")
+
+	cla, err = db.GetCLA(id)
+	mustNil(t, err, "get cla after adding version")
+	if cla.Version != 2 || cla.Text != "v2" || len(cla.Versions) != 2 || !cla.Versions[1].RequireResign {
+		t.Errorf("add version: unexpected cla: %+v", cla)
+	}
+
+	bound := createBinding(t, db, models.ApplyToIndividual, repoID)
+	b, err := db.GetBindingBetweenCLAAndOrg(bound)
+	mustNil(t, err, "get binding")
+	mustFail(t, db.DeleteCLA(b.CLAID), "delete cla bound")
+
+	mustNil(t, db.DeleteCLA(id), "delete cla")
+	_, err = db.GetCLA(id)
+	mustFail(t, err, "get cla deleted")
+}
+
+func testCLAMetadata(t *testing.T, db DB) {
+	data := models.CLAMetadata{Name: "cla", Text: "text", Language: "english", Submitter: "owner"}
+
+	id, err := db.CreateCLAMetadata(data)
+	mustNil(t, err, "create cla metadata")
+
+	_, err = db.CreateCLAMetadata(data)
+	mustFail(t, err, "create cla metadata with the same name")
+
+	v, err := db.GetCLAMetadata(id)
+	mustNil(t, err, "get cla metadata")
+	if v.ID != id || v.Name != "cla" {
+		t.Errorf("get cla metadata: unexpected metadata: %+v", v)
+	}
+
+	vs, err := db.ListCLAMetadata([]string{"owner", "other"})
+	mustNil(t, err, "list cla metadata")
+	if len(vs) != 1 {
+		t.Errorf("list cla metadata: expect 1, but got %d", len(vs))
+	}
+
+	mustNil(t, db.DeleteCLAMetadata(id), "delete cla metadata")
+	_, err = db.GetCLAMetadata(id)
+	mustFail(t, err, "get cla metadata deleted")
+}
+
+func testBinding(t *testing.T, db DB) {
+	id := createBinding(t, db, models.ApplyToIndividual, repoID)
+	other := createBinding(t, db, models.ApplyToIndividual, "other")
+
+	claID := createCLA(t, db, "dup", models.ApplyToIndividual)
+	_, err := db.CreateBindingBetweenCLAAndOrg(dbmodels.CLAOrg{
+		Platform: platform, OrgID: orgID, RepoID: repoID, CLAID: claID, CLALanguage: "english",
+		ApplyTo: models.ApplyToIndividual, OrgEmail: "org@example.org", Enabled: true, Submitter: "owner",
+	})
+	mustFail(t, err, "bind the same language twice")
+
+	b, err := db.GetBindingBetweenCLAAndOrg(id)
+	mustNil(t, err, "get binding")
+	if b.ID != id || b.RepoID != repoID || b.CLAVersion != 1 || !b.Enabled {
+		t.Errorf("get binding: unexpected binding: %+v", b)
+	}
+
+	v, err := db.ListBindingBetweenCLAAndOrg(dbmodels.CLAOrgListOption{Platform: platform, OrgID: orgID})
+	mustNil(t, err, "list bindings")
+	if v.Total != 2 || len(v.Bindings) != 2 {
+		t.Errorf("list bindings: expect 2, but got %d", len(v.Bindings))
+	}
+
+	v, err = db.ListBindingBetweenCLAAndOrg(dbmodels.CLAOrgListOption{Platform: platform, OrgID: orgID, RepoID: repoID})
+	mustNil(t, err, "list bindings of repo")
+	if len(v.Bindings) != 1 || v.Bindings[0].ID != id {
+		t.Errorf("list bindings of repo: unexpected bindings: %+v", v)
+	}
+
+	v, err = db.ListBindingBetweenCLAAndOrg(dbmodels.CLAOrgListOption{Platform: platform, OrgIDs: []string{"other"}})
+	mustNil(t, err, "list bindings of other orgs")
+	if len(v.Bindings) != 0 {
+		t.Errorf("list bindings of other orgs: expect none, but got %d", len(v.Bindings))
+	}
+
+	v, err = db.ListBindingBetweenCLAAndOrg(dbmodels.CLAOrgListOption{
+		Platform: platform, OrgID: orgID, Page: dbmodels.PageOption{Limit: 1, SortBy: "repo_id", Desc: true},
+	})
+	mustNil(t, err, "list the first page of bindings")
+	if v.Total != 2 || len(v.Bindings) != 1 || v.Bindings[0].ID != id || v.NextCursor == "" {
+		t.Errorf("list the first page of bindings: unexpected page: %+v", v)
+	}
+
+	v, err = db.ListBindingBetweenCLAAndOrg(dbmodels.CLAOrgListOption{
+		Platform: platform, OrgID: orgID,
+		Page: dbmodels.PageOption{Limit: 1, SortBy: "repo_id", Desc: true, Cursor: v.NextCursor},
+	})
+	mustNil(t, err, "list the last page of bindings")
+	if v.Total != 2 || len(v.Bindings) != 1 || v.Bindings[0].ID != other || v.NextCursor != "" {
+		t.Errorf("list the last page of bindings: unexpected page: %+v", v)
+	}
+
+	_, err = db.ListBindingBetweenCLAAndOrg(dbmodels.CLAOrgListOption{
+		Platform: platform, OrgID: orgID, Page: dbmodels.PageOption{SortBy: "org_email"},
+	})
+	mustFail(t, err, "list bindings sorted by invalid field")
+
+	mustNil(t, db.UpdateBindingCLAVersion(id, 2), "update cla version of binding")
+	b, err = db.GetBindingBetweenCLAAndOrg(id)
+	mustNil(t, err, "get binding after updating version")
+	if b.CLAVersion != 2 {
+		t.Errorf("update cla version of binding: expect 2, but got %d", b.CLAVersion)
+	}
+
+	mustFail(t, db.UpdateBindingDomainVerification(id, true), "require domain verification for individual binding")
+
+	_, err = db.GetBotToken(platform, orgID, repoID)
+	mustFail(t, err, "get bot token not set")
+	mustNil(t, db.SetBotToken(id, "token"), "set bot token")
+	token, err := db.GetBotToken(platform, orgID, repoID)
+	mustNil(t, err, "get bot token")
+	if token != "token" {
+		t.Errorf("get bot token: expect token, but got %s", token)
+	}
+
+	mustNil(t, db.DeleteBindingBetweenCLAAndOrg(other), "delete binding")
+	v, err = db.ListBindingBetweenCLAAndOrg(dbmodels.CLAOrgListOption{Platform: platform, OrgID: orgID})
+	mustNil(t, err, "list bindings after deleting")
+	if len(v.Bindings) != 1 || v.Bindings[0].ID != id {
+		t.Errorf("list bindings after deleting: unexpected bindings: %+v", v)
+	}
+	mustFail(t, db.SetBotToken(other, "token"), "set bot token of deleted binding")
+}
+
+func testIndividualSigning(t *testing.T, db DB) {
+	id := createBinding(t, db, models.ApplyToIndividual, repoID)
+
+	sign := func(email string, signedAt int64, version int) error {
+		return db.SignAsIndividual(id, dbmodels.IndividualSigningInfo{
+			Email:      email,
+			Info:       dbmodels.TypeSigningInfo{"1": email},
+			SignedAt:   signedAt,
+			CLAVersion: version,
+			Metadata:   &dbmodels.SigningMetadata{SignedBy: email, IP: "127.0.0.1"},
+		})
+	}
+
+	mustNil(t, sign("a@a.com", 100, 1), "sign as individual")
+	mustNil(t, sign("B@b.com", 300, 1), "sign as individual")
+	mustNil(t, sign("c@c.com", 200, 1), "sign as individual")
+	mustFail(t, sign("a@a.com", 400, 1), "sign the same version again")
+	mustNil(t, sign("a@a.com", 400, 2), "sign the new version")
+
+	r, err := db.CheckSigning(dbmodels.SigningCheckOption{Platform: platform, OrgID: orgID, RepoID: repoID, Email: "a@a.com"})
+	mustNil(t, err, "check signing")
+	if r == nil || r.Type != models.ApplyToIndividual || r.CLAOrgID != id || r.CLAVersion != 2 || r.SignedAt != 400 {
+		t.Errorf("check signing: unexpected result: %+v", r)
+	}
+
+	r, err = db.CheckSigning(dbmodels.SigningCheckOption{Platform: platform, OrgID: orgID, RepoID: repoID, Email: "x@x.com"})
+	mustNil(t, err, "check signing of unsigned")
+	if r != nil {
+		t.Errorf("check signing of unsigned: expect nil, but got %+v", r)
+	}
+
+	l, err := db.ListIndividualSigning(dbmodels.IndividualSigningListOption{
+		Platform: platform, OrgID: orgID, Page: dbmodels.PageOption{Desc: true},
+	})
+	mustNil(t, err, "list individual signings")
+	if l.Total != 3 || len(l.Signings) != 3 {
+		t.Fatalf("list individual signings: unexpected result: %+v", l)
+	}
+	for i, email := range []string{"a@a.com", "B@b.com", "c@c.com"} {
+		if l.Signings[i].Email != email || l.Signings[i].CLAOrgID != id {
+			t.Errorf("list individual signings: expect %s at %d, but got %+v", email, i, l.Signings[i])
+		}
+	}
+	if m := l.Signings[0].Metadata; m == nil || m.SignedBy != "a@a.com" {
+		t.Errorf("list individual signings: unexpected metadata: %+v", m)
+	}
+
+	l, err = db.ListIndividualSigning(dbmodels.IndividualSigningListOption{
+		Platform: platform, OrgID: orgID, EmailContains: "b@B", SignedFrom: 300, SignedTo: 300,
+	})
+	mustNil(t, err, "list individual signings with filters")
+	if l.Total != 1 || len(l.Signings) != 1 || l.Signings[0].Email != "B@b.com" {
+		t.Errorf("list individual signings with filters: unexpected result: %+v", l)
+	}
+
+	page := dbmodels.PageOption{Limit: 2, Desc: true}
+	l, err = db.ListIndividualSigning(dbmodels.IndividualSigningListOption{Platform: platform, OrgID: orgID, Page: page})
+	mustNil(t, err, "list individual signings by page")
+	if l.Total != 3 || len(l.Signings) != 2 || l.Signings[0].Email != "a@a.com" || l.NextCursor == "" {
+		t.Fatalf("list individual signings by page: unexpected result: %+v", l)
+	}
+
+	page.Cursor = l.NextCursor
+	l, err = db.ListIndividualSigning(dbmodels.IndividualSigningListOption{Platform: platform, OrgID: orgID, Page: page})
+	mustNil(t, err, "list individual signings of next page")
+	if l.Total != 3 || len(l.Signings) != 1 || l.Signings[0].Email != "c@c.com" || l.NextCursor != "" {
+		t.Errorf("list individual signings of next page: unexpected result: %+v", l)
+	}
+
+	revocation := dbmodels.SigningRevocation{Email: "c@c.com", RevokedBy: "owner", Reason: "test", RevokedAt: 500}
+	mustNil(t, db.RevokeIndividualSigning(id, revocation), "revoke individual signing")
+	mustFail(t, db.RevokeIndividualSigning(id, revocation), "revoke individual signing again")
+
+	rs, err := db.ListRevokedSigning(id)
+	mustNil(t, err, "list revoked signings")
+	if len(rs) != 1 || rs[0].Email != "c@c.com" || rs[0].SignedAt != 200 || rs[0].CLAVersion != 1 || rs[0].Reason != "test" {
+		t.Errorf("list revoked signings: unexpected result: %+v", rs)
+	}
+
+	r, err = db.CheckSigning(dbmodels.SigningCheckOption{Platform: platform, OrgID: orgID, RepoID: repoID, Email: "c@c.com"})
+	mustNil(t, err, "check signing of revoked")
+	if r != nil {
+		t.Errorf("check signing of revoked: expect nil, but got %+v", r)
+	}
+	mustNil(t, sign("c@c.com", 600, 1), "sign again after revoked")
+}
@@ -0,0 +1,328 @@
+package conformance
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+func testCorporationSigning(t *testing.T, db DB) {
+	id := createBinding(t, db, models.ApplyToCorporation, repoID)
+
+	signAsCorporation(t, db, id)
+	err := db.SignAsCorporation(id, dbmodels.CorporationSigningInfo{
+		AdminEmail: "other@example.com", AdminName: "other", CorporationName: "example", CorporationID: corpID,
+	})
+	mustFail(t, err, "sign as the same corporation again")
+
+	resign := dbmodels.CorporationSigningInfo{
+		AdminEmail: corpAdmin, AdminName: "admin2", CorporationName: "example", CorporationID: corpID,
+		CLAVersion: 1, SignedAt: 100,
+	}
+	mustFail(t, db.SignAsCorporation(id, resign), "sign the same version as corporation again")
+
+	opt := dbmodels.CorporationSigningListOption{Platform: platform, OrgID: orgID, RepoID: repoID}
+	v, err := db.ListCorporationSigning(opt)
+	mustNil(t, err, "list corporation signings")
+	if v.Total != 1 || len(v.Signings) != 1 {
+		t.Fatalf("list corporation signings: unexpected result: %+v", v)
+	}
+	if s := v.Signings[0]; s.CLAOrgID != id || s.AdminEmail != corpAdmin || s.Enabled || s.AdministratorEnabled || s.CLAVersion != 1 {
+		t.Errorf("list corporation signings: unexpected signing: %+v", s)
+	}
+
+	enabled := true
+	update := dbmodels.CorporationSigningUpdateInfo{Enabled: &enabled}
+	mustNil(t, db.UpdateCorporationSigning(id, corpAdmin, "example", dbmodels.CorporationSigningUpdateInfo{}), "update nothing")
+	mustFail(t, db.UpdateCorporationSigning(id, corpAdmin, "other", update), "enable the corporation not exist")
+	mustNil(t, db.UpdateCorporationSigning(id, corpAdmin, "example", update), "enable corporation")
+	mustFail(t, db.UpdateCorporationSigning(id, corpAdmin, "example", update), "enable corporation again")
+
+	v, err = db.ListCorporationSigning(opt)
+	mustNil(t, err, "list corporation signings after enabling")
+	if len(v.Signings) != 1 || !v.Signings[0].Enabled {
+		t.Errorf("enable corporation: unexpected result: %+v", v)
+	}
+
+	// sign the new version and keep the enabled status
+	resign.CLAVersion = 2
+	mustNil(t, db.SignAsCorporation(id, resign), "sign the new version as corporation")
+	mustFail(t, db.SignAsCorporation(id, resign), "sign the new version as corporation again")
+
+	v, err = db.ListCorporationSigning(opt)
+	mustNil(t, err, "list corporation signings after signing the new version")
+	if len(v.Signings) != 1 {
+		t.Fatalf("sign the new version as corporation: unexpected result: %+v", v)
+	}
+	if s := v.Signings[0]; !s.Enabled || s.CLAVersion != 2 || s.AdminName != "admin2" || s.AdminEmail != corpAdmin {
+		t.Errorf("sign the new version as corporation: unexpected signing: %+v", s)
+	}
+
+	mustNil(t, db.UpdateBindingDomainVerification(id, true), "require domain verification")
+	b, err := db.GetBindingBetweenCLAAndOrg(id)
+	mustNil(t, err, "get binding")
+	if !b.DomainVerificationRequired {
+		t.Errorf("require domain verification: it is not set")
+	}
+}
+
+func testCorporationManager(t *testing.T, db DB) {
+	id := createBinding(t, db, models.ApplyToCorporation, repoID)
+	signAsCorporation(t, db, id)
+
+	manager := func(role, email string) dbmodels.CorporationManagerCreateOption {
+		return dbmodels.CorporationManagerCreateOption{
+			Role: role, Email: email, Password: "pw", CorporationID: corpID, MustChangePassword: true,
+		}
+	}
+
+	admin := []dbmodels.CorporationManagerCreateOption{manager(models.RoleAdmin, corpAdmin)}
+	mustNil(t, db.AddCorporationManager(id, admin, 1), "add administrator")
+	mustFail(t, db.AddCorporationManager(id, []dbmodels.CorporationManagerCreateOption{
+		manager(models.RoleAdmin, "admin2@example.com"),
+	}, 1), "add administrator exceeding the limit")
+
+	managers := []dbmodels.CorporationManagerCreateOption{
+		manager(models.RoleManager, "m1@example.com"),
+		manager(models.RoleManager, "m2@example.com"),
+	}
+	mustNil(t, db.AddCorporationManager(id, managers, 5), "add managers")
+	mustFail(t, db.AddCorporationManager(id, managers[:1], 5), "add the same manager again")
+
+	ms, err := db.ListCorporationManager(id, dbmodels.CorporationManagerListOption{Role: models.RoleManager, CorporationID: corpID})
+	mustNil(t, err, "list managers")
+	if ms.Total != 2 || len(ms.Managers) != 2 {
+		t.Errorf("list managers: expect 2, but got %d", len(ms.Managers))
+	}
+
+	page := dbmodels.PageOption{Limit: 1}
+	ms, err = db.ListCorporationManager(id, dbmodels.CorporationManagerListOption{Role: models.RoleManager, CorporationID: corpID, Page: page})
+	mustNil(t, err, "list the first page of managers")
+	if ms.Total != 2 || len(ms.Managers) != 1 || ms.Managers[0].Email != "m1@example.com" || ms.NextCursor == "" {
+		t.Errorf("list the first page of managers: unexpected result: %+v", ms)
+	}
+
+	page.Cursor = ms.NextCursor
+	ms, err = db.ListCorporationManager(id, dbmodels.CorporationManagerListOption{Role: models.RoleManager, CorporationID: corpID, Page: page})
+	mustNil(t, err, "list the last page of managers")
+	if len(ms.Managers) != 1 || ms.Managers[0].Email != "m2@example.com" || ms.NextCursor != "" {
+		t.Errorf("list the last page of managers: unexpected result: %+v", ms)
+	}
+
+	page.Desc = true
+	_, err = db.ListCorporationManager(id, dbmodels.CorporationManagerListOption{Role: models.RoleManager, CorporationID: corpID, Page: page})
+	mustFail(t, err, "list managers with the cursor of other order")
+
+	all, err := db.ListAllCorporationManagers()
+	mustNil(t, err, "list all managers")
+	if len(all) != 3 {
+		t.Errorf("list all managers: expect 3, but got %d", len(all))
+	}
+
+	es, err := db.ListManagersWhenEmployeeSigning([]string{id}, corpID)
+	mustNil(t, err, "list managers when employee signing")
+	if len(es) != 3 {
+		t.Errorf("list managers when employee signing: expect 3, but got %d", len(es))
+	}
+
+	v, err := db.ListCorporationSigning(dbmodels.CorporationSigningListOption{Platform: platform, OrgID: orgID, RepoID: repoID})
+	mustNil(t, err, "list corporation signings")
+	if len(v.Signings) != 1 || !v.Signings[0].AdministratorEnabled {
+		t.Errorf("list corporation signings: the administrator should be enabled: %+v", v)
+	}
+
+	check := func(user string) []dbmodels.CorporationManagerCheckResult {
+		r, err := db.CheckCorporationManagerExist(dbmodels.CorporationManagerCheckInfo{User: user})
+		mustNil(t, err, "check manager")
+		return r
+	}
+
+	if r := check("m1@example.com"); len(r) != 1 || r[0].CLAOrgID != id || r[0].Role != models.RoleManager || r[0].Password != "pw" || !r[0].MustChangePassword {
+		t.Errorf("check manager: unexpected result: %+v", r)
+	}
+	if r := check("nobody@example.com"); len(r) != 0 {
+		t.Errorf("check manager not exist: unexpected result: %+v", r)
+	}
+
+	reset := dbmodels.CorporationManagerResetPassword{Email: "m1@example.com", OldPassword: "wrong", NewPassword: "new"}
+	mustFail(t, db.ResetCorporationManagerPassword(id, reset), "reset password with wrong old one")
+
+	reset.OldPassword = "pw"
+	mustNil(t, db.ResetCorporationManagerPassword(id, reset), "reset password")
+	if r := check("m1@example.com"); len(r) != 1 || r[0].Password != "new" || r[0].MustChangePassword {
+		t.Errorf("reset password: unexpected result: %+v", r)
+	}
+
+	mustFail(t, db.DeleteCorporationManager(id, []dbmodels.CorporationManagerCreateOption{
+		manager(models.RoleManager, "m1@example.com"), manager(models.RoleManager, "nobody@example.com"),
+	}), "delete managers not all registered")
+	mustNil(t, db.DeleteCorporationManager(id, managers[:1]), "delete manager")
+
+	ms, err = db.ListCorporationManager(id, dbmodels.CorporationManagerListOption{Role: models.RoleManager, CorporationID: corpID})
+	mustNil(t, err, "list managers after deleting")
+	if len(ms.Managers) != 1 || ms.Managers[0].Email != "m2@example.com" {
+		t.Errorf("list managers after deleting: unexpected result: %+v", ms)
+	}
+}
+
+func testEmployeeSigning(t *testing.T, db DB) {
+	ind := createBinding(t, db, models.ApplyToIndividual, repoID)
+	corp := createBinding(t, db, models.ApplyToCorporation, repoID)
+	signAsCorporation(t, db, corp)
+
+	employee := "e@example.com"
+	sign := func(version int) error {
+		return db.SignAsEmployee(ind, dbmodels.EmployeeSigningInfo{
+			Email: employee, Name: "e", Info: dbmodels.TypeSigningInfo{"1": "e"},
+			SignedAt: int64(100 * version), CLAVersion: version,
+		})
+	}
+
+	checkOpt := dbmodels.SigningCheckOption{Platform: platform, OrgID: orgID, RepoID: repoID, Email: employee}
+	checkSigned := func(expect bool, action string) {
+		t.Helper()
+
+		r, err := db.CheckSigning(checkOpt)
+		mustNil(t, err, action)
+		if (r != nil) != expect {
+			t.Fatalf("%s: expect signed=%v, but got %+v", action, expect, r)
+		}
+		if r != nil && (r.Type != models.ApplyToCorporation || r.CLAOrgID != ind) {
+			t.Errorf("%s: unexpected result: %+v", action, r)
+		}
+		if r != nil && (r.Corporation == nil || r.Corporation.CLAOrgID != corp || r.Corporation.CLAVersion != 1 ||
+			r.Corporation.CurrentCLAVersion != 1) {
+			t.Errorf("%s: unexpected signing of corporation: %+v", action, r.Corporation)
+		}
+	}
+
+	mustNil(t, sign(1), "sign as employee")
+	mustFail(t, sign(1), "sign as employee again")
+	checkSigned(false, "check signing of employee not enabled")
+
+	update := dbmodels.EmployeeSigningUpdateInfo{Enabled: true}
+	mustNil(t, db.UpdateEmployeeSigning(ind, employee, update), "enable employee")
+	mustFail(t, db.UpdateEmployeeSigning(ind, employee, update), "enable employee again")
+	checkSigned(false, "check signing when corporation is not enabled")
+
+	enabled := true
+	mustNil(t, db.UpdateCorporationSigning(corp, corpAdmin, "example", dbmodels.CorporationSigningUpdateInfo{Enabled: &enabled}), "enable corporation")
+	checkSigned(true, "check signing of employee")
+
+	// sign the new version and keep the enabled status
+	mustNil(t, sign(2), "sign the new version as employee")
+	v, err := db.ListEmployeeSigning(dbmodels.EmployeeSigningListOption{
+		Platform: platform, OrgID: orgID, RepoID: repoID, CorporationEmail: corpAdmin,
+	})
+	mustNil(t, err, "list employee signings")
+	if v.Total != 1 || len(v.Signings) != 1 {
+		t.Fatalf("list employee signings: unexpected result: %+v", v)
+	}
+	if e := v.Signings[0]; e.CLAOrgID != ind || e.Email != employee || !e.Enabled || e.CLAVersion != 2 || e.SignedAt != 200 {
+		t.Errorf("list employee signings: unexpected signing: %+v", e)
+	}
+
+	for i, email := range []string{"e1@example.com", "e2@example.com", "e3@example.com"} {
+		err := db.SignAsEmployee(ind, dbmodels.EmployeeSigningInfo{
+			Email: email, Name: "e", SignedAt: int64(300 - i*100), CLAVersion: 2,
+		})
+		mustNil(t, err, "sign as other employee")
+	}
+
+	// e2 and e signed at the same time, and they are sorted by email
+	expect := []string{"e3@example.com", "e2@example.com", "e@example.com", "e1@example.com"}
+	listOpt := dbmodels.EmployeeSigningListOption{
+		Platform: platform, OrgID: orgID, RepoID: repoID, CorporationEmail: corpAdmin,
+		Page: dbmodels.PageOption{Limit: 3},
+	}
+	var emails []string
+	for i := 0; i < 2; i++ {
+		v, err := db.ListEmployeeSigning(listOpt)
+		mustNil(t, err, "list the page of employee signings")
+		if v.Total != 4 {
+			t.Errorf("list the page of employee signings: expect total 4, but got %d", v.Total)
+		}
+
+		for _, e := range v.Signings {
+			emails = append(emails, e.Email)
+		}
+		listOpt.Page.Cursor = v.NextCursor
+	}
+	if listOpt.Page.Cursor != "" || strings.Join(emails, ",") != strings.Join(expect, ",") {
+		t.Errorf("list the pages of employee signings: expect %v, but got %v", expect, emails)
+	}
+
+	listOpt.Page = dbmodels.PageOption{SortBy: "email", Desc: true}
+	v, err = db.ListEmployeeSigning(listOpt)
+	mustNil(t, err, "list employee signings by email descendingly")
+	if len(v.Signings) != 4 || v.Signings[0].Email != "e@example.com" || v.NextCursor != "" {
+		t.Errorf("list employee signings by email descendingly: unexpected result: %+v", v)
+	}
+
+	revocation := dbmodels.SigningRevocation{Email: employee, RevokedBy: corpAdmin, Reason: "left", RevokedAt: 300}
+	mustNil(t, db.RevokeEmployeeSigning(ind, revocation), "revoke employee signing")
+	mustFail(t, db.RevokeEmployeeSigning(ind, revocation), "revoke employee signing again")
+	checkSigned(false, "check signing of revoked employee")
+
+	rs, err := db.ListRevokedSigning(ind)
+	mustNil(t, err, "list revoked signings")
+	if len(rs) != 1 || rs[0].Email != employee || rs[0].CLAVersion != 2 || rs[0].RevokedBy != corpAdmin {
+		t.Errorf("list revoked signings: unexpected result: %+v", rs)
+	}
+}
+
+func testCorporationDomain(t *testing.T, db DB) {
+	ind := createBinding(t, db, models.ApplyToIndividual, repoID)
+	id := createBinding(t, db, models.ApplyToCorporation, repoID)
+	signAsCorporation(t, db, id)
+
+	domain := dbmodels.CorporationDomain{Domain: "example.org", Token: "token", CreatedAt: 1}
+	mustNil(t, db.AddCorporationDomain(id, corpAdmin, domain), "add domain")
+	mustFail(t, db.AddCorporationDomain(id, corpAdmin, domain), "add domain again")
+	mustFail(t, db.AddCorporationDomain(id, corpAdmin, dbmodels.CorporationDomain{Domain: "example.com", Token: "token"}), "add the domain of administrator")
+	mustFail(t, db.AddCorporationDomain(id, "a@other.com", dbmodels.CorporationDomain{Domain: "other.org", Token: "token"}), "add domain for corporation not signed")
+
+	v, err := db.ListCorporationDomain(id, corpID)
+	mustNil(t, err, "list domains")
+	if len(v) != 1 || v[0].Domain != "example.org" || v[0].Token != "token" || v[0].Verified {
+		t.Errorf("list domains: unexpected result: %+v", v)
+	}
+
+	cid, err := db.GetCorporationIDByDomain(platform, orgID, repoID, "example.org")
+	mustNil(t, err, "get corporation by domain not verified")
+	if cid != "" {
+		t.Errorf("get corporation by domain not verified: expect none, but got %s", cid)
+	}
+
+	mustFail(t, db.VerifyCorporationDomain(id, corpAdmin, "example.net", 2), "verify domain not added")
+	mustFail(t, db.VerifyCorporationDomain(id, "other@example.com", "example.org", 2), "verify domain of others")
+	mustNil(t, db.VerifyCorporationDomain(id, corpAdmin, "example.org", 2), "verify domain")
+
+	v, err = db.ListCorporationDomain(id, corpID)
+	mustNil(t, err, "list domains after verifying")
+	if len(v) != 1 || !v[0].Verified || v[0].VerifiedAt != 2 {
+		t.Errorf("list domains after verifying: unexpected result: %+v", v)
+	}
+
+	cid, err = db.GetCorporationIDByDomain(platform, orgID, repoID, "example.org")
+	mustNil(t, err, "get corporation by domain")
+	if cid != corpID {
+		t.Errorf("get corporation by domain: expect %s, but got %s", corpID, cid)
+	}
+
+	// the employee in the additional domain belongs to the corporation
+	err = db.SignAsEmployee(ind, dbmodels.EmployeeSigningInfo{
+		Email: "e@example.org", Name: "e", SignedAt: 1, CLAVersion: 1, CorporationID: corpID,
+	})
+	mustNil(t, err, "sign as employee in additional domain")
+
+	es, err := db.ListEmployeeSigning(dbmodels.EmployeeSigningListOption{
+		Platform: platform, OrgID: orgID, RepoID: repoID, CorporationEmail: corpAdmin,
+	})
+	mustNil(t, err, "list employee signings")
+	if len(es.Signings) != 1 || es.Signings[0].Email != "e@example.org" {
+		t.Errorf("list employee signings: unexpected result: %+v", es)
+	}
+}
@@ -0,0 +1,337 @@
+package conformance
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+func testVerificationCode(t *testing.T, db DB) {
+	now := time.Now().Unix()
+	code := dbmodels.VerificationCode{
+		Email: "a@example.com", Code: "123456", Purpose: "signing", Expiry: now + 600,
+		CreatedAt: now, IP: "1.1.1.1",
+	}
+	mustNil(t, db.CreateVerificationCode(code), "create code")
+
+	checkCode := func(c string, expect bool, action string) {
+		t.Helper()
+
+		opt := code
+		opt.Code = c
+		ok, err := db.CheckVerificationCode(opt, 3)
+		mustNil(t, err, action)
+		if ok != expect {
+			t.Errorf("%s: expect %v, but got %v", action, expect, ok)
+		}
+	}
+
+	checkCode("000000", false, "check wrong code")
+	checkCode("123456", true, "check right code")
+	checkCode("123456", false, "check the used code")
+
+	mustNil(t, db.CreateVerificationCode(code), "create code again")
+	for i := 0; i < 3; i++ {
+		checkCode("000000", false, "guess code")
+	}
+	checkCode("123456", false, "check code after too many attempts")
+
+	mustNil(t, db.CreateVerificationCode(code), "resend code")
+	checkCode("123456", false, "check the resent code after too many attempts")
+
+	expired := code
+	expired.Purpose = "other"
+	expired.Expiry = now - 1
+	mustNil(t, db.CreateVerificationCode(expired), "create expired code")
+	ok, err := db.CheckVerificationCode(expired, 3)
+	mustNil(t, err, "check expired code")
+	if ok {
+		t.Errorf("check expired code: it should fail")
+	}
+
+	sent := func(email, purpose, ip string, since int64, expect bool, action string) {
+		t.Helper()
+
+		b, err := db.HasVerificationCodeSentSince(email, purpose, ip, since)
+		mustNil(t, err, action)
+		if b != expect {
+			t.Errorf("%s: expect %v, but got %v", action, expect, b)
+		}
+	}
+
+	sent(code.Email, code.Purpose, "", now, true, "check code sent to email")
+	sent(code.Email, code.Purpose, "", now+1, false, "check code sent to email later")
+	sent("b@example.com", code.Purpose, "1.1.1.1", now, true, "check code requested from ip")
+	sent("b@example.com", code.Purpose, "2.2.2.2", now, false, "check code sent to nobody")
+
+	// the attempts are reset once the old code has expired
+	later := code
+	later.CreatedAt = code.Expiry + 1
+	later.Expiry = later.CreatedAt + 600
+	mustNil(t, db.CreateVerificationCode(later), "resend code after the old one expired")
+	checkCode("123456", true, "check the code resent after the old one expired")
+}
+
+func testJob(t *testing.T, db DB) {
+	newJob := func(email string, nextRunAt, createdAt int64) string {
+		id, err := db.CreateJob(dbmodels.Job{
+			Kind: "email", CLAOrgID: "binding", CorporationID: corpID, Email: email,
+			Status: models.JobStatusPending, MaxAttempts: 3, NextRunAt: nextRunAt, CreatedAt: createdAt,
+		})
+		mustNil(t, err, "create job")
+		return id
+	}
+
+	job1 := newJob("a@example.com", 20, 1)
+	job2 := newJob("b@example.com", 10, 2)
+
+	claim := func(now, lockedUntil int64, token string) *dbmodels.Job {
+		t.Helper()
+
+		j, err := db.ClaimJob(now, lockedUntil, token)
+		mustNil(t, err, "claim job")
+		return j
+	}
+
+	if j := claim(5, 100, "c0"); j != nil {
+		t.Fatalf("claim job not due: unexpected job: %+v", j)
+	}
+	if j := claim(30, 100, "c1"); j == nil || j.ID != job2 || j.Status != models.JobStatusRunning || j.Attempts != 1 || j.LockedUntil != 100 || j.ClaimToken != "c1" {
+		t.Fatalf("claim job: expect the earliest one, but got %+v", j)
+	}
+	if j := claim(30, 100, "c2"); j == nil || j.ID != job1 {
+		t.Fatalf("claim job: expect the second one, but got %+v", j)
+	}
+	if j := claim(50, 100, "c3"); j != nil {
+		t.Fatalf("claim locked jobs: unexpected job: %+v", j)
+	}
+	if j := claim(101, 200, "c4"); j == nil || j.ID != job2 || j.Attempts != 2 || j.ClaimToken != "c4" {
+		t.Fatalf("claim job whose lock is expired: unexpected job: %+v", j)
+	}
+
+	pending := models.JobStatusPending
+	mustFail(t, db.UpdateJob(job2, dbmodels.JobUpdateInfo{
+		Status: &pending, ExpectedStatus: []string{models.JobStatusRunning}, ClaimToken: "c1",
+	}), "update job claimed by others")
+	mustNil(t, db.UpdateJob(job2, dbmodels.JobUpdateInfo{
+		Status: &pending, ExpectedStatus: []string{models.JobStatusRunning}, ClaimToken: "c4",
+	}), "update job with claim token")
+
+	done := models.JobStatusDone
+	mustFail(t, db.UpdateJob(job1, dbmodels.JobUpdateInfo{
+		Status: &done, ExpectedStatus: []string{models.JobStatusPending},
+	}), "update job in unexpected status")
+	mustNil(t, db.UpdateJob(job1, dbmodels.JobUpdateInfo{
+		Status: &done, ExpectedStatus: []string{models.JobStatusRunning},
+	}), "update job")
+
+	j, err := db.GetJob(job1)
+	mustNil(t, err, "get job")
+	if j.Status != done || j.Email != "a@example.com" {
+		t.Errorf("get job: unexpected job: %+v", j)
+	}
+
+	_, err = db.GetJob("000000000000000000000000")
+	mustFail(t, err, "get job not exist")
+
+	page := dbmodels.PageOption{Desc: true}
+	v, err := db.ListJob(dbmodels.JobListOption{CLAOrgID: "binding", Page: page})
+	mustNil(t, err, "list jobs")
+	if v.Total != 2 || len(v.Jobs) != 2 || v.Jobs[0].ID != job2 || v.Jobs[1].ID != job1 {
+		t.Errorf("list jobs: expect the newer one first, but got %+v", v)
+	}
+
+	page.Limit = 1
+	v, err = db.ListJob(dbmodels.JobListOption{CLAOrgID: "binding", Page: page})
+	mustNil(t, err, "list jobs by page")
+	if v.Total != 2 || len(v.Jobs) != 1 || v.Jobs[0].ID != job2 || v.NextCursor == "" {
+		t.Fatalf("list jobs by page: unexpected result: %+v", v)
+	}
+
+	page.Cursor = v.NextCursor
+	v, err = db.ListJob(dbmodels.JobListOption{CLAOrgID: "binding", Page: page})
+	mustNil(t, err, "list jobs of next page")
+	if v.Total != 2 || len(v.Jobs) != 1 || v.Jobs[0].ID != job1 || v.NextCursor != "" {
+		t.Errorf("list jobs of next page: unexpected result: %+v", v)
+	}
+
+	v, err = db.ListJob(dbmodels.JobListOption{CLAOrgID: "binding", Status: done})
+	mustNil(t, err, "list jobs by status")
+	if len(v.Jobs) != 1 || v.Jobs[0].ID != job1 {
+		t.Errorf("list jobs by status: unexpected result: %+v", v)
+	}
+}
+
+func testAccessToken(t *testing.T, db DB) {
+	expiry := time.Now().Unix() + 3600
+	for _, item := range []dbmodels.RefreshToken{
+		{ID: "t1", Token: "token1", User: "u", Permission: "owner", Expiry: expiry},
+		{ID: "t2", Token: "token2", User: "u", Permission: "owner", Expiry: expiry},
+		{ID: "t3", Token: "token3", User: "v", Permission: "owner", Expiry: expiry},
+	} {
+		mustNil(t, db.CreateRefreshToken(item), "create refresh token")
+	}
+
+	r, err := db.GetRefreshToken("t1")
+	mustNil(t, err, "get refresh token")
+	if r.Token != "token1" || r.User != "u" {
+		t.Errorf("get refresh token: unexpected result: %+v", r)
+	}
+
+	mustNil(t, db.DeleteRefreshToken("t1"), "delete refresh token")
+	_, err = db.GetRefreshToken("t1")
+	mustFail(t, err, "get deleted refresh token")
+
+	v, err := db.DeleteRefreshTokensOfUser("u")
+	mustNil(t, err, "delete refresh tokens of user")
+	if len(v) != 1 || v[0].ID != "t2" {
+		t.Errorf("delete refresh tokens of user: unexpected result: %+v", v)
+	}
+
+	_, err = db.GetRefreshToken("t3")
+	mustNil(t, err, "get refresh token of other user")
+
+	mustNil(t, db.RevokeSession(dbmodels.RevokedSession{ID: "s1", Expiry: expiry}), "revoke session")
+
+	for _, item := range []struct {
+		id     string
+		expect bool
+	}{{"s1", true}, {"s2", false}} {
+		b, err := db.IsSessionRevoked(item.id)
+		mustNil(t, err, "check session")
+		if b != item.expect {
+			t.Errorf("check session %s: expect %v, but got %v", item.id, item.expect, b)
+		}
+	}
+}
+
+func testAuditLog(t *testing.T, db DB) {
+	logs := []dbmodels.AuditLog{
+		{Actor: "owner", Action: "create", CLAOrgID: "b1", CreatedAt: 100},
+		{Actor: "owner", Action: "delete", CLAOrgID: "b1", CreatedAt: 300},
+		{Actor: corpAdmin, Action: "create", CLAOrgID: "b1", CorporationID: corpID, CreatedAt: 200},
+		{Actor: "owner", Action: "create", CLAOrgID: "b2", CreatedAt: 400},
+	}
+	for _, item := range logs {
+		mustNil(t, db.AddAuditLog(item), "add audit log")
+	}
+
+	desc := dbmodels.PageOption{Desc: true}
+
+	list := func(opt dbmodels.AuditLogListOption, total int, expect ...int64) dbmodels.AuditLogPage {
+		t.Helper()
+
+		r, err := db.ListAuditLog(opt)
+		mustNil(t, err, "list audit logs")
+		if r.Total != total || len(r.Logs) != len(expect) {
+			t.Fatalf("list audit logs with %+v: unexpected result: %+v", opt, r)
+		}
+		for i, item := range r.Logs {
+			if item.CreatedAt != expect[i] || item.ID == "" {
+				t.Errorf("list audit logs with %+v: unexpected log at %d: %+v", opt, i, item)
+			}
+		}
+		return r
+	}
+
+	list(dbmodels.AuditLogListOption{}, 4, 100, 200, 300, 400)
+	list(dbmodels.AuditLogListOption{Page: desc}, 4, 400, 300, 200, 100)
+	list(dbmodels.AuditLogListOption{CLAOrgID: "b1", Page: desc}, 3, 300, 200, 100)
+	list(dbmodels.AuditLogListOption{CorporationID: corpID}, 1, 200)
+	list(dbmodels.AuditLogListOption{Actor: "owner", Action: "create", Page: desc}, 2, 400, 100)
+	list(dbmodels.AuditLogListOption{From: 200, To: 300, Page: desc}, 2, 300, 200)
+	list(dbmodels.AuditLogListOption{Actor: "nobody"}, 0)
+
+	page := dbmodels.PageOption{Limit: 3, Desc: true}
+	r := list(dbmodels.AuditLogListOption{Page: page}, 4, 400, 300, 200)
+	if r.NextCursor == "" {
+		t.Fatalf("list audit logs by page: expect next cursor")
+	}
+
+	page.Cursor = r.NextCursor
+	if r = list(dbmodels.AuditLogListOption{Page: page}, 4, 100); r.NextCursor != "" {
+		t.Errorf("list audit logs of next page: unexpected next cursor: %s", r.NextCursor)
+	}
+}
+
+func testMisc(t *testing.T, db DB) {
+	// org email
+	email := dbmodels.OrgEmailCreateInfo{
+		Email: "bot@example.com", Platform: "gmail", Token: []byte("token"), Submitter: "bot@example.com",
+	}
+	mustNil(t, db.CreateOrgEmail(email), "create org email")
+	email.Token = []byte("new token")
+	mustNil(t, db.CreateOrgEmail(email), "update org email")
+
+	other := email
+	other.Platform, other.Token, other.Submitter = "smtp", []byte("other token"), "owner"
+	mustFail(t, db.CreateOrgEmail(other), "update org email by others")
+
+	e, err := db.GetOrgEmailInfo(email.Email)
+	mustNil(t, err, "get org email")
+	if e.Platform != "gmail" || string(e.Token) != "new token" || dbmodels.OrgEmailOwner(e) != email.Email {
+		t.Errorf("get org email: unexpected result: %+v", e)
+	}
+
+	other.Email = "smtp@example.com"
+	mustNil(t, db.CreateOrgEmail(other), "create smtp org email")
+	mustNil(t, db.CreateOrgEmail(other), "update smtp org email")
+	other.Submitter = "owner1"
+	mustFail(t, db.CreateOrgEmail(other), "update smtp org email by others")
+	_, err = db.GetOrgEmailInfo("nobody@example.com")
+	mustFail(t, err, "get org email not exist")
+
+	// platform token
+	mustNil(t, db.SavePlatformToken(dbmodels.PlatformToken{User: "github/u", Token: "t1"}), "save platform token")
+	mustNil(t, db.SavePlatformToken(dbmodels.PlatformToken{User: "github/u", Token: "t2"}), "update platform token")
+
+	pt, err := db.GetPlatformToken("github/u")
+	mustNil(t, err, "get platform token")
+	if pt.Token != "t2" {
+		t.Errorf("get platform token: expect t2, but got %s", pt.Token)
+	}
+	_, err = db.GetPlatformToken("github/v")
+	mustFail(t, err, "get platform token not exist")
+
+	// email templates
+	id := createBinding(t, db, models.ApplyToCorporation, repoID)
+	tmpl := dbmodels.EmailTemplate{Subject: "subject", Text: "text"}
+	mustNil(t, db.SetEmailTemplate(id, "signing", tmpl), "set email template")
+	mustFail(t, db.SetEmailTemplate("000000000000000000000000", "signing", tmpl), "set email template of binding not exist")
+
+	ts, err := db.ListEmailTemplate(id)
+	mustNil(t, err, "list email templates")
+	if len(ts) != 1 || ts["signing"] != tmpl {
+		t.Errorf("list email templates: unexpected result: %+v", ts)
+	}
+
+	mustNil(t, db.DeleteEmailTemplate(id, "signing"), "delete email template")
+	ts, err = db.ListEmailTemplate(id)
+	mustNil(t, err, "list email templates after deleting")
+	if len(ts) != 0 {
+		t.Errorf("list email templates after deleting: unexpected result: %+v", ts)
+	}
+
+	// signatures
+	_, err = db.DownloadOrgSignature(id)
+	mustFail(t, err, "download org signature not uploaded")
+
+	pdf := []byte("%PDF-1.4")
+	mustNil(t, db.UploadOrgSignature(id, pdf), "upload org signature")
+	b, err := db.DownloadOrgSignature(id)
+	mustNil(t, err, "download org signature")
+	if !bytes.Equal(b, pdf) {
+		t.Errorf("download org signature: unexpected content")
+	}
+
+	mustNil(t, db.UploadBlankSignature("english", pdf), "upload blank signature")
+	mustNil(t, db.UploadBlankSignature("english", []byte("other")), "upload blank signature again")
+	b, err = db.DownloadBlankSignature("english")
+	mustNil(t, err, "download blank signature")
+	if !bytes.Equal(b, pdf) {
+		t.Errorf("download blank signature: the first one should be kept")
+	}
+}
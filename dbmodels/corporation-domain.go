@@ -0,0 +1,25 @@
+package dbmodels
+
+// CorporationDomain is the email domain claimed by corporation. The employees
+// whose email is in the verified domain belong to the corporation.
+type CorporationDomain struct {
+	Domain string `json:"domain" required:"true"`
+
+	// Token is the value of DNS TXT record to prove the ownership of domain
+	Token      string `json:"token" required:"true"`
+	Verified   bool   `json:"verified"`
+	VerifiedAt int64  `json:"verified_at,omitempty"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+type ICorporationDomain interface {
+	// AddCorporationDomain adds the domain to the corporation whose administrator
+	// is adminEmail. It fails if the domain has been claimed by other corporation.
+	AddCorporationDomain(claOrgID, adminEmail string, opt CorporationDomain) error
+	// VerifyCorporationDomain marks the domain as verified
+	VerifyCorporationDomain(claOrgID, adminEmail, domain string, verifiedAt int64) error
+	ListCorporationDomain(claOrgID, corporationID string) ([]CorporationDomain, error)
+	// GetCorporationIDByDomain returns the corporation which has verified the domain
+	// in the corporation binding of org/repo. It returns empty string if not found.
+	GetCorporationIDByDomain(platform, orgID, repoID, domain string) (string, error)
+}
@@ -5,17 +5,23 @@ type CorporationManagerCreateOption struct {
 	Email         string `json:"email" required:"true"`
 	Password      string `json:"password" required:"true"`
 	CorporationID string `json:"corporation_id" required:"true"`
+
+	// MustChangePassword means the manager should change the initial password at first login
+	MustChangePassword bool `json:"must_change_password"`
 }
 
 type CorporationManagerCheckInfo struct {
-	Password string
-	User     string
+	User string
 }
 
 type CorporationManagerResetPassword struct {
-	Email       string
+	Email string
+
+	// OldPassword and NewPassword are hashed
 	OldPassword string
 	NewPassword string
+
+	MustChangePassword bool
 }
 
 type CorporationManagerCheckResult struct {
@@ -25,11 +31,24 @@ type CorporationManagerCheckResult struct {
 	Platform string `json:"platform"`
 	OrgID    string `json:"org_id"`
 	RepoID   string `json:"repo_id"`
+
+	// Password is the hashed password
+	Password           string `json:"-"`
+	MustChangePassword bool   `json:"must_change_password"`
 }
 
 type CorporationManagerListOption struct {
 	Role          string `json:"role"`
 	CorporationID string `json:"corporation_id"`
+
+	// Page is sorted by one of CorporationManagerSortFields
+	Page PageOption `json:"-"`
+}
+
+type CorporationManagerPage struct {
+	PageInfo
+
+	Managers []CorporationManagerListResult `json:"managers"`
 }
 
 type CorporationManagerListResult struct {
@@ -5,15 +5,20 @@ type TypeSigningInfo map[string]string
 type CorporationSigningDetails struct {
 	CorporationSigningInfo
 	AdministratorEnabled bool
+
+	CLAOrgID string
 }
 
 type CorporationSigningInfo struct {
-	AdminEmail      string          `json:"admin_email" required:"true"`
-	AdminName       string          `json:"admin_name" required:"true"`
-	CorporationName string          `json:"corporation_name" required:"true"`
-	CorporationID   string          `json:"corporation_id" required:"true"`
-	Enabled         bool            `json:"enabled"`
-	Info            TypeSigningInfo `json:"info,omitempty"`
+	AdminEmail      string           `json:"admin_email" required:"true"`
+	AdminName       string           `json:"admin_name" required:"true"`
+	CorporationName string           `json:"corporation_name" required:"true"`
+	CorporationID   string           `json:"corporation_id" required:"true"`
+	Enabled         bool             `json:"enabled"`
+	Info            TypeSigningInfo  `json:"info,omitempty"`
+	CLAVersion      int              `json:"cla_version,omitempty"`
+	SignedAt        int64            `json:"signed_at,omitempty"`
+	Metadata        *SigningMetadata `json:"metadata,omitempty"`
 }
 
 type CorporationSigningListOption struct {
@@ -21,6 +26,15 @@ type CorporationSigningListOption struct {
 	OrgID       string `json:"org_id" required:"true"`
 	RepoID      string `json:"repo_id" required:"true"`
 	CLALanguage string `json:"cla_language,omitempty"`
+
+	// Page is sorted by one of CorporationSigningSortFields
+	Page PageOption `json:"-"`
+}
+
+type CorporationSigningPage struct {
+	PageInfo
+
+	Signings []CorporationSigningDetails `json:"signings"`
 }
 
 type CorporationSigningUpdateInfo struct {
@@ -20,11 +20,20 @@ type IDB interface {
 	ICLA
 	IVerifiCode
 	IPDF
+	IPlatformToken
+	IJob
+	IEmailTemplate
+	IAccessToken
+	ISigningRevocation
+	IAuditLog
+	ICorporationDomain
 }
 
 type ICorporationSigning interface {
+	// SignAsCorporation signs again if the corporation has signed an older
+	// version of cla in the same binding by the same administrator.
 	SignAsCorporation(string, CorporationSigningInfo) error
-	ListCorporationSigning(CorporationSigningListOption) (map[string][]CorporationSigningDetails, error)
+	ListCorporationSigning(CorporationSigningListOption) (CorporationSigningPage, error)
 	UpdateCorporationSigning(claOrgID, adminEmail, corporationName string, opt CorporationSigningUpdateInfo) error
 }
 
@@ -33,13 +42,14 @@ type ICorporationManager interface {
 	AddCorporationManager(claOrgID string, opt []CorporationManagerCreateOption, managerNumber int) error
 	DeleteCorporationManager(claOrgID string, opt []CorporationManagerCreateOption) error
 	ResetCorporationManagerPassword(string, CorporationManagerResetPassword) error
-	ListCorporationManager(claOrgID string, opt CorporationManagerListOption) ([]CorporationManagerListResult, error)
+	ListCorporationManager(claOrgID string, opt CorporationManagerListOption) (CorporationManagerPage, error)
 	ListManagersWhenEmployeeSigning(claOrgIDs []string, corporID string) ([]CorporationManagerListResult, error)
+	ListAllCorporationManagers() ([]CorporationManagerCheckResult, error)
 }
 
 type IEmployeeSigning interface {
 	SignAsEmployee(claOrgID string, info EmployeeSigningInfo) error
-	ListEmployeeSigning(EmployeeSigningListOption) (map[string][]EmployeeSigningInfo, error)
+	ListEmployeeSigning(EmployeeSigningListOption) (EmployeeSigningPage, error)
 	UpdateEmployeeSigning(claOrgID, email string, opt EmployeeSigningUpdateInfo) error
 }
 
@@ -49,27 +59,51 @@ type IOrgEmail interface {
 }
 
 type ICLAOrg interface {
-	ListBindingBetweenCLAAndOrg(CLAOrgListOption) ([]CLAOrg, error)
+	// ListBindingBetweenCLAAndOrg lists the bindings of repo if there are, otherwise the ones of org
+	ListBindingBetweenCLAAndOrg(CLAOrgListOption) (CLAOrgPage, error)
 	GetBindingBetweenCLAAndOrg(string) (CLAOrg, error)
 	CreateBindingBetweenCLAAndOrg(CLAOrg) (string, error)
 	DeleteBindingBetweenCLAAndOrg(string) error
+	// UpdateBindingCLAVersion changes the version of cla which the binding points to
+	UpdateBindingCLAVersion(claOrgID string, version int) error
+
+	// SetBotToken saves the token used by bot to access the code platform for the binding
+	SetBotToken(claOrgID, token string) error
+	// GetBotToken returns the bot token of the binding of org/repo. The token of
+	// binding for the repo is preferred to the one for the whole org.
+	GetBotToken(platform, orgID, repoID string) (string, error)
+
+	// UpdateBindingDomainVerification sets whether the corporation must verify
+	// the domain of its email before being enabled
+	UpdateBindingDomainVerification(claOrgID string, required bool) error
 }
 
 type IIndividualSigning interface {
 	SignAsIndividual(string, IndividualSigningInfo) error
+	// CheckSigning checks whether the contributor has signed as individual or
+	// as employee of an enabled corporation. It returns nil if not.
+	CheckSigning(opt SigningCheckOption) (*SigningCheckResult, error)
+	ListIndividualSigning(opt IndividualSigningListOption) (IndividualSigningPage, error)
 }
 
 type ICLA interface {
 	CreateCLA(CLA) (string, error)
-	ListCLA(CLAListOptions) ([]CLA, error)
+	ListCLA(CLAListOptions) (CLAPage, error)
 	GetCLA(string) (CLA, error)
 	DeleteCLA(string) error
 	ListCLAByIDs(ids []string) ([]CLA, error)
+	// AddCLAVersion publishes the version which must be the next one of the latest version
+	AddCLAVersion(claID string, v CLAVersion) error
 }
 
 type IVerifiCode interface {
 	CreateVerificationCode(opt VerificationCode) error
-	CheckVerificationCode(opt VerificationCode) (bool, error)
+	// CheckVerificationCode checks the code and counts the attempt. The code
+	// can't pass the check any more once it has been tried maxAttempts times.
+	CheckVerificationCode(opt VerificationCode, maxAttempts int) (bool, error)
+	// HasVerificationCodeSentSince checks whether a code has been sent to
+	// the email for the purpose or requested from the ip since the time.
+	HasVerificationCodeSentSince(email, purpose, ip string, since int64) (bool, error)
 }
 
 type IPDF interface {
@@ -79,3 +113,42 @@ type IPDF interface {
 	UploadBlankSignature(language string, pdf []byte) error
 	DownloadBlankSignature(language string) ([]byte, error)
 }
+
+type IPlatformToken interface {
+	SavePlatformToken(opt PlatformToken) error
+	GetPlatformToken(user string) (PlatformToken, error)
+}
+
+type IJob interface {
+	CreateJob(Job) (string, error)
+	// ClaimJob picks up a job which is due to run and locks it until lockedUntil.
+	// It returns nil if there is no such job.
+	ClaimJob(now, lockedUntil int64, claimToken string) (*Job, error)
+	UpdateJob(uid string, opt JobUpdateInfo) error
+	GetJob(uid string) (Job, error)
+	ListJob(opt JobListOption) (JobPage, error)
+}
+
+type IEmailTemplate interface {
+	SetEmailTemplate(claOrgID, kind string, opt EmailTemplate) error
+	DeleteEmailTemplate(claOrgID, kind string) error
+	// ListEmailTemplate returns the customized templates of binding, key is the kind of template.
+	ListEmailTemplate(claOrgID string) (map[string]EmailTemplate, error)
+}
+
+type IAccessToken interface {
+	CreateRefreshToken(opt RefreshToken) error
+	GetRefreshToken(id string) (RefreshToken, error)
+	DeleteRefreshToken(id string) error
+	// DeleteRefreshTokensOfUser deletes all the refresh tokens of user and returns them
+	DeleteRefreshTokensOfUser(user string) ([]RefreshToken, error)
+
+	RevokeSession(opt RevokedSession) error
+	IsSessionRevoked(id string) (bool, error)
+}
+
+// IAuditLog is append-only, the logs can't be changed or deleted once added.
+type IAuditLog interface {
+	AddAuditLog(AuditLog) error
+	ListAuditLog(AuditLogListOption) (AuditLogPage, error)
+}
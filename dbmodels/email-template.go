@@ -0,0 +1,7 @@
+package dbmodels
+
+type EmailTemplate struct {
+	Subject string `json:"subject" required:"true"`
+	Text    string `json:"text" required:"true"`
+	HTML    string `json:"html,omitempty"`
+}
@@ -5,6 +5,14 @@ type EmployeeSigningInfo struct {
 	Name    string          `json:"name" required:"true"`
 	Enabled bool            `json:"enabled"`
 	Info    TypeSigningInfo `json:"info,omitempty"`
+
+	SignedAt   int64            `json:"signed_at,omitempty"`
+	CLAVersion int              `json:"cla_version,omitempty"`
+	Metadata   *SigningMetadata `json:"metadata,omitempty"`
+
+	// CorporationID is the corporation which the employee belongs to. It is the
+	// domain of email if empty, otherwise the email is in an additional domain.
+	CorporationID string `json:"-"`
 }
 
 type EmployeeSigningListOption struct {
@@ -13,8 +21,26 @@ type EmployeeSigningListOption struct {
 	RepoID           string `json:"repo_id,omitempty"`
 	CLALanguage      string `json:"cla_language,omitempty"`
 	CorporationEmail string `json:"-"`
+
+	// Page is sorted by one of EmployeeSigningSortFields
+	Page PageOption `json:"-"`
+}
+
+type EmployeeSigningDetail struct {
+	EmployeeSigningInfo
+
+	CLAOrgID string `json:"cla_org_id"`
+}
+
+type EmployeeSigningPage struct {
+	PageInfo
+
+	Signings []EmployeeSigningDetail `json:"signings"`
 }
 
 type EmployeeSigningUpdateInfo struct {
 	Enabled bool
+
+	// CorporationID is the same as the one of EmployeeSigningInfo
+	CorporationID string
 }
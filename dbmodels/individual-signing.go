@@ -1,6 +1,84 @@
 package dbmodels
 
 type IndividualSigningInfo struct {
-	Email string          `json:"email" required:"true"`
-	Info  TypeSigningInfo `json:"info,omitempty"`
+	Email    string          `json:"email" required:"true"`
+	Info     TypeSigningInfo `json:"info,omitempty"`
+	SignedAt int64           `json:"signed_at,omitempty"`
+
+	// CLAVersion is the version of cla accepted by the signer
+	CLAVersion int `json:"cla_version,omitempty"`
+
+	Metadata *SigningMetadata `json:"metadata,omitempty"`
+}
+
+type SigningCheckOption struct {
+	Platform string
+	OrgID    string
+	RepoID   string
+	Email    string
+
+	// CorporationID is the corporation which the contributor may be employee of.
+	// It is the domain of email if empty.
+	CorporationID string
+}
+
+// SigningCheckResult tells how the contributor has signed the cla.
+type SigningCheckResult struct {
+	// Type is the type of cla signed, individual or corporation
+	Type        string `json:"type"`
+	CLAOrgID    string `json:"cla_org_id"`
+	CLAID       string `json:"cla_id"`
+	CLALanguage string `json:"cla_language"`
+
+	// SignedAt is zero if the signing was done before the time was recorded
+	SignedAt int64 `json:"signed_at"`
+
+	// CLAVersion is the version signed, and CurrentCLAVersion is the
+	// version which the binding points to now.
+	CLAVersion        int `json:"cla_version"`
+	CurrentCLAVersion int `json:"current_cla_version"`
+
+	// Corporation is the signing of corporation which the employee belongs
+	// to, it is nil if the contributor signed as individual.
+	Corporation *CorporationSigningCheckResult `json:"corporation,omitempty"`
+}
+
+// CorporationSigningCheckResult is the cla signed by the corporation.
+type CorporationSigningCheckResult struct {
+	CLAOrgID string `json:"cla_org_id"`
+	CLAID    string `json:"cla_id"`
+
+	CLAVersion        int `json:"cla_version"`
+	CurrentCLAVersion int `json:"current_cla_version"`
+}
+
+type IndividualSigningListOption struct {
+	Platform    string
+	OrgID       string
+	RepoID      string
+	CLALanguage string
+
+	// EmailContains matches the email case-insensitively if it is not empty
+	EmailContains string
+
+	// SignedFrom and SignedTo are the range of signing time, zero means unlimited
+	SignedFrom int64
+	SignedTo   int64
+
+	// Page is sorted by one of IndividualSigningSortFields
+	Page PageOption
+}
+
+type IndividualSigningDetail struct {
+	IndividualSigningInfo
+
+	CLAOrgID    string `json:"cla_org_id"`
+	RepoID      string `json:"repo_id"`
+	CLALanguage string `json:"cla_language"`
+}
+
+type IndividualSigningPage struct {
+	PageInfo
+
+	Signings []IndividualSigningDetail `json:"signings"`
 }
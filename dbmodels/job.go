@@ -0,0 +1,51 @@
+package dbmodels
+
+type Job struct {
+	ID            string `json:"id,omitempty"`
+	Kind          string `json:"kind" required:"true"`
+	CLAOrgID      string `json:"cla_org_id" required:"true"`
+	CorporationID string `json:"corporation_id,omitempty"`
+	Email         string `json:"email" required:"true"`
+	Payload       string `json:"payload,omitempty"`
+	Status        string `json:"status" required:"true"`
+	Attempts      int    `json:"attempts"`
+	MaxAttempts   int    `json:"max_attempts" required:"true"`
+	NextRunAt     int64  `json:"next_run_at"`
+	LockedUntil   int64  `json:"locked_until"`
+	LastError     string `json:"last_error,omitempty"`
+	CreatedAt     int64  `json:"created_at"`
+	UpdatedAt     int64  `json:"updated_at"`
+
+	// ClaimToken is changed every time the job is claimed.
+	ClaimToken string `json:"-"`
+}
+
+type JobUpdateInfo struct {
+	Status    *string `json:"status,omitempty"`
+	Attempts  *int    `json:"attempts,omitempty"`
+	LastError *string `json:"last_error,omitempty"`
+	NextRunAt *int64  `json:"next_run_at,omitempty"`
+
+	// ExpectedStatus is the status the job must be in, if it is set.
+	ExpectedStatus []string `json:"-"`
+
+	// ClaimToken is the token of claim which the job must hold, if it is set.
+	// It prevents a worker whose lease has expired from overwriting the job
+	// which has been claimed by another one.
+	ClaimToken string `json:"-"`
+}
+
+type JobListOption struct {
+	CLAOrgID      string `json:"cla_org_id" required:"true"`
+	Status        string `json:"status,omitempty"`
+	CorporationID string `json:"corporation_id,omitempty"`
+
+	// Page is sorted by one of JobSortFields
+	Page PageOption `json:"-"`
+}
+
+type JobPage struct {
+	PageInfo
+
+	Jobs []Job `json:"jobs"`
+}
@@ -4,4 +4,17 @@ type OrgEmailCreateInfo struct {
 	Email    string `json:"email" required:"true"`
 	Platform string `json:"platform" required:"true"`
 	Token    []byte `json:"-"`
+
+	// Submitter is who added the email, and only it can update the email.
+	// The email authorized by oauth2 is submitted by itself, which is also
+	// the submitter of the email added before it was recorded.
+	Submitter string `json:"-"`
+}
+
+// OrgEmailOwner returns who can update the org email.
+func OrgEmailOwner(info OrgEmailCreateInfo) string {
+	if info.Submitter == "" {
+		return info.Email
+	}
+	return info.Submitter
 }
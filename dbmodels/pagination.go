@@ -0,0 +1,123 @@
+package dbmodels
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// The fields which the lists can be sorted by. The first one of each is the default.
+var (
+	CLASortFields                = []string{"name", "language"}
+	CLAOrgSortFields             = []string{"repo_id", "cla_language"}
+	CorporationSigningSortFields = []string{"signed_at", "corporation_name", "admin_email"}
+	EmployeeSigningSortFields    = []string{"signed_at", "email", "name"}
+	CorporationManagerSortFields = []string{"email"}
+	IndividualSigningSortFields  = []string{"signed_at", "email"}
+	AuditLogSortFields           = []string{"created_at"}
+	JobSortFields                = []string{"created_at"}
+)
+
+// PageOption specifies a page of the list which is ordered by SortBy. The items
+// having the same value of SortBy are ordered by their unique keys, so that the
+// cursor points to an exact position in the list.
+type PageOption struct {
+	// Cursor is the NextCursor of the previous page, empty means the first page
+	Cursor string
+
+	// Limit is the max number of items in the page, zero means all the items
+	Limit int
+
+	// SortBy is the default sort field of the list if it is empty
+	SortBy string
+	Desc   bool
+}
+
+type PageInfo struct {
+	Total int `json:"total"`
+
+	// NextCursor is empty if it is the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type pageCursor struct {
+	SortBy string        `json:"s"`
+	Desc   bool          `json:"d"`
+	Keys   []interface{} `json:"k"`
+}
+
+// SortField returns the field to sort by, which must be one of fields.
+func (p PageOption) SortField(fields []string) (string, error) {
+	if p.SortBy == "" {
+		return fields[0], nil
+	}
+
+	for _, item := range fields {
+		if item == p.SortBy {
+			return item, nil
+		}
+	}
+	return "", fmt.Errorf("invalid sort field: %s", p.SortBy)
+}
+
+// EncodeCursor returns the cursor of the item whose sort keys are keys.
+// The keys can only be string or int64.
+func (p PageOption) EncodeCursor(keys ...interface{}) string {
+	b, err := json.Marshal(pageCursor{SortBy: p.SortBy, Desc: p.Desc, Keys: keys})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Validate checks the sort field and whether the cursor was created in the same order.
+func (p PageOption) Validate(fields []string) error {
+	if _, err := p.SortField(fields); err != nil {
+		return err
+	}
+
+	_, err := p.DecodeCursor(-1)
+	return err
+}
+
+// DecodeCursor returns the n sort keys of the item which the cursor points to,
+// and the number of keys is not checked if n is negative. It returns nil if
+// there is no cursor.
+func (p PageOption) DecodeCursor(n int) ([]interface{}, error) {
+	if p.Cursor == "" {
+		return nil, nil
+	}
+
+	invalid := fmt.Errorf("invalid cursor: %s", p.Cursor)
+
+	b, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+	if err != nil {
+		return nil, invalid
+	}
+
+	var v pageCursor
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, invalid
+	}
+
+	// the cursor is only valid for the order it was created in
+	if v.SortBy != p.SortBy || v.Desc != p.Desc || len(v.Keys) == 0 || (n >= 0 && len(v.Keys) != n) {
+		return nil, invalid
+	}
+
+	for i, item := range v.Keys {
+		switch k := item.(type) {
+		case string:
+		case json.Number:
+			if v.Keys[i], err = k.Int64(); err != nil {
+				return nil, invalid
+			}
+		default:
+			return nil, invalid
+		}
+	}
+	return v.Keys, nil
+}
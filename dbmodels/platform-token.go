@@ -0,0 +1,7 @@
+package dbmodels
+
+type PlatformToken struct {
+	// User is the user of code platform, in the format of platform/login
+	User  string `json:"user" required:"true"`
+	Token string `json:"token" required:"true"`
+}
@@ -0,0 +1,13 @@
+package dbmodels
+
+// SigningMetadata is recorded when signing as the evidence of it.
+type SigningMetadata struct {
+	// SignedBy is the account on code platform of the signer.
+	// It is empty if the signer is identified by email only.
+	SignedBy  string `json:"signed_by,omitempty"`
+	IP        string `json:"ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+
+	// CLAHash is the sha256 of the cla text signed
+	CLAHash string `json:"cla_hash,omitempty"`
+}
@@ -0,0 +1,31 @@
+package dbmodels
+
+type SigningRevocation struct {
+	Email     string `json:"email" required:"true"`
+	RevokedBy string `json:"revoked_by" required:"true"`
+	Reason    string `json:"reason,omitempty"`
+	RevokedAt int64  `json:"revoked_at" required:"true"`
+
+	// CorporationID is the corporation of employee, see EmployeeSigningInfo
+	CorporationID string `json:"-"`
+}
+
+// RevokedSigning is the tombstone of revoked signing which is kept for audit.
+// The signing information filled by the signer is dropped.
+type RevokedSigning struct {
+	// Type is the type of signing, individual or employee
+	Type       string `json:"type"`
+	Email      string `json:"email"`
+	SignedAt   int64  `json:"signed_at"`
+	CLAVersion int    `json:"cla_version"`
+
+	RevokedBy string `json:"revoked_by"`
+	Reason    string `json:"reason"`
+	RevokedAt int64  `json:"revoked_at"`
+}
+
+type ISigningRevocation interface {
+	RevokeIndividualSigning(claOrgID string, opt SigningRevocation) error
+	RevokeEmployeeSigning(claOrgID string, opt SigningRevocation) error
+	ListRevokedSigning(claOrgID string) ([]RevokedSigning, error)
+}
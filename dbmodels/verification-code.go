@@ -5,4 +5,8 @@ type VerificationCode struct {
 	Code    string `json:"code" required:"true"`
 	Purpose string `json:"purpose" required:"true"`
 	Expiry  int64  `json:"expiry" required:"true"`
+
+	// CreatedAt and IP are used to limit the frequency of sending code
+	CreatedAt int64  `json:"created_at,omitempty"`
+	IP        string `json:"ip,omitempty"`
 }
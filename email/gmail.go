@@ -1,14 +1,10 @@
 package email
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
 	"io/ioutil"
-	"net/http"
-	"path"
-	"text/template"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -26,8 +22,6 @@ type gmailClient struct {
 	cfg *oauth2.Config
 
 	webRedirectDir string
-
-	emailTemp *template.Template
 }
 
 func (this *gmailClient) initialize(path, webRedirectDir string) error {
@@ -36,14 +30,6 @@ func (this *gmailClient) initialize(path, webRedirectDir string) error {
 		return fmt.Errorf("Failtd to initialize gmail client: %s", err.Error())
 	}
 
-	str := emailTempWithAttachmentForGmail()
-	tmpl, err := template.New("email").Parse(str)
-	if err != nil {
-		return fmt.Errorf("Failtd to initialize gmail client: %s", err.Error())
-	}
-
-	this.emailTemp = tmpl
-
 	this.cfg = cfg
 	this.webRedirectDir = webRedirectDir
 	return nil
@@ -84,8 +70,12 @@ func (this *gmailClient) GetOauth2CodeURL(state string) string {
 	return myoauth2.GetOauth2CodeURL(state, this.cfg)
 }
 
-func (this *gmailClient) SendEmail(token oauth2.Token, msg EmailMessage) error {
-	client := this.cfg.Client(context.Background(), &token)
+func (this *gmailClient) SendEmail(cfg *models.OrgEmail, msg EmailMessage) error {
+	if cfg.Token == nil {
+		return fmt.Errorf("missing the oauth2 token of email: %s", cfg.Email)
+	}
+
+	client := this.cfg.Client(context.Background(), cfg.Token)
 	srv, err := gmail.New(client)
 	if err != nil {
 		return err
@@ -120,68 +110,12 @@ func (this *gmailClient) getScope() []string {
 }
 
 func (this *gmailClient) createGmailMessage(msg EmailMessage) (*gmail.Message, error) {
-	attachment := msg.Attachment
-	if attachment == "" {
-		raw := fmt.Sprintf("To: %s\r\nSubject: %s\r\n\r\n%s", msg.To, msg.Subject, msg.Content)
-		return &gmail.Message{
-			Raw: base64.URLEncoding.EncodeToString([]byte(raw)),
-		}, nil
-	}
-
-	fileBytes, err := ioutil.ReadFile(attachment)
+	raw, err := genRawMessage("", msg)
 	if err != nil {
-		return nil, fmt.Errorf("Unable to read file for attachment: %s", err.Error())
-	}
-
-	data := struct {
-		To           string
-		Subject      string
-		Content      string
-		Boundary     string
-		FileName     string
-		FileData     string
-		FileMIMEType string
-	}{
-		To:           msg.To,
-		Subject:      msg.Subject,
-		Content:      msg.Content,
-		Boundary:     randStr(32, "alphanum"),
-		FileData:     base64.StdEncoding.EncodeToString(fileBytes),
-		FileName:     path.Base(attachment),
-		FileMIMEType: http.DetectContentType(fileBytes),
-	}
-
-	buf := new(bytes.Buffer)
-	err = this.emailTemp.Execute(buf, data)
-	if err != nil {
-		return nil, fmt.Errorf("parse template failed: %s", err.Error())
+		return nil, err
 	}
 
 	return &gmail.Message{
-		Raw: base64.URLEncoding.EncodeToString(buf.Bytes()),
+		Raw: base64.URLEncoding.EncodeToString(raw),
 	}, nil
 }
-
-func emailTempWithAttachmentForGmail() string {
-	return `Content-Type: multipart/mixed; boundary={{.Boundary}}
-MIME-Version: 1.0
-to: {{.To}}
-subject: {{.Subject}}
-
---{{.Boundary}}
-Content-Type: text/plain; charset="UTF-8"
-MIME-Version: 1.0
-Content-Transfer-Encoding: 7bit
-
-{{.Content}}
-
---{{.Boundary}}
-Content-Type: {{.FileMIMEType}}; name="{{.FileName}}"
-MIME-Version: 1.0
-Content-Transfer-Encoding: base64
-Content-Disposition: attachment; filename="{{.FileName}}"
-
-{{.FileData}}
-
---{{.Boundary}}--`
-}
@@ -0,0 +1,120 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path"
+	"time"
+)
+
+// genRawMessage builds the MIME message of email which can be sent directly.
+func genRawMessage(from string, msg EmailMessage) ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	header := func(k, v string) {
+		fmt.Fprintf(buf, "%s: %s\r\n", k, v)
+	}
+
+	if from != "" {
+		header("From", from)
+	}
+	header("To", msg.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	content := new(bytes.Buffer)
+	contentType, err := genContent(content, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.Attachment == "" {
+		header("Content-Type", contentType)
+		buf.WriteString("\r\n")
+		buf.Write(content.Bytes())
+		return buf.Bytes(), nil
+	}
+
+	fileBytes, err := ioutil.ReadFile(msg.Attachment)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read file for attachment: %s", err.Error())
+	}
+
+	w := multipart.NewWriter(buf)
+	header("Content-Type", fmt.Sprintf("multipart/mixed; boundary=%s", w.Boundary()))
+	buf.WriteString("\r\n")
+
+	part, err := w.CreatePart(textproto.MIMEHeader{"Content-Type": {contentType}})
+	if err != nil {
+		return nil, err
+	}
+	part.Write(content.Bytes())
+
+	fileName := path.Base(msg.Attachment)
+	part, err = w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {fmt.Sprintf("%s; name=%q", http.DetectContentType(fileBytes), fileName)},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", fileName)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeBase64Lines(part, fileBytes)
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// genContent writes the text content of msg and the html one if it has, then
+// returns the content type of them.
+func genContent(buf *bytes.Buffer, msg EmailMessage) (string, error) {
+	if msg.HTMLContent == "" {
+		buf.WriteString(msg.Content)
+		return `text/plain; charset="UTF-8"`, nil
+	}
+
+	w := multipart.NewWriter(buf)
+
+	contents := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain", msg.Content},
+		{"text/html", msg.HTMLContent},
+	}
+	for _, item := range contents {
+		part, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {fmt.Sprintf(`%s; charset="UTF-8"`, item.contentType)},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return "", err
+		}
+		part.Write([]byte(item.content))
+	}
+
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("multipart/alternative; boundary=%s", w.Boundary()), nil
+}
+
+// writeBase64Lines writes data in base64 with lines of 76 characters as RFC 2045 requires.
+func writeBase64Lines(w io.Writer, data []byte) {
+	s := base64.StdEncoding.EncodeToString(data)
+	for len(s) > 76 {
+		w.Write([]byte(s[:76] + "\r\n"))
+		s = s[76:]
+	}
+	w.Write([]byte(s + "\r\n"))
+}
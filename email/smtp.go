@@ -0,0 +1,176 @@
+package email
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/smtp"
+	"strconv"
+
+	"github.com/huaweicloud/golangsdk"
+	"sigs.k8s.io/yaml"
+
+	"github.com/zengchen1024/cla-server/models"
+)
+
+const (
+	smtpSecurityNone     = "none"
+	smtpSecuritySTARTTLS = "starttls"
+	smtpSecurityTLS      = "tls"
+)
+
+func init() {
+	emails[models.EmailPlatformSMTP] = &smtpClient{}
+}
+
+type smtpConfig struct {
+	Host string `json:"host" required:"true"`
+	Port int    `json:"port" required:"true"`
+
+	// Security is the way to secure the connection to SMTP server.
+	// It can be none, starttls or tls, and the default is starttls.
+	Security string `json:"security,omitempty"`
+}
+
+type smtpClient struct {
+	cfg *smtpConfig
+
+	webRedirectDir string
+}
+
+func (this *smtpClient) initialize(path, webRedirectDir string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Failtd to initialize smtp client: %s", err.Error())
+	}
+
+	cfg := &smtpConfig{}
+	if err := yaml.Unmarshal(b, cfg); err != nil {
+		return fmt.Errorf("Failtd to initialize smtp client: %s", err.Error())
+	}
+
+	if _, err := golangsdk.BuildRequestBody(cfg, ""); err != nil {
+		return fmt.Errorf("Failtd to initialize smtp client: %s", err.Error())
+	}
+
+	switch cfg.Security {
+	case "":
+		cfg.Security = smtpSecuritySTARTTLS
+	case smtpSecurityNone, smtpSecuritySTARTTLS, smtpSecurityTLS:
+	default:
+		return fmt.Errorf("Failtd to initialize smtp client: unknown security: %s", cfg.Security)
+	}
+
+	this.cfg = cfg
+	this.webRedirectDir = webRedirectDir
+	return nil
+}
+
+func (this *smtpClient) WebRedirectDir() string {
+	return this.webRedirectDir
+}
+
+func (this *smtpClient) GetOauth2CodeURL(state string) string {
+	return ""
+}
+
+func (this *smtpClient) GetAuthorizedEmail(code, scope string) (*models.OrgEmail, error) {
+	return nil, fmt.Errorf("smtp doesn't support oauth2 authorization")
+}
+
+func (this *smtpClient) SendEmail(cfg *models.OrgEmail, msg EmailMessage) error {
+	raw, err := genRawMessage(cfg.Email, msg)
+	if err != nil {
+		return err
+	}
+
+	c, err := this.dial(cfg.SMTP)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.Mail(cfg.Email); err != nil {
+		return err
+	}
+
+	if err := c.Rcpt(msg.To); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// dial connects and authenticates to the SMTP server
+func (this *smtpClient) dial(cred *models.SMTPCredential) (*smtp.Client, error) {
+	if this.cfg == nil {
+		return nil, fmt.Errorf("smtp has not been initialized")
+	}
+
+	host := this.cfg.Host
+	addr := net.JoinHostPort(host, strconv.Itoa(this.cfg.Port))
+	tlsCfg := &tls.Config{ServerName: host}
+
+	var conn net.Conn
+	var err error
+	if this.cfg.Security == smtpSecurityTLS {
+		conn, err = tls.Dial("tcp", addr, tlsCfg)
+	} else {
+		conn, err = net.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect to smtp server: %s", err.Error())
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Failed to connect to smtp server: %s", err.Error())
+	}
+
+	if this.cfg.Security == smtpSecuritySTARTTLS {
+		if err := c.StartTLS(tlsCfg); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("Failed to start tls: %s", err.Error())
+		}
+	}
+
+	if cred != nil && cred.User != "" {
+		if err := c.Auth(smtp.PlainAuth("", cred.User, cred.Password, host)); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("Failed to authenticate to smtp server: %s", err.Error())
+		}
+	}
+
+	return c, nil
+}
+
+// VerifySMTPCredential checks whether the credential can be used to login the SMTP server.
+func VerifySMTPCredential(cred *models.SMTPCredential) error {
+	e, err := GetEmailClient(models.EmailPlatformSMTP)
+	if err != nil {
+		return err
+	}
+
+	c, err := e.(*smtpClient).dial(cred)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	return c.Quit()
+}
@@ -0,0 +1,171 @@
+package email
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/zengchen1024/cla-server/models"
+)
+
+type fakeSMTPServer struct {
+	l net.Listener
+
+	auth  string
+	from  string
+	rcpts []string
+	data  string
+	done  chan struct{}
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &fakeSMTPServer{l: l, done: make(chan struct{})}
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+
+	conn, err := s.l.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(m string) {
+		conn.Write([]byte(m + "\r\n"))
+	}
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch cmd {
+		case "EHLO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			s.auth = line
+			reply("235 Authentication successful")
+		case "MAIL":
+			s.from = line
+			reply("250 OK")
+		case "RCPT":
+			s.rcpts = append(s.rcpts, line)
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(l)
+			}
+			s.data = b.String()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func newTestSMTPClient(t *testing.T, addr net.Addr) *smtpClient {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := ioutil.TempFile("", "smtp*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	f.WriteString("host: " + host + "\nport: " + port + "\nsecurity: none\n")
+	f.Close()
+
+	c := &smtpClient{}
+	if err := c.initialize(f.Name(), ""); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestSMTPSendEmail(t *testing.T) {
+	s := newFakeSMTPServer(t)
+	defer s.l.Close()
+
+	c := newTestSMTPClient(t, s.l.Addr())
+
+	attachment, err := ioutil.TempFile("", "signing*.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(attachment.Name())
+
+	attachment.WriteString("%PDF-1.4 fake pdf")
+	attachment.Close()
+
+	cfg := &models.OrgEmail{
+		Email:    "cla@example.com",
+		Platform: models.EmailPlatformSMTP,
+		SMTP:     &models.SMTPCredential{User: "cla", Password: "secret"},
+	}
+	msg := EmailMessage{
+		To:         "admin@corp.com",
+		Subject:    "pdf signing",
+		Content:    "pdf",
+		Attachment: attachment.Name(),
+	}
+	if err := c.SendEmail(cfg, msg); err != nil {
+		t.Fatal(err)
+	}
+	<-s.done
+
+	wantAuth := base64.StdEncoding.EncodeToString([]byte("\x00cla\x00secret"))
+	if !strings.HasSuffix(s.auth, wantAuth) {
+		t.Errorf("unexpected auth: %s", s.auth)
+	}
+
+	if s.from != "MAIL FROM:<cla@example.com>" {
+		t.Errorf("unexpected sender: %s", s.from)
+	}
+
+	if len(s.rcpts) != 1 || s.rcpts[0] != "RCPT TO:<admin@corp.com>" {
+		t.Errorf("unexpected recipients: %v", s.rcpts)
+	}
+
+	wantFile := base64.StdEncoding.EncodeToString([]byte("%PDF-1.4 fake pdf"))
+	if !strings.Contains(s.data, wantFile) {
+		t.Errorf("attachment is not found in the message:\n%s", s.data)
+	}
+
+	if !strings.Contains(s.data, "To: admin@corp.com\r\n") {
+		t.Errorf("recipient header is not found in the message:\n%s", s.data)
+	}
+}
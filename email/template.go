@@ -0,0 +1,342 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"text/template"
+
+	"github.com/zengchen1024/cla-server/models"
+)
+
+const defaultTemplateLanguage = "english"
+
+// EmailTemplateData is the data used to render the email template.
+type EmailTemplateData struct {
+	CLAOrg  *models.CLAOrg
+	CLA     *models.CLA
+	Signing *models.CorporationSigning
+
+	// Email is the receiver of email
+	Email string
+
+	// Code is the verification code or the token to reset password
+	Code string
+
+	// Password is the initial password of manager
+	Password string
+
+	// Reason is why the signing was revoked
+	Reason string
+}
+
+// The templates can be customized by the owners of org, so they are rendered
+// with the plain copies of data instead of the models which have methods, for
+// example CLAOrg.Delete, that can be called by the templates.
+
+type templateCLAOrg struct {
+	ID          string
+	Platform    string
+	OrgID       string
+	RepoID      string
+	CLALanguage string
+	CLAVersion  int
+	ApplyTo     string
+	OrgEmail    string
+}
+
+type templateCLA struct {
+	Name     string
+	Language string
+	ApplyTo  string
+	Version  int
+}
+
+type templateSigning struct {
+	AdminEmail      string
+	AdminName       string
+	CorporationName string
+	Info            map[string]string
+	SignedAt        int64
+	CLAVersion      int
+}
+
+type templateData struct {
+	CLAOrg   *templateCLAOrg
+	CLA      *templateCLA
+	Signing  *templateSigning
+	Email    string
+	Code     string
+	Password string
+	Reason   string
+}
+
+func (this *EmailTemplateData) toTemplateData() *templateData {
+	r := &templateData{
+		Email:    this.Email,
+		Code:     this.Code,
+		Password: this.Password,
+		Reason:   this.Reason,
+	}
+
+	if v := this.CLAOrg; v != nil {
+		r.CLAOrg = &templateCLAOrg{
+			ID:          v.ID,
+			Platform:    v.Platform,
+			OrgID:       v.OrgID,
+			RepoID:      v.RepoID,
+			CLALanguage: v.CLALanguage,
+			CLAVersion:  v.CLAVersion,
+			ApplyTo:     v.ApplyTo,
+			OrgEmail:    v.OrgEmail,
+		}
+	}
+
+	if v := this.CLA; v != nil {
+		r.CLA = &templateCLA{
+			Name:     v.Name,
+			Language: v.Language,
+			ApplyTo:  v.ApplyTo,
+			Version:  v.Version,
+		}
+	}
+
+	if v := this.Signing; v != nil {
+		info := make(map[string]string, len(v.Info))
+		for k, item := range v.Info {
+			info[k] = item
+		}
+
+		r.Signing = &templateSigning{
+			AdminEmail:      v.AdminEmail,
+			AdminName:       v.AdminName,
+			CorporationName: v.CorporationName,
+			Info:            info,
+			SignedAt:        v.SignedAt,
+			CLAVersion:      v.CLAVersion,
+		}
+	}
+
+	return r
+}
+
+// defaultTemplates is the templates used when the binding has not customized them.
+// key is the language of cla, then the kind of template.
+var defaultTemplates = map[string]map[string]models.EmailTemplate{
+	"english": {
+		models.EmailTemplateVerificationCode: {
+			Subject: "Verification code of signing CLA for {{.CLAOrg.OrgID}}",
+			Text: `Hello,
+
+Your verification code of signing "{{.CLA.Name}}" is {{.Code}}.
+
+If you did not request it, please ignore this email.`,
+		},
+		models.EmailTemplateCorporationSigning: {
+			Subject: "The CLA signed by {{.Signing.CorporationName}} for {{.CLAOrg.OrgID}}",
+			Text: `Dear {{.Signing.AdminName}},
+
+Thanks for signing "{{.CLA.Name}}" of {{.CLAOrg.OrgID}} on behalf of {{.Signing.CorporationName}}.
+The signed CLA is attached. Please sign the signature page and send it back to {{.CLAOrg.OrgEmail}}.`,
+		},
+		models.EmailTemplateManagerAccount: {
+			Subject: "Your account of managing CLA signing for {{.CLAOrg.OrgID}}",
+			Text: `Hello,
+
+An account has been created for you to manage the employees who sign "{{.CLA.Name}}" of {{.CLAOrg.OrgID}}.
+Please login with {{.Email}} and the initial password: {{.Password}}
+You will be asked to change the password at the first login.`,
+		},
+		models.EmailTemplateEmployeeEnabled: {
+			Subject: "You are able to contribute to {{.CLAOrg.OrgID}}",
+			Text: `Hello,
+
+Your signing of "{{.CLA.Name}}" as an employee has been approved by the manager of your corporation.
+Now you are able to contribute to {{.CLAOrg.OrgID}}.`,
+		},
+		models.EmailTemplatePasswordRetrieval: {
+			Subject: "Reset the password of managing CLA signing for {{.CLAOrg.OrgID}}",
+			Text: `Hello,
+
+The token to reset your password is: {{.Code}}
+It can be used only once. If you did not request it, please ignore this email.`,
+		},
+		models.EmailTemplateSigningWithdrawal: {
+			Subject: "Verification code of withdrawing CLA signing for {{.CLAOrg.OrgID}}",
+			Text: `Hello,
+
+Your verification code of withdrawing the signing of "{{.CLA.Name}}" is {{.Code}}.
+
+If you did not request it, please ignore this email.`,
+		},
+		models.EmailTemplateSigningRevoked: {
+			Subject: "Your CLA signing for {{.CLAOrg.OrgID}} has been revoked",
+			Text: `Hello,
+
+Your signing of "{{.CLA.Name}}" for {{.CLAOrg.OrgID}} with {{.Email}} has been revoked.
+Reason: {{.Reason}}
+
+You are not able to contribute to {{.CLAOrg.OrgID}} until you sign it again.`,
+		},
+	},
+	"chinese": {
+		models.EmailTemplateVerificationCode: {
+			Subject: "签署{{.CLAOrg.OrgID}}的CLA验证码",
+			Text: `您好：
+
+您签署《{{.CLA.Name}}》的验证码是：{{.Code}}。
+
+如果这不是您本人的操作，请忽略此邮件。`,
+		},
+		models.EmailTemplateCorporationSigning: {
+			Subject: "{{.Signing.CorporationName}}签署的{{.CLAOrg.OrgID}} CLA",
+			Text: `{{.Signing.AdminName}}，您好：
+
+感谢您代表{{.Signing.CorporationName}}签署{{.CLAOrg.OrgID}}的《{{.CLA.Name}}》。
+附件是已签署的CLA，请在签字页签字后发送至{{.CLAOrg.OrgEmail}}。`,
+		},
+		models.EmailTemplateManagerAccount: {
+			Subject: "{{.CLAOrg.OrgID}} CLA签署管理账号",
+			Text: `您好：
+
+已为您创建账号，用于管理签署{{.CLAOrg.OrgID}}《{{.CLA.Name}}》的员工。
+请使用{{.Email}}和初始密码{{.Password}}登录，首次登录时需要修改密码。`,
+		},
+		models.EmailTemplateEmployeeEnabled: {
+			Subject: "您已可以向{{.CLAOrg.OrgID}}贡献",
+			Text: `您好：
+
+您以员工身份签署的《{{.CLA.Name}}》已通过贵公司管理员的审核。
+现在您可以向{{.CLAOrg.OrgID}}贡献了。`,
+		},
+		models.EmailTemplatePasswordRetrieval: {
+			Subject: "重置{{.CLAOrg.OrgID}} CLA签署管理账号的密码",
+			Text: `您好：
+
+重置密码的令牌是：{{.Code}}
+该令牌只能使用一次。如果这不是您本人的操作，请忽略此邮件。`,
+		},
+		models.EmailTemplateSigningWithdrawal: {
+			Subject: "撤回{{.CLAOrg.OrgID}} CLA签署的验证码",
+			Text: `您好：
+
+您撤回《{{.CLA.Name}}》签署的验证码是：{{.Code}}。
+
+如果这不是您本人的操作，请忽略此邮件。`,
+		},
+		models.EmailTemplateSigningRevoked: {
+			Subject: "您的{{.CLAOrg.OrgID}} CLA签署已被撤销",
+			Text: `您好：
+
+您使用{{.Email}}签署的{{.CLAOrg.OrgID}}《{{.CLA.Name}}》已被撤销。
+原因：{{.Reason}}
+
+在重新签署之前，您将无法向{{.CLAOrg.OrgID}}贡献。`,
+		},
+	},
+}
+
+// DefaultEmailTemplate returns the default template of kind in the language.
+// The english one will be returned if there is no template in the language.
+func DefaultEmailTemplate(kind, language string) (models.EmailTemplate, error) {
+	if v, ok := defaultTemplates[language][kind]; ok {
+		return v, nil
+	}
+
+	if v, ok := defaultTemplates[defaultTemplateLanguage][kind]; ok {
+		return v, nil
+	}
+
+	return models.EmailTemplate{}, fmt.Errorf("unknown kind of email template: %s", kind)
+}
+
+// ValidateEmailTemplate checks whether the template can be parsed.
+func ValidateEmailTemplate(t *models.EmailTemplate) error {
+	if t.Subject == "" || t.Text == "" {
+		return fmt.Errorf("the subject and text of email template can't be empty")
+	}
+
+	if _, err := template.New("subject").Parse(t.Subject); err != nil {
+		return fmt.Errorf("invalid subject of email template: %s", err.Error())
+	}
+
+	if _, err := template.New("text").Parse(t.Text); err != nil {
+		return fmt.Errorf("invalid text of email template: %s", err.Error())
+	}
+
+	if t.HTML != "" {
+		if _, err := htmltemplate.New("html").Parse(t.HTML); err != nil {
+			return fmt.Errorf("invalid html of email template: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+// GenEmailMessage renders the template of kind to the email message sent to data.Email.
+// It uses the template customized for the binding or the default one in the language of cla.
+func GenEmailMessage(kind string, data *EmailTemplateData) (EmailMessage, error) {
+	msg := EmailMessage{To: data.Email}
+
+	t, err := getEmailTemplate(kind, data.CLAOrg)
+	if err != nil {
+		return msg, err
+	}
+
+	v := data.toTemplateData()
+
+	if msg.Subject, err = renderText(t.Subject, v); err != nil {
+		return msg, fmt.Errorf("Failed to render subject of email: %s", err.Error())
+	}
+
+	if msg.Content, err = renderText(t.Text, v); err != nil {
+		return msg, fmt.Errorf("Failed to render text of email: %s", err.Error())
+	}
+
+	if t.HTML != "" {
+		if msg.HTMLContent, err = renderHTML(t.HTML, v); err != nil {
+			return msg, fmt.Errorf("Failed to render html of email: %s", err.Error())
+		}
+	}
+
+	return msg, nil
+}
+
+func getEmailTemplate(kind string, claOrg *models.CLAOrg) (models.EmailTemplate, error) {
+	v, err := models.ListEmailTemplate(claOrg.ID)
+	if err != nil {
+		return models.EmailTemplate{}, err
+	}
+
+	if t, ok := v[kind]; ok {
+		return t, nil
+	}
+
+	return DefaultEmailTemplate(kind, claOrg.CLALanguage)
+}
+
+func renderText(text string, data *templateData) (string, error) {
+	t, err := template.New("email").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := t.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func renderHTML(text string, data *templateData) (string, error) {
+	t, err := htmltemplate.New("email").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := t.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,31 @@
+package email
+
+import (
+	"testing"
+
+	"github.com/zengchen1024/cla-server/models"
+)
+
+func TestRenderTemplateWithoutMethods(t *testing.T) {
+	data := &EmailTemplateData{
+		CLAOrg: &models.CLAOrg{ID: "id", OrgID: "org"},
+		CLA:    &models.CLA{Name: "cla"},
+		Email:  "a@example.com",
+	}
+	v := data.toTemplateData()
+
+	s, err := renderText("{{.CLAOrg.OrgID}} {{.CLA.Name}} {{.Email}}", v)
+	if err != nil || s != "org cla a@example.com" {
+		t.Errorf("render text: unexpected result: %s, %v", s, err)
+	}
+
+	for _, item := range []string{"{{.CLAOrg.Delete}}", "{{.CLA.Create}}"} {
+		if _, err := renderText(item, v); err == nil {
+			t.Errorf("render %s: expect error, but got nil", item)
+		}
+	}
+
+	if _, err := renderText("{{.Signing.AdminName}}", v); err == nil {
+		t.Error("render the field of absent signing: expect error, but got nil")
+	}
+}
@@ -1,15 +1,24 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/astaxie/beego"
 
 	platformAuth "github.com/zengchen1024/cla-server/code-platform-auth"
 	"github.com/zengchen1024/cla-server/dbmodels"
 	"github.com/zengchen1024/cla-server/email"
+	"github.com/zengchen1024/cla-server/memorydb"
 	"github.com/zengchen1024/cla-server/models"
 	"github.com/zengchen1024/cla-server/mongodb"
 	"github.com/zengchen1024/cla-server/pdf"
+	"github.com/zengchen1024/cla-server/postgres"
 	_ "github.com/zengchen1024/cla-server/routers"
+	"github.com/zengchen1024/cla-server/webhook"
 	"github.com/zengchen1024/cla-server/worker"
 )
 
@@ -19,38 +28,50 @@ func main() {
 		beego.BConfig.WebConfig.StaticDir["/swagger"] = "swagger"
 	}
 
-	c, err := mongodb.RegisterDatabase(
-		beego.AppConfig.String("mongodb_conn"),
-		beego.AppConfig.String("mongodb_db"))
+	c, err := registerDatabase()
 	if err != nil {
+		beego.Info(err)
 		return
 	}
 
 	models.RegisterDB(c)
 	dbmodels.RegisterDB(c)
 
-	path := beego.AppConfig.String("gmail::credentials")
-	webRedirectDir := beego.AppConfig.String("gmail::web_redirect_dir")
-	if err = email.RegisterPlatform("gmail", path, webRedirectDir); err != nil {
+	if err := models.MigrateCorporationManagerPassword(); err != nil {
 		beego.Info(err)
 		return
 	}
 
-	path = beego.AppConfig.String("gitee::credentials")
-	if err := platformAuth.RegisterPlatform("gitee", path); err != nil {
-		beego.Info(err)
-		return
+	for _, platform := range configStrings("email_platforms", "gmail") {
+		path := beego.AppConfig.String(platform + "::credentials")
+		webRedirectDir := beego.AppConfig.String(platform + "::web_redirect_dir")
+		if err := email.RegisterPlatform(platform, path, webRedirectDir); err != nil {
+			beego.Info(err)
+			return
+		}
+	}
+
+	// the default list of public email domains is used if it is not configured
+	if v := beego.AppConfig.Strings("public_email_domains"); len(v) > 0 && v[0] != "" {
+		models.SetPublicEmailDomains(v)
+	}
+
+	for _, platform := range configStrings("code_platforms", "gitee") {
+		path := beego.AppConfig.String(platform + "::credentials")
+		if err := platformAuth.RegisterPlatform(platform, path); err != nil {
+			beego.Info(err)
+			return
+		}
 	}
 
 	language := beego.AppConfig.String("blank_signature::language")
-	path = beego.AppConfig.String("blank_signature::pdf")
+	path := beego.AppConfig.String("blank_signature::pdf")
 	if err := pdf.UploadBlankSignature(language, path); err != nil {
 		beego.Info(err)
 		return
 	}
 
 	if err := pdf.InitPDFGenerator(
-		beego.AppConfig.String("python_bin"),
 		beego.AppConfig.String("pdf_out_dir"),
 		beego.AppConfig.String("pdf_org_signature_dir"),
 		beego.AppConfig.String("pdf_template_corporation::welcome"),
@@ -60,7 +81,81 @@ func main() {
 		return
 	}
 
-	worker.InitEmailWorker(pdf.GetPDFGenerator())
+	worker.InitEmailWorker(pdf.GetPDFGenerator(), worker.Config{
+		PoolSize:     beego.AppConfig.DefaultInt("email_worker::pool_size", 5),
+		MaxAttempts:  beego.AppConfig.DefaultInt("email_worker::max_attempts", 10),
+		PollInterval: configSeconds("email_worker::poll_interval", 10),
+		Lease:        configSeconds("email_worker::lease", 600),
+		BackoffBase:  configSeconds("email_worker::backoff_base", 60),
+		BackoffMax:   configSeconds("email_worker::backoff_max", 3600),
+	})
+
+	webhook.InitCLAChecker(beego.AppConfig.String("signing_page_url"))
+
+	// the webhook of gitee is enabled only if its secret is configured
+	if secret := beego.AppConfig.String("gitee_webhook::secret"); secret != "" {
+		webhook.InitGiteeWebhook(secret, webhook.GetCLAChecker())
+	}
+
+	go shutdownOnSignal()
 
 	beego.Run()
 }
+
+type database interface {
+	models.IDB
+	dbmodels.IDB
+}
+
+// registerDatabase creates the backend of database selected by db_backend.
+// The memory one loses all the data when exiting and is only for local development.
+func registerDatabase() (database, error) {
+	switch backend := beego.AppConfig.DefaultString("db_backend", "mongodb"); backend {
+	case "mongodb":
+		c, err := mongodb.RegisterDatabase(
+			beego.AppConfig.String("mongodb_conn"),
+			beego.AppConfig.String("mongodb_db"))
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+
+	case "postgres":
+		c, err := postgres.RegisterDatabase(beego.AppConfig.String("postgres_conn"))
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+
+	case "memory":
+		return memorydb.NewDatabase(), nil
+
+	default:
+		return nil, fmt.Errorf("unknown db_backend: %s", backend)
+	}
+}
+
+// configStrings returns the list separated by ";", or def if it is not configured,
+// so that the config written before supporting several platforms still works.
+func configStrings(key string, def ...string) []string {
+	if v := beego.AppConfig.Strings(key); len(v) > 0 && v[0] != "" {
+		return v
+	}
+	return def
+}
+
+func configSeconds(key string, def int64) time.Duration {
+	return time.Second * time.Duration(beego.AppConfig.DefaultInt64(key, def))
+}
+
+// shutdownOnSignal waits for the running jobs of email worker to finish before exiting.
+func shutdownOnSignal() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
+
+	<-sig
+	beego.Info("shutting down the email worker")
+
+	worker.GetEmailWorker().Shutdown()
+	os.Exit(0)
+}
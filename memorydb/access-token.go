@@ -0,0 +1,104 @@
+package memorydb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+func (this *client) CreateRefreshToken(opt dbmodels.RefreshToken) error {
+	f := func() error {
+		// clean up the expired ones
+		now := time.Now().Unix()
+		v := make([]dbmodels.RefreshToken, 0, len(this.refreshTokens)+1)
+		for _, item := range this.refreshTokens {
+			if item.Expiry >= now {
+				v = append(v, item)
+			}
+		}
+
+		this.refreshTokens = append(v, opt)
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) GetRefreshToken(id string) (dbmodels.RefreshToken, error) {
+	var r dbmodels.RefreshToken
+
+	f := func() error {
+		for _, item := range this.refreshTokens {
+			if item.ID == id {
+				r = item
+				return nil
+			}
+		}
+		return fmt.Errorf("error decoding to bson struct of refresh token: mongo: no documents in result")
+	}
+
+	return r, this.do(f)
+}
+
+func (this *client) DeleteRefreshToken(id string) error {
+	f := func() error {
+		for i, item := range this.refreshTokens {
+			if item.ID == id {
+				this.refreshTokens = append(this.refreshTokens[:i], this.refreshTokens[i+1:]...)
+				break
+			}
+		}
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) DeleteRefreshTokensOfUser(user string) ([]dbmodels.RefreshToken, error) {
+	r := make([]dbmodels.RefreshToken, 0)
+
+	f := func() error {
+		v := make([]dbmodels.RefreshToken, 0, len(this.refreshTokens))
+		for _, item := range this.refreshTokens {
+			if item.User == user {
+				r = append(r, item)
+			} else {
+				v = append(v, item)
+			}
+		}
+
+		this.refreshTokens = v
+		return nil
+	}
+
+	return r, this.do(f)
+}
+
+func (this *client) RevokeSession(opt dbmodels.RevokedSession) error {
+	f := func() error {
+		// the access tokens of expired sessions are invalid already
+		now := time.Now().Unix()
+		for k, expiry := range this.revokedSessions {
+			if expiry < now {
+				delete(this.revokedSessions, k)
+			}
+		}
+
+		this.revokedSessions[opt.ID] = opt.Expiry
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) IsSessionRevoked(id string) (bool, error) {
+	revoked := false
+
+	f := func() error {
+		_, revoked = this.revokedSessions[id]
+		return nil
+	}
+
+	return revoked, this.do(f)
+}
@@ -0,0 +1,57 @@
+package memorydb
+
+import (
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+// AddAuditLog appends the log. There is no way to update or delete the logs.
+func (this *client) AddAuditLog(info dbmodels.AuditLog) error {
+	f := func() error {
+		info.ID = this.newID()
+		this.auditLogs = append(this.auditLogs, info)
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) ListAuditLog(opt dbmodels.AuditLogListOption) (dbmodels.AuditLogPage, error) {
+	var r dbmodels.AuditLogPage
+
+	if _, err := opt.Page.SortField(dbmodels.AuditLogSortFields); err != nil {
+		return r, err
+	}
+
+	f := func() error {
+		v := make([]dbmodels.AuditLog, 0)
+		for _, item := range this.auditLogs {
+			if (opt.CLAOrgID != "" && item.CLAOrgID != opt.CLAOrgID) ||
+				(opt.CorporationID != "" && item.CorporationID != opt.CorporationID) ||
+				(opt.Actor != "" && item.Actor != opt.Actor) ||
+				(opt.Action != "" && item.Action != opt.Action) {
+				continue
+			}
+			if (opt.From > 0 && item.CreatedAt < opt.From) || (opt.To > 0 && item.CreatedAt > opt.To) {
+				continue
+			}
+			v = append(v, item)
+		}
+
+		// the id increases, so the later log is after the earlier one at the same time
+		index, info, err := pageOf(opt.Page, len(v), 2, func(i int) []interface{} {
+			return []interface{}{v[i].CreatedAt, v[i].ID}
+		})
+		if err != nil {
+			return err
+		}
+
+		r.PageInfo = info
+		r.Logs = make([]dbmodels.AuditLog, 0, len(index))
+		for _, i := range index {
+			r.Logs = append(r.Logs, v[i])
+		}
+		return nil
+	}
+
+	return r, this.do(f)
+}
@@ -0,0 +1,36 @@
+package memorydb
+
+import (
+	"fmt"
+)
+
+// UploadBlankSignature saves the pdf of language only if there is not one.
+func (this *client) UploadBlankSignature(language string, pdf []byte) error {
+	f := func() error {
+		if _, ok := this.blankSignatures[language]; !ok {
+			this.blankSignatures[language] = copyBytes(pdf)
+		}
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) DownloadBlankSignature(language string) ([]byte, error) {
+	var r []byte
+
+	f := func() error {
+		v, ok := this.blankSignatures[language]
+		if !ok {
+			return fmt.Errorf("error decoding to bson struct: mongo: no documents in result")
+		}
+
+		r = copyBytes(v)
+		return nil
+	}
+
+	if err := this.do(f); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,85 @@
+package memorydb
+
+import (
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/models"
+)
+
+func (this *client) CreateCLAMetadata(data models.CLAMetadata) (string, error) {
+	uid := ""
+
+	f := func() error {
+		for _, item := range this.claMetadatas {
+			if item.Name == data.Name && item.Submitter == data.Submitter {
+				return fmt.Errorf("the cla metadata(%s) is already existing", data.Name)
+			}
+		}
+
+		uid = this.newID()
+		data.ID = uid
+		this.claMetadatas = append(this.claMetadatas, data)
+		return nil
+	}
+
+	return uid, this.do(f)
+}
+
+func (this *client) DeleteCLAMetadata(uid string) error {
+	f := func() error {
+		if err := checkID(uid); err != nil {
+			return err
+		}
+
+		for i, item := range this.claMetadatas {
+			if item.ID == uid {
+				this.claMetadatas = append(this.claMetadatas[:i], this.claMetadatas[i+1:]...)
+				break
+			}
+		}
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) ListCLAMetadata(belongingTo []string) ([]models.CLAMetadata, error) {
+	m := make(map[string]bool, len(belongingTo))
+	for _, v := range belongingTo {
+		m[v] = true
+	}
+
+	var r []models.CLAMetadata
+
+	f := func() error {
+		r = make([]models.CLAMetadata, 0)
+		for _, item := range this.claMetadatas {
+			if m[item.Submitter] {
+				r = append(r, item)
+			}
+		}
+		return nil
+	}
+
+	return r, this.do(f)
+}
+
+func (this *client) GetCLAMetadata(uid string) (models.CLAMetadata, error) {
+	var r models.CLAMetadata
+
+	f := func() error {
+		if err := checkID(uid); err != nil {
+			return err
+		}
+
+		for _, item := range this.claMetadatas {
+			if item.ID == uid {
+				r = item
+				return nil
+			}
+		}
+		return fmt.Errorf("error decoding to bson struct of CLAMetadata: mongo: no documents in result")
+	}
+
+	return r, this.do(f)
+}
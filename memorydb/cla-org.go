@@ -0,0 +1,306 @@
+package memorydb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+type claOrg struct {
+	dbmodels.CLAOrg
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// Individuals is the cla signing information of ordinary contributors
+	// key is the email of contributor
+	Individuals map[string]dbmodels.IndividualSigningInfo
+
+	// Employees is the cla signing information of employees and grouped by corporation
+	// key is the email suffix of corporation
+	Employees map[string][]dbmodels.EmployeeSigningInfo
+
+	// Corporations is the cla signing information of corporation
+	Corporations []*corporationSigning
+
+	// CorporationManagers is the managers of corporation who can manage the employee
+	CorporationManagers []dbmodels.CorporationManagerCreateOption
+
+	OrgSignature []byte
+
+	// EmailTemplates is the customized templates of notification email
+	// key is the kind of template
+	EmailTemplates map[string]dbmodels.EmailTemplate
+
+	BotToken string
+
+	// RevokedSignings is the tombstones of revoked individual and employee signings
+	RevokedSignings []dbmodels.RevokedSigning
+}
+
+func (this *claOrg) isCorpoCLA() bool {
+	return this.Enabled && this.ApplyTo == models.ApplyToCorporation
+}
+
+func (this *claOrg) isIndividualCLA() bool {
+	return this.Enabled && this.ApplyTo == models.ApplyToIndividual
+}
+
+func (this *claOrg) isOf(platform, orgID, repoID string) bool {
+	return this.Platform == platform && this.OrgID == orgID && this.RepoID == repoID
+}
+
+// bindingCLAVersion returns the version of cla which the binding points to.
+// The binding created before versioning points to version 1.
+func (this *claOrg) bindingCLAVersion() int {
+	if this.CLAVersion == 0 {
+		return 1
+	}
+	return this.CLAVersion
+}
+
+func (this *claOrg) toDBModel() dbmodels.CLAOrg {
+	v := this.CLAOrg
+	v.CLAVersion = this.bindingCLAVersion()
+	// the mongodb one doesn't return it either
+	v.OrgSignatureUploaded = false
+	return v
+}
+
+// getCLAOrg returns the binding whatever it is enabled or not
+func (this *client) getCLAOrg(uid string) (*claOrg, error) {
+	if err := checkID(uid); err != nil {
+		return nil, err
+	}
+
+	for _, item := range this.claOrgs {
+		if item.ID == uid {
+			return item, nil
+		}
+	}
+	return nil, nil
+}
+
+func (this *client) getEnabledCLAOrg(uid string, f func(*claOrg) bool) (*claOrg, error) {
+	item, err := this.getCLAOrg(uid)
+	if err != nil || item == nil {
+		return nil, err
+	}
+
+	if f(item) {
+		return item, nil
+	}
+	return nil, nil
+}
+
+func (this *client) CreateBindingBetweenCLAAndOrg(info dbmodels.CLAOrg) (string, error) {
+	uid := ""
+
+	f := func() error {
+		for _, item := range this.claOrgs {
+			if item.Enabled && item.isOf(info.Platform, info.OrgID, info.RepoID) &&
+				item.CLALanguage == info.CLALanguage && item.ApplyTo == info.ApplyTo {
+
+				return fmt.Errorf("the org/repo:%s/%s/%s has already been bound a cla with language:%s",
+					info.Platform, info.OrgID, info.RepoID, info.CLALanguage)
+			}
+		}
+
+		uid = this.newID()
+		info.ID = uid
+		info.OrgSignatureUploaded = false
+
+		now := time.Now()
+		this.claOrgs = append(this.claOrgs, &claOrg{CLAOrg: info, CreatedAt: now, UpdatedAt: now})
+		return nil
+	}
+
+	return uid, this.do(f)
+}
+
+func (this *client) DeleteBindingBetweenCLAAndOrg(uid string) error {
+	f := func() error {
+		item, err := this.getCLAOrg(uid)
+		if err != nil || item == nil {
+			return err
+		}
+
+		item.Enabled = false
+		item.UpdatedAt = time.Now()
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) GetBindingBetweenCLAAndOrg(uid string) (dbmodels.CLAOrg, error) {
+	var r dbmodels.CLAOrg
+
+	f := func() error {
+		item, err := this.getCLAOrg(uid)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("error decoding to bson struct of CLA: mongo: no documents in result")
+		}
+
+		r = item.toDBModel()
+		return nil
+	}
+
+	return r, this.do(f)
+}
+
+func (this *client) ListBindingBetweenCLAAndOrg(opt dbmodels.CLAOrgListOption) (dbmodels.CLAOrgPage, error) {
+	var r dbmodels.CLAOrgPage
+
+	field, err := opt.Page.SortField(dbmodels.CLAOrgSortFields)
+	if err != nil {
+		return r, err
+	}
+
+	f := func() error {
+		v := make([]*claOrg, 0)
+		for _, item := range this.claOrgs {
+			if !item.Enabled || item.Platform != opt.Platform {
+				continue
+			}
+			if opt.OrgID != "" && item.OrgID != opt.OrgID {
+				continue
+			}
+			if opt.OrgIDs != nil && !isOrgIn(item.OrgID, opt.OrgIDs) {
+				continue
+			}
+			if opt.ApplyTo != "" && item.ApplyTo != opt.ApplyTo {
+				continue
+			}
+			v = append(v, item)
+		}
+
+		// list the bindings of repo if there are, otherwise the ones of org
+		if opt.RepoID != "" {
+			v1 := make([]*claOrg, 0, len(v))
+			for _, item := range v {
+				if item.RepoID == opt.RepoID {
+					v1 = append(v1, item)
+				}
+			}
+			if len(v1) != 0 {
+				v = v1
+			}
+		}
+
+		index, info, err := pageOf(opt.Page, len(v), 2, func(i int) []interface{} {
+			if field == "cla_language" {
+				return []interface{}{v[i].CLALanguage, v[i].ID}
+			}
+			return []interface{}{v[i].RepoID, v[i].ID}
+		})
+		if err != nil {
+			return err
+		}
+
+		r.PageInfo = info
+		r.Bindings = make([]dbmodels.CLAOrg, 0, len(index))
+		for _, i := range index {
+			r.Bindings = append(r.Bindings, v[i].toDBModel())
+		}
+		return nil
+	}
+
+	return r, this.do(f)
+}
+
+func (this *client) UpdateBindingCLAVersion(claOrgID string, version int) error {
+	f := func() error {
+		item, err := this.getEnabledCLAOrg(claOrgID, func(v *claOrg) bool { return v.Enabled })
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("Failed to update version of cla: can't find the binding")
+		}
+
+		item.CLAVersion = version
+		item.UpdatedAt = time.Now()
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) UpdateBindingDomainVerification(claOrgID string, required bool) error {
+	f := func() error {
+		item, err := this.getEnabledCLAOrg(claOrgID, (*claOrg).isCorpoCLA)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("Failed to update domain verification: can't find the binding of corporation cla")
+		}
+
+		item.DomainVerificationRequired = required
+		item.UpdatedAt = time.Now()
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) SetBotToken(claOrgID, token string) error {
+	f := func() error {
+		item, err := this.getEnabledCLAOrg(claOrgID, func(v *claOrg) bool { return v.Enabled })
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("Failed to set bot token: can't find the binding")
+		}
+
+		item.BotToken = token
+		item.UpdatedAt = time.Now()
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) GetBotToken(platform, orgID, repoID string) (string, error) {
+	token := ""
+
+	f := func() error {
+		for _, item := range this.claOrgs {
+			if !item.Enabled || item.BotToken == "" || item.Platform != platform || item.OrgID != orgID {
+				continue
+			}
+
+			if item.RepoID == repoID {
+				token = item.BotToken
+				return nil
+			}
+			if item.RepoID == "" {
+				token = item.BotToken
+			}
+		}
+		return nil
+	}
+
+	this.do(f)
+
+	if token == "" {
+		return "", fmt.Errorf("no bot token is set for %s/%s/%s", platform, orgID, repoID)
+	}
+	return token, nil
+}
+
+func isOrgIn(orgID string, orgIDs []string) bool {
+	for _, item := range orgIDs {
+		if item == orgID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package memorydb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+type cla struct {
+	dbmodels.CLA
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (this *cla) toDBModel() dbmodels.CLA {
+	v := this.CLA
+	if v.Version == 0 {
+		v.Version = 1
+	}
+
+	if this.Fields != nil {
+		v.Fields = append([]dbmodels.Field{}, this.Fields...)
+	}
+	if this.Versions != nil {
+		v.Versions = append([]dbmodels.CLAVersion{}, this.Versions...)
+	}
+	return v
+}
+
+func (this *client) getCLA(uid string) (*cla, error) {
+	if err := checkID(uid); err != nil {
+		return nil, err
+	}
+
+	for _, item := range this.clas {
+		if item.ID == uid {
+			return item, nil
+		}
+	}
+	return nil, fmt.Errorf("error decoding to bson struct of CLA: mongo: no documents in result")
+}
+
+func (this *client) CreateCLA(info dbmodels.CLA) (string, error) {
+	uid := ""
+
+	f := func() error {
+		for _, item := range this.clas {
+			if item.Name == info.Name && item.Submitter == info.Submitter {
+				return fmt.Errorf("the cla(%s) is already existing", info.Name)
+			}
+		}
+
+		uid = this.newID()
+		info.ID = uid
+
+		v := &cla{CLA: info}
+		v.Fields = append([]dbmodels.Field(nil), info.Fields...)
+		v.Versions = append([]dbmodels.CLAVersion(nil), info.Versions...)
+		this.clas = append(this.clas, v)
+		return nil
+	}
+
+	return uid, this.do(f)
+}
+
+func (this *client) DeleteCLA(uid string) error {
+	f := func() error {
+		if err := checkID(uid); err != nil {
+			return err
+		}
+
+		for _, item := range this.claOrgs {
+			if item.CLAID == uid {
+				return fmt.Errorf("can't delete the cla which has already been bound to org")
+			}
+		}
+
+		for i, item := range this.clas {
+			if item.ID == uid {
+				this.clas = append(this.clas[:i], this.clas[i+1:]...)
+				break
+			}
+		}
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) ListCLA(opt dbmodels.CLAListOptions) (dbmodels.CLAPage, error) {
+	var r dbmodels.CLAPage
+
+	field, err := opt.Page.SortField(dbmodels.CLASortFields)
+	if err != nil {
+		return r, err
+	}
+
+	f := func() error {
+		v := make([]*cla, 0)
+		for _, item := range this.clas {
+			if item.Submitter != opt.Submitter {
+				continue
+			}
+			if (opt.Name != "" && item.Name != opt.Name) ||
+				(opt.Language != "" && item.Language != opt.Language) ||
+				(opt.ApplyTo != "" && item.ApplyTo != opt.ApplyTo) {
+				continue
+			}
+			v = append(v, item)
+		}
+
+		index, info, err := pageOf(opt.Page, len(v), 2, func(i int) []interface{} {
+			if field == "language" {
+				return []interface{}{v[i].Language, v[i].ID}
+			}
+			return []interface{}{v[i].Name, v[i].ID}
+		})
+		if err != nil {
+			return err
+		}
+
+		r.PageInfo = info
+		r.CLAs = make([]dbmodels.CLA, 0, len(index))
+		for _, i := range index {
+			r.CLAs = append(r.CLAs, v[i].toDBModel())
+		}
+		return nil
+	}
+
+	return r, this.do(f)
+}
+
+func (this *client) ListCLAByIDs(ids []string) ([]dbmodels.CLA, error) {
+	for _, id := range ids {
+		if err := checkID(id); err != nil {
+			return nil, err
+		}
+	}
+
+	m := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		m[id] = true
+	}
+
+	var r []dbmodels.CLA
+
+	f := func() error {
+		r = make([]dbmodels.CLA, 0, len(ids))
+		for _, item := range this.clas {
+			if m[item.ID] {
+				r = append(r, item.toDBModel())
+			}
+		}
+		return nil
+	}
+
+	return r, this.do(f)
+}
+
+func (this *client) GetCLA(uid string) (dbmodels.CLA, error) {
+	var r dbmodels.CLA
+
+	f := func() error {
+		item, err := this.getCLA(uid)
+		if err == nil {
+			r = item.toDBModel()
+		}
+		return err
+	}
+
+	return r, this.do(f)
+}
+
+func (this *client) AddCLAVersion(claID string, v dbmodels.CLAVersion) error {
+	f := func() error {
+		item, err := this.getCLA(claID)
+		if err != nil {
+			return err
+		}
+
+		latest := item.Version
+		versions := []dbmodels.CLAVersion{}
+		if latest == 0 {
+			// keep the text of the cla created before versioning as version 1
+			latest = 1
+			versions = append(versions, dbmodels.CLAVersion{
+				Version:     1,
+				Text:        item.Text,
+				PublishedAt: item.CreatedAt.Unix(),
+			})
+		}
+
+		if v.Version != latest+1 {
+			return fmt.Errorf("Failed to add version of cla, the version(%d) is not the next one of %d", v.Version, latest)
+		}
+
+		item.Text = v.Text
+		item.Version = v.Version
+		item.Versions = append(item.Versions, append(versions, v)...)
+		item.UpdatedAt = time.Now()
+		return nil
+	}
+
+	return this.do(f)
+}
@@ -0,0 +1,144 @@
+package memorydb
+
+import (
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+// hasDomain checks whether the domain is the one of administrator's email or an additional one
+func hasDomain(item *corporationSigning, domain string) bool {
+	if item.CorporationID == emailToKey(domain) {
+		return true
+	}
+
+	for _, d := range item.Domains {
+		if d.Domain == domain {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *client) AddCorporationDomain(claOrgID, adminEmail string, opt dbmodels.CorporationDomain) error {
+	f := func() error {
+		item, err := this.getCLAOrg(claOrgID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("error decoding to bson struct of CLA: mongo: no documents in result")
+		}
+
+		// the domain can't be claimed by more than one corporation of org/repo
+		for _, b := range this.corpoCLAsOf(item.Platform, item.OrgID, item.RepoID) {
+			for _, c := range b.Corporations {
+				if !hasDomain(c, opt.Domain) {
+					continue
+				}
+
+				if c.AdminEmail == adminEmail {
+					return fmt.Errorf("Failed to add domain, it has been added")
+				}
+				return fmt.Errorf("Failed to add domain, it has been claimed by other corporation")
+			}
+		}
+
+		found := false
+		if item.isCorpoCLA() {
+			for _, c := range item.Corporations {
+				if c.AdminEmail == adminEmail {
+					c.Domains = append(c.Domains, dbmodels.CorporationDomain{
+						Domain:    opt.Domain,
+						Token:     opt.Token,
+						CreatedAt: opt.CreatedAt,
+					})
+					found = true
+				}
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("Failed to add domain, the corporation has not signed")
+		}
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) VerifyCorporationDomain(claOrgID, adminEmail, domain string, verifiedAt int64) error {
+	f := func() error {
+		item, err := this.getEnabledCLAOrg(claOrgID, (*claOrg).isCorpoCLA)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("Failed to verify domain, the binding is not exist")
+		}
+
+		for _, c := range item.Corporations {
+			if c.AdminEmail != adminEmail {
+				continue
+			}
+
+			for i := range c.Domains {
+				if c.Domains[i].Domain == domain {
+					c.Domains[i].Verified = true
+					c.Domains[i].VerifiedAt = verifiedAt
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("Failed to verify domain, the corporation or the domain is not exist")
+	}
+
+	return this.do(f)
+}
+
+func (this *client) ListCorporationDomain(claOrgID, corporationID string) ([]dbmodels.CorporationDomain, error) {
+	var r []dbmodels.CorporationDomain
+
+	f := func() error {
+		item, err := this.getEnabledCLAOrg(claOrgID, (*claOrg).isCorpoCLA)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("Failed to list domains, the binding is not exist")
+		}
+
+		for _, c := range item.Corporations {
+			if c.CorporationID == corporationID {
+				r = append([]dbmodels.CorporationDomain{}, c.Domains...)
+				return nil
+			}
+		}
+		return nil
+	}
+
+	if err := this.do(f); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (this *client) GetCorporationIDByDomain(platform, orgID, repoID, domain string) (string, error) {
+	r := ""
+
+	f := func() error {
+		for _, b := range this.corpoCLAsOf(platform, orgID, repoID) {
+			for _, c := range b.Corporations {
+				for _, d := range c.Domains {
+					if d.Domain == domain && d.Verified {
+						r = c.CorporationID
+						return nil
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	return r, this.do(f)
+}
@@ -0,0 +1,275 @@
+package memorydb
+
+import (
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+func toDBModelCorporationManagerCheckResult(item *claOrg, m dbmodels.CorporationManagerCreateOption) dbmodels.CorporationManagerCheckResult {
+	return dbmodels.CorporationManagerCheckResult{
+		Email:              m.Email,
+		Role:               m.Role,
+		Password:           m.Password,
+		MustChangePassword: m.MustChangePassword,
+		CLAOrgID:           item.ID,
+		Platform:           item.Platform,
+		OrgID:              item.OrgID,
+		RepoID:             item.RepoID,
+	}
+}
+
+func toCorporationManagerListResult(m dbmodels.CorporationManagerCreateOption) dbmodels.CorporationManagerListResult {
+	// the manager has no name, which is the same as the mongodb one
+	return dbmodels.CorporationManagerListResult{
+		Email: m.Email,
+		Role:  m.Role,
+	}
+}
+
+func (this *client) AddCorporationManager(claOrgID string, opt []dbmodels.CorporationManagerCreateOption, managerNumber int) error {
+	f := func() error {
+		item, err := this.getCLAOrg(claOrgID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("error decoding to bson struct of CLA: mongo: no documents in result")
+		}
+
+		emails := make(map[string]bool, len(opt))
+		for _, m := range opt {
+			emails[m.Email] = true
+		}
+
+		roleCount := 0
+		emailCount := 0
+		for _, b := range this.corpoCLAsOf(item.Platform, item.OrgID, item.RepoID) {
+			for _, m := range b.CorporationManagers {
+				if m.CorporationID == opt[0].CorporationID && m.Role == opt[0].Role {
+					roleCount++
+				}
+				if emails[m.Email] {
+					emailCount++
+				}
+			}
+		}
+
+		if roleCount+len(opt) > managerNumber {
+			return fmt.Errorf("Failed to add corporation manager: it will exceed %d managers allowed", managerNumber)
+		}
+		if emailCount != 0 {
+			return fmt.Errorf("Failed to add corporation manager: there are already %d same emails", emailCount)
+		}
+
+		item.CorporationManagers = append(item.CorporationManagers, opt...)
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) CheckCorporationManagerExist(opt dbmodels.CorporationManagerCheckInfo) ([]dbmodels.CorporationManagerCheckResult, error) {
+	var r []dbmodels.CorporationManagerCheckResult
+
+	f := func() error {
+		r = make([]dbmodels.CorporationManagerCheckResult, 0)
+		for _, item := range this.claOrgs {
+			if !item.isCorpoCLA() {
+				continue
+			}
+
+			var ms []dbmodels.CorporationManagerCreateOption
+			for _, m := range item.CorporationManagers {
+				// the manager has no name, so only the email is matched
+				if m.Email == opt.User {
+					ms = append(ms, m)
+				}
+			}
+
+			if len(ms) == 0 {
+				continue
+			}
+			if len(ms) != 1 {
+				return fmt.Errorf("Failed to check corporation manager: there isn't only one corporation manager")
+			}
+
+			r = append(r, toDBModelCorporationManagerCheckResult(item, ms[0]))
+		}
+		return nil
+	}
+
+	if err := this.do(f); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (this *client) ListAllCorporationManagers() ([]dbmodels.CorporationManagerCheckResult, error) {
+	var r []dbmodels.CorporationManagerCheckResult
+
+	f := func() error {
+		r = make([]dbmodels.CorporationManagerCheckResult, 0)
+		for _, item := range this.claOrgs {
+			if !item.isCorpoCLA() {
+				continue
+			}
+
+			for _, m := range item.CorporationManagers {
+				r = append(r, toDBModelCorporationManagerCheckResult(item, m))
+			}
+		}
+		return nil
+	}
+
+	return r, this.do(f)
+}
+
+func (this *client) ResetCorporationManagerPassword(claOrgID string, opt dbmodels.CorporationManagerResetPassword) error {
+	f := func() error {
+		item, err := this.getEnabledCLAOrg(claOrgID, (*claOrg).isCorpoCLA)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("Failed to reset password for corporation manager: maybe input wrong cla_org_id.")
+		}
+
+		modified := false
+		for i := range item.CorporationManagers {
+			m := &item.CorporationManagers[i]
+			if m.Email != opt.Email || m.Password != opt.OldPassword {
+				continue
+			}
+
+			if m.Password != opt.NewPassword || m.MustChangePassword != opt.MustChangePassword {
+				modified = true
+			}
+			m.Password = opt.NewPassword
+			m.MustChangePassword = opt.MustChangePassword
+		}
+
+		if !modified {
+			return fmt.Errorf("Failed to reset password for corporation manager: user name or old password is not correct.")
+		}
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) ListCorporationManager(claOrgID string, opt dbmodels.CorporationManagerListOption) (dbmodels.CorporationManagerPage, error) {
+	var r dbmodels.CorporationManagerPage
+
+	if _, err := opt.Page.SortField(dbmodels.CorporationManagerSortFields); err != nil {
+		return r, err
+	}
+
+	f := func() error {
+		item, err := this.getEnabledCLAOrg(claOrgID, (*claOrg).isCorpoCLA)
+		if err != nil {
+			return err
+		}
+
+		v := make([]dbmodels.CorporationManagerCreateOption, 0)
+		if item != nil {
+			for _, m := range item.CorporationManagers {
+				if m.Role == opt.Role && m.CorporationID == opt.CorporationID {
+					v = append(v, m)
+				}
+			}
+		}
+
+		index, info, err := pageOf(opt.Page, len(v), 1, func(i int) []interface{} {
+			return []interface{}{v[i].Email}
+		})
+		if err != nil {
+			return err
+		}
+
+		r.PageInfo = info
+		r.Managers = make([]dbmodels.CorporationManagerListResult, 0, len(index))
+		for _, i := range index {
+			r.Managers = append(r.Managers, toCorporationManagerListResult(v[i]))
+		}
+		return nil
+	}
+
+	return r, this.do(f)
+}
+
+func (this *client) ListManagersWhenEmployeeSigning(claOrgIDs []string, corporID string) ([]dbmodels.CorporationManagerListResult, error) {
+	for _, id := range claOrgIDs {
+		if err := checkID(id); err != nil {
+			return nil, err
+		}
+	}
+
+	var r []dbmodels.CorporationManagerListResult
+
+	f := func() error {
+		var v []*claOrg
+		for _, id := range claOrgIDs {
+			if item, _ := this.getCLAOrg(id); item != nil {
+				v = append(v, item)
+			}
+		}
+
+		if len(v) == 0 {
+			return nil
+		}
+		if len(v) != 1 {
+			return fmt.Errorf("Failed to list corporation managers when employeee signing: impossible")
+		}
+
+		r = make([]dbmodels.CorporationManagerListResult, 0)
+		for _, m := range v[0].CorporationManagers {
+			if m.CorporationID == corporID {
+				r = append(r, toCorporationManagerListResult(m))
+			}
+		}
+		return nil
+	}
+
+	if err := this.do(f); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (this *client) DeleteCorporationManager(claOrgID string, opt []dbmodels.CorporationManagerCreateOption) error {
+	f := func() error {
+		emails := make(map[string]bool, len(opt))
+		for _, m := range opt {
+			emails[m.Email] = true
+		}
+
+		item, err := this.getEnabledCLAOrg(claOrgID, (*claOrg).isCorpoCLA)
+		if err != nil {
+			return err
+		}
+
+		n := 0
+		if item != nil {
+			for _, m := range item.CorporationManagers {
+				if m.Role == opt[0].Role && emails[m.Email] {
+					n++
+				}
+			}
+		}
+		if item == nil || n != len(opt) {
+			return fmt.Errorf("Failed to delete corporation manager: check failed: the managers to be deleted are not all the ones registered")
+		}
+
+		ms := make([]dbmodels.CorporationManagerCreateOption, 0, len(item.CorporationManagers))
+		for _, m := range item.CorporationManagers {
+			if !emails[m.Email] {
+				ms = append(ms, m)
+			}
+		}
+		item.CorporationManagers = ms
+		return nil
+	}
+
+	return this.do(f)
+}
@@ -0,0 +1,165 @@
+package memorydb
+
+import (
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+type corporationSigning struct {
+	dbmodels.CorporationSigningInfo
+
+	// Domains is the email domains claimed by corporation
+	Domains []dbmodels.CorporationDomain
+}
+
+// corpoCLAsOf returns the enabled bindings of corporation cla for the org/repo
+func (this *client) corpoCLAsOf(platform, orgID, repoID string) []*claOrg {
+	r := make([]*claOrg, 0)
+	for _, item := range this.claOrgs {
+		if item.isCorpoCLA() && item.isOf(platform, orgID, repoID) {
+			r = append(r, item)
+		}
+	}
+	return r
+}
+
+func (this *client) SignAsCorporation(claOrgID string, info dbmodels.CorporationSigningInfo) error {
+	f := func() error {
+		item, err := this.getCLAOrg(claOrgID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("error decoding to bson struct of CLA: mongo: no documents in result")
+		}
+
+		info.Info = copySigningInfo(info.Info)
+		info.Metadata = copySigningMetadata(info.Metadata)
+
+		for _, b := range this.corpoCLAsOf(item.Platform, item.OrgID, item.RepoID) {
+			for _, c := range b.Corporations {
+				if c.CorporationID != info.CorporationID {
+					continue
+				}
+
+				// it can sign again only if the version signed is older
+				if b != item || c.AdminEmail != info.AdminEmail || signedCLAVersion(c.CLAVersion) >= info.CLAVersion {
+					return fmt.Errorf("Failed to add info when signing as corporation, it has signed")
+				}
+
+				c.AdminName = info.AdminName
+				c.CorporationName = info.CorporationName
+				c.Info = info.Info
+				c.CLAVersion = info.CLAVersion
+				c.SignedAt = info.SignedAt
+				c.Metadata = info.Metadata
+				return nil
+			}
+		}
+
+		item.Corporations = append(item.Corporations, &corporationSigning{CorporationSigningInfo: info})
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) ListCorporationSigning(opt dbmodels.CorporationSigningListOption) (dbmodels.CorporationSigningPage, error) {
+	var r dbmodels.CorporationSigningPage
+
+	field, err := opt.Page.SortField(dbmodels.CorporationSigningSortFields)
+	if err != nil {
+		return r, err
+	}
+
+	f := func() error {
+		v := make([]dbmodels.CorporationSigningDetails, 0)
+		for _, item := range this.corpoCLAsOf(opt.Platform, opt.OrgID, opt.RepoID) {
+			if opt.CLALanguage != "" && item.CLALanguage != opt.CLALanguage {
+				continue
+			}
+
+			admins := map[string]bool{}
+			for _, m := range item.CorporationManagers {
+				if m.Role == models.RoleAdmin {
+					admins[m.Email] = true
+				}
+			}
+
+			for _, c := range item.Corporations {
+				v = append(v, dbmodels.CorporationSigningDetails{
+					CorporationSigningInfo: dbmodels.CorporationSigningInfo{
+						CorporationName: c.CorporationName,
+						CorporationID:   c.CorporationID,
+						AdminEmail:      c.AdminEmail,
+						AdminName:       c.AdminName,
+						Enabled:         c.Enabled,
+						CLAVersion:      signedCLAVersion(c.CLAVersion),
+						SignedAt:        c.SignedAt,
+						Metadata:        copySigningMetadata(c.Metadata),
+					},
+					AdministratorEnabled: admins[c.AdminEmail],
+					CLAOrgID:             item.ID,
+				})
+			}
+		}
+
+		index, info, err := pageOf(opt.Page, len(v), 3, func(i int) []interface{} {
+			var k interface{}
+			switch field {
+			case "corporation_name":
+				k = v[i].CorporationName
+			case "admin_email":
+				k = v[i].AdminEmail
+			default:
+				k = v[i].SignedAt
+			}
+			return []interface{}{k, v[i].CLAOrgID, v[i].CorporationID}
+		})
+		if err != nil {
+			return err
+		}
+
+		r.PageInfo = info
+		r.Signings = make([]dbmodels.CorporationSigningDetails, 0, len(index))
+		for _, i := range index {
+			r.Signings = append(r.Signings, v[i])
+		}
+		return nil
+	}
+
+	return r, this.do(f)
+}
+
+func (this *client) UpdateCorporationSigning(claOrgID, adminEmail, corporationName string, opt dbmodels.CorporationSigningUpdateInfo) error {
+	if opt.Enabled == nil {
+		return nil
+	}
+
+	f := func() error {
+		item, err := this.getEnabledCLAOrg(claOrgID, (*claOrg).isCorpoCLA)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("Failed to update corporation signing, doesn't match any record")
+		}
+
+		modified := false
+		for _, c := range item.Corporations {
+			if c.AdminEmail == adminEmail && c.CorporationName == corporationName && c.Enabled != *opt.Enabled {
+				c.Enabled = *opt.Enabled
+				modified = true
+			}
+		}
+
+		if !modified {
+			return fmt.Errorf("Failed to update corporation signing, impossible")
+		}
+		return nil
+	}
+
+	return this.do(f)
+}
@@ -0,0 +1,66 @@
+package memorydb
+
+import (
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+func (this *client) SetEmailTemplate(claOrgID, kind string, opt dbmodels.EmailTemplate) error {
+	f := func() error {
+		item, err := this.getEnabledCLAOrg(claOrgID, func(v *claOrg) bool { return v.Enabled })
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("Failed to set email template: the binding(%s) doesn't exist", claOrgID)
+		}
+
+		if item.EmailTemplates == nil {
+			item.EmailTemplates = map[string]dbmodels.EmailTemplate{}
+		}
+		item.EmailTemplates[kind] = opt
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) DeleteEmailTemplate(claOrgID, kind string) error {
+	f := func() error {
+		item, err := this.getCLAOrg(claOrgID)
+		if err != nil || item == nil {
+			return err
+		}
+
+		delete(item.EmailTemplates, kind)
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) ListEmailTemplate(claOrgID string) (map[string]dbmodels.EmailTemplate, error) {
+	var r map[string]dbmodels.EmailTemplate
+
+	f := func() error {
+		item, err := this.getCLAOrg(claOrgID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("error decoding to bson struct of CLAOrg: mongo: no documents in result")
+		}
+
+		r = make(map[string]dbmodels.EmailTemplate, len(item.EmailTemplates))
+		for k, v := range item.EmailTemplates {
+			r[k] = v
+		}
+		return nil
+	}
+
+	if err := this.do(f); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
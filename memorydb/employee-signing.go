@@ -0,0 +1,167 @@
+package memorydb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+func (this *client) SignAsEmployee(claOrgID string, info dbmodels.EmployeeSigningInfo) error {
+	corporationID := corporationIDOfEmployee(info.CorporationID, info.Email)
+
+	f := func() error {
+		item, err := this.getCLAOrg(claOrgID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("error decoding to bson struct of CLA: mongo: no documents in result")
+		}
+
+		// the employee can sign again if the version signed is older
+		for _, b := range this.claOrgs {
+			if !b.isIndividualCLA() || !b.isOf(item.Platform, item.OrgID, item.RepoID) {
+				continue
+			}
+
+			for _, e := range b.Employees[corporationID] {
+				if e.Email == info.Email && signedCLAVersion(e.CLAVersion) >= info.CLAVersion {
+					return fmt.Errorf("Failed to sign as employee, it has signed")
+				}
+			}
+		}
+
+		info.Info = copySigningInfo(info.Info)
+		info.Metadata = copySigningMetadata(info.Metadata)
+		info.CorporationID = ""
+
+		es := item.Employees[corporationID]
+		resigned := false
+		for i := range es {
+			if es[i].Email == info.Email {
+				// resign in place and keep the enabled status
+				es[i].Name = info.Name
+				es[i].Info = info.Info
+				es[i].SignedAt = info.SignedAt
+				es[i].CLAVersion = info.CLAVersion
+				es[i].Metadata = info.Metadata
+				resigned = true
+			}
+		}
+		if resigned {
+			return nil
+		}
+
+		if item.Employees == nil {
+			item.Employees = map[string][]dbmodels.EmployeeSigningInfo{}
+		}
+		item.Employees[corporationID] = append(es, info)
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) ListEmployeeSigning(opt dbmodels.EmployeeSigningListOption) (dbmodels.EmployeeSigningPage, error) {
+	var r dbmodels.EmployeeSigningPage
+
+	if !strings.Contains(opt.CorporationEmail, "@") {
+		return r, fmt.Errorf("invalid corporation email: %s", opt.CorporationEmail)
+	}
+
+	field, err := opt.Page.SortField(dbmodels.EmployeeSigningSortFields)
+	if err != nil {
+		return r, err
+	}
+
+	corporationID := emailSuffixToKey(opt.CorporationEmail)
+
+	f := func() error {
+		v := make([]dbmodels.EmployeeSigningDetail, 0)
+		for _, item := range this.claOrgs {
+			if !item.isIndividualCLA() || item.Platform != opt.Platform || item.OrgID != opt.OrgID {
+				continue
+			}
+			if (opt.RepoID != "" && item.RepoID != opt.RepoID) ||
+				(opt.CLALanguage != "" && item.CLALanguage != opt.CLALanguage) {
+				continue
+			}
+
+			for _, e := range item.Employees[corporationID] {
+				// the info filled by employee is not listed
+				v = append(v, dbmodels.EmployeeSigningDetail{
+					EmployeeSigningInfo: dbmodels.EmployeeSigningInfo{
+						Email:      e.Email,
+						Name:       e.Name,
+						Enabled:    e.Enabled,
+						SignedAt:   e.SignedAt,
+						CLAVersion: signedCLAVersion(e.CLAVersion),
+						Metadata:   copySigningMetadata(e.Metadata),
+					},
+					CLAOrgID: item.ID,
+				})
+			}
+		}
+
+		index, info, err := pageOf(opt.Page, len(v), 3, func(i int) []interface{} {
+			var k interface{}
+			switch field {
+			case "email":
+				k = v[i].Email
+			case "name":
+				k = v[i].Name
+			default:
+				k = v[i].SignedAt
+			}
+			return []interface{}{k, v[i].CLAOrgID, v[i].Email}
+		})
+		if err != nil {
+			return err
+		}
+
+		r.PageInfo = info
+		r.Signings = make([]dbmodels.EmployeeSigningDetail, 0, len(index))
+		for _, i := range index {
+			r.Signings = append(r.Signings, v[i])
+		}
+		return nil
+	}
+
+	return r, this.do(f)
+}
+
+func (this *client) UpdateEmployeeSigning(claOrgID, email string, opt dbmodels.EmployeeSigningUpdateInfo) error {
+	corporationID := corporationIDOfEmployee(opt.CorporationID, email)
+
+	f := func() error {
+		item, err := this.getEnabledCLAOrg(claOrgID, (*claOrg).isIndividualCLA)
+		if err != nil {
+			return err
+		}
+
+		var es []dbmodels.EmployeeSigningInfo
+		ok := false
+		if item != nil {
+			es, ok = item.Employees[corporationID]
+		}
+		if !ok {
+			return fmt.Errorf("Failed to update employee signing, the cla which employee had signed is not exist")
+		}
+
+		modified := false
+		for i := range es {
+			if es[i].Email == email && es[i].Enabled != opt.Enabled {
+				es[i].Enabled = opt.Enabled
+				modified = true
+			}
+		}
+
+		if !modified {
+			return fmt.Errorf("Failed to update employee signing, impossible")
+		}
+		return nil
+	}
+
+	return this.do(f)
+}
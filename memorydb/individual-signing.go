@@ -0,0 +1,210 @@
+package memorydb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+func (this *client) SignAsIndividual(claOrgID string, info dbmodels.IndividualSigningInfo) error {
+	f := func() error {
+		item, err := this.getCLAOrg(claOrgID)
+		if err != nil {
+			return err
+		}
+
+		k := emailToKey(info.Email)
+
+		// it can sign again if the version signed is older
+		if item != nil {
+			if s, ok := item.Individuals[k]; ok && signedCLAVersion(s.CLAVersion) >= info.CLAVersion {
+				item = nil
+			}
+		}
+		if item == nil {
+			return fmt.Errorf("Failed to add info when signing as individual, maybe he/she has signed")
+		}
+
+		if item.Individuals == nil {
+			item.Individuals = map[string]dbmodels.IndividualSigningInfo{}
+		}
+
+		info.Info = copySigningInfo(info.Info)
+		info.Metadata = copySigningMetadata(info.Metadata)
+		item.Individuals[k] = info
+		return nil
+	}
+
+	return this.do(f)
+}
+
+// bindingsOfRepo returns the bindings of repo if there are, otherwise the ones of org.
+func bindingsOfRepo(v []*claOrg, repoID string) []*claOrg {
+	r := make([]*claOrg, 0, len(v))
+	if repoID != "" {
+		for _, item := range v {
+			if item.RepoID == repoID {
+				r = append(r, item)
+			}
+		}
+		if len(r) != 0 {
+			return r
+		}
+	}
+
+	for _, item := range v {
+		if item.RepoID == "" {
+			r = append(r, item)
+		}
+	}
+	return r
+}
+
+// enabledCorporationSigning returns the cla signed by the corporation if it
+// has signed and been enabled, otherwise nil.
+func (this *client) enabledCorporationSigning(platform, orgID, repoID, corporationID string) *dbmodels.CorporationSigningCheckResult {
+	for _, b := range this.corpoCLAsOf(platform, orgID, repoID) {
+		for _, c := range b.Corporations {
+			if c.CorporationID == corporationID && c.Enabled {
+				return &dbmodels.CorporationSigningCheckResult{
+					CLAOrgID:          b.ID,
+					CLAID:             b.CLAID,
+					CLAVersion:        signedCLAVersion(c.CLAVersion),
+					CurrentCLAVersion: b.bindingCLAVersion(),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (this *client) CheckSigning(opt dbmodels.SigningCheckOption) (*dbmodels.SigningCheckResult, error) {
+	var r *dbmodels.SigningCheckResult
+
+	corporationID := corporationIDOfEmployee(opt.CorporationID, opt.Email)
+
+	f := func() error {
+		var v []*claOrg
+		for _, item := range this.claOrgs {
+			if item.isIndividualCLA() && item.Platform == opt.Platform && item.OrgID == opt.OrgID {
+				v = append(v, item)
+			}
+		}
+
+		v = bindingsOfRepo(v, opt.RepoID)
+
+		for _, item := range v {
+			if s, ok := item.Individuals[emailToKey(opt.Email)]; ok {
+				r = &dbmodels.SigningCheckResult{
+					Type:        models.ApplyToIndividual,
+					CLAOrgID:    item.ID,
+					CLAID:       item.CLAID,
+					CLALanguage: item.CLALanguage,
+					SignedAt:    s.SignedAt,
+
+					CLAVersion:        signedCLAVersion(s.CLAVersion),
+					CurrentCLAVersion: item.bindingCLAVersion(),
+				}
+				return nil
+			}
+		}
+
+		for _, item := range v {
+			for _, e := range item.Employees[corporationID] {
+				if e.Email != opt.Email || !e.Enabled {
+					continue
+				}
+
+				corp := this.enabledCorporationSigning(opt.Platform, opt.OrgID, item.RepoID, corporationID)
+				if corp == nil {
+					continue
+				}
+
+				r = &dbmodels.SigningCheckResult{
+					Type:        models.ApplyToCorporation,
+					CLAOrgID:    item.ID,
+					CLAID:       item.CLAID,
+					CLALanguage: item.CLALanguage,
+					SignedAt:    e.SignedAt,
+
+					CLAVersion:        signedCLAVersion(e.CLAVersion),
+					CurrentCLAVersion: item.bindingCLAVersion(),
+					Corporation:       corp,
+				}
+				return nil
+			}
+		}
+		return nil
+	}
+
+	return r, this.do(f)
+}
+
+func (this *client) ListIndividualSigning(opt dbmodels.IndividualSigningListOption) (dbmodels.IndividualSigningPage, error) {
+	var r dbmodels.IndividualSigningPage
+
+	field, err := opt.Page.SortField(dbmodels.IndividualSigningSortFields)
+	if err != nil {
+		return r, err
+	}
+
+	emailContains := strings.ToLower(opt.EmailContains)
+
+	f := func() error {
+		v := make([]dbmodels.IndividualSigningDetail, 0)
+		for _, item := range this.claOrgs {
+			if !item.isIndividualCLA() || item.Platform != opt.Platform || item.OrgID != opt.OrgID {
+				continue
+			}
+			if (opt.RepoID != "" && item.RepoID != opt.RepoID) ||
+				(opt.CLALanguage != "" && item.CLALanguage != opt.CLALanguage) {
+				continue
+			}
+
+			for _, s := range item.Individuals {
+				if emailContains != "" && !strings.Contains(strings.ToLower(s.Email), emailContains) {
+					continue
+				}
+				if (opt.SignedFrom > 0 && s.SignedAt < opt.SignedFrom) ||
+					(opt.SignedTo > 0 && s.SignedAt > opt.SignedTo) {
+					continue
+				}
+
+				v = append(v, dbmodels.IndividualSigningDetail{
+					IndividualSigningInfo: dbmodels.IndividualSigningInfo{
+						Email:      s.Email,
+						Info:       copySigningInfo(s.Info),
+						SignedAt:   s.SignedAt,
+						CLAVersion: signedCLAVersion(s.CLAVersion),
+						Metadata:   copySigningMetadata(s.Metadata),
+					},
+					CLAOrgID:    item.ID,
+					RepoID:      item.RepoID,
+					CLALanguage: item.CLALanguage,
+				})
+			}
+		}
+
+		index, info, err := pageOf(opt.Page, len(v), 3, func(i int) []interface{} {
+			var k interface{} = v[i].SignedAt
+			if field == "email" {
+				k = v[i].Email
+			}
+			return []interface{}{k, v[i].CLAOrgID, v[i].Email}
+		})
+		if err != nil {
+			return err
+		}
+
+		r.PageInfo = info
+		r.Signings = make([]dbmodels.IndividualSigningDetail, 0, len(index))
+		for _, i := range index {
+			r.Signings = append(r.Signings, v[i])
+		}
+		return nil
+	}
+
+	return r, this.do(f)
+}
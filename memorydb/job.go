@@ -0,0 +1,177 @@
+package memorydb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+func (this *client) getJob(uid string) (*dbmodels.Job, error) {
+	if err := checkID(uid); err != nil {
+		return nil, err
+	}
+
+	for _, item := range this.jobs {
+		if item.ID == uid {
+			return item, nil
+		}
+	}
+	return nil, nil
+}
+
+func (this *client) CreateJob(info dbmodels.Job) (string, error) {
+	uid := ""
+
+	f := func() error {
+		uid = this.newID()
+		info.ID = uid
+		this.jobs = append(this.jobs, &info)
+		return nil
+	}
+
+	return uid, this.do(f)
+}
+
+func (this *client) ClaimJob(now, lockedUntil int64, claimToken string) (*dbmodels.Job, error) {
+	var r *dbmodels.Job
+
+	f := func() error {
+		var v *dbmodels.Job
+		for _, item := range this.jobs {
+			// A running job whose lock has expired was left by a crashed worker,
+			// so it can be picked up again.
+			due := (item.Status == models.JobStatusPending && item.NextRunAt <= now) ||
+				(item.Status == models.JobStatusRunning && item.LockedUntil < now)
+
+			if due && (v == nil || item.NextRunAt < v.NextRunAt) {
+				v = item
+			}
+		}
+
+		if v == nil {
+			return nil
+		}
+
+		v.Status = models.JobStatusRunning
+		v.LockedUntil = lockedUntil
+		v.ClaimToken = claimToken
+		v.UpdatedAt = now
+		v.Attempts++
+
+		j := *v
+		r = &j
+		return nil
+	}
+
+	return r, this.do(f)
+}
+
+func (this *client) UpdateJob(uid string, opt dbmodels.JobUpdateInfo) error {
+	if opt.Status == nil && opt.Attempts == nil && opt.LastError == nil && opt.NextRunAt == nil {
+		return nil
+	}
+
+	f := func() error {
+		item, err := this.getJob(uid)
+		if err != nil {
+			return err
+		}
+
+		if item != nil && len(opt.ExpectedStatus) != 0 {
+			matched := false
+			for _, s := range opt.ExpectedStatus {
+				if item.Status == s {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				item = nil
+			}
+		}
+
+		if item != nil && opt.ClaimToken != "" && item.ClaimToken != opt.ClaimToken {
+			item = nil
+		}
+
+		if item == nil {
+			return fmt.Errorf("Failed to update job, the job is not exist, its status is not in %v or it has been claimed by others", opt.ExpectedStatus)
+		}
+
+		if opt.Status != nil {
+			item.Status = *opt.Status
+		}
+		if opt.Attempts != nil {
+			item.Attempts = *opt.Attempts
+		}
+		if opt.LastError != nil {
+			item.LastError = *opt.LastError
+		}
+		if opt.NextRunAt != nil {
+			item.NextRunAt = *opt.NextRunAt
+		}
+		item.UpdatedAt = time.Now().Unix()
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) GetJob(uid string) (dbmodels.Job, error) {
+	var r dbmodels.Job
+
+	f := func() error {
+		item, err := this.getJob(uid)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("error decoding to bson struct of job: mongo: no documents in result")
+		}
+
+		r = *item
+		return nil
+	}
+
+	return r, this.do(f)
+}
+
+func (this *client) ListJob(opt dbmodels.JobListOption) (dbmodels.JobPage, error) {
+	var r dbmodels.JobPage
+
+	if _, err := opt.Page.SortField(dbmodels.JobSortFields); err != nil {
+		return r, err
+	}
+
+	f := func() error {
+		v := make([]*dbmodels.Job, 0)
+		for _, item := range this.jobs {
+			if item.CLAOrgID != opt.CLAOrgID {
+				continue
+			}
+			if (opt.Status != "" && item.Status != opt.Status) ||
+				(opt.CorporationID != "" && item.CorporationID != opt.CorporationID) {
+				continue
+			}
+			v = append(v, item)
+		}
+
+		index, info, err := pageOf(opt.Page, len(v), 2, func(i int) []interface{} {
+			return []interface{}{v[i].CreatedAt, v[i].ID}
+		})
+		if err != nil {
+			return err
+		}
+
+		r.PageInfo = info
+		r.Jobs = make([]dbmodels.Job, 0, len(index))
+		for _, i := range index {
+			r.Jobs = append(r.Jobs, *v[i])
+		}
+		return nil
+	}
+
+	return r, this.do(f)
+}
@@ -0,0 +1,193 @@
+package memorydb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+var _ models.IDB = (*client)(nil)
+var _ dbmodels.IDB = (*client)(nil)
+
+// client keeps all the data in memory and behaves like the mongodb one.
+// It is used for tests and local development, and the data is lost when
+// the server exits.
+type client struct {
+	lock sync.Mutex
+
+	// lastID is used to generate the ids which look like the ones of mongodb
+	lastID int64
+
+	claOrgs      []*claOrg
+	clas         []*cla
+	claMetadatas []models.CLAMetadata
+
+	orgEmails       map[string]dbmodels.OrgEmailCreateInfo
+	blankSignatures map[string][]byte
+	platformTokens  map[string]dbmodels.PlatformToken
+	verifiCodes     []*verificationCode
+	jobs            []*dbmodels.Job
+	refreshTokens   []dbmodels.RefreshToken
+	revokedSessions map[string]int64
+	auditLogs       []dbmodels.AuditLog
+}
+
+func NewDatabase() *client {
+	return &client{
+		orgEmails:       map[string]dbmodels.OrgEmailCreateInfo{},
+		blankSignatures: map[string][]byte{},
+		platformTokens:  map[string]dbmodels.PlatformToken{},
+		revokedSessions: map[string]int64{},
+	}
+}
+
+func (this *client) Close() error {
+	return nil
+}
+
+// do runs f with the lock held, so that every operation is atomic like the transaction of mongodb
+func (this *client) do(f func() error) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return f()
+}
+
+func (this *client) newID() string {
+	this.lastID++
+	return fmt.Sprintf("%024x", this.lastID)
+}
+
+// checkID returns the same error as mongodb for the invalid id
+func checkID(uid string) error {
+	if len(uid) != 24 || strings.Trim(strings.ToLower(uid), "0123456789abcdef") != "" {
+		return fmt.Errorf("the provided hex string is not a valid ObjectID")
+	}
+	return nil
+}
+
+func emailToKey(email string) string {
+	return strings.ReplaceAll(email, ".", "_")
+}
+
+func emailSuffixToKey(email string) string {
+	return emailToKey(strings.Split(email, "@")[1])
+}
+
+// corporationIDOfEmployee returns the corporation which the employee belongs to.
+// It is the domain of email if the corporation is not specified.
+func corporationIDOfEmployee(corporationID, email string) string {
+	if corporationID != "" {
+		return corporationID
+	}
+	return emailSuffixToKey(email)
+}
+
+// signedCLAVersion returns the version of cla signed, and the signing
+// done before versioning is treated as version 1.
+func signedCLAVersion(v int) int {
+	if v == 0 {
+		return 1
+	}
+	return v
+}
+
+func copySigningInfo(info dbmodels.TypeSigningInfo) dbmodels.TypeSigningInfo {
+	if info == nil {
+		return nil
+	}
+
+	r := make(dbmodels.TypeSigningInfo, len(info))
+	for k, v := range info {
+		r[k] = v
+	}
+	return r
+}
+
+func copySigningMetadata(m *dbmodels.SigningMetadata) *dbmodels.SigningMetadata {
+	if m == nil {
+		return nil
+	}
+
+	v := *m
+	return &v
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+// pageOf sorts the n items and returns the indexes of the items in the page.
+// keys returns the sort keys of item i, and width is the number of keys.
+func pageOf(page dbmodels.PageOption, n, width int, keys func(i int) []interface{}) ([]int, dbmodels.PageInfo, error) {
+	info := dbmodels.PageInfo{Total: n}
+
+	cursor, err := page.DecodeCursor(width)
+	if err != nil {
+		return nil, info, err
+	}
+
+	v := make([][]interface{}, n)
+	index := make([]int, n)
+	for i := range index {
+		index[i] = i
+		v[i] = keys(i)
+	}
+
+	less := func(a, b []interface{}) bool {
+		if page.Desc {
+			return compareKeys(a, b) > 0
+		}
+		return compareKeys(a, b) < 0
+	}
+
+	sort.Slice(index, func(i, j int) bool {
+		return less(v[index[i]], v[index[j]])
+	})
+
+	start := 0
+	if cursor != nil {
+		start = sort.Search(n, func(i int) bool {
+			return less(cursor, v[index[i]])
+		})
+	}
+
+	end := n
+	if page.Limit > 0 && start+page.Limit < n {
+		end = start + page.Limit
+		info.NextCursor = page.EncodeCursor(v[index[end-1]]...)
+	}
+	return index[start:end], info, nil
+}
+
+func compareKeys(a, b []interface{}) int {
+	for i := range a {
+		if r := compareKey(a[i], b[i]); r != 0 {
+			return r
+		}
+	}
+	return 0
+}
+
+func compareKey(a, b interface{}) int {
+	x, ok := a.(int64)
+	y, ok1 := b.(int64)
+	if !ok || !ok1 {
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,13 @@
+package memorydb
+
+import (
+	"testing"
+
+	"github.com/zengchen1024/cla-server/dbmodels/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) conformance.DB {
+		return NewDatabase()
+	})
+}
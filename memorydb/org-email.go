@@ -0,0 +1,39 @@
+package memorydb
+
+import (
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+func (this *client) CreateOrgEmail(opt dbmodels.OrgEmailCreateInfo) error {
+	f := func() error {
+		// update the credential if the email has been added by the same submitter
+		if v, ok := this.orgEmails[opt.Email]; ok && dbmodels.OrgEmailOwner(v) != opt.Submitter {
+			return fmt.Errorf("Failed to create org email info: it was added by others")
+		}
+
+		opt.Token = copyBytes(opt.Token)
+		this.orgEmails[opt.Email] = opt
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) GetOrgEmailInfo(email string) (dbmodels.OrgEmailCreateInfo, error) {
+	var r dbmodels.OrgEmailCreateInfo
+
+	f := func() error {
+		v, ok := this.orgEmails[email]
+		if !ok {
+			return fmt.Errorf("error decoding to bson struct: mongo: no documents in result")
+		}
+
+		r = v
+		r.Token = copyBytes(v.Token)
+		return nil
+	}
+
+	return r, this.do(f)
+}
@@ -0,0 +1,42 @@
+package memorydb
+
+import (
+	"fmt"
+)
+
+func (this *client) UploadOrgSignature(claOrgID string, pdf []byte) error {
+	f := func() error {
+		item, err := this.getCLAOrg(claOrgID)
+		if err != nil || item == nil {
+			return err
+		}
+
+		item.OrgSignature = copyBytes(pdf)
+		item.OrgSignatureUploaded = true
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) DownloadOrgSignature(claOrgID string) ([]byte, error) {
+	var r []byte
+
+	f := func() error {
+		item, err := this.getCLAOrg(claOrgID)
+		if err != nil {
+			return err
+		}
+		if item == nil || !item.OrgSignatureUploaded {
+			return fmt.Errorf("error decoding to bson struct of CLAOrg: mongo: no documents in result")
+		}
+
+		r = copyBytes(item.OrgSignature)
+		return nil
+	}
+
+	if err := this.do(f); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
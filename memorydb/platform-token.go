@@ -0,0 +1,32 @@
+package memorydb
+
+import (
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+func (this *client) SavePlatformToken(opt dbmodels.PlatformToken) error {
+	f := func() error {
+		this.platformTokens[opt.User] = opt
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) GetPlatformToken(user string) (dbmodels.PlatformToken, error) {
+	var r dbmodels.PlatformToken
+
+	f := func() error {
+		v, ok := this.platformTokens[user]
+		if !ok {
+			return fmt.Errorf("error decoding to bson struct of platform token: mongo: no documents in result")
+		}
+
+		r = v
+		return nil
+	}
+
+	return r, this.do(f)
+}
@@ -0,0 +1,115 @@
+package memorydb
+
+import (
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+const (
+	revokedSigningTypeIndividual = "individual"
+	revokedSigningTypeEmployee   = "employee"
+)
+
+func newRevokedSigning(t string, signedAt int64, claVersion int, opt dbmodels.SigningRevocation) dbmodels.RevokedSigning {
+	return dbmodels.RevokedSigning{
+		Type:       t,
+		Email:      opt.Email,
+		SignedAt:   signedAt,
+		CLAVersion: signedCLAVersion(claVersion),
+		RevokedBy:  opt.RevokedBy,
+		Reason:     opt.Reason,
+		RevokedAt:  opt.RevokedAt,
+	}
+}
+
+func (this *client) RevokeIndividualSigning(claOrgID string, opt dbmodels.SigningRevocation) error {
+	k := emailToKey(opt.Email)
+
+	f := func() error {
+		item, err := this.getCLAOrg(claOrgID)
+		if err != nil {
+			return err
+		}
+
+		var s dbmodels.IndividualSigningInfo
+		ok := false
+		if item != nil {
+			s, ok = item.Individuals[k]
+		}
+		if !ok {
+			return fmt.Errorf("Failed to revoke individual signing, he/she has not signed")
+		}
+
+		delete(item.Individuals, k)
+		item.RevokedSignings = append(
+			item.RevokedSignings,
+			newRevokedSigning(revokedSigningTypeIndividual, s.SignedAt, s.CLAVersion, opt),
+		)
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) RevokeEmployeeSigning(claOrgID string, opt dbmodels.SigningRevocation) error {
+	corporationID := corporationIDOfEmployee(opt.CorporationID, opt.Email)
+
+	f := func() error {
+		item, err := this.getCLAOrg(claOrgID)
+		if err != nil {
+			return err
+		}
+
+		var es []dbmodels.EmployeeSigningInfo
+		if item != nil {
+			es = item.Employees[corporationID]
+		}
+
+		var tombstone *dbmodels.RevokedSigning
+		es1 := make([]dbmodels.EmployeeSigningInfo, 0, len(es))
+		for _, e := range es {
+			if e.Email != opt.Email {
+				es1 = append(es1, e)
+				continue
+			}
+
+			if tombstone == nil {
+				v := newRevokedSigning(revokedSigningTypeEmployee, e.SignedAt, e.CLAVersion, opt)
+				tombstone = &v
+			}
+		}
+
+		if tombstone == nil {
+			return fmt.Errorf("Failed to revoke employee signing, he/she has not signed")
+		}
+
+		item.Employees[corporationID] = es1
+		item.RevokedSignings = append(item.RevokedSignings, *tombstone)
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) ListRevokedSigning(claOrgID string) ([]dbmodels.RevokedSigning, error) {
+	var r []dbmodels.RevokedSigning
+
+	f := func() error {
+		item, err := this.getCLAOrg(claOrgID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("error decoding to bson struct of CLAOrg: mongo: no documents in result")
+		}
+
+		r = append([]dbmodels.RevokedSigning{}, item.RevokedSignings...)
+		return nil
+	}
+
+	if err := this.do(f); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,80 @@
+package memorydb
+
+import (
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+type verificationCode struct {
+	dbmodels.VerificationCode
+
+	Attempts int
+}
+
+func (this *client) CreateVerificationCode(opt dbmodels.VerificationCode) error {
+	f := func() error {
+		// delete the old codes, including unused ones. The attempts of the
+		// unexpired one are kept, so resending can't reset the limit.
+		attempts := 0
+		v := make([]*verificationCode, 0, len(this.verifiCodes)+1)
+		for _, item := range this.verifiCodes {
+			if item.Email != opt.Email || item.Purpose != opt.Purpose {
+				v = append(v, item)
+			} else if item.Expiry >= opt.CreatedAt {
+				attempts = item.Attempts
+			}
+		}
+
+		this.verifiCodes = append(v, &verificationCode{VerificationCode: opt, Attempts: attempts})
+		return nil
+	}
+
+	return this.do(f)
+}
+
+func (this *client) CheckVerificationCode(opt dbmodels.VerificationCode, maxAttempts int) (bool, error) {
+	valid := false
+
+	f := func() error {
+		for i, item := range this.verifiCodes {
+			if item.Email != opt.Email || item.Purpose != opt.Purpose || item.Attempts >= maxAttempts {
+				continue
+			}
+
+			// count the attempt first, so the guesses can't exceed the limit.
+			item.Attempts++
+
+			if item.Code != opt.Code || item.Expiry < time.Now().Unix() {
+				return nil
+			}
+
+			this.verifiCodes = append(this.verifiCodes[:i], this.verifiCodes[i+1:]...)
+			valid = true
+			return nil
+		}
+		return nil
+	}
+
+	return valid, this.do(f)
+}
+
+func (this *client) HasVerificationCodeSentSince(email, purpose, ip string, since int64) (bool, error) {
+	sent := false
+
+	f := func() error {
+		for _, item := range this.verifiCodes {
+			if item.CreatedAt < since {
+				continue
+			}
+
+			if (item.Email == email && item.Purpose == purpose) || (ip != "" && item.IP == ip) {
+				sent = true
+				break
+			}
+		}
+		return nil
+	}
+
+	return sent, this.do(f)
+}
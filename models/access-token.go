@@ -0,0 +1,118 @@
+package models
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+// RefreshToken is the session of user. The token returned to user is in
+// the format of id.secret, and only the hash of secret is saved.
+type RefreshToken struct {
+	ID         string `json:"id"`
+	User       string `json:"user"`
+	Permission string `json:"permission"`
+	Expiry     int64  `json:"expiry"`
+}
+
+func hashToken(token string) string {
+	v := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(v[:])
+}
+
+// Create creates the session which expires after expiry seconds and returns the refresh token.
+func (this *RefreshToken) Create(expiry int64) (string, error) {
+	id, err := genToken()
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := genToken()
+	if err != nil {
+		return "", err
+	}
+
+	this.ID = id
+	this.Expiry = time.Now().Unix() + expiry
+
+	opt := dbmodels.RefreshToken{
+		ID:         this.ID,
+		Token:      hashToken(secret),
+		User:       this.User,
+		Permission: this.Permission,
+		Expiry:     this.Expiry,
+	}
+	if err := dbmodels.GetDB().CreateRefreshToken(opt); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s.%s", id, secret), nil
+}
+
+// Check checks the refresh token and loads the session.
+func (this *RefreshToken) Check(token string) error {
+	v := strings.Split(token, ".")
+	if len(v) != 2 {
+		return fmt.Errorf("invalid refresh token")
+	}
+
+	r, err := dbmodels.GetDB().GetRefreshToken(v[0])
+	if err != nil {
+		return fmt.Errorf("invalid refresh token")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(r.Token), []byte(hashToken(v[1]))) != 1 {
+		return fmt.Errorf("invalid refresh token")
+	}
+
+	if r.Expiry < time.Now().Unix() {
+		return fmt.Errorf("refresh token is expired")
+	}
+
+	this.ID = r.ID
+	this.User = r.User
+	this.Permission = r.Permission
+	this.Expiry = r.Expiry
+	return nil
+}
+
+// RevokeSession deletes the refresh token of session and revokes all its access tokens.
+// The access tokens never outlive the session, so they are revoked until the later one
+// of the session expiry and tokenExpiry.
+func RevokeSession(id string, tokenExpiry int64) error {
+	if r, err := dbmodels.GetDB().GetRefreshToken(id); err == nil && r.Expiry > tokenExpiry {
+		tokenExpiry = r.Expiry
+	}
+
+	if err := dbmodels.GetDB().DeleteRefreshToken(id); err != nil {
+		return err
+	}
+
+	return dbmodels.GetDB().RevokeSession(dbmodels.RevokedSession{ID: id, Expiry: tokenExpiry})
+}
+
+// RevokeSessionsOfUser revokes all the sessions of user, such as a deleted manager
+// or the one whose password is changed.
+func RevokeSessionsOfUser(user string) error {
+	v, err := dbmodels.GetDB().DeleteRefreshTokensOfUser(user)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range v {
+		opt := dbmodels.RevokedSession{ID: item.ID, Expiry: item.Expiry}
+		if err := dbmodels.GetDB().RevokeSession(opt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func IsSessionRevoked(id string) (bool, error) {
+	return dbmodels.GetDB().IsSessionRevoked(id)
+}
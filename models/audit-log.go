@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+const (
+	AuditActionBindCLA                 = "bind-cla"
+	AuditActionUnbindCLA               = "unbind-cla"
+	AuditActionSetBotToken             = "set-bot-token"
+	AuditActionUpdateBindingCLA        = "update-binding-cla-version"
+	AuditActionCreateCLA               = "create-cla"
+	AuditActionPublishCLAVersion       = "publish-cla-version"
+	AuditActionDeleteCLA               = "delete-cla"
+	AuditActionAddOrgEmail             = "add-org-email"
+	AuditActionSetEmailTemplate        = "set-email-template"
+	AuditActionDeleteEmailTemplate     = "delete-email-template"
+	AuditActionUploadOrgSignature      = "upload-org-signature"
+	AuditActionEnableCorporation       = "enable-corporation"
+	AuditActionDisableCorporation      = "disable-corporation"
+	AuditActionAddCorporationManager   = "add-corporation-manager"
+	AuditActionAddEmployeeManager      = "add-employee-manager"
+	AuditActionDeleteEmployeeManager   = "delete-employee-manager"
+	AuditActionEnableEmployee          = "enable-employee"
+	AuditActionDisableEmployee         = "disable-employee"
+	AuditActionRevokeIndividual        = "revoke-individual-signing"
+	AuditActionRevokeEmployee          = "revoke-employee-signing"
+	AuditActionChangePassword          = "change-password"
+	AuditActionResetPassword           = "reset-password"
+	AuditActionRetryJob                = "retry-job"
+	AuditActionCancelJob               = "cancel-job"
+	AuditActionSetDomainVerification   = "set-domain-verification"
+	AuditActionAddCorporationDomain    = "add-corporation-domain"
+	AuditActionVerifyCorporationDomain = "verify-corporation-domain"
+	AuditActionCreateCLAMetadata       = "create-cla-metadata"
+	AuditActionDeleteCLAMetadata       = "delete-cla-metadata"
+	AuditActionSendEmail               = "send-email"
+)
+
+// AuditLog records who did what to which target. Before and After
+// are the values of target in json if they are meaningful.
+type AuditLog struct {
+	ID            string `json:"id"`
+	Actor         string `json:"actor"`
+	Permission    string `json:"permission"`
+	Action        string `json:"action"`
+	CLAOrgID      string `json:"cla_org_id"`
+	CorporationID string `json:"corporation_id"`
+	Target        string `json:"target"`
+	Before        string `json:"before"`
+	After         string `json:"after"`
+	CreatedAt     int64  `json:"created_at"`
+}
+
+func (this *AuditLog) Create() error {
+	this.CreatedAt = time.Now().Unix()
+
+	p := dbmodels.AuditLog{}
+	if err := copyBetweenStructs(this, &p); err != nil {
+		return err
+	}
+	return dbmodels.GetDB().AddAuditLog(p)
+}
+
+type AuditLogListOption struct {
+	CLAOrgID      string `json:"cla_org_id"`
+	CorporationID string `json:"corporation_id"`
+	Actor         string `json:"actor"`
+	Action        string `json:"action"`
+	From          int64  `json:"from"`
+	To            int64  `json:"to"`
+
+	Page dbmodels.PageOption `json:"-"`
+}
+
+func (this AuditLogListOption) Validate() error {
+	return this.Page.Validate(dbmodels.AuditLogSortFields)
+}
+
+func (this AuditLogListOption) List() (dbmodels.AuditLogPage, error) {
+	return dbmodels.GetDB().ListAuditLog(dbmodels.AuditLogListOption{
+		CLAOrgID:      this.CLAOrgID,
+		CorporationID: this.CorporationID,
+		Actor:         this.Actor,
+		Action:        this.Action,
+		From:          this.From,
+		To:            this.To,
+		Page:          this.Page,
+	})
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+const (
+	FieldTypeText   = "text"
+	FieldTypeEmail  = "email"
+	FieldTypeDate   = "date"
+	FieldTypePhone  = "phone"
+	FieldTypeNumber = "number"
+
+	fieldDateLayout = "2006-01-02"
+)
+
+var phoneRegexp = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{4,19}$`)
+
+// FieldError is the reason why a field is invalid
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// FieldErrors is returned when some fields are invalid, which tells the reason of each field.
+type FieldErrors []FieldError
+
+func (this FieldErrors) Error() string {
+	s := make([]string, 0, len(this))
+	for _, item := range this {
+		s = append(s, fmt.Sprintf("%s: %s", item.Field, item.Reason))
+	}
+	return fmt.Sprintf("invalid fields, %s", strings.Join(s, "; "))
+}
+
+func (this FieldErrors) add(field, reason string, v ...interface{}) FieldErrors {
+	return append(this, FieldError{Field: field, Reason: fmt.Sprintf(reason, v...)})
+}
+
+func (this FieldErrors) toError() error {
+	if len(this) == 0 {
+		return nil
+	}
+	return this
+}
+
+func isValidFieldType(t string) bool {
+	switch t {
+	case FieldTypeText, FieldTypeEmail, FieldTypeDate, FieldTypePhone, FieldTypeNumber:
+		return true
+	}
+	return false
+}
+
+// validateFields checks the definitions of fields. The id of field must be
+// a number, because the fields are sorted by it when generating pdf.
+func validateFields(fields []Field) error {
+	var errs FieldErrors
+
+	ids := map[string]bool{}
+	for i, item := range fields {
+		name := fmt.Sprintf("fields[%d]", i)
+
+		if _, err := strconv.Atoi(item.ID); err != nil {
+			errs = errs.add(name, "the id(%s) is not a number", item.ID)
+		} else if ids[item.ID] {
+			errs = errs.add(name, "the id(%s) is duplicate", item.ID)
+		}
+		ids[item.ID] = true
+
+		if strings.TrimSpace(item.Title) == "" {
+			errs = errs.add(name, "missing title")
+		}
+
+		if !isValidFieldType(item.Type) {
+			errs = errs.add(name, "unknown type: %s", item.Type)
+		}
+	}
+	return errs.toError()
+}
+
+// validateSigningInfo checks the info filled when signing against the fields of cla.
+// The key of info is the id of field.
+func validateSigningInfo(fields []Field, info dbmodels.TypeSigningInfo) error {
+	var errs FieldErrors
+
+	m := make(map[string]Field, len(fields))
+	for _, item := range fields {
+		m[item.ID] = item
+	}
+
+	for k := range info {
+		if _, ok := m[k]; !ok {
+			errs = errs.add(k, "unknown field")
+		}
+	}
+
+	for _, item := range fields {
+		v := strings.TrimSpace(info[item.ID])
+		if v == "" {
+			if item.Required {
+				errs = errs.add(item.ID, "%s is required", item.Title)
+			}
+			continue
+		}
+
+		if err := checkFieldValue(item.Type, v); err != nil {
+			errs = errs.add(item.ID, "%s %s", item.Title, err.Error())
+		}
+	}
+	return errs.toError()
+}
+
+func checkFieldValue(t, v string) error {
+	switch t {
+	case FieldTypeEmail:
+		if a, err := mail.ParseAddress(v); err != nil || a.Address != v {
+			return fmt.Errorf("is not a valid email")
+		}
+	case FieldTypeDate:
+		if _, err := time.Parse(fieldDateLayout, v); err != nil {
+			return fmt.Errorf("is not a valid date, such as 2020-01-01")
+		}
+	case FieldTypePhone:
+		if !phoneRegexp.MatchString(v) {
+			return fmt.Errorf("is not a valid phone number")
+		}
+	case FieldTypeNumber:
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return fmt.Errorf("is not a number")
+		}
+	}
+	return nil
+}
+
+// validateSigningInfoOfBinding checks the info against the fields of cla which the binding points to
+func validateSigningInfoOfBinding(claOrgID string, info dbmodels.TypeSigningInfo) error {
+	binding, err := dbmodels.GetDB().GetBindingBetweenCLAAndOrg(claOrgID)
+	if err != nil {
+		return err
+	}
+
+	cla := &CLA{ID: binding.CLAID}
+	if err := cla.Get(); err != nil {
+		return err
+	}
+
+	return validateSigningInfo(cla.Fields, info)
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+func TestValidateFields(t *testing.T) {
+	fields := []Field{
+		{ID: "1", Title: "Name", Type: FieldTypeText},
+		{ID: "a", Title: "Email", Type: FieldTypeEmail},
+		{ID: "1", Title: "", Type: "unknown"},
+	}
+
+	err := validateFields(fields)
+	errs, ok := err.(FieldErrors)
+	if !ok {
+		t.Fatalf("expect FieldErrors, but got %v", err)
+	}
+
+	want := []string{"fields[1]", "fields[2]", "fields[2]", "fields[2]"}
+	if len(errs) != len(want) {
+		t.Fatalf("expect %d errors, but got: %v", len(want), errs)
+	}
+	for i, item := range errs {
+		if item.Field != want[i] {
+			t.Errorf("expect error of %s, but got: %v", want[i], item)
+		}
+	}
+
+	if err := validateFields(fields[:1]); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateSigningInfo(t *testing.T) {
+	fields := []Field{
+		{ID: "1", Title: "Name", Type: FieldTypeText, Required: true},
+		{ID: "2", Title: "Email", Type: FieldTypeEmail, Required: true},
+		{ID: "3", Title: "Date", Type: FieldTypeDate},
+		{ID: "4", Title: "Phone", Type: FieldTypePhone},
+		{ID: "5", Title: "Employees", Type: FieldTypeNumber},
+	}
+
+	cases := []struct {
+		name string
+		info dbmodels.TypeSigningInfo
+		want map[string]bool
+	}{
+		{
+			name: "valid",
+			info: dbmodels.TypeSigningInfo{
+				"1": "Alice", "2": "alice@example.com", "3": "2020-01-31",
+				"4": "+86 (010) 1234-5678", "5": "100",
+			},
+		},
+		{
+			name: "optional fields can be empty",
+			info: dbmodels.TypeSigningInfo{"1": "Alice", "2": "alice@example.com", "3": ""},
+		},
+		{
+			name: "required fields are missing",
+			info: dbmodels.TypeSigningInfo{"1": " "},
+			want: map[string]bool{"1": true, "2": true},
+		},
+		{
+			name: "invalid values",
+			info: dbmodels.TypeSigningInfo{
+				"1": "Alice", "2": "Alice <alice@example.com>", "3": "2020-02-30",
+				"4": "phone", "5": "1e",
+			},
+			want: map[string]bool{"2": true, "3": true, "4": true, "5": true},
+		},
+		{
+			name: "unknown field",
+			info: dbmodels.TypeSigningInfo{"1": "Alice", "2": "alice@example.com", "6": "x"},
+			want: map[string]bool{"6": true},
+		},
+	}
+
+	for _, c := range cases {
+		err := validateSigningInfo(fields, c.info)
+		if len(c.want) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", c.name, err)
+			}
+			continue
+		}
+
+		errs, ok := err.(FieldErrors)
+		if !ok {
+			t.Errorf("%s: expect FieldErrors, but got %v", c.name, err)
+			continue
+		}
+
+		got := map[string]bool{}
+		for _, item := range errs {
+			got[item.Field] = true
+		}
+		if len(got) != len(c.want) {
+			t.Errorf("%s: expect errors of %v, but got: %v", c.name, c.want, errs)
+			continue
+		}
+		for k := range c.want {
+			if !got[k] {
+				t.Errorf("%s: expect error of field %s, but got: %v", c.name, k, errs)
+			}
+		}
+	}
+}
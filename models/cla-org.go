@@ -13,6 +13,7 @@ type CLAOrg struct {
 	RepoID               string    `json:"repo_id"`
 	CLAID                string    `json:"cla_id"`
 	CLALanguage          string    `json:"cla_language"`
+	CLAVersion           int       `json:"cla_version"`
 	ApplyTo              string    `json:"apply_to"`
 	OrgEmail             string    `json:"org_email"`
 	Enabled              bool      `json:"enabled"`
@@ -20,6 +21,8 @@ type CLAOrg struct {
 	CreatedAt            time.Time `json:"created_at"`
 	UpdatedAt            time.Time `json:"updated_at"`
 	OrgSignatureUploaded bool      `json:"org_signature_uploaded"`
+
+	DomainVerificationRequired bool `json:"domain_verification_required"`
 }
 
 func (this *CLAOrg) Create() error {
@@ -42,6 +45,15 @@ func (this CLAOrg) Delete() error {
 	return dbmodels.GetDB().DeleteBindingBetweenCLAAndOrg(this.ID)
 }
 
+func (this CLAOrg) UpdateCLAVersion(version int) error {
+	return dbmodels.GetDB().UpdateBindingCLAVersion(this.ID, version)
+}
+
+// UpdateDomainVerification sets whether the corporation must verify its domain before being enabled
+func (this CLAOrg) UpdateDomainVerification(required bool) error {
+	return dbmodels.GetDB().UpdateBindingDomainVerification(this.ID, required)
+}
+
 func (this *CLAOrg) Get() error {
 	v, err := dbmodels.GetDB().GetBindingBetweenCLAAndOrg(this.ID)
 	if err != nil {
@@ -55,14 +67,37 @@ type CLAOrgListOption struct {
 	OrgID    string `json:"org_id"`
 	RepoID   string `json:"repo_id"`
 	ApplyTo  string `json:"apply_to"`
+
+	// OrgIDs restricts the orgs of bindings if it is not nil
+	OrgIDs []string            `json:"-"`
+	Page   dbmodels.PageOption `json:"-"`
+}
+
+func (this CLAOrgListOption) Validate() error {
+	return this.Page.Validate(dbmodels.CLAOrgSortFields)
 }
 
-func (this CLAOrgListOption) List() ([]dbmodels.CLAOrg, error) {
+func (this CLAOrgListOption) List() (dbmodels.CLAOrgPage, error) {
 	p := dbmodels.CLAOrgListOption{}
 	if err := copyBetweenStructs(&this, &p); err != nil {
-		return nil, err
+		return dbmodels.CLAOrgPage{}, err
 	}
 	p.RepoID = this.RepoID
+	p.OrgIDs = this.OrgIDs
+	p.Page = this.Page
 
 	return dbmodels.GetDB().ListBindingBetweenCLAAndOrg(p)
 }
+
+// BotToken is the token used by bot to access the code platform on behalf of the binding
+type BotToken struct {
+	Token string `json:"token"`
+}
+
+func (this BotToken) Set(claOrgID string) error {
+	return dbmodels.GetDB().SetBotToken(claOrgID, this.Token)
+}
+
+func GetBotToken(platform, orgID, repoID string) (string, error) {
+	return dbmodels.GetDB().GetBotToken(platform, orgID, repoID)
+}
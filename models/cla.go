@@ -1,6 +1,11 @@
 package models
 
-import "github.com/zengchen1024/cla-server/dbmodels"
+import (
+	"fmt"
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
 
 const (
 	ApplyToCorporation = "corporation"
@@ -15,6 +20,17 @@ type CLA struct {
 	Submitter string  `json:"submitter"`
 	ApplyTo   string  `json:"apply_to"`
 	Fields    []Field `json:"fields"`
+
+	// Version is the latest version of cla, and Text is the text of it
+	Version  int          `json:"version"`
+	Versions []CLAVersion `json:"versions"`
+}
+
+type CLAVersion struct {
+	Version       int    `json:"version"`
+	Text          string `json:"text"`
+	RequireResign bool   `json:"require_resign"`
+	PublishedAt   int64  `json:"published_at"`
 }
 
 type Field struct {
@@ -25,7 +41,17 @@ type Field struct {
 	Required    bool   `json:"required"`
 }
 
+// Validate checks the definitions of fields to be filled when signing
+func (this *CLA) Validate() error {
+	return validateFields(this.Fields)
+}
+
 func (this *CLA) Create() error {
+	this.Version = 1
+	this.Versions = []CLAVersion{
+		{Version: 1, Text: this.Text, PublishedAt: time.Now().Unix()},
+	}
+
 	p := dbmodels.CLA{}
 	if err := copyBetweenStructs(this, &p); err != nil {
 		return err
@@ -46,6 +72,54 @@ func (this *CLA) Get() error {
 	return err
 }
 
+// PublishVersion publishes the text as the next version of cla and returns the version.
+func (this *CLA) PublishVersion(text string, requireResign bool) (int, error) {
+	if err := this.Get(); err != nil {
+		return 0, err
+	}
+
+	v := dbmodels.CLAVersion{
+		Version:       this.Version + 1,
+		Text:          text,
+		RequireResign: requireResign,
+		PublishedAt:   time.Now().Unix(),
+	}
+	if err := dbmodels.GetDB().AddCLAVersion(this.ID, v); err != nil {
+		return 0, err
+	}
+	return v.Version, nil
+}
+
+// TextOfVersion returns the text of the version.
+func (this *CLA) TextOfVersion(version int) (string, error) {
+	if version == 0 || version == this.Version {
+		return this.Text, nil
+	}
+
+	for _, v := range this.Versions {
+		if v.Version == version {
+			return v.Text, nil
+		}
+	}
+	return "", fmt.Errorf("the cla(%s) has no version %d", this.ID, version)
+}
+
+// NeedResign checks whether the contributor who signed the version signed must
+// sign again for the version current, because one of the versions published
+// after the signed one requires it. The zero version is treated as version 1.
+func (this *CLA) NeedResign(signed, current int) bool {
+	if signed == 0 {
+		signed = 1
+	}
+
+	for _, v := range this.Versions {
+		if v.Version > signed && v.Version <= current && v.RequireResign {
+			return true
+		}
+	}
+	return false
+}
+
 func (this *CLA) Delete() error {
 	return dbmodels.GetDB().DeleteCLA(this.ID)
 }
@@ -55,16 +129,67 @@ type CLAListOptions struct {
 	Name      string `json:"name"`
 	Language  string `json:"language"`
 	ApplyTo   string `json:"apply_to"`
+
+	Page dbmodels.PageOption `json:"-"`
 }
 
-func (this CLAListOptions) Get() ([]dbmodels.CLA, error) {
+func (this CLAListOptions) Validate() error {
+	return this.Page.Validate(dbmodels.CLASortFields)
+}
+
+func (this CLAListOptions) Get() (dbmodels.CLAPage, error) {
 	p := dbmodels.CLAListOptions{}
 	if err := copyBetweenStructs(&this, &p); err != nil {
-		return nil, err
+		return dbmodels.CLAPage{}, err
 	}
+	p.Page = this.Page
+
 	return dbmodels.GetDB().ListCLA(p)
 }
 
 func ListCLAByIDs(ids []string) ([]dbmodels.CLA, error) {
 	return dbmodels.GetDB().ListCLAByIDs(ids)
 }
+
+// ListCLAOfBindings returns the cla of each binding with the text of version
+// which the binding points to. The key is the id of binding.
+func ListCLAOfBindings(bindings []dbmodels.CLAOrg) (map[string]CLA, error) {
+	ids := make([]string, 0, len(bindings))
+	for _, item := range bindings {
+		ids = append(ids, item.CLAID)
+	}
+
+	v, err := dbmodels.GetDB().ListCLAByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	clas := make(map[string]*CLA, len(v))
+	for i := range v {
+		cla := &CLA{}
+		if err := copyBetweenStructs(&v[i], cla); err != nil {
+			return nil, err
+		}
+		clas[cla.ID] = cla
+	}
+
+	r := make(map[string]CLA, len(bindings))
+	for _, item := range bindings {
+		cla, ok := clas[item.CLAID]
+		if !ok {
+			continue
+		}
+
+		text, err := cla.TextOfVersion(item.CLAVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		c := *cla
+		c.Text = text
+		c.Version = item.CLAVersion
+		c.Versions = nil
+		r[item.ID] = c
+	}
+	return r, nil
+}
@@ -0,0 +1,196 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+const (
+	// domainVerificationRecordPrefix is prepended to the domain as the name of TXT record
+	domainVerificationRecordPrefix = "_cla-verification."
+	// domainVerificationValuePrefix is prepended to the token as the value of TXT record
+	domainVerificationValuePrefix = "cla-verification="
+
+	dnsLookupTimeout = 10 * time.Second
+)
+
+// DNSResolver looks up the TXT records of name. It can be faked in tests.
+type DNSResolver interface {
+	LookupTXT(name string) ([]string, error)
+}
+
+type netResolver struct{}
+
+func (this netResolver) LookupTXT(name string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsLookupTimeout)
+	defer cancel()
+
+	return net.DefaultResolver.LookupTXT(ctx, name)
+}
+
+// DefaultDNSResolver returns the resolver which looks up the real DNS
+func DefaultDNSResolver() DNSResolver {
+	return netResolver{}
+}
+
+// CorporationDomain is the domain claimed by corporation. The owner of domain
+// proves it by adding a TXT record of RecordName whose value is RecordValue.
+type CorporationDomain struct {
+	Domain      string `json:"domain"`
+	Verified    bool   `json:"verified"`
+	VerifiedAt  int64  `json:"verified_at,omitempty"`
+	RecordName  string `json:"record_name"`
+	RecordValue string `json:"record_value"`
+}
+
+func newCorporationDomain(d dbmodels.CorporationDomain) CorporationDomain {
+	return CorporationDomain{
+		Domain:      d.Domain,
+		Verified:    d.Verified,
+		VerifiedAt:  d.VerifiedAt,
+		RecordName:  domainVerificationRecordPrefix + d.Domain,
+		RecordValue: domainVerificationValuePrefix + d.Token,
+	}
+}
+
+// CorporationDomainOption is the domain which the corporation administrator operates on
+type CorporationDomainOption struct {
+	CLAOrgID string `json:"cla_org_id"`
+	Domain   string `json:"domain"`
+}
+
+func (this *CorporationDomainOption) Validate() error {
+	this.Domain = normalizeDomain(this.Domain)
+
+	if this.CLAOrgID == "" || this.Domain == "" {
+		return fmt.Errorf("missing cla_org_id or domain")
+	}
+
+	if !strings.Contains(this.Domain, ".") || strings.ContainsAny(this.Domain, "@/ ") {
+		return fmt.Errorf("invalid domain: %s", this.Domain)
+	}
+
+	if IsPublicEmailDomain(this.Domain) {
+		return fmt.Errorf("the domain of public email provider(%s) can't be claimed", this.Domain)
+	}
+	return nil
+}
+
+// Add claims the domain for the corporation of administrator and returns how to verify it.
+func (this CorporationDomainOption) Add(adminEmail string) (CorporationDomain, error) {
+	token, err := genToken()
+	if err != nil {
+		return CorporationDomain{}, err
+	}
+
+	d := dbmodels.CorporationDomain{
+		Domain:    this.Domain,
+		Token:     token,
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := dbmodels.GetDB().AddCorporationDomain(this.CLAOrgID, adminEmail, d); err != nil {
+		return CorporationDomain{}, err
+	}
+	return newCorporationDomain(d), nil
+}
+
+// Verify checks the TXT record of domain and marks it verified if the record is correct.
+func (this CorporationDomainOption) Verify(adminEmail string, r DNSResolver) error {
+	v, err := dbmodels.GetDB().ListCorporationDomain(this.CLAOrgID, emailSuffixToKey(adminEmail))
+	if err != nil {
+		return err
+	}
+
+	for _, item := range v {
+		if item.Domain != this.Domain {
+			continue
+		}
+
+		if item.Verified {
+			return nil
+		}
+
+		if err := checkTXTRecord(r, item.Domain, item.Token); err != nil {
+			return err
+		}
+
+		return dbmodels.GetDB().VerifyCorporationDomain(
+			this.CLAOrgID, adminEmail, item.Domain, time.Now().Unix(),
+		)
+	}
+
+	return fmt.Errorf("the domain(%s) has not been added", this.Domain)
+}
+
+// checkTXTRecord checks whether the domain has the TXT record of token
+func checkTXTRecord(r DNSResolver, domain, token string) error {
+	name := domainVerificationRecordPrefix + domain
+
+	v, err := r.LookupTXT(name)
+	if err != nil {
+		return fmt.Errorf("Failed to look up the TXT record of %s: %s", name, err.Error())
+	}
+
+	expect := domainVerificationValuePrefix + token
+	for _, item := range v {
+		if strings.TrimSpace(item) == expect {
+			return nil
+		}
+	}
+	return fmt.Errorf("the TXT record of %s is not found or not correct", name)
+}
+
+// ListCorporationDomain returns the domains claimed by the corporation
+func ListCorporationDomain(claOrgID, corporationEmail string) ([]CorporationDomain, error) {
+	v, err := dbmodels.GetDB().ListCorporationDomain(claOrgID, emailSuffixToKey(corporationEmail))
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]CorporationDomain, 0, len(v))
+	for _, item := range v {
+		r = append(r, newCorporationDomain(item))
+	}
+	return r, nil
+}
+
+// isCorporationDomainVerified checks whether the domain of corporation's email has been verified
+func isCorporationDomainVerified(claOrgID, corporationEmail string) (bool, error) {
+	v, err := dbmodels.GetDB().ListCorporationDomain(claOrgID, emailSuffixToKey(corporationEmail))
+	if err != nil {
+		return false, err
+	}
+
+	d := emailDomain(corporationEmail)
+	for _, item := range v {
+		if item.Domain == d {
+			return item.Verified, nil
+		}
+	}
+	return false, nil
+}
+
+// corporationIDOfEmail returns the corporation which the email belongs to in the
+// binding. It is the corporation which has verified the domain of email as an
+// additional domain, otherwise the one identified by the domain.
+func corporationIDOfEmail(claOrgID, email string) (string, error) {
+	binding, err := dbmodels.GetDB().GetBindingBetweenCLAAndOrg(claOrgID)
+	if err != nil {
+		return "", err
+	}
+
+	return corporationIDOfEmailInRepo(binding.Platform, binding.OrgID, binding.RepoID, email)
+}
+
+func corporationIDOfEmailInRepo(platform, orgID, repoID, email string) (string, error) {
+	v, err := dbmodels.GetDB().GetCorporationIDByDomain(platform, orgID, repoID, emailDomain(email))
+	if err != nil || v != "" {
+		return v, err
+	}
+	return emailSuffixToKey(email), nil
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+)
+
+type fakeResolver map[string][]string
+
+func (this fakeResolver) LookupTXT(name string) ([]string, error) {
+	v, ok := this[name]
+	if !ok {
+		return nil, fmt.Errorf("no such host")
+	}
+	return v, nil
+}
+
+func TestCheckTXTRecord(t *testing.T) {
+	r := fakeResolver{
+		"_cla-verification.example.com": {"v=spf1 -all", "cla-verification=abc"},
+		"_cla-verification.example.org": {"cla-verification=xyz"},
+	}
+
+	cases := []struct {
+		domain string
+		token  string
+		ok     bool
+	}{
+		{"example.com", "abc", true},
+		{"example.org", "abc", false},
+		{"example.net", "abc", false},
+	}
+	for _, c := range cases {
+		err := checkTXTRecord(r, c.domain, c.token)
+		if (err == nil) != c.ok {
+			t.Errorf("check %s: expect ok=%v, but got: %v", c.domain, c.ok, err)
+		}
+	}
+}
+
+func TestCorporationEmail(t *testing.T) {
+	defer SetPublicEmailDomains(defaultPublicEmailDomains)
+
+	if err := checkCorporationEmail("a@Gmail.com"); err == nil {
+		t.Error("expect error for public email domain")
+	}
+	if err := checkCorporationEmail("a@example.com"); err != nil {
+		t.Errorf("expect no error, but got: %v", err)
+	}
+
+	SetPublicEmailDomains([]string{"example.com"})
+	if err := checkCorporationEmail("a@example.com"); err == nil {
+		t.Error("expect error for configured public email domain")
+	}
+	if err := checkCorporationEmail("a@gmail.com"); err != nil {
+		t.Errorf("expect no error, but got: %v", err)
+	}
+}
@@ -1,13 +1,19 @@
 package models
 
 import (
+	"fmt"
+
 	"github.com/zengchen1024/cla-server/dbmodels"
-	"github.com/zengchen1024/cla-server/util"
 )
 
 const (
 	RoleAdmin   = "admin"
 	RoleManager = "manager"
+
+	// oldDefaultPassword is the password set for every manager by the old version
+	oldDefaultPassword = "123456"
+
+	ActionPasswordRetrieval = "password-retrieval"
 )
 
 type CorporationManagerCreateOption struct {
@@ -15,17 +21,40 @@ type CorporationManagerCreateOption struct {
 	Email    string `json:"email"`
 }
 
-func (this *CorporationManagerCreateOption) Create() error {
-	pw := "123456"
+// Create adds the corporation administrator with a random password which is returned.
+func (this *CorporationManagerCreateOption) Create() (string, error) {
+	pw, hash, err := genInitialPassword()
+	if err != nil {
+		return "", err
+	}
+
 	opt := []dbmodels.CorporationManagerCreateOption{
 		{
-			Role:          RoleAdmin,
-			Email:         this.Email,
-			Password:      pw,
-			CorporationID: emailSuffixToKey(this.Email),
+			Role:               RoleAdmin,
+			Email:              this.Email,
+			Password:           hash,
+			CorporationID:      emailSuffixToKey(this.Email),
+			MustChangePassword: true,
 		},
 	}
-	return dbmodels.GetDB().AddCorporationManager(this.CLAOrgID, opt, 1)
+	if err := dbmodels.GetDB().AddCorporationManager(this.CLAOrgID, opt, 1); err != nil {
+		return "", err
+	}
+	return pw, nil
+}
+
+// genInitialPassword returns the random password and its hash
+func genInitialPassword() (string, string, error) {
+	pw, err := genPassword()
+	if err != nil {
+		return "", "", err
+	}
+
+	hash, err := hashPassword(pw)
+	if err != nil {
+		return "", "", err
+	}
+	return pw, hash, nil
 }
 
 type CorporationManagerAuthentication struct {
@@ -34,12 +63,20 @@ type CorporationManagerAuthentication struct {
 }
 
 func (this CorporationManagerAuthentication) Authenticate() ([]dbmodels.CorporationManagerCheckResult, error) {
-	opt := dbmodels.CorporationManagerCheckInfo{
-		User:     this.User,
-		Password: this.Password,
+	opt := dbmodels.CorporationManagerCheckInfo{User: this.User}
+
+	v, err := dbmodels.GetDB().CheckCorporationManagerExist(opt)
+	if err != nil {
+		return nil, err
 	}
 
-	return dbmodels.GetDB().CheckCorporationManagerExist(opt)
+	r := make([]dbmodels.CorporationManagerCheckResult, 0, len(v))
+	for _, item := range v {
+		if isPasswordCorrect(item.Password, this.Password) {
+			r = append(r, item)
+		}
+	}
+	return r, nil
 }
 
 type CorporationManagerResetPassword struct {
@@ -49,30 +86,198 @@ type CorporationManagerResetPassword struct {
 	NewPassword string `json:"new_password"`
 }
 
+func (this CorporationManagerResetPassword) Validate() error {
+	if err := validateNewPassword(this.NewPassword); err != nil {
+		return err
+	}
+
+	if this.NewPassword == this.OldPassword {
+		return fmt.Errorf("the new password is same as the old one")
+	}
+	return nil
+}
+
 func (this CorporationManagerResetPassword) Reset() error {
+	oldHash, err := getCorporationManagerPassword(this.CLAOrgID, this.Email)
+	if err != nil {
+		return err
+	}
+	if oldHash == "" || !isPasswordCorrect(oldHash, this.OldPassword) {
+		return fmt.Errorf("Failed to reset password for corporation manager: user name or old password is not correct.")
+	}
+
+	return setCorporationManagerPassword(this.CLAOrgID, this.Email, oldHash, this.NewPassword)
+}
+
+// getCorporationManagerPassword returns the hashed password of manager.
+// It returns empty string if the manager doesn't exist.
+func getCorporationManagerPassword(claOrgID, email string) (string, error) {
+	v, err := dbmodels.GetDB().CheckCorporationManagerExist(
+		dbmodels.CorporationManagerCheckInfo{User: email},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	for _, item := range v {
+		if item.CLAOrgID == claOrgID && item.Email == email {
+			return item.Password, nil
+		}
+	}
+	return "", nil
+}
+
+// setCorporationManagerPassword sets the new password of manager and revokes
+// all the sessions started with the old password.
+func setCorporationManagerPassword(claOrgID, email, oldHash, newPassword string) error {
+	hash, err := hashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
 	opt := dbmodels.CorporationManagerResetPassword{
-		Email:       this.Email,
-		OldPassword: this.OldPassword,
-		NewPassword: this.NewPassword,
+		Email:       email,
+		OldPassword: oldHash,
+		NewPassword: hash,
+	}
+	if err := dbmodels.GetDB().ResetCorporationManagerPassword(claOrgID, opt); err != nil {
+		return err
+	}
+
+	if err := RevokeSessionsOfUser(email); err != nil {
+		return fmt.Errorf("Failed to revoke the sessions of manager(%s): %s", email, err.Error())
+	}
+	return nil
+}
+
+func validateNewPassword(pw string) error {
+	if len(pw) < minPasswordLength {
+		return fmt.Errorf("the length of new password should be at least %d", minPasswordLength)
+	}
+	return nil
+}
+
+func passwordRetrievalPurpose(claOrgID string) string {
+	return fmt.Sprintf("%s:%s", ActionPasswordRetrieval, claOrgID)
+}
+
+// CorporationManagerPasswordRetrieval is the request of manager who forgets the password
+type CorporationManagerPasswordRetrieval struct {
+	CLAOrgID string `json:"cla_org_id"`
+	Email    string `json:"email"`
+}
+
+func (this CorporationManagerPasswordRetrieval) CheckFrequency(ip string, interval int64) error {
+	return checkVerificationCodeFrequency(this.Email, passwordRetrievalPurpose(this.CLAOrgID), ip, interval)
+}
+
+// Create generates the one-time token to reset password. It returns empty
+// token if the manager doesn't exist.
+func (this CorporationManagerPasswordRetrieval) Create(ip string, expiry int64) (string, error) {
+	hash, err := getCorporationManagerPassword(this.CLAOrgID, this.Email)
+	if err != nil || hash == "" {
+		return "", err
+	}
+
+	token, err := genToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = saveVerificationCode(this.Email, passwordRetrievalPurpose(this.CLAOrgID), token, ip, expiry)
+	return token, err
+}
+
+// CorporationManagerPasswordRetrievalReset resets the password with the one-time token
+type CorporationManagerPasswordRetrievalReset struct {
+	CLAOrgID    string `json:"cla_org_id"`
+	Email       string `json:"email"`
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+func (this CorporationManagerPasswordRetrievalReset) Validate(maxAttempts int) error {
+	if err := validateNewPassword(this.NewPassword); err != nil {
+		return err
+	}
+
+	return validateVerificationCode(this.Email, passwordRetrievalPurpose(this.CLAOrgID), this.Token, maxAttempts)
+}
+
+func (this CorporationManagerPasswordRetrievalReset) Reset() error {
+	oldHash, err := getCorporationManagerPassword(this.CLAOrgID, this.Email)
+	if err != nil {
+		return err
+	}
+	if oldHash == "" {
+		return fmt.Errorf("Failed to reset password for corporation manager: the manager doesn't exist")
 	}
 
-	return dbmodels.GetDB().ResetCorporationManagerPassword(this.CLAOrgID, opt)
+	return setCorporationManagerPassword(this.CLAOrgID, this.Email, oldHash, this.NewPassword)
+}
+
+// MigrateCorporationManagerPassword hashes the plaintext passwords saved by the old version.
+// The manager who is still using the default password has to change it at next login.
+func MigrateCorporationManagerPassword() error {
+	v, err := dbmodels.GetDB().ListAllCorporationManagers()
+	if err != nil {
+		return err
+	}
+
+	for _, item := range v {
+		if isPasswordHashed(item.Password) {
+			continue
+		}
+
+		hash, err := hashPassword(item.Password)
+		if err != nil {
+			return err
+		}
+
+		opt := dbmodels.CorporationManagerResetPassword{
+			Email:              item.Email,
+			OldPassword:        item.Password,
+			NewPassword:        hash,
+			MustChangePassword: item.Password == oldDefaultPassword,
+		}
+		if err := dbmodels.GetDB().ResetCorporationManagerPassword(item.CLAOrgID, opt); err != nil {
+			return fmt.Errorf("Failed to migrate password of corporation manager(%s): %s", item.Email, err.Error())
+		}
+	}
+	return nil
 }
 
 type CorporationManagerListOption struct {
 	CLAOrgID string `json:"cla_org_id"`
 	Role     string `json:"role"`
 	Email    string `json:"email"`
+
+	Page dbmodels.PageOption `json:"-"`
 }
 
-func (this CorporationManagerListOption) List() ([]dbmodels.CorporationManagerListResult, error) {
+func (this CorporationManagerListOption) Validate() error {
+	return this.Page.Validate(dbmodels.CorporationManagerSortFields)
+}
+
+func (this CorporationManagerListOption) List() (dbmodels.CorporationManagerPage, error) {
 	opt := dbmodels.CorporationManagerListOption{
 		Role:          this.Role,
 		CorporationID: emailSuffixToKey(this.Email),
+		Page:          this.Page,
 	}
 	return dbmodels.GetDB().ListCorporationManager(this.CLAOrgID, opt)
 }
 
+// ListManagersWhenEmployeeSigning lists the managers of the employee's corporation.
+// All the bindings must be of the same org/repo.
 func ListManagersWhenEmployeeSigning(claOrgIDs []string, employeeEmail string) ([]dbmodels.CorporationManagerListResult, error) {
-	return dbmodels.GetDB().ListManagersWhenEmployeeSigning(claOrgIDs, util.EmailSuffixToKey(employeeEmail))
+	if len(claOrgIDs) == 0 {
+		return nil, nil
+	}
+
+	corporationID, err := corporationIDOfEmail(claOrgIDs[0], employeeEmail)
+	if err != nil {
+		return nil, err
+	}
+	return dbmodels.GetDB().ListManagersWhenEmployeeSigning(claOrgIDs, corporationID)
 }
@@ -22,6 +22,11 @@ type CorporationSigning struct {
 	Enabled         bool   `json:"enabled"`
 
 	Info dbmodels.TypeSigningInfo `json:"info"`
+
+	// the fields below are recorded when signing and can't be set by request
+	SignedAt   int64                     `json:"signed_at,omitempty"`
+	CLAVersion int                       `json:"cla_version,omitempty"`
+	Metadata   *dbmodels.SigningMetadata `json:"metadata,omitempty"`
 }
 
 type CorporationSigningCreateOption struct {
@@ -30,24 +35,32 @@ type CorporationSigningCreateOption struct {
 	VerifiCode string `json:"verifi_code"`
 }
 
-func (this *CorporationSigningCreateOption) Validate() error {
-	vc := dbmodels.VerificationCode{
-		Email:   this.AdminEmail,
-		Code:    this.VerifiCode,
-		Purpose: ActionCorporationSigning,
+// Validate checks the info against the fields of cla before the verification code,
+// so that the attempt of code will not be counted if the info is invalid.
+func (this *CorporationSigningCreateOption) Validate(maxAttempts int) error {
+	if err := checkCorporationEmail(this.AdminEmail); err != nil {
+		return err
 	}
 
-	v, err := dbmodels.GetDB().CheckVerificationCode(vc)
-	if err != nil {
+	if err := validateSigningInfoOfBinding(this.CLAOrgID, this.Info); err != nil {
 		return err
 	}
-	if !v {
-		return fmt.Errorf("Verification Code is expired or wrong")
-	}
-	return nil
+
+	return validateVerificationCode(this.AdminEmail, ActionCorporationSigning, this.VerifiCode, maxAttempts)
 }
 
-func (this *CorporationSigningCreateOption) Create() error {
+// Create signs as corporation. The time and metadata of signing are also
+// set to the CorporationSigning, which will be embedded in the pdf.
+func (this *CorporationSigningCreateOption) Create(meta SigningMetadata) error {
+	version, hash, err := claOfBinding(this.CLAOrgID)
+	if err != nil {
+		return err
+	}
+
+	this.SignedAt = time.Now().Unix()
+	this.CLAVersion = version
+	this.Metadata = meta.toDB(hash)
+
 	p := dbmodels.CorporationSigningInfo{
 		AdminEmail:      this.AdminEmail,
 		AdminName:       this.AdminName,
@@ -55,6 +68,9 @@ func (this *CorporationSigningCreateOption) Create() error {
 		CorporationID:   emailSuffixToKey(this.AdminEmail),
 		Enabled:         false,
 		Info:            this.Info,
+		CLAVersion:      version,
+		SignedAt:        this.SignedAt,
+		Metadata:        this.Metadata,
 	}
 	return dbmodels.GetDB().SignAsCorporation(this.CLAOrgID, p)
 }
@@ -66,6 +82,31 @@ type CorporationSigningUdateInfo struct {
 	Enabled         bool   `json:"enabled"`
 }
 
+// CheckEnabling checks whether the corporation can be enabled. The domain of
+// corporation must have been verified if the binding requires it.
+func (this *CorporationSigningUdateInfo) CheckEnabling() error {
+	if !this.Enabled {
+		return nil
+	}
+
+	binding, err := dbmodels.GetDB().GetBindingBetweenCLAAndOrg(this.CLAOrgID)
+	if err != nil {
+		return err
+	}
+	if !binding.DomainVerificationRequired {
+		return nil
+	}
+
+	b, err := isCorporationDomainVerified(this.CLAOrgID, this.AdminEmail)
+	if err != nil {
+		return err
+	}
+	if !b {
+		return fmt.Errorf("the domain of corporation must be verified before being enabled")
+	}
+	return nil
+}
+
 func (this *CorporationSigningUdateInfo) Update() error {
 	return dbmodels.GetDB().UpdateCorporationSigning(
 		this.CLAOrgID, this.AdminEmail, this.CorporationName,
@@ -77,41 +118,50 @@ type CorporationSigningListOption struct {
 	OrgID       string `json:"org_id"`
 	RepoID      string `json:"repo_id"`
 	CLALanguage string `json:"cla_language"`
+
+	Page dbmodels.PageOption `json:"-"`
+}
+
+type CorporationSigningPage struct {
+	dbmodels.PageInfo
+
+	Signings []CorporationSigningDetails
 }
 
-func (this CorporationSigningListOption) List() ([]CorporationSigningDetails, error) {
+func (this CorporationSigningListOption) Validate() error {
+	return this.Page.Validate(dbmodels.CorporationSigningSortFields)
+}
+
+func (this CorporationSigningListOption) List() (CorporationSigningPage, error) {
 	opt := dbmodels.CorporationSigningListOption{
 		Platform:    this.Platform,
 		OrgID:       this.OrgID,
 		RepoID:      this.RepoID,
 		CLALanguage: this.CLALanguage,
+		Page:        this.Page,
 	}
 	v, err := dbmodels.GetDB().ListCorporationSigning(opt)
 	if err != nil {
-		return nil, err
+		return CorporationSigningPage{}, err
 	}
 
-	n := 0
-	for _, items := range v {
-		n += len(items)
+	r := make([]CorporationSigningDetails, 0, len(v.Signings))
+	for _, item := range v.Signings {
+		r = append(r, CorporationSigningDetails{
+			CorporationSigning: CorporationSigning{
+				CLAOrgID:        item.CLAOrgID,
+				AdminEmail:      item.AdminEmail,
+				AdminName:       item.AdminName,
+				CorporationName: item.CorporationName,
+				Enabled:         item.Enabled,
+				SignedAt:        item.SignedAt,
+				CLAVersion:      item.CLAVersion,
+				Metadata:        item.Metadata,
+			},
+			AdministratorEnabled: item.AdministratorEnabled,
+		})
 	}
-
-	r := make([]CorporationSigningDetails, 0, n)
-	for k, items := range v {
-		for _, item := range items {
-			r = append(r, CorporationSigningDetails{
-				CorporationSigning: CorporationSigning{
-					CLAOrgID:        k,
-					AdminEmail:      item.AdminEmail,
-					AdminName:       item.AdminName,
-					CorporationName: item.CorporationName,
-					Enabled:         item.Enabled,
-				},
-				AdministratorEnabled: item.AdministratorEnabled,
-			})
-		}
-	}
-	return r, nil
+	return CorporationSigningPage{PageInfo: v.PageInfo, Signings: r}, nil
 }
 
 type CorporationSigningVerifCode struct {
@@ -121,16 +171,14 @@ type CorporationSigningVerifCode struct {
 	Email string `json:"email"`
 }
 
-func (this CorporationSigningVerifCode) Create(expiry int64) (string, error) {
-	code := "123456"
+func (this CorporationSigningVerifCode) Validate() error {
+	return checkCorporationEmail(this.Email)
+}
 
-	vc := dbmodels.VerificationCode{
-		Email:   this.Email,
-		Code:    code,
-		Purpose: ActionCorporationSigning,
-		Expiry:  time.Now().Unix() + expiry,
-	}
+func (this CorporationSigningVerifCode) CheckFrequency(ip string, interval int64) error {
+	return checkVerificationCodeFrequency(this.Email, ActionCorporationSigning, ip, interval)
+}
 
-	err := dbmodels.GetDB().CreateVerificationCode(vc)
-	return code, err
+func (this CorporationSigningVerifCode) Create(ip string, expiry int64) (string, error) {
+	return createVerificationCode(this.Email, ActionCorporationSigning, ip, expiry)
 }
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultPublicEmailDomains is the domains of public email providers,
+// which can't be used to identify a corporation.
+var defaultPublicEmailDomains = []string{
+	"gmail.com", "googlemail.com", "outlook.com", "hotmail.com", "live.com",
+	"msn.com", "yahoo.com", "ymail.com", "aol.com", "icloud.com", "me.com",
+	"mail.com", "gmx.com", "gmx.net", "protonmail.com", "proton.me",
+	"zoho.com", "yandex.com", "yandex.ru", "qq.com", "foxmail.com",
+	"163.com", "126.com", "yeah.net", "sina.com", "sohu.com", "aliyun.com",
+	"139.com",
+}
+
+var publicEmailDomains = toDomainSet(defaultPublicEmailDomains)
+
+// SetPublicEmailDomains replaces the default list of public email domains
+func SetPublicEmailDomains(domains []string) {
+	publicEmailDomains = toDomainSet(domains)
+}
+
+func toDomainSet(domains []string) map[string]bool {
+	m := make(map[string]bool, len(domains))
+	for _, item := range domains {
+		if d := normalizeDomain(item); d != "" {
+			m[d] = true
+		}
+	}
+	return m
+}
+
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+func emailDomain(email string) string {
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return ""
+	}
+	return normalizeDomain(email[i+1:])
+}
+
+// IsPublicEmailDomain checks whether the domain belongs to a public email provider
+func IsPublicEmailDomain(domain string) bool {
+	return publicEmailDomains[normalizeDomain(domain)]
+}
+
+// checkCorporationEmail checks whether the email can identify a corporation
+func checkCorporationEmail(email string) error {
+	d := emailDomain(email)
+	if d == "" {
+		return fmt.Errorf("invalid email: %s", email)
+	}
+
+	if IsPublicEmailDomain(d) {
+		return fmt.Errorf("the email of public email provider(%s) can't be used for corporation", d)
+	}
+	return nil
+}
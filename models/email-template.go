@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+const (
+	EmailTemplateVerificationCode   = "verification-code"
+	EmailTemplateCorporationSigning = "corporation-signing"
+	EmailTemplateManagerAccount     = "manager-account"
+	EmailTemplateEmployeeEnabled    = "employee-enabled"
+	EmailTemplatePasswordRetrieval  = "password-retrieval"
+	EmailTemplateSigningWithdrawal  = "signing-withdrawal"
+	EmailTemplateSigningRevoked     = "signing-revoked"
+)
+
+var EmailTemplateKinds = []string{
+	EmailTemplateVerificationCode,
+	EmailTemplateCorporationSigning,
+	EmailTemplateManagerAccount,
+	EmailTemplateEmployeeEnabled,
+	EmailTemplatePasswordRetrieval,
+	EmailTemplateSigningWithdrawal,
+	EmailTemplateSigningRevoked,
+}
+
+func IsValidEmailTemplateKind(kind string) bool {
+	for _, item := range EmailTemplateKinds {
+		if item == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// EmailTemplate is the template of notification email. The subject and text
+// are rendered by text/template and the html is rendered by html/template.
+type EmailTemplate struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html,omitempty"`
+}
+
+func (this EmailTemplate) Set(claOrgID, kind string) error {
+	if !IsValidEmailTemplateKind(kind) {
+		return fmt.Errorf("unknown kind of email template: %s", kind)
+	}
+
+	opt := dbmodels.EmailTemplate{
+		Subject: this.Subject,
+		Text:    this.Text,
+		HTML:    this.HTML,
+	}
+	return dbmodels.GetDB().SetEmailTemplate(claOrgID, kind, opt)
+}
+
+func DeleteEmailTemplate(claOrgID, kind string) error {
+	return dbmodels.GetDB().DeleteEmailTemplate(claOrgID, kind)
+}
+
+func ListEmailTemplate(claOrgID string) (map[string]EmailTemplate, error) {
+	v, err := dbmodels.GetDB().ListEmailTemplate(claOrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make(map[string]EmailTemplate, len(v))
+	for k, item := range v {
+		r[k] = EmailTemplate{
+			Subject: item.Subject,
+			Text:    item.Text,
+			HTML:    item.HTML,
+		}
+	}
+	return r, nil
+}
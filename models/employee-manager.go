@@ -35,21 +35,31 @@ func (this *EmployeeManagerCreateOption) Validate() error {
 	return nil
 }
 
-func (this *EmployeeManagerCreateOption) Create() error {
-	pw := "123456"
-
+// Create adds the employee managers with random passwords and returns them, key is the email.
+func (this *EmployeeManagerCreateOption) Create() (map[string]string, error) {
+	pws := make(map[string]string, len(this.Emails))
 	opt := make([]dbmodels.CorporationManagerCreateOption, 0, len(this.Emails))
 
 	for _, item := range this.Emails {
+		pw, hash, err := genInitialPassword()
+		if err != nil {
+			return nil, err
+		}
+		pws[item] = pw
+
 		opt = append(opt, dbmodels.CorporationManagerCreateOption{
-			Role:          RoleManager,
-			Email:         item,
-			Password:      pw,
-			CorporationID: emailSuffixToKey(this.Emails[0]),
+			Role:               RoleManager,
+			Email:              item,
+			Password:           hash,
+			CorporationID:      emailSuffixToKey(this.Emails[0]),
+			MustChangePassword: true,
 		})
 	}
 
-	return dbmodels.GetDB().AddCorporationManager(this.CLAOrgID, opt, 5)
+	if err := dbmodels.GetDB().AddCorporationManager(this.CLAOrgID, opt, 5); err != nil {
+		return nil, err
+	}
+	return pws, nil
 }
 
 func (this *EmployeeManagerCreateOption) Delete() error {
@@ -62,5 +72,15 @@ func (this *EmployeeManagerCreateOption) Delete() error {
 		})
 	}
 
-	return dbmodels.GetDB().DeleteCorporationManager(this.CLAOrgID, opt)
+	if err := dbmodels.GetDB().DeleteCorporationManager(this.CLAOrgID, opt); err != nil {
+		return err
+	}
+
+	// the deleted managers should not be able to access any more
+	for _, item := range this.Emails {
+		if err := RevokeSessionsOfUser(item); err != nil {
+			return fmt.Errorf("Failed to revoke the sessions of manager(%s): %s", item, err.Error())
+		}
+	}
+	return nil
 }
@@ -1,6 +1,10 @@
 package models
 
-import "github.com/zengchen1024/cla-server/dbmodels"
+import (
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
 
 type EmployeeSigning struct {
 	CLAOrgID string                   `json:"cla_org_id"`
@@ -8,14 +12,41 @@ type EmployeeSigning struct {
 	Name     string                   `json:"name"`
 	Enabled  bool                     `json:"enabled"`
 	Info     dbmodels.TypeSigningInfo `json:"info,omitempty"`
+
+	// the fields below are recorded when signing and can't be set by request
+	SignedAt   int64                     `json:"signed_at,omitempty"`
+	CLAVersion int                       `json:"cla_version,omitempty"`
+	Metadata   *dbmodels.SigningMetadata `json:"metadata,omitempty"`
+}
+
+// Validate checks the info against the fields of cla. It returns FieldErrors if some fields are invalid.
+func (this *EmployeeSigning) Validate() error {
+	return validateSigningInfoOfBinding(this.CLAOrgID, this.Info)
 }
 
-func (this *EmployeeSigning) Create() error {
+// Create signs as employee. If the employee has signed an older version
+// of cla, the signing will be updated and keep the enabled status.
+func (this *EmployeeSigning) Create(meta SigningMetadata) error {
+	version, hash, err := claOfBinding(this.CLAOrgID)
+	if err != nil {
+		return err
+	}
+
+	corporationID, err := corporationIDOfEmail(this.CLAOrgID, this.Email)
+	if err != nil {
+		return err
+	}
+
 	p := dbmodels.EmployeeSigningInfo{
-		Email:   this.Email,
-		Name:    this.Name,
-		Enabled: false,
-		Info:    this.Info,
+		Email:      this.Email,
+		Name:       this.Name,
+		Enabled:    false,
+		Info:       this.Info,
+		SignedAt:   time.Now().Unix(),
+		CLAVersion: version,
+		Metadata:   meta.toDB(hash),
+
+		CorporationID: corporationID,
 	}
 	return dbmodels.GetDB().SignAsEmployee(this.CLAOrgID, p)
 }
@@ -26,38 +57,48 @@ type EmployeeSigningListOption struct {
 	RepoID           string `json:"repo_id"`
 	CLALanguage      string `json:"cla_language"`
 	CorporationEmail string `json:"corporation_email"`
+
+	Page dbmodels.PageOption `json:"-"`
+}
+
+type EmployeeSigningPage struct {
+	dbmodels.PageInfo
+
+	Signings []EmployeeSigning
+}
+
+func (this EmployeeSigningListOption) Validate() error {
+	return this.Page.Validate(dbmodels.EmployeeSigningSortFields)
 }
 
-func (this EmployeeSigningListOption) List() ([]EmployeeSigning, error) {
+func (this EmployeeSigningListOption) List() (EmployeeSigningPage, error) {
 	opt := dbmodels.EmployeeSigningListOption{
 		Platform:         this.Platform,
 		OrgID:            this.OrgID,
 		RepoID:           this.RepoID,
 		CLALanguage:      this.CLALanguage,
 		CorporationEmail: this.CorporationEmail,
+		Page:             this.Page,
 	}
 	v, err := dbmodels.GetDB().ListEmployeeSigning(opt)
 	if err != nil {
-		return nil, err
+		return EmployeeSigningPage{}, err
 	}
 
-	n := 0
-	for _, items := range v {
-		n += len(items)
-	}
+	r := make([]EmployeeSigning, 0, len(v.Signings))
+	for _, item := range v.Signings {
+		r = append(r, EmployeeSigning{
+			CLAOrgID: item.CLAOrgID,
+			Email:    item.Email,
+			Name:     item.Name,
+			Enabled:  item.Enabled,
 
-	r := make([]EmployeeSigning, 0, n)
-	for k, items := range v {
-		for _, item := range items {
-			r = append(r, EmployeeSigning{
-				CLAOrgID: k,
-				Email:    item.Email,
-				Name:     item.Name,
-				Enabled:  item.Enabled,
-			})
-		}
+			SignedAt:   item.SignedAt,
+			CLAVersion: item.CLAVersion,
+			Metadata:   item.Metadata,
+		})
 	}
-	return r, nil
+	return EmployeeSigningPage{PageInfo: v.PageInfo, Signings: r}, nil
 }
 
 type EmployeeSigningUdateInfo struct {
@@ -67,8 +108,13 @@ type EmployeeSigningUdateInfo struct {
 }
 
 func (this *EmployeeSigningUdateInfo) Update() error {
+	corporationID, err := corporationIDOfEmail(this.CLAOrgID, this.Email)
+	if err != nil {
+		return err
+	}
+
 	return dbmodels.GetDB().UpdateEmployeeSigning(
 		this.CLAOrgID, this.Email,
-		dbmodels.EmployeeSigningUpdateInfo{Enabled: this.Enabled},
+		dbmodels.EmployeeSigningUpdateInfo{Enabled: this.Enabled, CorporationID: corporationID},
 	)
 }
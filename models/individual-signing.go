@@ -1,6 +1,10 @@
 package models
 
-import "github.com/zengchen1024/cla-server/dbmodels"
+import (
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
 
 type IndividualSigning struct {
 	CLAOrgID string                   `json:"cla_org_id"`
@@ -8,11 +12,119 @@ type IndividualSigning struct {
 	Info     dbmodels.TypeSigningInfo `json:"info"`
 }
 
-func (this *IndividualSigning) Create() error {
+// Validate checks the info against the fields of cla. It returns FieldErrors if some fields are invalid.
+func (this *IndividualSigning) Validate() error {
+	return validateSigningInfoOfBinding(this.CLAOrgID, this.Info)
+}
+
+// Create signs as individual. The signing will be replaced if the contributor
+// has signed an older version of cla.
+func (this *IndividualSigning) Create(meta SigningMetadata) error {
+	version, hash, err := claOfBinding(this.CLAOrgID)
+	if err != nil {
+		return err
+	}
+
 	p := dbmodels.IndividualSigningInfo{}
 	if err := copyBetweenStructs(this, &p); err != nil {
 		return err
 	}
+	p.SignedAt = time.Now().Unix()
+	p.CLAVersion = version
+	p.Metadata = meta.toDB(hash)
 
 	return dbmodels.GetDB().SignAsIndividual(this.CLAOrgID, p)
 }
+
+type SigningCheckOption struct {
+	Platform string `json:"platform"`
+	OrgID    string `json:"org_id"`
+	RepoID   string `json:"repo_id"`
+	Email    string `json:"email"`
+}
+
+type SigningCheckResult struct {
+	Signed bool `json:"signed"`
+
+	*dbmodels.SigningCheckResult
+	CLAName string `json:"cla_name,omitempty"`
+
+	// ResignRequired means the contributor has signed an old version of cla
+	// and must sign the current version, so it is treated as unsigned.
+	ResignRequired bool `json:"resign_required,omitempty"`
+
+	// CorporationResignRequired means the corporation of employee has signed
+	// an old version of cla and must sign the current version, so the employee
+	// is treated as unsigned.
+	CorporationResignRequired bool `json:"corporation_resign_required,omitempty"`
+}
+
+// Check checks whether the contributor has signed the cla for the org/repo
+func (this SigningCheckOption) Check() (SigningCheckResult, error) {
+	r := SigningCheckResult{}
+
+	corporationID, err := corporationIDOfEmailInRepo(this.Platform, this.OrgID, this.RepoID, this.Email)
+	if err != nil {
+		return r, err
+	}
+
+	v, err := dbmodels.GetDB().CheckSigning(dbmodels.SigningCheckOption{
+		Platform:      this.Platform,
+		OrgID:         this.OrgID,
+		RepoID:        this.RepoID,
+		Email:         this.Email,
+		CorporationID: corporationID,
+	})
+	if err != nil || v == nil {
+		return r, err
+	}
+
+	cla := &CLA{ID: v.CLAID}
+	if err := cla.Get(); err != nil {
+		return r, err
+	}
+
+	r.ResignRequired = cla.NeedResign(v.CLAVersion, v.CurrentCLAVersion)
+
+	if c := v.Corporation; c != nil {
+		corpCLA := &CLA{ID: c.CLAID}
+		if err := corpCLA.Get(); err != nil {
+			return r, err
+		}
+		r.CorporationResignRequired = corpCLA.NeedResign(c.CLAVersion, c.CurrentCLAVersion)
+	}
+
+	r.Signed = !r.ResignRequired && !r.CorporationResignRequired
+	r.SigningCheckResult = v
+	r.CLAName = cla.Name
+	return r, nil
+}
+
+type IndividualSigningListOption struct {
+	Platform      string `json:"platform"`
+	OrgID         string `json:"org_id"`
+	RepoID        string `json:"repo_id"`
+	CLALanguage   string `json:"cla_language"`
+	EmailContains string `json:"email"`
+	SignedFrom    int64  `json:"signed_from"`
+	SignedTo      int64  `json:"signed_to"`
+
+	Page dbmodels.PageOption `json:"-"`
+}
+
+func (this IndividualSigningListOption) Validate() error {
+	return this.Page.Validate(dbmodels.IndividualSigningSortFields)
+}
+
+func (this IndividualSigningListOption) List() (dbmodels.IndividualSigningPage, error) {
+	return dbmodels.GetDB().ListIndividualSigning(dbmodels.IndividualSigningListOption{
+		Platform:      this.Platform,
+		OrgID:         this.OrgID,
+		RepoID:        this.RepoID,
+		CLALanguage:   this.CLALanguage,
+		EmailContains: this.EmailContains,
+		SignedFrom:    this.SignedFrom,
+		SignedTo:      this.SignedTo,
+		Page:          this.Page,
+	})
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusDone      = "done"
+	JobStatusCancelled = "cancelled"
+	// JobStatusDead means the job has failed too many times and will not be retried.
+	JobStatusDead = "dead"
+
+	JobKindCorporationSigningPDF = "corporation-signing-pdf"
+)
+
+type Job struct {
+	ID            string `json:"id"`
+	Kind          string `json:"kind"`
+	CLAOrgID      string `json:"cla_org_id"`
+	CorporationID string `json:"corporation_id"`
+	Email         string `json:"email"`
+	Payload       string `json:"payload"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	MaxAttempts   int    `json:"max_attempts"`
+	NextRunAt     int64  `json:"next_run_at"`
+	LockedUntil   int64  `json:"locked_until"`
+	LastError     string `json:"last_error"`
+	CreatedAt     int64  `json:"created_at"`
+	UpdatedAt     int64  `json:"updated_at"`
+	ClaimToken    string `json:"-"`
+}
+
+func (this *Job) Create() error {
+	now := time.Now().Unix()
+	this.Status = JobStatusPending
+	this.Attempts = 0
+	this.NextRunAt = now
+	this.CreatedAt = now
+	this.UpdatedAt = now
+
+	p := dbmodels.Job{}
+	if err := copyBetweenStructs(this, &p); err != nil {
+		return err
+	}
+
+	v, err := dbmodels.GetDB().CreateJob(p)
+	if err == nil {
+		this.ID = v
+	}
+	return err
+}
+
+func ClaimJob(now, lockedUntil int64) (*Job, error) {
+	token, err := genToken()
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := dbmodels.GetDB().ClaimJob(now, lockedUntil, token)
+	if err != nil || v == nil {
+		return nil, err
+	}
+
+	job := &Job{}
+	if err := copyBetweenStructs(v, job); err != nil {
+		return nil, err
+	}
+	job.ClaimToken = v.ClaimToken
+	return job, nil
+}
+
+type JobUpdateInfo struct {
+	Status    *string `json:"status"`
+	Attempts  *int    `json:"attempts"`
+	LastError *string `json:"last_error"`
+	NextRunAt *int64  `json:"next_run_at"`
+
+	// ExpectedStatus is the status the job must be in, if it is set.
+	ExpectedStatus []string `json:"-"`
+
+	// ClaimToken is the token of claim which the job must hold, if it is set.
+	ClaimToken string `json:"-"`
+}
+
+func (this JobUpdateInfo) Update(jobID string) error {
+	return dbmodels.GetDB().UpdateJob(jobID, dbmodels.JobUpdateInfo{
+		Status:         this.Status,
+		Attempts:       this.Attempts,
+		LastError:      this.LastError,
+		NextRunAt:      this.NextRunAt,
+		ExpectedStatus: this.ExpectedStatus,
+		ClaimToken:     this.ClaimToken,
+	})
+}
+
+func (this *Job) Get() error {
+	v, err := dbmodels.GetDB().GetJob(this.ID)
+	if err != nil {
+		return err
+	}
+	return copyBetweenStructs(&v, this)
+}
+
+type JobListOption struct {
+	CLAOrgID         string `json:"cla_org_id"`
+	Status           string `json:"status"`
+	CorporationEmail string `json:"corporation_email"`
+
+	Page dbmodels.PageOption `json:"-"`
+}
+
+func (this JobListOption) Validate() error {
+	return this.Page.Validate(dbmodels.JobSortFields)
+}
+
+func (this JobListOption) List() (dbmodels.JobPage, error) {
+	opt := dbmodels.JobListOption{
+		CLAOrgID: this.CLAOrgID,
+		Status:   this.Status,
+		Page:     this.Page,
+	}
+	if this.CorporationEmail != "" {
+		opt.CorporationID = emailSuffixToKey(this.CorporationEmail)
+	}
+
+	return dbmodels.GetDB().ListJob(opt)
+}
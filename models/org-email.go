@@ -8,23 +8,43 @@ import (
 	"golang.org/x/oauth2"
 )
 
+// EmailPlatformSMTP is the email platform which sends email by SMTP server
+// with the user and password instead of oauth2 token.
+const EmailPlatformSMTP = "smtp"
+
 type OrgEmail struct {
 	Email string `json:"email"`
 	// Platform is the email platform, such as gmail
 	Platform string        `json:"platform"`
 	Token    *oauth2.Token `json:"token"`
+	// SMTP is the credential of SMTP server, it is used when the platform is smtp
+	SMTP *SMTPCredential `json:"smtp,omitempty"`
+
+	// Submitter is who added the email, only it can update the email
+	Submitter string `json:"-"`
+}
+
+type SMTPCredential struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
 }
 
 func (this *OrgEmail) Create() error {
-	b, err := json.Marshal(this.Token)
+	var v interface{} = this.Token
+	if this.Platform == EmailPlatformSMTP {
+		v = this.SMTP
+	}
+
+	b, err := json.Marshal(v)
 	if err != nil {
-		return fmt.Errorf("Failed to marshal oauth2 token: %s", err.Error())
+		return fmt.Errorf("Failed to marshal credential of email: %s", err.Error())
 	}
 
 	opt := dbmodels.OrgEmailCreateInfo{
-		Email:    this.Email,
-		Platform: this.Platform,
-		Token:    b,
+		Email:     this.Email,
+		Platform:  this.Platform,
+		Token:     b,
+		Submitter: this.Submitter,
 	}
 	return dbmodels.GetDB().CreateOrgEmail(opt)
 }
@@ -36,6 +56,18 @@ func (this *OrgEmail) Get() error {
 	}
 
 	this.Platform = info.Platform
+	this.Submitter = dbmodels.OrgEmailOwner(info)
+
+	if this.Platform == EmailPlatformSMTP {
+		var c SMTPCredential
+
+		if err := json.Unmarshal(info.Token, &c); err != nil {
+			return fmt.Errorf("Failed to unmarshal smtp credential: %s", err.Error())
+		}
+
+		this.SMTP = &c
+		return nil
+	}
 
 	var token oauth2.Token
 
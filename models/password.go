@@ -0,0 +1,49 @@
+package models
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	initialPasswordLength = 12
+	minPasswordLength     = 8
+	passwordChars         = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnpqrstuvwxyz23456789"
+)
+
+// genPassword generates a random password for the new corporation manager.
+func genPassword() (string, error) {
+	max := big.NewInt(int64(len(passwordChars)))
+
+	b := make([]byte, initialPasswordLength)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("Failed to generate password: %s", err.Error())
+		}
+		b[i] = passwordChars[n.Int64()]
+	}
+	return string(b), nil
+}
+
+func hashPassword(pw string) (string, error) {
+	b, err := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("Failed to hash password: %s", err.Error())
+	}
+	return string(b), nil
+}
+
+func isPasswordCorrect(hash, pw string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(pw)) == nil
+}
+
+// isPasswordHashed tells whether the stored password is hashed or
+// is the plaintext one saved by the old version.
+func isPasswordHashed(pw string) bool {
+	_, err := bcrypt.Cost([]byte(pw))
+	return err == nil
+}
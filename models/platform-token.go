@@ -0,0 +1,25 @@
+package models
+
+import "github.com/zengchen1024/cla-server/dbmodels"
+
+type PlatformToken struct {
+	User  string `json:"user"`
+	Token string `json:"token"`
+}
+
+func (this PlatformToken) Save() error {
+	return dbmodels.GetDB().SavePlatformToken(dbmodels.PlatformToken{
+		User:  this.User,
+		Token: this.Token,
+	})
+}
+
+func (this *PlatformToken) Get() error {
+	v, err := dbmodels.GetDB().GetPlatformToken(this.User)
+	if err != nil {
+		return err
+	}
+
+	this.Token = v.Token
+	return nil
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+// SigningMetadata is the information of signer's request, which is recorded
+// along with the signing to prove when, by whom and what was signed.
+type SigningMetadata struct {
+	// SignedBy is the account on code platform of the signer
+	SignedBy  string
+	IP        string
+	UserAgent string
+}
+
+func (this SigningMetadata) toDB(claHash string) *dbmodels.SigningMetadata {
+	return &dbmodels.SigningMetadata{
+		SignedBy:  this.SignedBy,
+		IP:        this.IP,
+		UserAgent: this.UserAgent,
+		CLAHash:   claHash,
+	}
+}
+
+// CLATextHash returns the sha256 of cla text in hex
+func CLATextHash(text string) string {
+	h := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(h[:])
+}
+
+// claOfBinding returns the version of cla which the binding points to and the hash of its text.
+func claOfBinding(claOrgID string) (int, string, error) {
+	binding, err := dbmodels.GetDB().GetBindingBetweenCLAAndOrg(claOrgID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	cla := &CLA{ID: binding.CLAID}
+	if err := cla.Get(); err != nil {
+		return 0, "", err
+	}
+
+	text, err := cla.TextOfVersion(binding.CLAVersion)
+	if err != nil {
+		return 0, "", err
+	}
+	return binding.CLAVersion, CLATextHash(text), nil
+}
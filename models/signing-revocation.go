@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+const ActionSigningWithdrawal = "signing-withdrawal"
+
+// SigningRevocation is used to revoke the individual or employee signing.
+// The signer who withdraws the signing must provide the verification code
+// sent to the email, but the org owner or employee manager needn't.
+type SigningRevocation struct {
+	CLAOrgID   string `json:"cla_org_id"`
+	Email      string `json:"email"`
+	Reason     string `json:"reason"`
+	VerifiCode string `json:"verifi_code,omitempty"`
+}
+
+func (this *SigningRevocation) ValidateWithdrawal(maxAttempts int) error {
+	return validateVerificationCode(this.Email, ActionSigningWithdrawal, this.VerifiCode, maxAttempts)
+}
+
+func (this *SigningRevocation) RevokeIndividual(revokedBy string) error {
+	return dbmodels.GetDB().RevokeIndividualSigning(this.CLAOrgID, this.toDBModel(revokedBy))
+}
+
+func (this *SigningRevocation) RevokeEmployee(revokedBy string) error {
+	corporationID, err := corporationIDOfEmail(this.CLAOrgID, this.Email)
+	if err != nil {
+		return err
+	}
+
+	opt := this.toDBModel(revokedBy)
+	opt.CorporationID = corporationID
+	return dbmodels.GetDB().RevokeEmployeeSigning(this.CLAOrgID, opt)
+}
+
+// CorporationIDOfEmployee returns the corporation which the employee belongs to
+func (this *SigningRevocation) CorporationIDOfEmployee() (string, error) {
+	return corporationIDOfEmail(this.CLAOrgID, this.Email)
+}
+
+func (this *SigningRevocation) toDBModel(revokedBy string) dbmodels.SigningRevocation {
+	return dbmodels.SigningRevocation{
+		Email:     this.Email,
+		RevokedBy: revokedBy,
+		Reason:    this.Reason,
+		RevokedAt: time.Now().Unix(),
+	}
+}
+
+func ListRevokedSigning(claOrgID string) ([]dbmodels.RevokedSigning, error) {
+	return dbmodels.GetDB().ListRevokedSigning(claOrgID)
+}
+
+type SigningWithdrawalVerifCode struct {
+	CLAOrgID string `json:"cla_org_id"`
+	Email    string `json:"email"`
+}
+
+func (this SigningWithdrawalVerifCode) CheckFrequency(ip string, interval int64) error {
+	return checkVerificationCodeFrequency(this.Email, ActionSigningWithdrawal, ip, interval)
+}
+
+func (this SigningWithdrawalVerifCode) Create(ip string, expiry int64) (string, error) {
+	return createVerificationCode(this.Email, ActionSigningWithdrawal, ip, expiry)
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+const verificationCodeLength = 6
+
+// genVerificationCode generates a random numeric code.
+func genVerificationCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < verificationCodeLength; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate verification code: %s", err.Error())
+	}
+
+	return fmt.Sprintf("%0*d", verificationCodeLength, n), nil
+}
+
+// genToken generates a random token which is longer than verification code and
+// can be sent as a link.
+func genToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Failed to generate token: %s", err.Error())
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func createVerificationCode(email, purpose, ip string, expiry int64) (string, error) {
+	code, err := genVerificationCode()
+	if err != nil {
+		return "", err
+	}
+
+	return code, saveVerificationCode(email, purpose, code, ip, expiry)
+}
+
+func saveVerificationCode(email, purpose, code, ip string, expiry int64) error {
+	now := time.Now().Unix()
+	vc := dbmodels.VerificationCode{
+		Email:     email,
+		Code:      code,
+		Purpose:   purpose,
+		Expiry:    now + expiry,
+		CreatedAt: now,
+		IP:        ip,
+	}
+
+	return dbmodels.GetDB().CreateVerificationCode(vc)
+}
+
+// checkVerificationCodeFrequency returns error if a code has been sent to
+// the email or requested from the ip within the interval.
+func checkVerificationCodeFrequency(email, purpose, ip string, interval int64) error {
+	since := time.Now().Unix() - interval
+
+	sent, err := dbmodels.GetDB().HasVerificationCodeSentSince(email, purpose, ip, since)
+	if err != nil {
+		return err
+	}
+	if sent {
+		return fmt.Errorf("the verification code was sent just now, please retry after %d seconds", interval)
+	}
+	return nil
+}
+
+func validateVerificationCode(email, purpose, code string, maxAttempts int) error {
+	vc := dbmodels.VerificationCode{
+		Email:   email,
+		Code:    code,
+		Purpose: purpose,
+	}
+
+	v, err := dbmodels.GetDB().CheckVerificationCode(vc, maxAttempts)
+	if err != nil {
+		return err
+	}
+	if !v {
+		return fmt.Errorf("Verification Code is expired or wrong")
+	}
+	return nil
+}
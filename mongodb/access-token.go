@@ -0,0 +1,169 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/huaweicloud/golangsdk"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+const (
+	refreshTokenCollection   = "refresh_tokens"
+	revokedSessionCollection = "revoked_sessions"
+)
+
+type refreshToken struct {
+	ID         string `bson:"id"`
+	Token      string `bson:"token"`
+	User       string `bson:"user"`
+	Permission string `bson:"permission"`
+	Expiry     int64  `bson:"expiry"`
+}
+
+func toDBModelRefreshToken(item refreshToken) dbmodels.RefreshToken {
+	return dbmodels.RefreshToken{
+		ID:         item.ID,
+		Token:      item.Token,
+		User:       item.User,
+		Permission: item.Permission,
+		Expiry:     item.Expiry,
+	}
+}
+
+func (c *client) CreateRefreshToken(opt dbmodels.RefreshToken) error {
+	body, err := golangsdk.BuildRequestBody(opt, "")
+	if err != nil {
+		return fmt.Errorf("Failed to build body for creating refresh token, err:%v", err)
+	}
+
+	f := func(ctx context.Context) error {
+		col := c.collection(refreshTokenCollection)
+
+		// clean up the expired ones
+		col.DeleteMany(ctx, bson.M{"expiry": bson.M{"$lt": time.Now().Unix()}})
+
+		if _, err := col.InsertOne(ctx, bson.M(body)); err != nil {
+			return fmt.Errorf("Failed to create refresh token: write db err:%v", err)
+		}
+		return nil
+	}
+
+	return withContext(f)
+}
+
+func (c *client) GetRefreshToken(id string) (dbmodels.RefreshToken, error) {
+	var sr *mongo.SingleResult
+
+	f := func(ctx context.Context) error {
+		col := c.collection(refreshTokenCollection)
+
+		sr = col.FindOne(ctx, bson.M{"id": id})
+		return nil
+	}
+
+	withContext(f)
+
+	var v refreshToken
+	if err := sr.Decode(&v); err != nil {
+		return dbmodels.RefreshToken{}, fmt.Errorf("error decoding to bson struct of refresh token: %v", err)
+	}
+
+	return toDBModelRefreshToken(v), nil
+}
+
+func (c *client) DeleteRefreshToken(id string) error {
+	f := func(ctx context.Context) error {
+		col := c.collection(refreshTokenCollection)
+
+		if _, err := col.DeleteOne(ctx, bson.M{"id": id}); err != nil {
+			return fmt.Errorf("Failed to delete refresh token: %s", err.Error())
+		}
+		return nil
+	}
+
+	return withContext(f)
+}
+
+func (c *client) DeleteRefreshTokensOfUser(user string) ([]dbmodels.RefreshToken, error) {
+	var v []refreshToken
+
+	f := func(ctx mongo.SessionContext) error {
+		col := c.collection(refreshTokenCollection)
+
+		filter := bson.M{"user": user}
+
+		cursor, err := col.Find(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("error find refresh tokens: %v", err)
+		}
+
+		if err := cursor.All(ctx, &v); err != nil {
+			return fmt.Errorf("error decoding to bson struct of refresh token: %v", err)
+		}
+
+		if _, err := col.DeleteMany(ctx, filter); err != nil {
+			return fmt.Errorf("Failed to delete refresh tokens: %s", err.Error())
+		}
+		return nil
+	}
+
+	if err := c.doTransaction(f); err != nil {
+		return nil, err
+	}
+
+	r := make([]dbmodels.RefreshToken, 0, len(v))
+	for _, item := range v {
+		r = append(r, toDBModelRefreshToken(item))
+	}
+	return r, nil
+}
+
+func (c *client) RevokeSession(opt dbmodels.RevokedSession) error {
+	body, err := golangsdk.BuildRequestBody(opt, "")
+	if err != nil {
+		return fmt.Errorf("Failed to build body for revoking session, err:%v", err)
+	}
+
+	f := func(ctx context.Context) error {
+		col := c.collection(revokedSessionCollection)
+
+		// the access tokens of expired sessions are invalid already
+		col.DeleteMany(ctx, bson.M{"expiry": bson.M{"$lt": time.Now().Unix()}})
+
+		upsert := true
+		_, err := col.UpdateOne(
+			ctx, bson.M{"id": opt.ID}, bson.M{"$set": bson.M(body)},
+			&options.UpdateOptions{Upsert: &upsert},
+		)
+		if err != nil {
+			return fmt.Errorf("Failed to revoke session: write db err:%v", err)
+		}
+		return nil
+	}
+
+	return withContext(f)
+}
+
+func (c *client) IsSessionRevoked(id string) (bool, error) {
+	revoked := false
+
+	f := func(ctx context.Context) error {
+		col := c.collection(revokedSessionCollection)
+
+		n, err := col.CountDocuments(ctx, bson.M{"id": id})
+		if err != nil {
+			return fmt.Errorf("Failed to check revoked session: %s", err.Error())
+		}
+
+		revoked = (n > 0)
+		return nil
+	}
+
+	return revoked, withContext(f)
+}
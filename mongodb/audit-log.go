@@ -0,0 +1,110 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/huaweicloud/golangsdk"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+// auditLogCollection is append-only. There is no way to update or delete the logs.
+const auditLogCollection = "audit_logs"
+
+type auditLog struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Actor         string             `bson:"actor"`
+	Permission    string             `bson:"permission"`
+	Action        string             `bson:"action"`
+	CLAOrgID      string             `bson:"cla_org_id"`
+	CorporationID string             `bson:"corporation_id"`
+	Target        string             `bson:"target"`
+	Before        string             `bson:"before"`
+	After         string             `bson:"after"`
+	CreatedAt     int64              `bson:"created_at"`
+}
+
+func (c *client) AddAuditLog(info dbmodels.AuditLog) error {
+	body, err := golangsdk.BuildRequestBody(info, "")
+	if err != nil {
+		return fmt.Errorf("Failed to build body for adding audit log, err:%v", err)
+	}
+	delete(body, "id")
+
+	f := func(ctx context.Context) error {
+		col := c.collection(auditLogCollection)
+
+		if _, err := col.InsertOne(ctx, bson.M(body)); err != nil {
+			return fmt.Errorf("Failed to add audit log: write db err:%v", err)
+		}
+		return nil
+	}
+
+	return withContext(f)
+}
+
+func (c *client) ListAuditLog(opt dbmodels.AuditLogListOption) (dbmodels.AuditLogPage, error) {
+	var r dbmodels.AuditLogPage
+
+	if _, err := opt.Page.SortField(dbmodels.AuditLogSortFields); err != nil {
+		return r, err
+	}
+
+	filter := bson.M{}
+	if opt.CLAOrgID != "" {
+		filter["cla_org_id"] = opt.CLAOrgID
+	}
+	if opt.CorporationID != "" {
+		filter["corporation_id"] = opt.CorporationID
+	}
+	if opt.Actor != "" {
+		filter["actor"] = opt.Actor
+	}
+	if opt.Action != "" {
+		filter["action"] = opt.Action
+	}
+	if opt.From > 0 || opt.To > 0 {
+		t := bson.M{}
+		if opt.From > 0 {
+			t["$gte"] = opt.From
+		}
+		if opt.To > 0 {
+			t["$lte"] = opt.To
+		}
+		filter["created_at"] = t
+	}
+
+	keys := []sortKey{{field: "created_at", zero: int64(0)}, {field: "_id", zero: primitive.NilObjectID}}
+
+	r.Logs = make([]dbmodels.AuditLog, 0)
+	decode := func(doc bson.Raw) error {
+		var item auditLog
+		if err := bson.Unmarshal(doc, &item); err != nil {
+			return fmt.Errorf("error decoding to bson struct of audit log: %v", err)
+		}
+		r.Logs = append(r.Logs, dbmodels.AuditLog{
+			ID:            objectIDToUID(item.ID),
+			Actor:         item.Actor,
+			Permission:    item.Permission,
+			Action:        item.Action,
+			CLAOrgID:      item.CLAOrgID,
+			CorporationID: item.CorporationID,
+			Target:        item.Target,
+			Before:        item.Before,
+			After:         item.After,
+			CreatedAt:     item.CreatedAt,
+		})
+		return nil
+	}
+
+	f := func(ctx context.Context) error {
+		var err error
+		r.PageInfo, err = c.listPage(ctx, auditLogCollection, filter, nil, keys, opt.Page, decode)
+		return err
+	}
+
+	return r, withContext(f)
+}
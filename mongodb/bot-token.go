@@ -0,0 +1,84 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (c *client) SetBotToken(claOrgID, token string) error {
+	oid, err := toObjectID(claOrgID)
+	if err != nil {
+		return err
+	}
+
+	f := func(ctx context.Context) error {
+		col := c.collection(claOrgCollection)
+
+		filter := bson.M{"_id": oid}
+		additionalConditionForCLAOrgDoc(filter)
+
+		v := bson.M{fieldBotToken: token, "updated_at": time.Now()}
+		r, err := col.UpdateOne(ctx, filter, bson.M{"$set": v})
+		if err != nil {
+			return fmt.Errorf("Failed to set bot token: %s", err.Error())
+		}
+
+		if r.MatchedCount == 0 {
+			return fmt.Errorf("Failed to set bot token: can't find the binding")
+		}
+		return nil
+	}
+
+	return withContext(f)
+}
+
+func (c *client) GetBotToken(platform, orgID, repoID string) (string, error) {
+	filter := bson.M{
+		"platform":    platform,
+		"org_id":      orgID,
+		"repo_id":     bson.M{"$in": []string{repoID, ""}},
+		fieldBotToken: bson.M{"$exists": true, "$ne": ""},
+	}
+	additionalConditionForCLAOrgDoc(filter)
+
+	var v []CLAOrg
+
+	f := func(ctx context.Context) error {
+		col := c.collection(claOrgCollection)
+
+		opts := options.FindOptions{
+			Projection: bson.M{"repo_id": 1, fieldBotToken: 1},
+		}
+		cursor, err := col.Find(ctx, filter, &opts)
+		if err != nil {
+			return fmt.Errorf("error find bindings: %v", err)
+		}
+
+		err = cursor.All(ctx, &v)
+		if err != nil {
+			return fmt.Errorf("error decoding to bson struct of CLAOrg: %v", err)
+		}
+		return nil
+	}
+
+	if err := withContext(f); err != nil {
+		return "", err
+	}
+
+	token := ""
+	for _, item := range v {
+		if item.RepoID == repoID {
+			return item.BotToken, nil
+		}
+		token = item.BotToken
+	}
+
+	if token == "" {
+		return "", fmt.Errorf("no bot token is set for %s/%s/%s", platform, orgID, repoID)
+	}
+	return token, nil
+}
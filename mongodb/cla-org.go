@@ -12,23 +12,22 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
 )
 
 const (
-	claOrgCollection     = "cla_orgs"
-	orgIdentifierName    = "org_identifier"
-	fieldIndividuals     = "individuals"
-	fieldEmployees       = "employees"
-	fieldCorporations    = "corporations"
-	fieldCorpoManagers   = "corporation_managers"
-	fieldOrgSignature    = "org_signature"
-	fieldOrgSignatureTag = "org_signature_uploaded"
+	claOrgCollection    = "cla_orgs"
+	orgIdentifierName   = "org_identifier"
+	fieldEmailTemplates = "email_templates"
+	fieldBotToken       = "bot_token"
 )
 
 func additionalConditionForCLAOrgDoc(filter bson.M) {
 	filter["enabled"] = true
 }
 
+// CLAOrg is the binding between cla and org/repo. The signings, managers and
+// signature of it are kept in the separate collections and refer to it by cla_org_id.
 type CLAOrg struct {
 	ID primitive.ObjectID `bson:"_id"`
 
@@ -39,28 +38,22 @@ type CLAOrg struct {
 	RepoID      string    `bson:"repo_id"`
 	CLAID       string    `bson:"cla_id"`
 	CLALanguage string    `bson:"cla_language"`
+	CLAVersion  int       `bson:"cla_version,omitempty"`
 	ApplyTo     string    `bson:"apply_to" required:"true"`
 	OrgEmail    string    `bson:"org_email,omitempty"`
 	Enabled     bool      `bson:"enabled"`
 	Submitter   string    `bson:"submitter"`
 
-	// Individuals is the cla signing information of ordinary contributors
-	// key is the email of contributor
-	Individuals map[string]dbmodels.TypeSigningInfo `bson:"individuals,omitempty"`
+	// EmailTemplates is the customized templates of notification email
+	// key is the kind of template
+	EmailTemplates map[string]emailTemplate `bson:"email_templates,omitempty"`
 
-	// Employees is the cla signing information of employees and grouped by corporation
-	// key is the email suffix of corporation
-	Employees map[string][]employeeSigning `bson:"employees,omitempty"`
+	// BotToken is used by bot to label pull requests and report the commit status
+	BotToken string `bson:"bot_token,omitempty"`
 
-	// Corporations is the cla signing information of corporation
-	// key is the email suffix of corporation
-	Corporations []corporationSigning `bson:"corporations,omitempty"`
-
-	// CorporationManagers is the managers of corporation who can manage the employee
-	CorporationManagers []corporationManager `bson:"corporation_managers,omitempty"`
-
-	OrgSignatureUploaded bool   `bson:"org_signature_uploaded"`
-	OrgSignature         []byte `bson:"org_signature"`
+	// DomainVerificationRequired means the corporation must verify the
+	// domain of its email before being enabled
+	DomainVerificationRequired bool `bson:"domain_verification_required,omitempty"`
 }
 
 func orgIdentifier(platform, org string) string {
@@ -159,57 +152,126 @@ func (c *client) GetBindingBetweenCLAAndOrg(uid string) (dbmodels.CLAOrg, error)
 	return toModelCLAOrg(v), nil
 }
 
-func (c *client) ListBindingBetweenCLAAndOrg(opt dbmodels.CLAOrgListOption) ([]dbmodels.CLAOrg, error) {
+func (c *client) ListBindingBetweenCLAAndOrg(opt dbmodels.CLAOrgListOption) (dbmodels.CLAOrgPage, error) {
+	var r dbmodels.CLAOrgPage
+
+	field, err := opt.Page.SortField(dbmodels.CLAOrgSortFields)
+	if err != nil {
+		return r, err
+	}
+
 	body, err := golangsdk.BuildRequestBody(opt, "")
 	if err != nil {
-		return nil, fmt.Errorf("build options to list cla-org failed, err:%v", err)
+		return r, fmt.Errorf("build options to list cla-org failed, err:%v", err)
 	}
 	filter := bson.M(body)
 	additionalConditionForCLAOrgDoc(filter)
+	if opt.OrgIDs != nil {
+		cond := bson.M{"$in": opt.OrgIDs}
+		if opt.OrgID != "" {
+			cond["$eq"] = opt.OrgID
+		}
+		filter["org_id"] = cond
+	}
 
-	var v []CLAOrg
+	keys := []sortKey{{field: field, zero: ""}, {field: "_id", zero: primitive.NilObjectID}}
+
+	r.Bindings = make([]dbmodels.CLAOrg, 0)
+	decode := func(doc bson.Raw) error {
+		var v CLAOrg
+		if err := bson.Unmarshal(doc, &v); err != nil {
+			return fmt.Errorf("error decoding to bson struct of CLAOrg: %v", err)
+		}
+		r.Bindings = append(r.Bindings, toModelCLAOrg(v))
+		return nil
+	}
 
 	f := func(ctx context.Context) error {
-		col := c.db.Collection(claOrgCollection)
+		// list the bindings of repo if there are, otherwise the ones of org
+		if opt.RepoID != "" {
+			cond := bson.M{"repo_id": opt.RepoID}
+			for k, v := range filter {
+				cond[k] = v
+			}
 
-		opts := options.FindOptions{
-			Projection: projectOfClaOrg(),
+			b, err := c.isCLAOrgExist(ctx, cond)
+			if err != nil {
+				return err
+			}
+			if b {
+				filter = cond
+			}
 		}
-		cursor, err := col.Find(ctx, filter, &opts)
+
+		var err error
+		r.PageInfo, err = c.listPage(ctx, claOrgCollection, filter, projectOfClaOrg(), keys, opt.Page, decode)
+		return err
+	}
+
+	return r, withContext(f)
+}
+
+func (c *client) UpdateBindingCLAVersion(claOrgID string, version int) error {
+	oid, err := toObjectID(claOrgID)
+	if err != nil {
+		return err
+	}
+
+	f := func(ctx context.Context) error {
+		col := c.collection(claOrgCollection)
+
+		filter := bson.M{"_id": oid}
+		additionalConditionForCLAOrgDoc(filter)
+
+		v := bson.M{"cla_version": version, "updated_at": time.Now()}
+		r, err := col.UpdateOne(ctx, filter, bson.M{"$set": v})
 		if err != nil {
-			return fmt.Errorf("error find bindings: %v", err)
+			return fmt.Errorf("Failed to update version of cla: %s", err.Error())
 		}
 
-		err = cursor.All(ctx, &v)
-		if err != nil {
-			return fmt.Errorf("error decoding to bson struct of CLAOrg: %v", err)
+		if r.MatchedCount == 0 {
+			return fmt.Errorf("Failed to update version of cla: can't find the binding")
 		}
 		return nil
 	}
 
-	err = withContext(f)
+	return withContext(f)
+}
+
+func (c *client) UpdateBindingDomainVerification(claOrgID string, required bool) error {
+	oid, err := toObjectID(claOrgID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	n := len(v)
-	r := make([]dbmodels.CLAOrg, 0, n)
-	for _, item := range v {
-		r = append(r, toModelCLAOrg(item))
-	}
+	f := func(ctx context.Context) error {
+		col := c.collection(claOrgCollection)
 
-	if opt.RepoID != "" {
-		r1 := make([]dbmodels.CLAOrg, 0, n)
-		for i := 0; i < n; i++ {
-			if r[i].RepoID == opt.RepoID {
-				r1 = append(r1, r[i])
-			}
+		filter := bson.M{"_id": oid, "apply_to": models.ApplyToCorporation}
+		additionalConditionForCLAOrgDoc(filter)
+
+		v := bson.M{"domain_verification_required": required, "updated_at": time.Now()}
+		r, err := col.UpdateOne(ctx, filter, bson.M{"$set": v})
+		if err != nil {
+			return fmt.Errorf("Failed to update domain verification: %s", err.Error())
 		}
-		if len(r1) != 0 {
-			return r1, nil
+
+		if r.MatchedCount == 0 {
+			return fmt.Errorf("Failed to update domain verification: can't find the binding of corporation cla")
 		}
+		return nil
+	}
+
+	return withContext(f)
+}
+
+// bindingCLAVersion returns the version of cla which the binding points to.
+// The binding created before versioning points to version 1.
+func bindingCLAVersion(item CLAOrg) int {
+	if item.CLAVersion == 0 {
+		return 1
 	}
-	return r, nil
+	return item.CLAVersion
 }
 
 func toModelCLAOrg(item CLAOrg) dbmodels.CLAOrg {
@@ -220,19 +282,79 @@ func toModelCLAOrg(item CLAOrg) dbmodels.CLAOrg {
 		RepoID:      item.RepoID,
 		CLAID:       item.CLAID,
 		CLALanguage: item.CLALanguage,
+		CLAVersion:  bindingCLAVersion(item),
 		ApplyTo:     item.ApplyTo,
 		OrgEmail:    item.OrgEmail,
 		Enabled:     item.Enabled,
 		Submitter:   item.Submitter,
+
+		DomainVerificationRequired: item.DomainVerificationRequired,
 	}
 }
 
 func projectOfClaOrg() bson.M {
 	return bson.M{
-		fieldIndividuals:   0,
-		fieldEmployees:     0,
-		fieldCorporations:  0,
-		fieldCorpoManagers: 0,
-		fieldOrgSignature:  0,
+		fieldEmailTemplates: 0,
+		fieldBotToken:       0,
 	}
 }
+
+func repoFilter(platform, orgID, repoID string) bson.M {
+	return bson.M{
+		"platform": platform,
+		"org_id":   orgID,
+		"repo_id":  repoID,
+	}
+}
+
+// listCLAOrgs returns the bindings which match the filter without the signings and templates
+func (c *client) listCLAOrgs(ctx context.Context, filter bson.M) ([]CLAOrg, error) {
+	col := c.collection(claOrgCollection)
+
+	cursor, err := col.Find(ctx, filter, &options.FindOptions{Projection: projectOfClaOrg()})
+	if err != nil {
+		return nil, fmt.Errorf("error find bindings: %v", err)
+	}
+
+	var v []CLAOrg
+	if err := cursor.All(ctx, &v); err != nil {
+		return nil, fmt.Errorf("error decoding to bson struct of CLAOrg: %v", err)
+	}
+	return v, nil
+}
+
+func claOrgIDs(v []CLAOrg) bson.A {
+	ids := make(bson.A, 0, len(v))
+	for _, item := range v {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+// corpoCLAIDsOfRepo returns the ids of enabled bindings of corporation cla for the org/repo.
+// The signings and managers of corporation are unique among them.
+func (c *client) corpoCLAIDsOfRepo(ctx context.Context, platform, orgID, repoID string) (bson.A, error) {
+	filter := repoFilter(platform, orgID, repoID)
+	additionalConditionForCorpoCLADoc(filter)
+
+	v, err := c.listCLAOrgs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return claOrgIDs(v), nil
+}
+
+func (c *client) isCLAOrgExist(ctx context.Context, filter bson.M) (bool, error) {
+	n, err := c.collection(claOrgCollection).CountDocuments(ctx, filter)
+	if err != nil {
+		return false, fmt.Errorf("error find bindings: %v", err)
+	}
+	return n > 0, nil
+}
+
+func (c *client) isCorpoCLAExist(ctx context.Context, oid primitive.ObjectID) (bool, error) {
+	filter := bson.M{"_id": oid}
+	additionalConditionForCorpoCLADoc(filter)
+
+	return c.isCLAOrgExist(ctx, filter)
+}
@@ -26,6 +26,18 @@ type CLA struct {
 	Submitter string             `bson:"submitter"`
 	ApplyTo   string             `bson:"apply_to" required:"true"`
 	Fields    []Field            `bson:"fields,omitempty"`
+
+	// Version is the latest version and Text is the text of it.
+	// The cla created before versioning has no version and is treated as version 1.
+	Version  int          `bson:"version,omitempty"`
+	Versions []claVersion `bson:"versions,omitempty"`
+}
+
+type claVersion struct {
+	Version       int    `bson:"version"`
+	Text          string `bson:"text"`
+	RequireResign bool   `bson:"require_resign"`
+	PublishedAt   int64  `bson:"published_at"`
 }
 
 type Field struct {
@@ -104,41 +116,38 @@ func (this *client) DeleteCLA(uid string) error {
 	return this.doTransaction(f)
 }
 
-func (c *client) ListCLA(opts dbmodels.CLAListOptions) ([]dbmodels.CLA, error) {
-	body, err := golangsdk.BuildRequestBody(opts, "")
+func (c *client) ListCLA(opts dbmodels.CLAListOptions) (dbmodels.CLAPage, error) {
+	var r dbmodels.CLAPage
+
+	field, err := opts.Page.SortField(dbmodels.CLASortFields)
 	if err != nil {
-		return nil, fmt.Errorf("build options to list cla failed, err:%v", err)
+		return r, err
 	}
 
-	var v []CLA
+	body, err := golangsdk.BuildRequestBody(opts, "")
+	if err != nil {
+		return r, fmt.Errorf("build options to list cla failed, err:%v", err)
+	}
 
-	f := func(ctx context.Context) error {
-		col := c.db.Collection(clasCollection)
-		filter := bson.M(body)
+	keys := []sortKey{{field: field, zero: ""}, {field: "_id", zero: primitive.NilObjectID}}
 
-		cursor, err := col.Find(ctx, filter)
-		if err != nil {
-			return fmt.Errorf("error find clas: %v", err)
-		}
-
-		err = cursor.All(ctx, &v)
-		if err != nil {
+	r.CLAs = make([]dbmodels.CLA, 0)
+	decode := func(doc bson.Raw) error {
+		var v CLA
+		if err := bson.Unmarshal(doc, &v); err != nil {
 			return fmt.Errorf("error decoding to bson struct of CLA: %v", err)
 		}
+		r.CLAs = append(r.CLAs, toModelCLA(v))
 		return nil
 	}
 
-	err = withContext(f)
-	if err != nil {
-		return nil, err
-	}
-
-	r := make([]dbmodels.CLA, 0, len(v))
-	for _, item := range v {
-		r = append(r, toModelCLA(item))
+	f := func(ctx context.Context) error {
+		var err error
+		r.PageInfo, err = c.listPage(ctx, clasCollection, bson.M(body), nil, keys, opts.Page, decode)
+		return err
 	}
 
-	return r, nil
+	return r, withContext(f)
 }
 
 func (c *client) ListCLAByIDs(ids []string) ([]dbmodels.CLA, error) {
@@ -210,6 +219,71 @@ func (c *client) GetCLA(uid string) (dbmodels.CLA, error) {
 	return toModelCLA(v), nil
 }
 
+func (c *client) AddCLAVersion(claID string, v dbmodels.CLAVersion) error {
+	oid, err := toObjectID(claID)
+	if err != nil {
+		return err
+	}
+
+	f := func(ctx mongo.SessionContext) error {
+		col := c.collection(clasCollection)
+
+		var cla CLA
+		if err := col.FindOne(ctx, bson.M{"_id": oid}).Decode(&cla); err != nil {
+			return fmt.Errorf("error decoding to bson struct of CLA: %v", err)
+		}
+
+		latest := cla.Version
+		versions := bson.A{}
+		if latest == 0 {
+			// keep the text of the cla created before versioning as version 1
+			latest = 1
+			versions = append(versions, claVersion{
+				Version:     1,
+				Text:        cla.Text,
+				PublishedAt: cla.CreatedAt.Unix(),
+			})
+		}
+
+		if v.Version != latest+1 {
+			return fmt.Errorf("Failed to add version of cla, the version(%d) is not the next one of %d", v.Version, latest)
+		}
+
+		versions = append(versions, claVersion{
+			Version:       v.Version,
+			Text:          v.Text,
+			RequireResign: v.RequireResign,
+			PublishedAt:   v.PublishedAt,
+		})
+
+		update := bson.M{
+			"$set": bson.M{
+				"text":       v.Text,
+				"version":    v.Version,
+				"updated_at": time.Now(),
+			},
+			"$push": bson.M{"versions": bson.M{"$each": versions}},
+		}
+
+		filter := bson.M{"_id": oid, "version": cla.Version}
+		if cla.Version == 0 {
+			filter["version"] = bson.M{"$exists": false}
+		}
+
+		r, err := col.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return fmt.Errorf("Failed to add version of cla: %s", err.Error())
+		}
+
+		if r.MatchedCount == 0 {
+			return fmt.Errorf("Failed to add version of cla, it is being updated concurrently")
+		}
+		return nil
+	}
+
+	return c.doTransaction(f)
+}
+
 func toModelCLA(item CLA) dbmodels.CLA {
 	cla := dbmodels.CLA{
 		ID:        objectIDToUID(item.ID),
@@ -218,6 +292,20 @@ func toModelCLA(item CLA) dbmodels.CLA {
 		Language:  item.Language,
 		ApplyTo:   item.ApplyTo,
 		Submitter: item.Submitter,
+		Version:   item.Version,
+	}
+
+	if cla.Version == 0 {
+		cla.Version = 1
+	}
+
+	for _, v := range item.Versions {
+		cla.Versions = append(cla.Versions, dbmodels.CLAVersion{
+			Version:       v.Version,
+			Text:          v.Text,
+			RequireResign: v.RequireResign,
+			PublishedAt:   v.PublishedAt,
+		})
 	}
 
 	if item.Fields != nil {
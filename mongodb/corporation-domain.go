@@ -0,0 +1,245 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+type corporationDomain struct {
+	Domain     string `bson:"domain"`
+	Token      string `bson:"token"`
+	Verified   bool   `bson:"verified"`
+	VerifiedAt int64  `bson:"verified_at,omitempty"`
+	CreatedAt  int64  `bson:"created_at"`
+}
+
+func (c *client) AddCorporationDomain(claOrgID, adminEmail string, opt dbmodels.CorporationDomain) error {
+	claOrg, err := c.GetBindingBetweenCLAAndOrg(claOrgID)
+	if err != nil {
+		return err
+	}
+
+	oid, err := toObjectID(claOrgID)
+	if err != nil {
+		return err
+	}
+
+	f := func(ctx mongo.SessionContext) error {
+		// the domain can't be claimed by more than one corporation of org/repo
+		ids, err := c.corpoCLAIDsOfRepo(ctx, claOrg.Platform, claOrg.OrgID, claOrg.RepoID)
+		if err != nil {
+			return err
+		}
+
+		col := c.collection(corporationSigningCollection)
+
+		opts := options.FindOptions{
+			Projection: bson.M{
+				"admin_email":    1,
+				"corporation_id": 1,
+				"domains":        1,
+			},
+		}
+
+		cursor, err := col.Find(ctx, bson.M{"cla_org_id": bson.M{"$in": ids}}, &opts)
+		if err != nil {
+			return fmt.Errorf("error find corporation signings: %v", err)
+		}
+
+		var v []corporationSigning
+		if err := cursor.All(ctx, &v); err != nil {
+			return fmt.Errorf("error decoding to bson struct of corporation signing: %v", err)
+		}
+
+		for _, item := range v {
+			if !hasDomain(item, opt.Domain) {
+				continue
+			}
+
+			if item.AdminEmail == adminEmail {
+				return fmt.Errorf("Failed to add domain, it has been added")
+			}
+			return fmt.Errorf("Failed to add domain, it has been claimed by other corporation")
+		}
+
+		d := corporationDomain{
+			Domain:    opt.Domain,
+			Token:     opt.Token,
+			CreatedAt: opt.CreatedAt,
+		}
+
+		filter := bson.M{"cla_org_id": oid, "admin_email": adminEmail}
+
+		r, err := col.UpdateOne(ctx, filter, bson.M{"$push": bson.M{"domains": d}})
+		if err != nil {
+			return fmt.Errorf("Failed to add domain: %s", err.Error())
+		}
+
+		if r.MatchedCount == 0 {
+			return fmt.Errorf("Failed to add domain, the corporation has not signed")
+		}
+		return nil
+	}
+
+	return c.doTransaction(f)
+}
+
+// hasDomain checks whether the domain is the one of administrator's email or an additional one
+func hasDomain(item corporationSigning, domain string) bool {
+	if item.CorporationID == emailToKey(domain) {
+		return true
+	}
+
+	for _, d := range item.Domains {
+		if d.Domain == domain {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *client) VerifyCorporationDomain(claOrgID, adminEmail, domain string, verifiedAt int64) error {
+	oid, err := toObjectID(claOrgID)
+	if err != nil {
+		return err
+	}
+
+	f := func(ctx context.Context) error {
+		b, err := c.isCorpoCLAExist(ctx, oid)
+		if err != nil {
+			return err
+		}
+		if !b {
+			return fmt.Errorf("Failed to verify domain, the binding is not exist")
+		}
+
+		col := c.collection(corporationSigningCollection)
+
+		filter := bson.M{"cla_org_id": oid, "admin_email": adminEmail, "domains.domain": domain}
+
+		update := bson.M{"$set": bson.M{
+			"domains.$[d].verified":    true,
+			"domains.$[d].verified_at": verifiedAt,
+		}}
+
+		updateOpt := options.UpdateOptions{
+			ArrayFilters: &options.ArrayFilters{
+				Filters: bson.A{
+					bson.M{"d.domain": domain},
+				},
+			},
+		}
+
+		r, err := col.UpdateOne(ctx, filter, update, &updateOpt)
+		if err != nil {
+			return fmt.Errorf("Failed to verify domain: %s", err.Error())
+		}
+
+		if r.MatchedCount == 0 {
+			return fmt.Errorf("Failed to verify domain, the corporation or the domain is not exist")
+		}
+		return nil
+	}
+
+	return withContext(f)
+}
+
+func (c *client) ListCorporationDomain(claOrgID, corporationID string) ([]dbmodels.CorporationDomain, error) {
+	oid, err := toObjectID(claOrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var v corporationSigning
+
+	f := func(ctx context.Context) error {
+		b, err := c.isCorpoCLAExist(ctx, oid)
+		if err != nil {
+			return err
+		}
+		if !b {
+			return fmt.Errorf("Failed to list domains, the binding is not exist")
+		}
+
+		col := c.collection(corporationSigningCollection)
+
+		opts := options.FindOneOptions{
+			Projection: bson.M{"corporation_id": 1, "domains": 1},
+		}
+
+		sr := col.FindOne(ctx, bson.M{"cla_org_id": oid, "corporation_id": corporationID}, &opts)
+		if err := sr.Decode(&v); err != nil {
+			if err.Error() == mongo.ErrNoDocuments.Error() {
+				return nil
+			}
+			return fmt.Errorf("error decoding to bson struct of corporation signing: %v", err)
+		}
+		return nil
+	}
+
+	if err := withContext(f); err != nil {
+		return nil, err
+	}
+
+	if v.CorporationID == "" {
+		return nil, nil
+	}
+
+	r := make([]dbmodels.CorporationDomain, 0, len(v.Domains))
+	for _, d := range v.Domains {
+		r = append(r, dbmodels.CorporationDomain{
+			Domain:     d.Domain,
+			Token:      d.Token,
+			Verified:   d.Verified,
+			VerifiedAt: d.VerifiedAt,
+			CreatedAt:  d.CreatedAt,
+		})
+	}
+	return r, nil
+}
+
+func (c *client) GetCorporationIDByDomain(platform, orgID, repoID, domain string) (string, error) {
+	var v corporationSigning
+
+	f := func(ctx context.Context) error {
+		ids, err := c.corpoCLAIDsOfRepo(ctx, platform, orgID, repoID)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		col := c.collection(corporationSigningCollection)
+
+		filter := bson.M{
+			"cla_org_id": bson.M{"$in": ids},
+			"domains": bson.M{"$elemMatch": bson.M{
+				"domain":   domain,
+				"verified": true,
+			}},
+		}
+
+		opts := options.FindOneOptions{
+			Projection: bson.M{"corporation_id": 1},
+		}
+
+		sr := col.FindOne(ctx, filter, &opts)
+		if err := sr.Decode(&v); err != nil {
+			if err.Error() == mongo.ErrNoDocuments.Error() {
+				return nil
+			}
+			return fmt.Errorf("error decoding to bson struct of corporation signing: %v", err)
+		}
+		return nil
+	}
+
+	if err := withContext(f); err != nil {
+		return "", err
+	}
+
+	return v.CorporationID, nil
+}
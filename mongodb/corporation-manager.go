@@ -4,25 +4,24 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/huaweicloud/golangsdk"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/zengchen1024/cla-server/dbmodels"
 )
 
-type corporationManager struct {
-	Name          string `bson:"name"`
-	Role          string `bson:"role"`
-	Email         string `bson:"email"`
-	Password      string `bson:"password"`
-	CorporationID string `bson:"corporation_id"`
-}
+const corpoManagerCollection = "corporation_managers"
 
-func corpoManagerElemKey(field string) string {
-	return fmt.Sprintf("%s.%s", fieldCorpoManagers, field)
+type corporationManager struct {
+	CLAOrgID      primitive.ObjectID `bson:"cla_org_id"`
+	Name          string             `bson:"name,omitempty"`
+	Role          string             `bson:"role"`
+	Email         string             `bson:"email"`
+	Password      string             `bson:"password"`
+	CorporationID string             `bson:"corporation_id"`
+
+	MustChangePassword bool `bson:"must_change_password"`
 }
 
 func checkBeforeAddingCorporationManager(c *client, ctx mongo.SessionContext, claOrg dbmodels.CLAOrg, opt []dbmodels.CorporationManagerCreateOption) (int, int, error) {
@@ -31,59 +30,31 @@ func checkBeforeAddingCorporationManager(c *client, ctx mongo.SessionContext, cl
 		emails = append(emails, item.Email)
 	}
 
-	filter := bson.M{
-		"platform": claOrg.Platform,
-		"org_id":   claOrg.OrgID,
-		"repo_id":  claOrg.RepoID,
-	}
-	additionalConditionForCorpoCLADoc(filter)
-	pipeline := bson.A{
-		bson.M{"$match": filter},
-		bson.M{"$project": bson.M{
-			"role_count": bson.M{"$cond": bson.A{
-				bson.M{"$isArray": fmt.Sprintf("$%s", fieldCorpoManagers)},
-				bson.M{"$size": bson.M{"$filter": bson.M{
-					"input": fmt.Sprintf("$%s", fieldCorpoManagers),
-					"cond": bson.M{"$and": bson.A{
-						bson.M{"$eq": bson.A{"$$this.corporation_id", opt[0].CorporationID}},
-						bson.M{"$eq": bson.A{"$$this.role", opt[0].Role}},
-					}},
-				}}},
-				0,
-			}},
-			"email_count": bson.M{"$cond": bson.A{
-				bson.M{"$isArray": fmt.Sprintf("$%s", fieldCorpoManagers)},
-				bson.M{"$size": bson.M{"$filter": bson.M{
-					"input": fmt.Sprintf("$%s", fieldCorpoManagers),
-					"cond":  bson.M{"$in": bson.A{"$$this.email", emails}},
-				}}},
-				0,
-			}},
-		}},
-	}
-
-	col := c.collection(claOrgCollection)
-	cursor, err := col.Aggregate(ctx, pipeline)
+	ids, err := c.corpoCLAIDsOfRepo(ctx, claOrg.Platform, claOrg.OrgID, claOrg.RepoID)
 	if err != nil {
 		return 0, 0, err
 	}
 
-	var count []struct {
-		RoleCount  int `bson:"role_count"`
-		EmailCount int `bson:"email_count"`
-	}
-	err = cursor.All(ctx, &count)
+	col := c.collection(corpoManagerCollection)
+
+	roleCount, err := col.CountDocuments(ctx, bson.M{
+		"cla_org_id":     bson.M{"$in": ids},
+		"corporation_id": opt[0].CorporationID,
+		"role":           opt[0].Role,
+	})
 	if err != nil {
 		return 0, 0, err
 	}
 
-	roleCount := 0
-	emailCount := 0
-	for _, item := range count {
-		roleCount += item.RoleCount
-		emailCount += item.EmailCount
+	emailCount, err := col.CountDocuments(ctx, bson.M{
+		"cla_org_id": bson.M{"$in": ids},
+		"email":      bson.M{"$in": emails},
+	})
+	if err != nil {
+		return 0, 0, err
 	}
-	return roleCount, emailCount, nil
+
+	return int(roleCount), int(emailCount), nil
 }
 
 func (c *client) AddCorporationManager(claOrgID string, opt []dbmodels.CorporationManagerCreateOption, managerNumber int) error {
@@ -92,20 +63,24 @@ func (c *client) AddCorporationManager(claOrgID string, opt []dbmodels.Corporati
 		return err
 	}
 
-	updates := make(bson.A, 0, len(opt))
-	for _, item := range opt {
-		body, err := golangsdk.BuildRequestBody(item, "")
-		if err != nil {
-			return fmt.Errorf("Failed to build body for adding corporation manager, err:%v", err)
-		}
-		updates = append(updates, bson.M(body))
-	}
-
 	oid, err := toObjectID(claOrgID)
 	if err != nil {
 		return err
 	}
 
+	docs := make([]interface{}, 0, len(opt))
+	for _, item := range opt {
+		docs = append(docs, corporationManager{
+			CLAOrgID:      oid,
+			Role:          item.Role,
+			Email:         item.Email,
+			Password:      item.Password,
+			CorporationID: item.CorporationID,
+
+			MustChangePassword: item.MustChangePassword,
+		})
+	}
+
 	f := func(ctx mongo.SessionContext) error {
 		roleCount, emailCount, err := checkBeforeAddingCorporationManager(c, ctx, claOrg, opt)
 		if err != nil {
@@ -119,133 +94,147 @@ func (c *client) AddCorporationManager(claOrgID string, opt []dbmodels.Corporati
 			return fmt.Errorf("Failed to add corporation manager: there are already %d same emails", emailCount)
 		}
 
-		col := c.collection(claOrgCollection)
+		col := c.collection(corpoManagerCollection)
 
-		v, err := col.UpdateOne(
-			ctx, bson.M{"_id": oid},
-			bson.M{"$push": bson.M{fieldCorpoManagers: bson.M{"$each": updates}}},
-		)
-		if err != nil {
+		if _, err := col.InsertMany(ctx, docs); err != nil {
 			return fmt.Errorf("Failed to add corporation manager: add record failed: %s", err.Error())
 		}
-
-		if v.ModifiedCount != 1 {
-			return fmt.Errorf("Failed to add corporation manager: impossible")
-		}
 		return nil
 	}
 
 	return c.doTransaction(f)
 }
 
-func (c *client) CheckCorporationManagerExist(opt dbmodels.CorporationManagerCheckInfo) ([]dbmodels.CorporationManagerCheckResult, error) {
-	filter := bson.M{}
-	additionalConditionForCorpoCLADoc(filter)
+// managersOfCorpoCLAs returns the managers matching the filter which belong to
+// the enabled bindings of corporation cla, and the bindings keyed by id.
+func (c *client) managersOfCorpoCLAs(ctx context.Context, filter bson.M) ([]corporationManager, map[primitive.ObjectID]CLAOrg, error) {
+	cond := bson.M{}
+	additionalConditionForCorpoCLADoc(cond)
 
-	var v []CLAOrg
+	bindings, err := c.listCLAOrgs(ctx, cond)
+	if err != nil || len(bindings) == 0 {
+		return nil, nil, err
+	}
 
-	f := func(ctx context.Context) error {
-		col := c.collection(claOrgCollection)
-
-		pipeline := bson.A{
-			bson.M{"$match": filter},
-			bson.M{"$project": bson.M{
-				"platform": 1,
-				"org_id":   1,
-				"repo_id":  1,
-				fieldCorpoManagers: bson.M{"$filter": bson.M{
-					"input": fmt.Sprintf("$%s", fieldCorpoManagers),
-					"cond": bson.M{"$and": bson.A{
-						bson.M{"$eq": bson.A{"$$this.password", opt.Password}},
-						bson.M{"$or": bson.A{
-							bson.M{"$eq": bson.A{"$$this.email", opt.User}},
-							bson.M{"$eq": bson.A{"$$this.name", opt.User}},
-						}},
-					}},
-				}}},
-			},
-			bson.M{"$project": bson.M{
-				"platform":                   1,
-				"org_id":                     1,
-				"repo_id":                    1,
-				corpoManagerElemKey("role"):  1,
-				corpoManagerElemKey("email"): 1,
-			}},
-		}
+	filter["cla_org_id"] = bson.M{"$in": claOrgIDs(bindings)}
 
-		cursor, err := col.Aggregate(ctx, pipeline)
-		if err != nil {
-			return fmt.Errorf("error find bindings: %v", err)
-		}
+	cursor, err := c.collection(corpoManagerCollection).Find(ctx, filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error find corporation managers: %v", err)
+	}
+
+	var v []corporationManager
+	if err := cursor.All(ctx, &v); err != nil {
+		return nil, nil, fmt.Errorf("error decoding to bson struct of corporation manager: %v", err)
+	}
+
+	m := make(map[primitive.ObjectID]CLAOrg, len(bindings))
+	for _, item := range bindings {
+		m[item.ID] = item
+	}
+	return v, m, nil
+}
+
+func (c *client) CheckCorporationManagerExist(opt dbmodels.CorporationManagerCheckInfo) ([]dbmodels.CorporationManagerCheckResult, error) {
+	var v []corporationManager
+	var bindings map[primitive.ObjectID]CLAOrg
 
-		return cursor.All(ctx, &v)
+	f := func(ctx context.Context) error {
+		filter := bson.M{"$or": bson.A{
+			bson.M{"email": opt.User},
+			bson.M{"name": opt.User},
+		}}
+
+		var err error
+		v, bindings, err = c.managersOfCorpoCLAs(ctx, filter)
+		return err
 	}
 
 	if err := withContext(f); err != nil {
 		return nil, err
 	}
 
-	ms := []CLAOrg{}
+	count := map[primitive.ObjectID]int{}
 	for _, item := range v {
-		cm := item.CorporationManagers
-		if cm == nil || len(cm) == 0 {
-			continue
-		}
-
-		if len(cm) != 1 {
+		count[item.CLAOrgID]++
+		if count[item.CLAOrgID] > 1 {
 			return nil, fmt.Errorf(
 				"Failed to check corporation manager: there isn't only one corporation manager")
 		}
-		ms = append(ms, item)
-	}
-
-	result := make([]dbmodels.CorporationManagerCheckResult, 0, len(ms))
-	for _, item := range ms {
-		result = append(result, dbmodels.CorporationManagerCheckResult{
-			Email:    item.CorporationManagers[0].Email,
-			Role:     item.CorporationManagers[0].Role,
-			CLAOrgID: objectIDToUID(item.ID),
-			Platform: item.Platform,
-			OrgID:    item.OrgID,
-			RepoID:   item.RepoID,
-		})
+	}
+
+	result := make([]dbmodels.CorporationManagerCheckResult, 0, len(v))
+	for _, item := range v {
+		result = append(result, toDBModelCorporationManagerCheckResult(bindings[item.CLAOrgID], item))
 	}
 	return result, nil
 }
 
+func (c *client) ListAllCorporationManagers() ([]dbmodels.CorporationManagerCheckResult, error) {
+	var v []corporationManager
+	var bindings map[primitive.ObjectID]CLAOrg
+
+	f := func(ctx context.Context) error {
+		var err error
+		v, bindings, err = c.managersOfCorpoCLAs(ctx, bson.M{})
+		return err
+	}
+
+	if err := withContext(f); err != nil {
+		return nil, err
+	}
+
+	result := make([]dbmodels.CorporationManagerCheckResult, 0, len(v))
+	for _, item := range v {
+		result = append(result, toDBModelCorporationManagerCheckResult(bindings[item.CLAOrgID], item))
+	}
+	return result, nil
+}
+
+func toDBModelCorporationManagerCheckResult(claOrg CLAOrg, m corporationManager) dbmodels.CorporationManagerCheckResult {
+	return dbmodels.CorporationManagerCheckResult{
+		Email:              m.Email,
+		Role:               m.Role,
+		Password:           m.Password,
+		MustChangePassword: m.MustChangePassword,
+		CLAOrgID:           objectIDToUID(claOrg.ID),
+		Platform:           claOrg.Platform,
+		OrgID:              claOrg.OrgID,
+		RepoID:             claOrg.RepoID,
+	}
+}
+
 func (c *client) ResetCorporationManagerPassword(claOrgID string, opt dbmodels.CorporationManagerResetPassword) error {
 	oid, err := toObjectID(claOrgID)
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{"_id": oid}
-	additionalConditionForCorpoCLADoc(filter)
-
 	f := func(ctx context.Context) error {
-		col := c.collection(claOrgCollection)
-
-		update := bson.M{"$set": bson.M{fmt.Sprintf("%s.$[ms].password", fieldCorpoManagers): opt.NewPassword}}
-
-		updateOpt := options.UpdateOptions{
-			ArrayFilters: &options.ArrayFilters{
-				Filters: bson.A{
-					bson.M{
-						"ms.password": opt.OldPassword,
-						"ms.email":    opt.Email,
-					},
-				},
-			},
+		b, err := c.isCorpoCLAExist(ctx, oid)
+		if err != nil {
+			return fmt.Errorf("Failed to reset password for corporation manager: %s", err.Error())
+		}
+		if !b {
+			return fmt.Errorf("Failed to reset password for corporation manager: maybe input wrong cla_org_id.")
 		}
 
-		v, err := col.UpdateOne(ctx, filter, update, &updateOpt)
+		col := c.collection(corpoManagerCollection)
 
-		if err != nil {
-			return fmt.Errorf("Failed to reset password for corporation manager: %s", err.Error())
+		filter := bson.M{
+			"cla_org_id": oid,
+			"email":      opt.Email,
+			"password":   opt.OldPassword,
 		}
 
-		if v.MatchedCount == 0 {
-			return fmt.Errorf("Failed to reset password for corporation manager: maybe input wrong cla_org_id.")
+		update := bson.M{"$set": bson.M{
+			"password":             opt.NewPassword,
+			"must_change_password": opt.MustChangePassword,
+		}}
+
+		v, err := col.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return fmt.Errorf("Failed to reset password for corporation manager: %s", err.Error())
 		}
 
 		if v.ModifiedCount != 1 {
@@ -258,65 +247,57 @@ func (c *client) ResetCorporationManagerPassword(claOrgID string, opt dbmodels.C
 	return withContext(f)
 }
 
-func (c *client) ListCorporationManager(claOrgID string, opt dbmodels.CorporationManagerListOption) ([]dbmodels.CorporationManagerListResult, error) {
+func (c *client) ListCorporationManager(claOrgID string, opt dbmodels.CorporationManagerListOption) (dbmodels.CorporationManagerPage, error) {
+	var r dbmodels.CorporationManagerPage
+
 	oid, err := toObjectID(claOrgID)
 	if err != nil {
-		return nil, err
+		return r, err
 	}
 
-	filter := bson.M{"_id": oid}
-	additionalConditionForCorpoCLADoc(filter)
+	field, err := opt.Page.SortField(dbmodels.CorporationManagerSortFields)
+	if err != nil {
+		return r, err
+	}
 
-	var v []CLAOrg
+	keys := []sortKey{{field: field, zero: ""}}
 
-	f := func(ctx context.Context) error {
-		col := c.collection(claOrgCollection)
-
-		pipeline := bson.A{
-			bson.M{"$match": filter},
-			bson.M{"$project": bson.M{
-				fieldCorpoManagers: bson.M{"$filter": bson.M{
-					"input": fmt.Sprintf("$%s", fieldCorpoManagers),
-					"cond": bson.M{"$and": bson.A{
-						bson.M{"$eq": bson.A{"$$this.role", opt.Role}},
-						bson.M{"$eq": bson.A{"$$this.corporation_id", opt.CorporationID}},
-					}},
-				}}},
-			},
-			bson.M{"$project": bson.M{
-				corpoManagerElemKey("name"):  1,
-				corpoManagerElemKey("email"): 1,
-				corpoManagerElemKey("role"):  1,
-			}},
+	r.Managers = make([]dbmodels.CorporationManagerListResult, 0)
+	decode := func(doc bson.Raw) error {
+		var v corporationManager
+		if err := bson.Unmarshal(doc, &v); err != nil {
+			return fmt.Errorf("error decoding to bson struct of corporation manager: %v", err)
 		}
+		r.Managers = append(r.Managers, dbmodels.CorporationManagerListResult{
+			Name:  v.Name,
+			Email: v.Email,
+			Role:  v.Role,
+		})
+		return nil
+	}
 
-		cursor, err := col.Aggregate(ctx, pipeline)
+	f := func(ctx context.Context) error {
+		b, err := c.isCorpoCLAExist(ctx, oid)
 		if err != nil {
-			return fmt.Errorf("error find bindings: %v", err)
+			return err
+		}
+		if !b {
+			// check the cursor even if there is no manager
+			_, err := opt.Page.DecodeCursor(len(keys))
+			return err
 		}
 
-		return cursor.All(ctx, &v)
-	}
-
-	err = withContext(f)
-	if err != nil {
-		return nil, err
-	}
+		filter := bson.M{
+			"cla_org_id":     oid,
+			"role":           opt.Role,
+			"corporation_id": opt.CorporationID,
+		}
 
-	if len(v) == 0 {
-		return nil, nil
+		r.PageInfo, err = c.listPage(ctx, corpoManagerCollection, filter, nil, keys, opt.Page, decode)
+		return err
 	}
 
-	ms := v[0].CorporationManagers
-	r := make([]dbmodels.CorporationManagerListResult, 0, len(ms))
-	for _, item := range ms {
-		r = append(r, dbmodels.CorporationManagerListResult{
-			Name:  item.Name,
-			Email: item.Email,
-			Role:  item.Role,
-		})
-	}
-	return r, nil
+	return r, withContext(f)
 }
 
 func (c *client) ListManagersWhenEmployeeSigning(claOrgIDs []string, corporID string) ([]dbmodels.CorporationManagerListResult, error) {
@@ -329,57 +310,61 @@ func (c *client) ListManagersWhenEmployeeSigning(claOrgIDs []string, corporID st
 		ids = append(ids, oid)
 	}
 
-	filter := bson.M{"_id": bson.M{"$in": ids}}
-
-	var v []CLAOrg
+	var v []corporationManager
 
 	f := func(ctx context.Context) error {
-		col := c.collection(claOrgCollection)
-
-		pipeline := bson.A{
-			bson.M{"$match": filter},
-			bson.M{"$project": bson.M{
-				fieldCorpoManagers: bson.M{"$filter": bson.M{
-					"input": fmt.Sprintf("$%s", fieldCorpoManagers),
-					"cond":  bson.M{"$eq": bson.A{"$$this.corporation_id", corporID}},
-				}}},
-			},
-			bson.M{"$project": bson.M{
-				corpoManagerElemKey("name"):  1,
-				corpoManagerElemKey("email"): 1,
-				corpoManagerElemKey("role"):  1,
-			}},
+		bindings, err := c.listCLAOrgs(ctx, bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil || len(bindings) == 0 {
+			return err
+		}
+		if len(bindings) != 1 {
+			return fmt.Errorf("Failed to list corporation managers when employeee signing: impossible")
 		}
 
-		cursor, err := col.Aggregate(ctx, pipeline)
-		if err != nil {
-			return fmt.Errorf("error find bindings: %v", err)
+		filter := bson.M{
+			"cla_org_id":     bindings[0].ID,
+			"corporation_id": corporID,
 		}
 
-		return cursor.All(ctx, &v)
+		v, err = c.findCorporationManagers(ctx, filter)
+		return err
 	}
 
 	if err := withContext(f); err != nil {
 		return nil, err
 	}
 
-	if len(v) == 0 {
-		return nil, nil
+	return toDBModelCorporationManagerListResult(v), nil
+}
+
+func (c *client) findCorporationManagers(ctx context.Context, filter bson.M) ([]corporationManager, error) {
+	cursor, err := c.collection(corpoManagerCollection).Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error find corporation managers: %v", err)
 	}
-	if len(v) != 1 {
-		return nil, fmt.Errorf("Failed to list corporation managers when employeee signing: impossible")
+
+	v := []corporationManager{}
+	if err := cursor.All(ctx, &v); err != nil {
+		return nil, fmt.Errorf("error decoding to bson struct of corporation manager: %v", err)
 	}
+	return v, nil
+}
 
-	ms := v[0].CorporationManagers
-	r := make([]dbmodels.CorporationManagerListResult, 0, len(ms))
-	for _, item := range ms {
+// toDBModelCorporationManagerListResult returns nil if the binding is not found
+func toDBModelCorporationManagerListResult(v []corporationManager) []dbmodels.CorporationManagerListResult {
+	if v == nil {
+		return nil
+	}
+
+	r := make([]dbmodels.CorporationManagerListResult, 0, len(v))
+	for _, item := range v {
 		r = append(r, dbmodels.CorporationManagerListResult{
 			Name:  item.Name,
 			Email: item.Email,
 			Role:  item.Role,
 		})
 	}
-	return r, nil
+	return r
 }
 
 func (c *client) DeleteCorporationManager(claOrgID string, opt []dbmodels.CorporationManagerCreateOption) error {
@@ -388,31 +373,25 @@ func (c *client) DeleteCorporationManager(claOrgID string, opt []dbmodels.Corpor
 		return err
 	}
 
+	emails := make(bson.A, 0, len(opt))
+	for _, item := range opt {
+		emails = append(emails, item.Email)
+	}
+
 	f := func(ctx mongo.SessionContext) error {
-		err := checkBeforeDeletingCorporationManager(c, ctx, oid, opt)
+		err := checkBeforeDeletingCorporationManager(c, ctx, oid, opt[0].Role, emails)
 		if err != nil {
 			return fmt.Errorf("Failed to delete corporation manager: check failed: %s", err.Error())
 		}
 
-		col := c.collection(claOrgCollection)
+		col := c.collection(corpoManagerCollection)
 
-		emails := make(bson.A, 0, len(opt))
-		for _, item := range opt {
-			emails = append(emails, item.Email)
-		}
-
-		update := bson.M{"$pull": bson.M{
-			fieldCorpoManagers: bson.M{
-				"email": bson.M{"$in": emails},
-			},
-		}}
-
-		v, err := col.UpdateOne(ctx, bson.M{"_id": oid}, update)
+		v, err := col.DeleteMany(ctx, bson.M{"cla_org_id": oid, "email": bson.M{"$in": emails}})
 		if err != nil {
 			return fmt.Errorf("Failed to delete corporation manager: %s", err.Error())
 		}
 
-		if v.ModifiedCount != 1 {
+		if v.DeletedCount == 0 {
 			return fmt.Errorf("Failed to delete corporation manager: impossible.")
 		}
 
@@ -422,47 +401,25 @@ func (c *client) DeleteCorporationManager(claOrgID string, opt []dbmodels.Corpor
 	return c.doTransaction(f)
 }
 
-func checkBeforeDeletingCorporationManager(c *client, ctx mongo.SessionContext, claOrgID primitive.ObjectID, opt []dbmodels.CorporationManagerCreateOption) error {
-	emails := make(bson.A, 0, len(opt))
-	for _, item := range opt {
-		emails = append(emails, item.Email)
-	}
-
-	filter := bson.M{"_id": claOrgID}
-	additionalConditionForCorpoCLADoc(filter)
-
-	pipeline := bson.A{
-		bson.M{"$match": filter},
-		bson.M{"$project": bson.M{
-			"email_count": bson.M{"$cond": bson.A{
-				bson.M{"$isArray": fmt.Sprintf("$%s", fieldCorpoManagers)},
-				bson.M{"$size": bson.M{"$filter": bson.M{
-					"input": fmt.Sprintf("$%s", fieldCorpoManagers),
-					"cond": bson.M{"$and": bson.A{
-						bson.M{"$eq": bson.A{"$$this.role", opt[0].Role}},
-						bson.M{"$in": bson.A{"$$this.email", emails}},
-					}},
-				}}},
-				0,
-			}},
-		}},
-	}
-
-	col := c.collection(claOrgCollection)
-	cursor, err := col.Aggregate(ctx, pipeline)
+func checkBeforeDeletingCorporationManager(c *client, ctx mongo.SessionContext, claOrgID primitive.ObjectID, role string, emails bson.A) error {
+	b, err := c.isCorpoCLAExist(ctx, claOrgID)
 	if err != nil {
 		return err
 	}
 
-	var count []struct {
-		EmailCount int `bson:"email_count"`
-	}
-	err = cursor.All(ctx, &count)
-	if err != nil {
-		return err
+	n := int64(0)
+	if b {
+		n, err = c.collection(corpoManagerCollection).CountDocuments(ctx, bson.M{
+			"cla_org_id": claOrgID,
+			"role":       role,
+			"email":      bson.M{"$in": emails},
+		})
+		if err != nil {
+			return err
+		}
 	}
 
-	if len(count) == 0 || count[0].EmailCount != len(emails) {
+	if n != int64(len(emails)) {
 		return fmt.Errorf("the managers to be deleted are not all the ones registered")
 	}
 	return nil
@@ -6,20 +6,29 @@ import (
 
 	"github.com/huaweicloud/golangsdk"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/zengchen1024/cla-server/dbmodels"
 	"github.com/zengchen1024/cla-server/models"
 )
 
+const corporationSigningCollection = "corporation_signings"
+
 type corporationSigning struct {
+	CLAOrgID        primitive.ObjectID       `bson:"cla_org_id"`
 	AdminEmail      string                   `bson:"admin_email"`
 	AdminName       string                   `bson:"admin_name"`
 	CorporationName string                   `bson:"corporation_name"`
 	CorporationID   string                   `bson:"corporation_id"`
 	Enabled         bool                     `bson:"enabled"`
 	SigningInfo     dbmodels.TypeSigningInfo `bson:"info"`
+	CLAVersion      int                      `bson:"cla_version,omitempty"`
+	SignedAt        int64                    `bson:"signed_at,omitempty"`
+	Metadata        *signingMetadata         `bson:"metadata,omitempty"`
+
+	// Domains is the email domains claimed by corporation
+	Domains []corporationDomain `bson:"domains,omitempty"`
 }
 
 func additionalConditionForCorpoCLADoc(filter bson.M) {
@@ -27,10 +36,6 @@ func additionalConditionForCorpoCLADoc(filter bson.M) {
 	filter["enabled"] = true
 }
 
-func corporationsElemKey(field string) string {
-	return fmt.Sprintf("%s.%s", fieldCorporations, field)
-}
-
 func (c *client) SignAsCorporation(claOrgID string, info dbmodels.CorporationSigningInfo) error {
 	claOrg, err := c.GetBindingBetweenCLAAndOrg(claOrgID)
 	if err != nil {
@@ -42,65 +47,67 @@ func (c *client) SignAsCorporation(claOrgID string, info dbmodels.CorporationSig
 		return err
 	}
 
-	body, err := golangsdk.BuildRequestBody(info, "")
-	if err != nil {
-		return fmt.Errorf("Failed to build body for signing as corporation, err:%v", err)
-	}
-
 	f := func(ctx mongo.SessionContext) error {
-		col := c.collection(claOrgCollection)
-
-		filter := bson.M{
-			"platform": claOrg.Platform,
-			"org_id":   claOrg.OrgID,
-			"repo_id":  claOrg.RepoID,
-		}
-		additionalConditionForCorpoCLADoc(filter)
-
-		pipeline := bson.A{
-			bson.M{"$match": filter},
-			bson.M{"$project": bson.M{
-				"count": bson.M{"$cond": bson.A{
-					bson.M{"$isArray": fmt.Sprintf("$%s", fieldCorporations)},
-					bson.M{"$size": bson.M{"$filter": bson.M{
-						"input": fmt.Sprintf("$%s", fieldCorporations),
-						"cond":  bson.M{"$eq": bson.A{"$$this.corporation_id", info.CorporationID}},
-					}}},
-					0,
-				}},
-			}},
-		}
-
-		cursor, err := col.Aggregate(ctx, pipeline)
+		ids, err := c.corpoCLAIDsOfRepo(ctx, claOrg.Platform, claOrg.OrgID, claOrg.RepoID)
 		if err != nil {
 			return err
 		}
 
-		var count []struct {
-			Count int `bson:"count"`
-		}
-		err = cursor.All(ctx, &count)
+		col := c.collection(corporationSigningCollection)
+
+		cursor, err := col.Find(ctx, bson.M{
+			"cla_org_id":     bson.M{"$in": append(ids, oid)},
+			"corporation_id": info.CorporationID,
+		})
 		if err != nil {
 			return err
 		}
 
-		for _, item := range count {
-			if item.Count != 0 {
+		var v []corporationSigning
+		if err := cursor.All(ctx, &v); err != nil {
+			return fmt.Errorf("error decoding to bson struct of corporation signing: %v", err)
+		}
+
+		if len(v) > 0 {
+			// it can sign again only if the version signed is older
+			s := v[0]
+			if len(v) > 1 || s.CLAOrgID != oid || s.AdminEmail != info.AdminEmail ||
+				signedCLAVersion(s.CLAVersion) >= info.CLAVersion {
 				return fmt.Errorf("Failed to add info when signing as corporation, it has signed")
 			}
-		}
 
-		r, err := col.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$push": bson.M{fieldCorporations: bson.M(body)}})
-		if err != nil {
-			return err
+			_, err := col.UpdateOne(
+				ctx,
+				bson.M{"cla_org_id": oid, "corporation_id": info.CorporationID},
+				bson.M{"$set": bson.M{
+					"admin_name":       info.AdminName,
+					"corporation_name": info.CorporationName,
+					"info":             info.Info,
+					"cla_version":      info.CLAVersion,
+					"signed_at":        info.SignedAt,
+					"metadata":         toSigningMetadata(info.Metadata),
+				}},
+			)
+			if err != nil {
+				return fmt.Errorf("Failed to add info when signing as corporation: %s", err.Error())
+			}
+			return nil
 		}
 
-		if r.MatchedCount == 0 {
-			return fmt.Errorf("Failed to add info when signing as corporation, the cla bound to org is not exist")
+		doc := corporationSigning{
+			CLAOrgID:        oid,
+			AdminEmail:      info.AdminEmail,
+			AdminName:       info.AdminName,
+			CorporationName: info.CorporationName,
+			CorporationID:   info.CorporationID,
+			Enabled:         info.Enabled,
+			SigningInfo:     info.Info,
+			CLAVersion:      info.CLAVersion,
+			SignedAt:        info.SignedAt,
+			Metadata:        toSigningMetadata(info.Metadata),
 		}
-
-		if r.ModifiedCount == 0 {
-			return fmt.Errorf("Failed to add info when signing as corporation, impossible")
+		if _, err := col.InsertOne(ctx, doc); err != nil {
+			return fmt.Errorf("Failed to add info when signing as corporation: %s", err.Error())
 		}
 		return nil
 	}
@@ -108,77 +115,91 @@ func (c *client) SignAsCorporation(claOrgID string, info dbmodels.CorporationSig
 	return c.doTransaction(f)
 }
 
-func (c *client) ListCorporationSigning(opt dbmodels.CorporationSigningListOption) (map[string][]dbmodels.CorporationSigningDetails, error) {
+func (c *client) ListCorporationSigning(opt dbmodels.CorporationSigningListOption) (dbmodels.CorporationSigningPage, error) {
+	var r dbmodels.CorporationSigningPage
+
+	field, err := opt.Page.SortField(dbmodels.CorporationSigningSortFields)
+	if err != nil {
+		return r, err
+	}
+
 	body, err := golangsdk.BuildRequestBody(opt, "")
 	if err != nil {
-		return nil, fmt.Errorf("build options to list corporation signing failed, err:%v", err)
+		return r, fmt.Errorf("build options to list corporation signing failed, err:%v", err)
 	}
 	filter := bson.M(body)
 	additionalConditionForCorpoCLADoc(filter)
 
-	var v []CLAOrg
-
-	f := func(ctx context.Context) error {
-		col := c.collection(claOrgCollection)
-
-		pipeline := bson.A{
-			bson.M{"$match": filter},
-			bson.M{"$project": bson.M{
-				fieldCorporations: 1,
+	keys := []sortKey{
+		{field: field, zero: ""},
+		{field: "cla_org_id", zero: primitive.NilObjectID},
+		{field: "corporation_id", zero: ""},
+	}
+	if field == "signed_at" {
+		keys[0].zero = int64(0)
+	}
 
-				fieldCorpoManagers: bson.M{"$filter": bson.M{
-					"input": fmt.Sprintf("$%s", fieldCorpoManagers),
-					"cond":  bson.M{"$eq": bson.A{"$$this.role", models.RoleAdmin}},
-				}},
-			}},
-			bson.M{"$project": bson.M{
-				corporationsElemKey("corporation_name"): 1,
-				corporationsElemKey("admin_email"):      1,
-				corporationsElemKey("admin_name"):       1,
-				corporationsElemKey("enabled"):          1,
+	var v []corporationSigning
+	var admins []corporationManager
 
-				corpoManagerElemKey("email"): 1,
-			}},
+	decode := func(doc bson.Raw) error {
+		var item corporationSigning
+		if err := bson.Unmarshal(doc, &item); err != nil {
+			return fmt.Errorf("error decoding to bson struct of corporation signing: %v", err)
 		}
-		cursor, err := col.Aggregate(ctx, pipeline)
+		v = append(v, item)
+		return nil
+	}
+
+	f := func(ctx context.Context) error {
+		bindings, err := c.listCLAOrgs(ctx, filter)
 		if err != nil {
-			return fmt.Errorf("error find bindings: %v", err)
+			return err
+		}
+		if len(bindings) == 0 {
+			// check the cursor even if there is no signing
+			_, err := opt.Page.DecodeCursor(len(keys))
+			return err
+		}
+		ids := claOrgIDs(bindings)
+
+		r.PageInfo, err = c.listPage(
+			ctx, corporationSigningCollection, bson.M{"cla_org_id": bson.M{"$in": ids}},
+			nil, keys, opt.Page, decode,
+		)
+		if err != nil || len(v) == 0 {
+			return err
 		}
 
-		err = cursor.All(ctx, &v)
+		cursor, err := c.collection(corpoManagerCollection).Find(
+			ctx, bson.M{"cla_org_id": bson.M{"$in": ids}, "role": models.RoleAdmin},
+		)
 		if err != nil {
-			return fmt.Errorf("error decoding to bson struct of corporation signing: %v", err)
+			return fmt.Errorf("error find corporation managers: %v", err)
+		}
+		if err := cursor.All(ctx, &admins); err != nil {
+			return fmt.Errorf("error decoding to bson struct of corporation manager: %v", err)
 		}
 		return nil
 	}
 
-	err = withContext(f)
-	if err != nil {
-		return nil, err
+	if err := withContext(f); err != nil {
+		return r, err
 	}
 
-	r := map[string][]dbmodels.CorporationSigningDetails{}
-
-	for i := 0; i < len(v); i++ {
-		cs := v[i].Corporations
-		if cs == nil || len(cs) == 0 {
-			continue
-		}
-
-		admins := map[string]bool{}
-		for _, m := range v[i].CorporationManagers {
-			admins[m.Email] = true
-		}
-
-		cs1 := make([]dbmodels.CorporationSigningDetails, 0, len(cs))
-		for _, item := range cs {
+	enabled := map[string]bool{}
+	for _, m := range admins {
+		enabled[objectIDToUID(m.CLAOrgID)+m.Email] = true
+	}
 
-			cs1 = append(cs1, dbmodels.CorporationSigningDetails{
-				CorporationSigningInfo: toDBModelCorporationSigningInfo(item),
-				AdministratorEnabled:   admins[item.AdminEmail],
-			})
-		}
-		r[objectIDToUID(v[i].ID)] = cs1
+	r.Signings = make([]dbmodels.CorporationSigningDetails, 0, len(v))
+	for _, item := range v {
+		k := objectIDToUID(item.CLAOrgID)
+		r.Signings = append(r.Signings, dbmodels.CorporationSigningDetails{
+			CorporationSigningInfo: toDBModelCorporationSigningInfo(item),
+			AdministratorEnabled:   enabled[k+item.AdminEmail],
+			CLAOrgID:               k,
+		})
 	}
 
 	return r, nil
@@ -193,36 +214,29 @@ func (c *client) UpdateCorporationSigning(claOrgID, adminEmail, corporationName
 		return nil
 	}
 
-	info := bson.M{}
-	for k, v := range body {
-		info[fmt.Sprintf("%s.$[elem].%s", fieldCorporations, k)] = v
-	}
-
 	oid, err := toObjectID(claOrgID)
 	if err != nil {
 		return err
 	}
 
 	f := func(ctx context.Context) error {
-		col := c.collection(claOrgCollection)
-
-		filter := bson.M{"_id": oid}
-		additionalConditionForCorpoCLADoc(filter)
+		b, err := c.isCorpoCLAExist(ctx, oid)
+		if err != nil {
+			return err
+		}
+		if !b {
+			return fmt.Errorf("Failed to update corporation signing, doesn't match any record")
+		}
 
-		update := bson.M{"$set": info}
+		col := c.collection(corporationSigningCollection)
 
-		updateOpt := options.UpdateOptions{
-			ArrayFilters: &options.ArrayFilters{
-				Filters: bson.A{
-					bson.M{
-						"elem.corporation_name": corporationName,
-						"elem.admin_email":      adminEmail,
-					},
-				},
-			},
+		filter := bson.M{
+			"cla_org_id":       oid,
+			"admin_email":      adminEmail,
+			"corporation_name": corporationName,
 		}
 
-		r, err := col.UpdateOne(ctx, filter, update, &updateOpt)
+		r, err := col.UpdateOne(ctx, filter, bson.M{"$set": bson.M(body)})
 		if err != nil {
 			return err
 		}
@@ -247,5 +261,8 @@ func toDBModelCorporationSigningInfo(info corporationSigning) dbmodels.Corporati
 		AdminName:       info.AdminName,
 		Enabled:         info.Enabled,
 		Info:            info.SigningInfo,
+		CLAVersion:      signedCLAVersion(info.CLAVersion),
+		SignedAt:        info.SignedAt,
+		Metadata:        toDBModelSigningMetadata(info.Metadata),
 	}
 }
@@ -0,0 +1,110 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/huaweicloud/golangsdk"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+type emailTemplate struct {
+	Subject string `bson:"subject"`
+	Text    string `bson:"text"`
+	HTML    string `bson:"html,omitempty"`
+}
+
+func emailTemplateField(kind string) string {
+	return fmt.Sprintf("%s.%s", fieldEmailTemplates, kind)
+}
+
+func (c *client) SetEmailTemplate(claOrgID, kind string, opt dbmodels.EmailTemplate) error {
+	oid, err := toObjectID(claOrgID)
+	if err != nil {
+		return err
+	}
+
+	body, err := golangsdk.BuildRequestBody(opt, "")
+	if err != nil {
+		return fmt.Errorf("Failed to set email template: build body err:%v", err)
+	}
+
+	f := func(ctx context.Context) error {
+		col := c.collection(claOrgCollection)
+
+		filter := bson.M{"_id": oid}
+		additionalConditionForCLAOrgDoc(filter)
+
+		r, err := col.UpdateOne(ctx, filter, bson.M{"$set": bson.M{emailTemplateField(kind): bson.M(body)}})
+		if err != nil {
+			return fmt.Errorf("Failed to set email template: write db err:%v", err)
+		}
+
+		if r.MatchedCount == 0 {
+			return fmt.Errorf("Failed to set email template: the binding(%s) doesn't exist", claOrgID)
+		}
+		return nil
+	}
+
+	return withContext(f)
+}
+
+func (c *client) DeleteEmailTemplate(claOrgID, kind string) error {
+	oid, err := toObjectID(claOrgID)
+	if err != nil {
+		return err
+	}
+
+	f := func(ctx context.Context) error {
+		col := c.collection(claOrgCollection)
+
+		_, err := col.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$unset": bson.M{emailTemplateField(kind): ""}})
+		if err != nil {
+			return fmt.Errorf("Failed to delete email template: write db err:%v", err)
+		}
+		return nil
+	}
+
+	return withContext(f)
+}
+
+func (c *client) ListEmailTemplate(claOrgID string) (map[string]dbmodels.EmailTemplate, error) {
+	oid, err := toObjectID(claOrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sr *mongo.SingleResult
+
+	f := func(ctx context.Context) error {
+		col := c.collection(claOrgCollection)
+
+		opt := options.FindOneOptions{
+			Projection: bson.M{fieldEmailTemplates: 1},
+		}
+
+		sr = col.FindOne(ctx, bson.M{"_id": oid}, &opt)
+		return nil
+	}
+
+	withContext(f)
+
+	var v CLAOrg
+	if err := sr.Decode(&v); err != nil {
+		return nil, fmt.Errorf("error decoding to bson struct of CLAOrg: %v", err)
+	}
+
+	r := make(map[string]dbmodels.EmailTemplate, len(v.EmailTemplates))
+	for k, item := range v.EmailTemplates {
+		r[k] = dbmodels.EmailTemplate{
+			Subject: item.Subject,
+			Text:    item.Text,
+			HTML:    item.HTML,
+		}
+	}
+	return r, nil
+}
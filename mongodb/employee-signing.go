@@ -7,18 +7,18 @@ import (
 
 	"github.com/huaweicloud/golangsdk"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/zengchen1024/cla-server/dbmodels"
 	"github.com/zengchen1024/cla-server/models"
 )
 
-func additionalConditionForIndividualSigningDoc(filter bson.M, email string) {
+const employeeSigningCollection = "employee_signings"
+
+func additionalConditionForIndividualSigningDoc(filter bson.M) {
 	filter["apply_to"] = models.ApplyToIndividual
 	filter["enabled"] = true
-
-	filter[employeeSigningField(email)] = bson.M{"$exists": true}
 }
 
 func emailToKey(email string) string {
@@ -29,27 +29,25 @@ func emailSuffixToKey(email string) string {
 	return emailToKey(strings.Split(email, "@")[1])
 }
 
-func employeeSigningField(email string) string {
-	return fmt.Sprintf("%s.%s", fieldEmployees, emailSuffixToKey(email))
-}
-
-func employeeSigningElemField(email string) func(string) string {
-	return func(field string) string {
-		return fmt.Sprintf("%s.%s", employeeSigningField(email), field)
+// corporationIDOfEmployee returns the corporation which the employee belongs to.
+// It is the domain of email if the corporation is not specified.
+func corporationIDOfEmployee(corporationID, email string) string {
+	if corporationID != "" {
+		return corporationID
 	}
-}
-
-type employeeSignings struct {
-	// Employees is the cla signing information of employee
-	// key is the email of employee
-	Employees map[string]employeeSigning `bson:"employees,omitempty"`
+	return emailSuffixToKey(email)
 }
 
 type employeeSigning struct {
-	Name        string                   `bson:"name"`
-	Email       string                   `bson:"email"`
-	Enabled     bool                     `bson:"enabled"`
-	SigningInfo dbmodels.TypeSigningInfo `bson:"signing_info"`
+	CLAOrgID      primitive.ObjectID       `bson:"cla_org_id"`
+	CorporationID string                   `bson:"corporation_id"`
+	Name          string                   `bson:"name"`
+	Email         string                   `bson:"email"`
+	Enabled       bool                     `bson:"enabled"`
+	SigningInfo   dbmodels.TypeSigningInfo `bson:"signing_info"`
+	SignedAt      int64                    `bson:"signed_at"`
+	CLAVersion    int                      `bson:"cla_version,omitempty"`
+	Metadata      *signingMetadata         `bson:"metadata,omitempty"`
 }
 
 func (c *client) SignAsEmployee(claOrgID string, info dbmodels.EmployeeSigningInfo) error {
@@ -63,67 +61,57 @@ func (c *client) SignAsEmployee(claOrgID string, info dbmodels.EmployeeSigningIn
 		return err
 	}
 
-	body, err := golangsdk.BuildRequestBody(info, "")
-	if err != nil {
-		return fmt.Errorf("Failed to build body for signing as corporation, err:%v", err)
-	}
-
-	field := employeeSigningField(info.Email)
+	corporationID := corporationIDOfEmployee(info.CorporationID, info.Email)
 
 	f := func(ctx mongo.SessionContext) error {
-		col := c.collection(claOrgCollection)
-
-		filter := bson.M{
-			"platform": claOrg.Platform,
-			"org_id":   claOrg.OrgID,
-			"repo_id":  claOrg.RepoID,
-		}
-		additionalConditionForIndividualSigningDoc(filter, info.Email)
-
-		pipeline := bson.A{
-			bson.M{"$match": filter},
-			bson.M{"$project": bson.M{
-				"count": bson.M{"$cond": bson.A{
-					bson.M{"$isArray": fmt.Sprintf("$%s", field)},
-					bson.M{"$size": bson.M{"$filter": bson.M{
-						"input": fmt.Sprintf("$%s", field),
-						"cond":  bson.M{"$eq": bson.A{"$$this.email", info.Email}},
-					}}},
-					0,
-				}},
-			}},
-		}
-
-		cursor, err := col.Aggregate(ctx, pipeline)
+		filter := repoFilter(claOrg.Platform, claOrg.OrgID, claOrg.RepoID)
+		additionalConditionForIndividualSigningDoc(filter)
+
+		bindings, err := c.listCLAOrgs(ctx, filter)
 		if err != nil {
 			return err
 		}
 
-		var count []struct {
-			Count int `bson:"count"`
+		col := c.collection(employeeSigningCollection)
+
+		// count the signings of the version or newer one, and
+		// the employee can sign again if the version signed is older.
+		versions := bson.A{bson.M{"cla_version": bson.M{"$gte": info.CLAVersion}}}
+		if info.CLAVersion <= 1 {
+			versions = append(versions, bson.M{"cla_version": bson.M{"$exists": false}})
 		}
-		err = cursor.All(ctx, &count)
+
+		n, err := col.CountDocuments(ctx, bson.M{
+			"cla_org_id":     bson.M{"$in": claOrgIDs(bindings)},
+			"corporation_id": corporationID,
+			"email":          info.Email,
+			"$or":            versions,
+		})
 		if err != nil {
 			return err
 		}
-
-		for _, item := range count {
-			if item.Count != 0 {
-				return fmt.Errorf("Failed to sign as employee, it has signed")
-			}
+		if n != 0 {
+			return fmt.Errorf("Failed to sign as employee, it has signed")
 		}
 
-		r, err := col.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$push": bson.M{field: bson.M(body)}})
-		if err != nil {
+		resigned, err := c.resignAsEmployee(ctx, oid, corporationID, info)
+		if err != nil || resigned {
 			return err
 		}
 
-		if r.MatchedCount == 0 {
-			return fmt.Errorf("Failed to sign as employee, the cla bound to org is not exist")
+		doc := employeeSigning{
+			CLAOrgID:      oid,
+			CorporationID: corporationID,
+			Name:          info.Name,
+			Email:         info.Email,
+			Enabled:       info.Enabled,
+			SigningInfo:   info.Info,
+			SignedAt:      info.SignedAt,
+			CLAVersion:    info.CLAVersion,
+			Metadata:      toSigningMetadata(info.Metadata),
 		}
-
-		if r.ModifiedCount == 0 {
-			return fmt.Errorf("Failed to sign as employee, impossible")
+		if _, err := col.InsertOne(ctx, doc); err != nil {
+			return fmt.Errorf("Failed to sign as employee: %s", err.Error())
 		}
 		return nil
 	}
@@ -131,104 +119,124 @@ func (c *client) SignAsEmployee(claOrgID string, info dbmodels.EmployeeSigningIn
 	return c.doTransaction(f)
 }
 
-func (c *client) ListEmployeeSigning(opt dbmodels.EmployeeSigningListOption) (map[string][]dbmodels.EmployeeSigningInfo, error) {
-	body, err := golangsdk.BuildRequestBody(opt, "")
-	if err != nil {
-		return nil, fmt.Errorf("build options to list employee signing failed, err:%v", err)
-	}
-	filter := bson.M(body)
-	additionalConditionForIndividualSigningDoc(filter, opt.CorporationEmail)
+// resignAsEmployee updates the signing of older version in place.
+// It returns false if the employee has not signed.
+func (c *client) resignAsEmployee(ctx mongo.SessionContext, oid primitive.ObjectID, corporationID string, info dbmodels.EmployeeSigningInfo) (bool, error) {
+	col := c.collection(employeeSigningCollection)
 
-	var v []CLAOrg
+	update := bson.M{"$set": bson.M{
+		"name":         info.Name,
+		"signing_info": info.Info,
+		"signed_at":    info.SignedAt,
+		"cla_version":  info.CLAVersion,
+		"metadata":     toSigningMetadata(info.Metadata),
+	}}
 
-	f := func(ctx context.Context) error {
-		col := c.collection(claOrgCollection)
+	filter := bson.M{"cla_org_id": oid, "corporation_id": corporationID, "email": info.Email}
 
-		fieldFunc := employeeSigningElemField(opt.CorporationEmail)
+	r, err := col.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("Failed to sign as employee again: %s", err.Error())
+	}
+	return r.MatchedCount > 0, nil
+}
 
-		pipeline := bson.A{
-			bson.M{"$match": filter},
-			bson.M{"$project": bson.M{
-				fieldFunc("email"):   1,
-				fieldFunc("name"):    1,
-				fieldFunc("enabled"): 1,
-			}},
-		}
-		cursor, err := col.Aggregate(ctx, pipeline)
-		if err != nil {
-			return fmt.Errorf("error find bindings: %v", err)
-		}
+func (c *client) ListEmployeeSigning(opt dbmodels.EmployeeSigningListOption) (dbmodels.EmployeeSigningPage, error) {
+	var r dbmodels.EmployeeSigningPage
 
-		err = cursor.All(ctx, &v)
-		if err != nil {
-			return fmt.Errorf("error decoding to bson struct of employee signing: %v", err)
-		}
-		return nil
+	field, err := opt.Page.SortField(dbmodels.EmployeeSigningSortFields)
+	if err != nil {
+		return r, err
 	}
 
-	err = withContext(f)
+	body, err := golangsdk.BuildRequestBody(opt, "")
 	if err != nil {
-		return nil, err
+		return r, fmt.Errorf("build options to list employee signing failed, err:%v", err)
 	}
+	filter := bson.M(body)
+	additionalConditionForIndividualSigningDoc(filter)
 
-	r := map[string][]dbmodels.EmployeeSigningInfo{}
+	corporationID := emailSuffixToKey(opt.CorporationEmail)
 
-	suffix := emailSuffixToKey(opt.CorporationEmail)
+	keys := []sortKey{
+		{field: field, zero: ""},
+		{field: "cla_org_id", zero: primitive.NilObjectID},
+		{field: "email", zero: ""},
+	}
+	if field == "signed_at" {
+		keys[0].zero = int64(0)
+	}
 
-	for i := 0; i < len(v); i++ {
-		m := v[i].Employees
-		if m == nil || len(m) == 0 {
-			continue
+	r.Signings = make([]dbmodels.EmployeeSigningDetail, 0)
+	decode := func(doc bson.Raw) error {
+		var item employeeSigning
+		if err := bson.Unmarshal(doc, &item); err != nil {
+			return fmt.Errorf("error decoding to bson struct of employee signing: %v", err)
 		}
 
-		es, ok := m[suffix]
-		if !ok || len(es) == 0 {
-			continue
+		r.Signings = append(r.Signings, dbmodels.EmployeeSigningDetail{
+			EmployeeSigningInfo: toDBModelEmployeeSigningInfo(item),
+			CLAOrgID:            objectIDToUID(item.CLAOrgID),
+		})
+		return nil
+	}
+
+	f := func(ctx context.Context) error {
+		bindings, err := c.listCLAOrgs(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if len(bindings) == 0 {
+			// check the cursor even if there is no signing
+			_, err := opt.Page.DecodeCursor(len(keys))
+			return err
 		}
 
-		es1 := make([]dbmodels.EmployeeSigningInfo, 0, len(es))
-		for _, item := range es {
-			es1 = append(es1, toDBModelEmployeeSigningInfo(item))
+		cond := bson.M{
+			"cla_org_id":     bson.M{"$in": claOrgIDs(bindings)},
+			"corporation_id": corporationID,
 		}
-		r[objectIDToUID(v[i].ID)] = es1
+
+		r.PageInfo, err = c.listPage(ctx, employeeSigningCollection, cond, nil, keys, opt.Page, decode)
+		return err
 	}
 
-	return r, nil
+	return r, withContext(f)
 }
+
 func (c *client) UpdateEmployeeSigning(claOrgID, email string, opt dbmodels.EmployeeSigningUpdateInfo) error {
 	oid, err := toObjectID(claOrgID)
 	if err != nil {
 		return err
 	}
 
-	f := func(ctx context.Context) error {
-		col := c.collection(claOrgCollection)
+	corporationID := corporationIDOfEmployee(opt.CorporationID, email)
 
+	f := func(ctx context.Context) error {
 		filter := bson.M{"_id": oid}
-		additionalConditionForIndividualSigningDoc(filter, email)
-
-		field := employeeSigningField(email)
-
-		update := bson.M{"$set": bson.M{fmt.Sprintf("%s.$[ms].enabled", field): opt.Enabled}}
+		additionalConditionForIndividualSigningDoc(filter)
 
-		updateOpt := options.UpdateOptions{
-			ArrayFilters: &options.ArrayFilters{
-				Filters: bson.A{
-					bson.M{
-						"ms.enabled": !opt.Enabled,
-						"ms.email":   email,
-					},
-				},
-			},
+		b, err := c.isCLAOrgExist(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if !b {
+			return fmt.Errorf("Failed to update employee signing, the cla which employee had signed is not exist")
 		}
 
-		r, err := col.UpdateOne(ctx, filter, update, &updateOpt)
+		col := c.collection(employeeSigningCollection)
+
+		r, err := col.UpdateOne(
+			ctx,
+			bson.M{"cla_org_id": oid, "corporation_id": corporationID, "email": email},
+			bson.M{"$set": bson.M{"enabled": opt.Enabled}},
+		)
 		if err != nil {
 			return fmt.Errorf("Failed to update employee signing: %s", err.Error())
 		}
 
 		if r.MatchedCount == 0 {
-			return fmt.Errorf("Failed to update employee signing, the cla which employee had signed is not exist")
+			return fmt.Errorf("Failed to update employee signing, the employee has not signed")
 		}
 
 		if r.ModifiedCount == 0 {
@@ -242,8 +250,12 @@ func (c *client) UpdateEmployeeSigning(claOrgID, email string, opt dbmodels.Empl
 
 func toDBModelEmployeeSigningInfo(item employeeSigning) dbmodels.EmployeeSigningInfo {
 	return dbmodels.EmployeeSigningInfo{
-		Email:   item.Email,
-		Name:    item.Name,
-		Enabled: item.Enabled,
+		Email:    item.Email,
+		Name:     item.Name,
+		Enabled:  item.Enabled,
+		SignedAt: item.SignedAt,
+
+		CLAVersion: signedCLAVersion(item.CLAVersion),
+		Metadata:   toDBModelSigningMetadata(item.Metadata),
 	}
 }
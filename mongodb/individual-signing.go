@@ -3,15 +3,30 @@ package mongodb
 import (
 	"context"
 	"fmt"
-	"strings"
-
-	"github.com/zengchen1024/cla-server/dbmodels"
+	"regexp"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
 )
 
-func individualSigningKey(email string) string {
-	return fmt.Sprintf("%s.%s", fieldIndividuals, strings.ReplaceAll(email, ".", "_"))
+const individualSigningCollection = "individual_signings"
+
+type individualSigning struct {
+	CLAOrgID primitive.ObjectID       `bson:"cla_org_id"`
+	Email    string                   `bson:"email"`
+	Info     dbmodels.TypeSigningInfo `bson:"info"`
+	SignedAt int64                    `bson:"signed_at"`
+
+	// CLAVersion is the version of cla accepted. It is absent for the
+	// signing done before versioning, which means version 1.
+	CLAVersion int `bson:"cla_version,omitempty"`
+
+	Metadata *signingMetadata `bson:"metadata,omitempty"`
 }
 
 func (c *client) SignAsIndividual(claOrgID string, info dbmodels.IndividualSigningInfo) error {
@@ -20,27 +35,319 @@ func (c *client) SignAsIndividual(claOrgID string, info dbmodels.IndividualSigni
 		return err
 	}
 
+	f := func(ctx mongo.SessionContext) error {
+		b, err := c.isCLAOrgExist(ctx, bson.M{"_id": oid})
+		if err != nil {
+			return err
+		}
+		if !b {
+			return fmt.Errorf("Failed to add info when signing as individual, the cla bound to org is not exist")
+		}
+
+		col := c.collection(individualSigningCollection)
+
+		filter := bson.M{"cla_org_id": oid, "email": info.Email}
+
+		var v individualSigning
+		if err := col.FindOne(ctx, filter).Decode(&v); err != nil {
+			if err.Error() != mongo.ErrNoDocuments.Error() {
+				return fmt.Errorf("error decoding to bson struct of individual signing: %v", err)
+			}
+		} else if signedCLAVersion(v.CLAVersion) >= info.CLAVersion {
+			// it can sign again only if the version signed is older
+			return fmt.Errorf("Failed to add info when signing as individual, maybe he/she has signed")
+		}
+
+		doc := individualSigning{
+			CLAOrgID:   oid,
+			Email:      info.Email,
+			Info:       info.Info,
+			SignedAt:   info.SignedAt,
+			CLAVersion: info.CLAVersion,
+			Metadata:   toSigningMetadata(info.Metadata),
+		}
+
+		upsert := true
+		_, err = col.ReplaceOne(ctx, filter, doc, &options.ReplaceOptions{Upsert: &upsert})
+		if err != nil {
+			return fmt.Errorf("Failed to add info when signing as individual: %s", err.Error())
+		}
+		return nil
+	}
+
+	return c.doTransaction(f)
+}
+
+func (c *client) CheckSigning(opt dbmodels.SigningCheckOption) (*dbmodels.SigningCheckResult, error) {
+	filter := bson.M{
+		"platform": opt.Platform,
+		"org_id":   opt.OrgID,
+		"apply_to": models.ApplyToIndividual,
+		"enabled":  true,
+	}
+
+	corporationID := corporationIDOfEmployee(opt.CorporationID, opt.Email)
+
+	var v []CLAOrg
+	var individuals []individualSigning
+	var employees []employeeSigning
+
 	f := func(ctx context.Context) error {
-		col := c.collection(claOrgCollection)
+		bindings, err := c.listCLAOrgs(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		v = bindingsOfRepo(bindings, opt.RepoID)
+		if len(v) == 0 {
+			return nil
+		}
+		ids := claOrgIDs(v)
 
-		k := individualSigningKey(info.Email)
-		v := bson.M{k: info.Info}
+		cursor, err := c.collection(individualSigningCollection).Find(
+			ctx, bson.M{"cla_org_id": bson.M{"$in": ids}, "email": opt.Email},
+		)
+		if err != nil {
+			return fmt.Errorf("error find individual signings: %v", err)
+		}
+		if err := cursor.All(ctx, &individuals); err != nil {
+			return fmt.Errorf("error decoding to bson struct of individual signing: %v", err)
+		}
 
-		r, err := col.UpdateOne(ctx, bson.M{"_id": oid, k: bson.M{"$exists": false}}, bson.M{"$set": v})
+		cursor, err = c.collection(employeeSigningCollection).Find(
+			ctx, bson.M{
+				"cla_org_id":     bson.M{"$in": ids},
+				"corporation_id": corporationID,
+				"email":          opt.Email,
+				"enabled":        true,
+			},
+		)
 		if err != nil {
+			return fmt.Errorf("error find employee signings: %v", err)
+		}
+		if err := cursor.All(ctx, &employees); err != nil {
+			return fmt.Errorf("error decoding to bson struct of employee signing: %v", err)
+		}
+		return nil
+	}
+
+	if err := withContext(f); err != nil {
+		return nil, err
+	}
+
+	for _, item := range v {
+		for _, s := range individuals {
+			if s.CLAOrgID != item.ID {
+				continue
+			}
+
+			return &dbmodels.SigningCheckResult{
+				Type:        models.ApplyToIndividual,
+				CLAOrgID:    objectIDToUID(item.ID),
+				CLAID:       item.CLAID,
+				CLALanguage: item.CLALanguage,
+				SignedAt:    s.SignedAt,
+
+				CLAVersion:        signedCLAVersion(s.CLAVersion),
+				CurrentCLAVersion: bindingCLAVersion(item),
+			}, nil
+		}
+	}
+
+	for _, item := range v {
+		for _, e := range employees {
+			if e.CLAOrgID != item.ID {
+				continue
+			}
+
+			corp, err := c.enabledCorporationSigning(opt.Platform, opt.OrgID, item.RepoID, corporationID)
+			if err != nil {
+				return nil, err
+			}
+			if corp == nil {
+				continue
+			}
+
+			return &dbmodels.SigningCheckResult{
+				Type:        models.ApplyToCorporation,
+				CLAOrgID:    objectIDToUID(item.ID),
+				CLAID:       item.CLAID,
+				CLALanguage: item.CLALanguage,
+				SignedAt:    e.SignedAt,
+
+				CLAVersion:        signedCLAVersion(e.CLAVersion),
+				CurrentCLAVersion: bindingCLAVersion(item),
+				Corporation:       corp,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// signedCLAVersion returns the version of cla signed, and the signing
+// done before versioning is treated as version 1.
+func signedCLAVersion(v int) int {
+	if v == 0 {
+		return 1
+	}
+	return v
+}
+
+// bindingsOfRepo returns the bindings of repo if there are, otherwise the ones of org.
+func bindingsOfRepo(v []CLAOrg, repoID string) []CLAOrg {
+	r := make([]CLAOrg, 0, len(v))
+	if repoID != "" {
+		for _, item := range v {
+			if item.RepoID == repoID {
+				r = append(r, item)
+			}
+		}
+		if len(r) != 0 {
+			return r
+		}
+	}
+
+	for _, item := range v {
+		if item.RepoID == "" {
+			r = append(r, item)
+		}
+	}
+	return r
+}
+
+// enabledCorporationSigning returns the cla signed by the corporation if it
+// has signed and been enabled, otherwise nil.
+func (c *client) enabledCorporationSigning(platform, orgID, repoID, corporationID string) (*dbmodels.CorporationSigningCheckResult, error) {
+	var r *dbmodels.CorporationSigningCheckResult
+
+	f := func(ctx context.Context) error {
+		filter := repoFilter(platform, orgID, repoID)
+		additionalConditionForCorpoCLADoc(filter)
+
+		bindings, err := c.listCLAOrgs(ctx, filter)
+		if err != nil || len(bindings) == 0 {
 			return err
 		}
 
-		if r.MatchedCount == 0 {
-			return fmt.Errorf("Failed to add info when signing as individual, maybe he/she has signed")
+		var v corporationSigning
+		err = c.collection(corporationSigningCollection).FindOne(ctx, bson.M{
+			"cla_org_id":     bson.M{"$in": claOrgIDs(bindings)},
+			"corporation_id": corporationID,
+			"enabled":        true,
+		}).Decode(&v)
+		if err != nil {
+			if err.Error() == mongo.ErrNoDocuments.Error() {
+				return nil
+			}
+			return fmt.Errorf("error find corporation signing: %v", err)
+		}
+
+		for _, item := range bindings {
+			if item.ID == v.CLAOrgID {
+				r = &dbmodels.CorporationSigningCheckResult{
+					CLAOrgID:          objectIDToUID(item.ID),
+					CLAID:             item.CLAID,
+					CLAVersion:        signedCLAVersion(v.CLAVersion),
+					CurrentCLAVersion: bindingCLAVersion(item),
+				}
+			}
+		}
+		return nil
+	}
+
+	return r, withContext(f)
+}
+
+func (c *client) ListIndividualSigning(opt dbmodels.IndividualSigningListOption) (dbmodels.IndividualSigningPage, error) {
+	var r dbmodels.IndividualSigningPage
+
+	field, err := opt.Page.SortField(dbmodels.IndividualSigningSortFields)
+	if err != nil {
+		return r, err
+	}
+
+	filter := bson.M{
+		"platform": opt.Platform,
+		"org_id":   opt.OrgID,
+		"apply_to": models.ApplyToIndividual,
+		"enabled":  true,
+	}
+	if opt.RepoID != "" {
+		filter["repo_id"] = opt.RepoID
+	}
+	if opt.CLALanguage != "" {
+		filter["cla_language"] = opt.CLALanguage
+	}
+
+	cond := bson.M{}
+	if opt.EmailContains != "" {
+		cond["email"] = bson.M{"$regex": regexp.QuoteMeta(opt.EmailContains), "$options": "i"}
+	}
+	if opt.SignedFrom > 0 || opt.SignedTo > 0 {
+		t := bson.M{}
+		if opt.SignedFrom > 0 {
+			t["$gte"] = opt.SignedFrom
 		}
+		if opt.SignedTo > 0 {
+			t["$lte"] = opt.SignedTo
+		}
+		cond["signed_at"] = t
+	}
+
+	keys := []sortKey{
+		{field: field, zero: ""},
+		{field: "cla_org_id", zero: primitive.NilObjectID},
+		{field: "email", zero: ""},
+	}
+	if field == "signed_at" {
+		keys[0].zero = int64(0)
+	}
 
-		if r.ModifiedCount == 0 {
-			return fmt.Errorf("Failed to add info when signing as individual, impossible")
+	bindings := map[primitive.ObjectID]CLAOrg{}
 
+	r.Signings = make([]dbmodels.IndividualSigningDetail, 0)
+	decode := func(doc bson.Raw) error {
+		var s individualSigning
+		if err := bson.Unmarshal(doc, &s); err != nil {
+			return fmt.Errorf("error decoding to bson struct of individual signing: %v", err)
 		}
+
+		b := bindings[s.CLAOrgID]
+		r.Signings = append(r.Signings, dbmodels.IndividualSigningDetail{
+			IndividualSigningInfo: dbmodels.IndividualSigningInfo{
+				Email:      s.Email,
+				Info:       s.Info,
+				SignedAt:   s.SignedAt,
+				CLAVersion: signedCLAVersion(s.CLAVersion),
+				Metadata:   toDBModelSigningMetadata(s.Metadata),
+			},
+			CLAOrgID:    objectIDToUID(s.CLAOrgID),
+			RepoID:      b.RepoID,
+			CLALanguage: b.CLALanguage,
+		})
 		return nil
 	}
 
-	return withContext(f)
+	f := func(ctx context.Context) error {
+		v, err := c.listCLAOrgs(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if len(v) == 0 {
+			// check the cursor even if there is no signing
+			_, err := opt.Page.DecodeCursor(len(keys))
+			return err
+		}
+
+		for _, item := range v {
+			bindings[item.ID] = item
+		}
+		cond["cla_org_id"] = bson.M{"$in": claOrgIDs(v)}
+
+		r.PageInfo, err = c.listPage(ctx, individualSigningCollection, cond, nil, keys, opt.Page, decode)
+		return err
+	}
+
+	return r, withContext(f)
 }
@@ -0,0 +1,227 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/huaweicloud/golangsdk"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+const jobCollection = "jobs"
+
+type job struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Kind          string             `bson:"kind"`
+	CLAOrgID      string             `bson:"cla_org_id"`
+	CorporationID string             `bson:"corporation_id"`
+	Email         string             `bson:"email"`
+	Payload       string             `bson:"payload"`
+	Status        string             `bson:"status"`
+	Attempts      int                `bson:"attempts"`
+	MaxAttempts   int                `bson:"max_attempts"`
+	NextRunAt     int64              `bson:"next_run_at"`
+	LockedUntil   int64              `bson:"locked_until"`
+	LastError     string             `bson:"last_error"`
+	CreatedAt     int64              `bson:"created_at"`
+	UpdatedAt     int64              `bson:"updated_at"`
+	ClaimToken    string             `bson:"claim_token"`
+}
+
+func (c *client) CreateJob(info dbmodels.Job) (string, error) {
+	body, err := golangsdk.BuildRequestBody(info, "")
+	if err != nil {
+		return "", fmt.Errorf("Failed to build body for creating job, err:%v", err)
+	}
+	delete(body, "id")
+
+	var r *mongo.InsertOneResult
+
+	f := func(ctx context.Context) error {
+		col := c.collection(jobCollection)
+
+		r, err = col.InsertOne(ctx, bson.M(body))
+		if err != nil {
+			return fmt.Errorf("Failed to create job: write db err:%v", err)
+		}
+		return nil
+	}
+
+	if err := withContext(f); err != nil {
+		return "", err
+	}
+
+	return toUID(r.InsertedID)
+}
+
+func (c *client) ClaimJob(now, lockedUntil int64, claimToken string) (*dbmodels.Job, error) {
+	var sr *mongo.SingleResult
+
+	f := func(ctx context.Context) error {
+		col := c.collection(jobCollection)
+
+		// A running job whose lock has expired was left by a crashed worker,
+		// so it can be picked up again.
+		filter := bson.M{"$or": bson.A{
+			bson.M{"status": models.JobStatusPending, "next_run_at": bson.M{"$lte": now}},
+			bson.M{"status": models.JobStatusRunning, "locked_until": bson.M{"$lt": now}},
+		}}
+
+		update := bson.M{
+			"$set": bson.M{
+				"status":       models.JobStatusRunning,
+				"locked_until": lockedUntil,
+				"claim_token":  claimToken,
+				"updated_at":   now,
+			},
+			"$inc": bson.M{"attempts": 1},
+		}
+
+		after := options.After
+		opt := options.FindOneAndUpdateOptions{
+			Sort:           bson.M{"next_run_at": 1},
+			ReturnDocument: &after,
+		}
+
+		sr = col.FindOneAndUpdate(ctx, filter, update, &opt)
+		return nil
+	}
+
+	withContext(f)
+
+	var v job
+	if err := sr.Decode(&v); err != nil {
+		if err.Error() == mongo.ErrNoDocuments.Error() {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Failed to claim job: %s", err.Error())
+	}
+
+	r := toDBModelJob(v)
+	return &r, nil
+}
+
+func (c *client) UpdateJob(uid string, opt dbmodels.JobUpdateInfo) error {
+	body, err := golangsdk.BuildRequestBody(opt, "")
+	if err != nil {
+		return fmt.Errorf("Failed to build options for updating job, err:%v", err)
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	body["updated_at"] = time.Now().Unix()
+
+	oid, err := toObjectID(uid)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": oid}
+	if len(opt.ExpectedStatus) != 0 {
+		filter["status"] = bson.M{"$in": opt.ExpectedStatus}
+	}
+	if opt.ClaimToken != "" {
+		filter["claim_token"] = opt.ClaimToken
+	}
+
+	f := func(ctx context.Context) error {
+		col := c.collection(jobCollection)
+
+		r, err := col.UpdateOne(ctx, filter, bson.M{"$set": bson.M(body)})
+		if err != nil {
+			return fmt.Errorf("Failed to update job: %s", err.Error())
+		}
+
+		if r.MatchedCount == 0 {
+			return fmt.Errorf("Failed to update job, the job is not exist, its status is not in %v or it has been claimed by others", opt.ExpectedStatus)
+		}
+		return nil
+	}
+
+	return withContext(f)
+}
+
+func (c *client) GetJob(uid string) (dbmodels.Job, error) {
+	oid, err := toObjectID(uid)
+	if err != nil {
+		return dbmodels.Job{}, err
+	}
+
+	var sr *mongo.SingleResult
+
+	f := func(ctx context.Context) error {
+		col := c.collection(jobCollection)
+
+		sr = col.FindOne(ctx, bson.M{"_id": oid})
+		return nil
+	}
+
+	withContext(f)
+
+	var v job
+	if err := sr.Decode(&v); err != nil {
+		return dbmodels.Job{}, fmt.Errorf("error decoding to bson struct of job: %v", err)
+	}
+
+	return toDBModelJob(v), nil
+}
+
+func (c *client) ListJob(opt dbmodels.JobListOption) (dbmodels.JobPage, error) {
+	var r dbmodels.JobPage
+
+	if _, err := opt.Page.SortField(dbmodels.JobSortFields); err != nil {
+		return r, err
+	}
+
+	body, err := golangsdk.BuildRequestBody(opt, "")
+	if err != nil {
+		return r, fmt.Errorf("build options to list job failed, err:%v", err)
+	}
+
+	keys := []sortKey{{field: "created_at", zero: int64(0)}, {field: "_id", zero: primitive.NilObjectID}}
+
+	r.Jobs = make([]dbmodels.Job, 0)
+	decode := func(doc bson.Raw) error {
+		var v job
+		if err := bson.Unmarshal(doc, &v); err != nil {
+			return fmt.Errorf("error decoding to bson struct of job: %v", err)
+		}
+		r.Jobs = append(r.Jobs, toDBModelJob(v))
+		return nil
+	}
+
+	f := func(ctx context.Context) error {
+		var err error
+		r.PageInfo, err = c.listPage(ctx, jobCollection, bson.M(body), nil, keys, opt.Page, decode)
+		return err
+	}
+
+	return r, withContext(f)
+}
+
+func toDBModelJob(item job) dbmodels.Job {
+	return dbmodels.Job{
+		ID:            objectIDToUID(item.ID),
+		Kind:          item.Kind,
+		CLAOrgID:      item.CLAOrgID,
+		CorporationID: item.CorporationID,
+		Email:         item.Email,
+		Payload:       item.Payload,
+		Status:        item.Status,
+		Attempts:      item.Attempts,
+		MaxAttempts:   item.MaxAttempts,
+		NextRunAt:     item.NextRunAt,
+		LockedUntil:   item.LockedUntil,
+		LastError:     item.LastError,
+		CreatedAt:     item.CreatedAt,
+		UpdatedAt:     item.UpdatedAt,
+		ClaimToken:    item.ClaimToken,
+	}
+}
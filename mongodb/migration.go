@@ -0,0 +1,284 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The fields of cla_orgs which kept the signings before they were moved to
+// the separate collections. They are only used to migrate the old documents.
+const (
+	legacyFieldIndividuals     = "individuals"
+	legacyFieldEmployees       = "employees"
+	legacyFieldCorporations    = "corporations"
+	legacyFieldCorpoManagers   = "corporation_managers"
+	legacyFieldOrgSignature    = "org_signature"
+	legacyFieldOrgSignatureTag = "org_signature_uploaded"
+	legacyFieldRevokedSignings = "revoked_signings"
+)
+
+func indexKeys(keys ...string) bson.D {
+	d := make(bson.D, 0, len(keys))
+	for _, k := range keys {
+		d = append(d, bson.E{Key: k, Value: 1})
+	}
+	return d
+}
+
+func uniqueIndex(keys ...string) mongo.IndexModel {
+	return mongo.IndexModel{Keys: indexKeys(keys...), Options: options.Index().SetUnique(true)}
+}
+
+func index(keys ...string) mongo.IndexModel {
+	return mongo.IndexModel{Keys: indexKeys(keys...)}
+}
+
+// ensureIndexes creates the indexes which the queries rely on and the unique
+// ones which keep a single signing per binding. It also creates the collections
+// which must exist before being written in a transaction.
+func (c *client) ensureIndexes() error {
+	indexes := map[string][]mongo.IndexModel{
+		claOrgCollection: {
+			index("platform", "org_id", "repo_id"),
+			{
+				Keys: indexKeys("platform", "org_id", "repo_id", "cla_language", "apply_to"),
+				Options: options.Index().SetUnique(true).SetPartialFilterExpression(
+					bson.M{"enabled": true},
+				),
+			},
+		},
+		individualSigningCollection: {
+			uniqueIndex("cla_org_id", "email"),
+			index("email"),
+		},
+		employeeSigningCollection: {
+			uniqueIndex("cla_org_id", "corporation_id", "email"),
+		},
+		corporationSigningCollection: {
+			uniqueIndex("cla_org_id", "corporation_id"),
+			index("domains.domain"),
+		},
+		corpoManagerCollection: {
+			uniqueIndex("cla_org_id", "email"),
+			index("email"),
+			{Keys: indexKeys("name"), Options: options.Index().SetSparse(true)},
+		},
+		orgSignatureCollection: {
+			uniqueIndex("cla_org_id"),
+		},
+		revokedSigningCollection: {
+			index("cla_org_id"),
+		},
+	}
+
+	for name, models := range indexes {
+		f := func(ctx context.Context) error {
+			_, err := c.collection(name).Indexes().CreateMany(ctx, models)
+			return err
+		}
+
+		if err := withContext(f); err != nil {
+			return fmt.Errorf("Failed to create indexes of %s: %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+// legacyCLAOrg is the document of cla_orgs which still embeds the signings.
+// The embedded items are kept as they are, so that the fields written by the
+// older versions are moved without loss.
+type legacyCLAOrg struct {
+	ID primitive.ObjectID `bson:"_id"`
+
+	Individuals          map[string]bson.M   `bson:"individuals,omitempty"`
+	Employees            map[string][]bson.M `bson:"employees,omitempty"`
+	Corporations         []bson.M            `bson:"corporations,omitempty"`
+	CorporationManagers  []bson.M            `bson:"corporation_managers,omitempty"`
+	OrgSignatureUploaded bool                `bson:"org_signature_uploaded"`
+	OrgSignature         []byte              `bson:"org_signature"`
+	RevokedSignings      []bson.M            `bson:"revoked_signings,omitempty"`
+}
+
+// legacyIndividualSigning returns the individual signing embedded at key of
+// individuals. The baseline versions kept only the signing info there, so the
+// email is recovered from the value of info which is mapped to key. It returns
+// false if the email can't be recovered.
+func legacyIndividualSigning(key string, item bson.M) (bson.M, bool) {
+	if email, ok := item["email"].(string); ok && email != "" {
+		return item, true
+	}
+
+	for _, v := range item {
+		if email, ok := v.(string); ok && strings.Contains(email, "@") && emailToKey(email) == key {
+			return bson.M{"email": email, "info": item}, true
+		}
+	}
+	return nil, false
+}
+
+// migrateCLAOrgs moves the signings embedded in the documents of cla_orgs to
+// the separate collections. Each document is migrated in a transaction, and
+// the embedded fields are removed at the end, so it is safe to run it again.
+// The individual signings which can't be migrated are left in the bindings.
+func (c *client) migrateCLAOrgs() error {
+	legacyFields := []string{
+		legacyFieldIndividuals, legacyFieldEmployees, legacyFieldCorporations,
+		legacyFieldCorpoManagers, legacyFieldOrgSignature, legacyFieldOrgSignatureTag,
+		legacyFieldRevokedSignings,
+	}
+
+	cond := make(bson.A, 0, len(legacyFields))
+	for _, field := range legacyFields {
+		cond = append(cond, bson.M{field: bson.M{"$exists": true}})
+	}
+
+	var ids []primitive.ObjectID
+
+	f := func(ctx context.Context) error {
+		col := c.collection(claOrgCollection)
+
+		cursor, err := col.Find(ctx, bson.M{"$or": cond}, &options.FindOptions{
+			Projection: bson.M{"_id": 1},
+		})
+		if err != nil {
+			return fmt.Errorf("error find bindings to migrate: %v", err)
+		}
+
+		var v []struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.All(ctx, &v); err != nil {
+			return fmt.Errorf("error decoding to bson struct of CLAOrg: %v", err)
+		}
+
+		for _, item := range v {
+			ids = append(ids, item.ID)
+		}
+		return nil
+	}
+
+	if err := withContext(f); err != nil {
+		return err
+	}
+
+	unset := bson.M{}
+	for _, field := range legacyFields {
+		unset[field] = ""
+	}
+
+	for _, oid := range ids {
+		if err := c.migrateCLAOrg(oid, unset); err != nil {
+			return fmt.Errorf("Failed to migrate the binding(%s): %s", objectIDToUID(oid), err.Error())
+		}
+	}
+	return nil
+}
+
+func (c *client) migrateCLAOrg(oid primitive.ObjectID, unset bson.M) error {
+	f := func(ctx mongo.SessionContext) error {
+		col := c.collection(claOrgCollection)
+
+		var v legacyCLAOrg
+		if err := col.FindOne(ctx, bson.M{"_id": oid}).Decode(&v); err != nil {
+			return fmt.Errorf("error decoding to bson struct of CLAOrg: %v", err)
+		}
+
+		upsert := func(name string, items []bson.M, keys ...string) error {
+			if len(items) == 0 {
+				return nil
+			}
+
+			models := make([]mongo.WriteModel, 0, len(items))
+			for _, item := range items {
+				item["cla_org_id"] = oid
+
+				filter := bson.M{}
+				for _, k := range keys {
+					filter[k] = item[k]
+				}
+
+				models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(
+					bson.M{"$setOnInsert": item},
+				).SetUpsert(true))
+			}
+
+			_, err := c.collection(name).BulkWrite(ctx, models)
+			return err
+		}
+
+		// the individual signings whose email can't be recovered are kept
+		// in the binding, so that they can be fixed by hand later.
+		update := unset
+		individuals := make([]bson.M, 0, len(v.Individuals))
+		migrated := make([]string, 0, len(v.Individuals))
+		for key, item := range v.Individuals {
+			if doc, ok := legacyIndividualSigning(key, item); ok {
+				individuals = append(individuals, doc)
+				migrated = append(migrated, key)
+			}
+		}
+		if len(migrated) < len(v.Individuals) {
+			update = bson.M{}
+			for k := range unset {
+				if k != legacyFieldIndividuals {
+					update[k] = ""
+				}
+			}
+			for _, key := range migrated {
+				update[fmt.Sprintf("%s.%s", legacyFieldIndividuals, key)] = ""
+			}
+		}
+		if err := upsert(individualSigningCollection, individuals, "cla_org_id", "email"); err != nil {
+			return err
+		}
+
+		employees := []bson.M{}
+		for corporationID, items := range v.Employees {
+			for _, item := range items {
+				item["corporation_id"] = corporationID
+				employees = append(employees, item)
+			}
+		}
+		if err := upsert(employeeSigningCollection, employees, "cla_org_id", "corporation_id", "email"); err != nil {
+			return err
+		}
+
+		if err := upsert(corporationSigningCollection, v.Corporations, "cla_org_id", "corporation_id"); err != nil {
+			return err
+		}
+
+		if err := upsert(corpoManagerCollection, v.CorporationManagers, "cla_org_id", "email"); err != nil {
+			return err
+		}
+
+		if v.OrgSignatureUploaded {
+			sig := []bson.M{{"pdf": v.OrgSignature}}
+			if err := upsert(orgSignatureCollection, sig, "cla_org_id"); err != nil {
+				return err
+			}
+		}
+
+		if len(v.RevokedSignings) > 0 {
+			docs := make([]interface{}, 0, len(v.RevokedSignings))
+			for _, item := range v.RevokedSignings {
+				item["cla_org_id"] = oid
+				docs = append(docs, item)
+			}
+
+			if _, err := c.collection(revokedSigningCollection).InsertMany(ctx, docs); err != nil {
+				return err
+			}
+		}
+
+		_, err := col.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$unset": update})
+		return err
+	}
+
+	return c.doTransaction(f)
+}
@@ -33,6 +33,14 @@ func RegisterDatabase(conn, db string) (*client, error) {
 		c:  c,
 		db: c.Database(db),
 	}
+
+	if err := cli.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	if err := cli.migrateCLAOrgs(); err != nil {
+		return nil, err
+	}
 	return cli, nil
 }
 
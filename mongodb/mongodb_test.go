@@ -0,0 +1,148 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/dbmodels/conformance"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+// The suite needs a replica set of mongodb because of the transactions, for example:
+// CLA_TEST_MONGODB_CONN=mongodb://localhost:27017/?replicaSet=rs0 go test ./mongodb
+func TestConformance(t *testing.T) {
+	conn := os.Getenv("CLA_TEST_MONGODB_CONN")
+	if conn == "" {
+		t.Skip("CLA_TEST_MONGODB_CONN is not set")
+	}
+
+	n := 0
+	conformance.Run(t, func(t *testing.T) conformance.DB {
+		n++
+		return newTestClient(t, conn, fmt.Sprintf("cla_conformance_%d_%d", time.Now().Unix(), n))
+	})
+}
+
+func newTestClient(t *testing.T, conn, db string) *client {
+	c, err := RegisterDatabase(conn, db)
+	if err != nil {
+		t.Fatalf("connect to mongodb: %v", err)
+	}
+
+	t.Cleanup(func() {
+		c.db.Drop(context.Background())
+		c.Close()
+	})
+	return c
+}
+
+func TestMigrateCLAOrgs(t *testing.T) {
+	conn := os.Getenv("CLA_TEST_MONGODB_CONN")
+	if conn == "" {
+		t.Skip("CLA_TEST_MONGODB_CONN is not set")
+	}
+
+	c := newTestClient(t, conn, fmt.Sprintf("cla_migration_%d", time.Now().Unix()))
+
+	ind := primitive.NewObjectID()
+	corp := primitive.NewObjectID()
+	binding := func(id primitive.ObjectID, applyTo string) bson.M {
+		return bson.M{
+			"_id": id, "platform": "github", "org_id": "org", "repo_id": "", "cla_id": "cla",
+			"cla_language": applyTo, "apply_to": applyTo, "enabled": true, "submitter": "owner",
+		}
+	}
+
+	doc := binding(ind, models.ApplyToIndividual)
+	doc["individuals"] = bson.M{
+		"a@a_com": bson.M{"email": "a@a.com", "signed_at": int64(1)},
+		// the shape of baseline which has only the info
+		"c@c_com": bson.M{"1": "c", "2": "c@c.com"},
+		"d@d_com": bson.M{"1": "d"},
+	}
+	doc["employees"] = bson.M{"example_com": bson.A{
+		bson.M{"email": "e@example.com", "name": "e", "enabled": true, "signed_at": int64(2)},
+	}}
+	doc["revoked_signings"] = bson.A{bson.M{"type": "individual", "email": "b@b.com", "revoked_at": int64(3)}}
+
+	doc1 := binding(corp, models.ApplyToCorporation)
+	doc1["corporations"] = bson.A{bson.M{
+		"admin_email": "admin@example.com", "corporation_name": "example",
+		"corporation_id": "example_com", "enabled": true,
+	}}
+	doc1["corporation_managers"] = bson.A{bson.M{
+		"role": models.RoleAdmin, "email": "admin@example.com", "password": "pw", "corporation_id": "example_com",
+	}}
+	doc1["org_signature"] = []byte("pdf")
+	doc1["org_signature_uploaded"] = true
+
+	_, err := c.collection(claOrgCollection).InsertMany(context.Background(), []interface{}{doc, doc1})
+	if err != nil {
+		t.Fatalf("insert legacy documents: %v", err)
+	}
+
+	// the second run must do nothing
+	for i := 0; i < 2; i++ {
+		if err := c.migrateCLAOrgs(); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+	}
+
+	r, err := c.CheckSigning(dbmodels.SigningCheckOption{Platform: "github", OrgID: "org", Email: "a@a.com"})
+	if err != nil || r == nil || r.SignedAt != 1 {
+		t.Errorf("check individual signing: unexpected result: %+v, %v", r, err)
+	}
+
+	r, err = c.CheckSigning(dbmodels.SigningCheckOption{Platform: "github", OrgID: "org", Email: "c@c.com"})
+	if err != nil || r == nil || r.Type != models.ApplyToIndividual {
+		t.Errorf("check individual signing of baseline: unexpected result: %+v, %v", r, err)
+	}
+
+	r, err = c.CheckSigning(dbmodels.SigningCheckOption{Platform: "github", OrgID: "org", Email: "e@example.com"})
+	if err != nil || r == nil || r.Type != models.ApplyToCorporation || r.SignedAt != 2 {
+		t.Errorf("check employee signing: unexpected result: %+v, %v", r, err)
+	}
+
+	rs, err := c.ListRevokedSigning(ind.Hex())
+	if err != nil || len(rs) != 1 || rs[0].Email != "b@b.com" {
+		t.Errorf("list revoked signings: unexpected result: %+v, %v", rs, err)
+	}
+
+	ms, err := c.ListAllCorporationManagers()
+	if err != nil || len(ms) != 1 || ms[0].CLAOrgID != corp.Hex() {
+		t.Errorf("list corporation managers: unexpected result: %+v, %v", ms, err)
+	}
+
+	pdf, err := c.DownloadOrgSignature(corp.Hex())
+	if err != nil || string(pdf) != "pdf" {
+		t.Errorf("download org signature: unexpected result: %s, %v", pdf, err)
+	}
+
+	n, err := c.collection(individualSigningCollection).CountDocuments(
+		context.Background(), bson.M{"cla_org_id": ind},
+	)
+	if err != nil || n != 2 {
+		t.Errorf("migrate individual signings: expect 2, but got %d, %v", n, err)
+	}
+
+	// the signing whose email can't be recovered is kept
+	var v legacyCLAOrg
+	err = c.collection(claOrgCollection).FindOne(context.Background(), bson.M{"_id": ind}).Decode(&v)
+	if err != nil || len(v.Individuals) != 1 || v.Individuals["d@d_com"] == nil || len(v.Employees) != 0 {
+		t.Errorf("the embedded signings are not removed as expected: %+v, %v", v, err)
+	}
+
+	n, err = c.collection(claOrgCollection).CountDocuments(
+		context.Background(), bson.M{legacyFieldEmployees: bson.M{"$exists": true}},
+	)
+	if err != nil || n != 0 {
+		t.Errorf("the embedded signings are not removed: %d, %v", n, err)
+	}
+}
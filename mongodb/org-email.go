@@ -21,6 +21,8 @@ type OrgEmail struct {
 	Email    string             `bson:"email"`
 	Platform string             `bson:"platform"`
 	Token    []byte             `bson:"token"`
+
+	Submitter string `bson:"submitter,omitempty"`
 }
 
 func (c *client) CreateOrgEmail(opt dbmodels.OrgEmailCreateInfo) error {
@@ -34,13 +36,25 @@ func (c *client) CreateOrgEmail(opt dbmodels.OrgEmailCreateInfo) error {
 		return fmt.Errorf("Failed to create org email info: marshal token err:%v", err)
 	}
 	body["token"] = string(token)
+	body["submitter"] = opt.Submitter
 
-	f := func(ctx context.Context) error {
+	f := func(ctx mongo.SessionContext) error {
 		col := c.collection(orgEmailCollection)
 
 		filter := bson.M{"email": opt.Email}
+
+		var v OrgEmail
+		if err := col.FindOne(ctx, filter).Decode(&v); err != nil {
+			if err.Error() != mongo.ErrNoDocuments.Error() {
+				return fmt.Errorf("Failed to create org email info: read db err:%v", err)
+			}
+		} else if dbmodels.OrgEmailOwner(toDBModelOrgEmail(v)) != opt.Submitter {
+			return fmt.Errorf("Failed to create org email info: it was added by others")
+		}
+
 		upsert := true
-		update := bson.M{"$setOnInsert": bson.M(body)}
+		// update the credential if the email has been added by the same submitter
+		update := bson.M{"$set": bson.M(body)}
 
 		r, err := col.UpdateOne(ctx, filter, update, &options.UpdateOptions{Upsert: &upsert})
 		if err != nil {
@@ -54,7 +68,7 @@ func (c *client) CreateOrgEmail(opt dbmodels.OrgEmailCreateInfo) error {
 		return nil
 	}
 
-	return withContext(f)
+	return c.doTransaction(f)
 }
 
 func (c *client) GetOrgEmailInfo(email string) (dbmodels.OrgEmailCreateInfo, error) {
@@ -62,11 +76,8 @@ func (c *client) GetOrgEmailInfo(email string) (dbmodels.OrgEmailCreateInfo, err
 
 	f := func(ctx context.Context) error {
 		col := c.db.Collection(orgEmailCollection)
-		opt := options.FindOneOptions{
-			Projection: bson.M{"email": 0},
-		}
 
-		sr = col.FindOne(ctx, bson.M{"email": email}, &opt)
+		sr = col.FindOne(ctx, bson.M{"email": email})
 		return nil
 	}
 
@@ -93,7 +104,9 @@ func (c *client) GetOrgEmailInfo(email string) (dbmodels.OrgEmailCreateInfo, err
 
 func toDBModelOrgEmail(item OrgEmail) dbmodels.OrgEmailCreateInfo {
 	return dbmodels.OrgEmailCreateInfo{
-		Platform: item.Platform,
-		Token:    item.Token,
+		Email:     item.Email,
+		Platform:  item.Platform,
+		Token:     item.Token,
+		Submitter: item.Submitter,
 	}
 }
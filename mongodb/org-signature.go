@@ -5,10 +5,18 @@ import (
 	"fmt"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+const orgSignatureCollection = "org_signatures"
+
+type orgSignature struct {
+	CLAOrgID primitive.ObjectID `bson:"cla_org_id"`
+	PDF      []byte             `bson:"pdf"`
+}
+
 func (c *client) UploadOrgSignature(claOrgID string, pdf []byte) error {
 	oid, err := toObjectID(claOrgID)
 	if err != nil {
@@ -16,13 +24,14 @@ func (c *client) UploadOrgSignature(claOrgID string, pdf []byte) error {
 	}
 
 	f := func(ctx context.Context) error {
-		col := c.collection(claOrgCollection)
+		col := c.collection(orgSignatureCollection)
 
-		v := bson.M{
-			fieldOrgSignature:    pdf,
-			fieldOrgSignatureTag: true,
-		}
-		_, err := col.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$set": v})
+		upsert := true
+		_, err := col.ReplaceOne(
+			ctx, bson.M{"cla_org_id": oid},
+			orgSignature{CLAOrgID: oid, PDF: pdf},
+			&options.ReplaceOptions{Upsert: &upsert},
+		)
 		return err
 	}
 
@@ -38,25 +47,19 @@ func (c *client) DownloadOrgSignature(claOrgID string) ([]byte, error) {
 	var sr *mongo.SingleResult
 
 	f := func(ctx context.Context) error {
-		col := c.collection(claOrgCollection)
-
-		opt := options.FindOneOptions{
-			Projection: bson.M{
-				fieldOrgSignature: 1,
-			},
-		}
+		col := c.collection(orgSignatureCollection)
 
-		sr = col.FindOne(ctx, bson.M{"_id": oid, fieldOrgSignatureTag: true}, &opt)
+		sr = col.FindOne(ctx, bson.M{"cla_org_id": oid})
 		return nil
 	}
 
 	withContext(f)
 
-	var v CLAOrg
+	var v orgSignature
 	err = sr.Decode(&v)
 	if err != nil {
-		return nil, fmt.Errorf("error decoding to bson struct of CLAOrg: %v", err)
+		return nil, fmt.Errorf("error decoding to bson struct of org signature: %v", err)
 	}
 
-	return v.OrgSignature, nil
+	return v.PDF, nil
 }
@@ -0,0 +1,175 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+// sortKey is the field which the documents are sorted by. The document
+// without the field is sorted as the field is zero.
+type sortKey struct {
+	field string
+	zero  interface{}
+}
+
+func sortKeyName(i int) string {
+	return fmt.Sprintf("_sort_key%d", i)
+}
+
+// cursorCondition returns the condition of the documents after the cursor.
+func cursorCondition(keys []sortKey, cursor []interface{}, op string) (bson.M, error) {
+	v := make(bson.A, 0, len(cursor))
+	for i, k := range keys {
+		switch k.zero.(type) {
+		case primitive.ObjectID:
+			s, ok := cursor[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid cursor")
+			}
+
+			oid, err := toObjectID(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cursor")
+			}
+			v = append(v, oid)
+
+		case int64:
+			if _, ok := cursor[i].(int64); !ok {
+				return nil, fmt.Errorf("invalid cursor")
+			}
+			v = append(v, cursor[i])
+
+		default:
+			if _, ok := cursor[i].(string); !ok {
+				return nil, fmt.Errorf("invalid cursor")
+			}
+			v = append(v, cursor[i])
+		}
+	}
+
+	cond := make(bson.A, 0, len(keys))
+	for i := range keys {
+		m := bson.M{sortKeyName(i): bson.M{op: v[i]}}
+		for j := 0; j < i; j++ {
+			m[sortKeyName(j)] = v[j]
+		}
+		cond = append(cond, m)
+	}
+	return bson.M{"$or": cond}, nil
+}
+
+// cursorOfDoc returns the values of sort keys of the document
+func cursorOfDoc(doc bson.Raw, n int) []interface{} {
+	r := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v := doc.Lookup(sortKeyName(i))
+
+		switch v.Type {
+		case bsontype.ObjectID:
+			r = append(r, objectIDToUID(v.ObjectID()))
+		case bsontype.String:
+			r = append(r, v.StringValue())
+		default:
+			x, _ := v.AsInt64OK()
+			r = append(r, x)
+		}
+	}
+	return r
+}
+
+// listPage lists the documents of collection matched by filter in the order of
+// keys, and calls decode for each document of the page. The fields in project
+// are excluded from the documents if it is not nil.
+func (c *client) listPage(
+	ctx context.Context, name string, filter, project bson.M,
+	keys []sortKey, page dbmodels.PageOption, decode func(bson.Raw) error,
+) (dbmodels.PageInfo, error) {
+	var info dbmodels.PageInfo
+
+	cursor, err := page.DecodeCursor(len(keys))
+	if err != nil {
+		return info, err
+	}
+
+	op, dir := "$gt", 1
+	if page.Desc {
+		op, dir = "$lt", -1
+	}
+
+	fields := bson.M{}
+	order := make(bson.D, 0, len(keys))
+	for i, k := range keys {
+		fields[sortKeyName(i)] = bson.M{"$ifNull": bson.A{"$" + k.field, k.zero}}
+		order = append(order, bson.E{Key: sortKeyName(i), Value: dir})
+	}
+
+	items := bson.A{}
+	if cursor != nil {
+		cond, err := cursorCondition(keys, cursor, op)
+		if err != nil {
+			return info, err
+		}
+		items = append(items, bson.M{"$match": cond})
+	}
+	items = append(items, bson.M{"$sort": order})
+	if page.Limit > 0 {
+		// one more document tells whether there is next page
+		items = append(items, bson.M{"$limit": page.Limit + 1})
+	}
+
+	pipeline := bson.A{bson.M{"$match": filter}}
+	if project != nil {
+		pipeline = append(pipeline, bson.M{"$project": project})
+	}
+	pipeline = append(
+		pipeline,
+		bson.M{"$addFields": fields},
+		bson.M{"$facet": bson.M{
+			"total": bson.A{bson.M{"$count": "n"}},
+			"items": items,
+		}},
+	)
+
+	var v []struct {
+		Total []struct {
+			N int `bson:"n"`
+		} `bson:"total"`
+
+		Items []bson.Raw `bson:"items"`
+	}
+
+	r, err := c.collection(name).Aggregate(ctx, pipeline)
+	if err != nil {
+		return info, fmt.Errorf("error find %s: %v", name, err)
+	}
+	if err := r.All(ctx, &v); err != nil {
+		return info, fmt.Errorf("error decoding the page of %s: %v", name, err)
+	}
+
+	if len(v) == 0 {
+		return info, nil
+	}
+
+	if len(v[0].Total) > 0 {
+		info.Total = v[0].Total[0].N
+	}
+
+	docs := v[0].Items
+	if page.Limit > 0 && len(docs) > page.Limit {
+		docs = docs[:page.Limit]
+		info.NextCursor = page.EncodeCursor(cursorOfDoc(docs[len(docs)-1], len(keys))...)
+	}
+
+	for _, item := range docs {
+		if err := decode(item); err != nil {
+			return info, err
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,63 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/huaweicloud/golangsdk"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+const platformTokenCollection = "platform_tokens"
+
+type platformToken struct {
+	User  string `bson:"user"`
+	Token string `bson:"token"`
+}
+
+func (c *client) SavePlatformToken(opt dbmodels.PlatformToken) error {
+	body, err := golangsdk.BuildRequestBody(opt, "")
+	if err != nil {
+		return fmt.Errorf("Failed to save platform token: build body err:%v", err)
+	}
+
+	f := func(ctx context.Context) error {
+		col := c.collection(platformTokenCollection)
+
+		upsert := true
+		_, err := col.UpdateOne(
+			ctx, bson.M{"user": opt.User}, bson.M{"$set": bson.M(body)},
+			&options.UpdateOptions{Upsert: &upsert},
+		)
+		if err != nil {
+			return fmt.Errorf("Failed to save platform token: write db err:%v", err)
+		}
+		return nil
+	}
+
+	return withContext(f)
+}
+
+func (c *client) GetPlatformToken(user string) (dbmodels.PlatformToken, error) {
+	var sr *mongo.SingleResult
+
+	f := func(ctx context.Context) error {
+		col := c.collection(platformTokenCollection)
+
+		sr = col.FindOne(ctx, bson.M{"user": user})
+		return nil
+	}
+
+	withContext(f)
+
+	var v platformToken
+	if err := sr.Decode(&v); err != nil {
+		return dbmodels.PlatformToken{}, fmt.Errorf("error decoding to bson struct of platform token: %v", err)
+	}
+
+	return dbmodels.PlatformToken{User: v.User, Token: v.Token}, nil
+}
@@ -0,0 +1,37 @@
+package mongodb
+
+import "github.com/zengchen1024/cla-server/dbmodels"
+
+type signingMetadata struct {
+	SignedBy  string `bson:"signed_by,omitempty"`
+	IP        string `bson:"ip,omitempty"`
+	UserAgent string `bson:"user_agent,omitempty"`
+	CLAHash   string `bson:"cla_hash,omitempty"`
+}
+
+// toDBModelSigningMetadata returns nil for the signing done before the metadata was recorded
+func toDBModelSigningMetadata(m *signingMetadata) *dbmodels.SigningMetadata {
+	if m == nil {
+		return nil
+	}
+
+	return &dbmodels.SigningMetadata{
+		SignedBy:  m.SignedBy,
+		IP:        m.IP,
+		UserAgent: m.UserAgent,
+		CLAHash:   m.CLAHash,
+	}
+}
+
+func toSigningMetadata(m *dbmodels.SigningMetadata) *signingMetadata {
+	if m == nil {
+		return nil
+	}
+
+	return &signingMetadata{
+		SignedBy:  m.SignedBy,
+		IP:        m.IP,
+		UserAgent: m.UserAgent,
+		CLAHash:   m.CLAHash,
+	}
+}
@@ -0,0 +1,156 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+const revokedSigningCollection = "revoked_signings"
+
+const (
+	revokedSigningTypeIndividual = "individual"
+	revokedSigningTypeEmployee   = "employee"
+)
+
+type revokedSigning struct {
+	CLAOrgID   primitive.ObjectID `bson:"cla_org_id"`
+	Type       string             `bson:"type"`
+	Email      string             `bson:"email"`
+	SignedAt   int64              `bson:"signed_at"`
+	CLAVersion int                `bson:"cla_version"`
+	RevokedBy  string             `bson:"revoked_by"`
+	Reason     string             `bson:"reason"`
+	RevokedAt  int64              `bson:"revoked_at"`
+}
+
+func newRevokedSigning(claOrgID primitive.ObjectID, t string, signedAt int64, claVersion int, opt dbmodels.SigningRevocation) revokedSigning {
+	return revokedSigning{
+		CLAOrgID:   claOrgID,
+		Type:       t,
+		Email:      opt.Email,
+		SignedAt:   signedAt,
+		CLAVersion: signedCLAVersion(claVersion),
+		RevokedBy:  opt.RevokedBy,
+		Reason:     opt.Reason,
+		RevokedAt:  opt.RevokedAt,
+	}
+}
+
+func (c *client) RevokeIndividualSigning(claOrgID string, opt dbmodels.SigningRevocation) error {
+	oid, err := toObjectID(claOrgID)
+	if err != nil {
+		return err
+	}
+
+	f := func(ctx mongo.SessionContext) error {
+		col := c.collection(individualSigningCollection)
+
+		var v individualSigning
+		sr := col.FindOneAndDelete(ctx, bson.M{"cla_org_id": oid, "email": opt.Email})
+		if err := sr.Decode(&v); err != nil {
+			if err.Error() == mongo.ErrNoDocuments.Error() {
+				return fmt.Errorf("Failed to revoke individual signing, he/she has not signed")
+			}
+			return fmt.Errorf("error decoding to bson struct of individual signing: %v", err)
+		}
+
+		tombstone := newRevokedSigning(oid, revokedSigningTypeIndividual, v.SignedAt, v.CLAVersion, opt)
+		if _, err := c.collection(revokedSigningCollection).InsertOne(ctx, tombstone); err != nil {
+			return fmt.Errorf("Failed to revoke individual signing: %s", err.Error())
+		}
+		return nil
+	}
+
+	return c.doTransaction(f)
+}
+
+func (c *client) RevokeEmployeeSigning(claOrgID string, opt dbmodels.SigningRevocation) error {
+	oid, err := toObjectID(claOrgID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{
+		"cla_org_id":     oid,
+		"corporation_id": corporationIDOfEmployee(opt.CorporationID, opt.Email),
+		"email":          opt.Email,
+	}
+
+	f := func(ctx mongo.SessionContext) error {
+		col := c.collection(employeeSigningCollection)
+
+		var v employeeSigning
+		if err := col.FindOneAndDelete(ctx, filter).Decode(&v); err != nil {
+			if err.Error() == mongo.ErrNoDocuments.Error() {
+				return fmt.Errorf("Failed to revoke employee signing, he/she has not signed")
+			}
+			return fmt.Errorf("error decoding to bson struct of employee signing: %v", err)
+		}
+
+		tombstone := newRevokedSigning(oid, revokedSigningTypeEmployee, v.SignedAt, v.CLAVersion, opt)
+		if _, err := c.collection(revokedSigningCollection).InsertOne(ctx, tombstone); err != nil {
+			return fmt.Errorf("Failed to revoke employee signing: %s", err.Error())
+		}
+		return nil
+	}
+
+	return c.doTransaction(f)
+}
+
+func (c *client) ListRevokedSigning(claOrgID string) ([]dbmodels.RevokedSigning, error) {
+	oid, err := toObjectID(claOrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var v []revokedSigning
+
+	f := func(ctx context.Context) error {
+		b, err := c.isCLAOrgExist(ctx, bson.M{"_id": oid})
+		if err != nil {
+			return err
+		}
+		if !b {
+			return fmt.Errorf("Failed to list revoked signings, the binding is not exist")
+		}
+
+		col := c.collection(revokedSigningCollection)
+
+		cursor, err := col.Find(ctx, bson.M{"cla_org_id": oid}, &options.FindOptions{
+			Sort: bson.M{"_id": 1},
+		})
+		if err != nil {
+			return fmt.Errorf("error find revoked signings: %v", err)
+		}
+
+		if err := cursor.All(ctx, &v); err != nil {
+			return fmt.Errorf("error decoding to bson struct of revoked signing: %v", err)
+		}
+		return nil
+	}
+
+	if err := withContext(f); err != nil {
+		return nil, err
+	}
+
+	r := make([]dbmodels.RevokedSigning, 0, len(v))
+	for _, item := range v {
+		r = append(r, dbmodels.RevokedSigning{
+			Type:       item.Type,
+			Email:      item.Email,
+			SignedAt:   item.SignedAt,
+			CLAVersion: item.CLAVersion,
+			RevokedBy:  item.RevokedBy,
+			Reason:     item.Reason,
+			RevokedAt:  item.RevokedAt,
+		})
+	}
+	return r, nil
+}
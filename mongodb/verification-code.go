@@ -7,6 +7,7 @@ import (
 
 	"github.com/huaweicloud/golangsdk"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
@@ -15,6 +16,13 @@ import (
 
 const verifCodeCollection = "verification_codes"
 
+type verificationCode struct {
+	ID       primitive.ObjectID `bson:"_id"`
+	Code     string             `bson:"code"`
+	Expiry   int64              `bson:"expiry"`
+	Attempts int                `bson:"attempts"`
+}
+
 func (c *client) CreateVerificationCode(opt dbmodels.VerificationCode) error {
 	body, err := golangsdk.BuildRequestBody(opt, "")
 	if err != nil {
@@ -24,8 +32,19 @@ func (c *client) CreateVerificationCode(opt dbmodels.VerificationCode) error {
 	f := func(ctx mongo.SessionContext) error {
 		col := c.collection(verifCodeCollection)
 
-		// delete the old codes, including unused ones.
+		// delete the old codes, including unused ones. The attempts of the
+		// unexpired one are kept, so resending can't reset the limit.
 		filter := bson.M{"email": opt.Email, "purpose": opt.Purpose}
+
+		var old verificationCode
+		err := col.FindOne(
+			ctx, bson.M{"email": opt.Email, "purpose": opt.Purpose, "expiry": bson.M{"$gte": opt.CreatedAt}},
+		).Decode(&old)
+		if err != nil && err.Error() != mongo.ErrNoDocuments.Error() {
+			return fmt.Errorf("Failed to create verification code: %s", err.Error())
+		}
+		body["attempts"] = old.Attempts
+
 		col.DeleteMany(ctx, filter)
 
 		upsert := true
@@ -47,37 +66,72 @@ func (c *client) CreateVerificationCode(opt dbmodels.VerificationCode) error {
 	return c.doTransaction(f)
 }
 
-func (c *client) CheckVerificationCode(opt dbmodels.VerificationCode) (bool, error) {
+func (c *client) CheckVerificationCode(opt dbmodels.VerificationCode, maxAttempts int) (bool, error) {
 	valid := false
 
 	f := func(ctx context.Context) error {
 		col := c.collection(verifCodeCollection)
 
+		// count the attempt first, so the concurrent guesses can't exceed the limit.
 		filter := bson.M{
-			"email":   opt.Email,
-			"purpose": opt.Purpose,
-			"code":    opt.Code,
-		}
-		opt := options.FindOneAndDeleteOptions{
-			Projection: bson.M{"expiry": 1},
+			"email":    opt.Email,
+			"purpose":  opt.Purpose,
+			"attempts": bson.M{"$not": bson.M{"$gte": maxAttempts}},
 		}
+		update := bson.M{"$inc": bson.M{"attempts": 1}}
 
-		r := col.FindOneAndDelete(ctx, filter, &opt)
+		r := col.FindOneAndUpdate(ctx, filter, update)
 
-		var v struct {
-			Expiry int64 `bson:"expiry"`
-		}
+		var v verificationCode
 		if err := r.Decode(&v); err != nil {
 			if err.Error() == mongo.ErrNoDocuments.Error() {
 				return nil
 			}
 
-			return fmt.Errorf("Failed to check verification code: %s", r.Err().Error())
+			return fmt.Errorf("Failed to check verification code: %s", err.Error())
 		}
 
-		valid = (v.Expiry >= time.Now().Unix())
+		if v.Code != opt.Code || v.Expiry < time.Now().Unix() {
+			return nil
+		}
+
+		dr, err := col.DeleteOne(ctx, bson.M{"_id": v.ID})
+		if err != nil {
+			return fmt.Errorf("Failed to check verification code: %s", err.Error())
+		}
+
+		// it may be used by a concurrent request
+		valid = (dr.DeletedCount == 1)
 		return nil
 	}
 
 	return valid, withContext(f)
 }
+
+func (c *client) HasVerificationCodeSentSince(email, purpose, ip string, since int64) (bool, error) {
+	sent := false
+
+	f := func(ctx context.Context) error {
+		col := c.collection(verifCodeCollection)
+
+		conditions := bson.A{bson.M{"email": email, "purpose": purpose}}
+		if ip != "" {
+			conditions = append(conditions, bson.M{"ip": ip})
+		}
+
+		filter := bson.M{
+			"$or":        conditions,
+			"created_at": bson.M{"$gte": since},
+		}
+
+		n, err := col.CountDocuments(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("Failed to count verification codes: %s", err.Error())
+		}
+
+		sent = (n > 0)
+		return nil
+	}
+
+	return sent, withContext(f)
+}
@@ -3,9 +3,9 @@ package pdf
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"sort"
 	"strconv"
+	"time"
 
 	"github.com/zengchen1024/cla-server/models"
 	"github.com/zengchen1024/cla-server/util"
@@ -56,7 +56,15 @@ func (this *pdfGenerator) genCorporPDFMissingSig(claOrg *models.CLAOrg, signing
 	c.cla(pdf, cla.Text)
 
 	// second page
-	c.secondPage(pdf)
+	signedAt := time.Now()
+	if signing.SignedAt > 0 {
+		signedAt = time.Unix(signing.SignedAt, 0)
+	}
+	signedAt = signedAt.UTC()
+
+	pdf.SetCreationDate(signedAt)
+	c.secondPage(pdf, signedAt)
+	c.signingRecord(pdf, buildSigningRecord(signing, signedAt))
 
 	path := util.CorporCLAPDFFile(this.pdfOutDir, claOrg.ID, signing.AdminEmail, "_missing_sig")
 	if err := c.end(pdf, path); err != nil {
@@ -66,18 +74,40 @@ func (this *pdfGenerator) genCorporPDFMissingSig(claOrg *models.CLAOrg, signing
 }
 
 func (this *pdfGenerator) mergeCorporPDFSignaturePage(pdfFile, sigFile, outfile string) error {
-	cmd := exec.Command(
-		this.pythonBin, "./util/merge-signature.py",
-		pdfFile, sigFile, outfile,
-	)
-	_, err := cmd.Output()
-	if err != nil {
+	if err := mergeSignaturePage(pdfFile, sigFile, outfile); err != nil {
 		return fmt.Errorf("Failed to merge signature page for corporation pdf: %s", err.Error())
 	}
 
 	return nil
 }
 
+func buildSigningRecord(signing *models.CorporationSigning, signedAt time.Time) [][2]string {
+	r := [][2]string{
+		{"Signed At", signedAt.Format(time.RFC3339)},
+		{"Signer", signing.AdminEmail},
+	}
+
+	if signing.CLAVersion > 0 {
+		r = append(r, [2]string{"CLA Version", strconv.Itoa(signing.CLAVersion)})
+	}
+
+	if m := signing.Metadata; m != nil {
+		if m.CLAHash != "" {
+			r = append(r, [2]string{"CLA SHA-256", m.CLAHash})
+		}
+		if m.SignedBy != "" {
+			r = append(r, [2]string{"Signed By", m.SignedBy})
+		}
+		if m.IP != "" {
+			r = append(r, [2]string{"IP", m.IP})
+		}
+		if m.UserAgent != "" {
+			r = append(r, [2]string{"User Agent", m.UserAgent})
+		}
+	}
+	return r
+}
+
 func buildCorporContact(cla *models.CLA) ([]string, error) {
 	ids := make(sort.IntSlice, 0, len(cla.Fields))
 	m := map[int]string{}
@@ -85,7 +115,7 @@ func buildCorporContact(cla *models.CLA) ([]string, error) {
 	for _, item := range cla.Fields {
 		v, err := strconv.Atoi(item.ID)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("Failed to sort the fields of cla(%s): the id(%s) is not a number", cla.ID, item.ID)
 		}
 
 		ids = append(ids, v)
@@ -0,0 +1,49 @@
+package pdf
+
+import (
+	"fmt"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/jung-kurt/gofpdf/contrib/gofpdi"
+)
+
+const pageBox = "/MediaBox"
+
+// mergeSignaturePage copies the pages of pdfFile to outfile except that the
+// last page is put on the first page of sigFile which has the signature of org.
+func mergeSignaturePage(pdfFile, sigFile, outfile string) (err error) {
+	// gofpdi panics when it fails to parse the pdf
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	imp := gofpdi.NewImporter()
+	out := gofpdf.New("P", "pt", "A4", "")
+
+	tpl := imp.ImportPage(out, pdfFile, 1, pageBox)
+	pages := imp.GetPageSizes()
+	n := len(pages)
+
+	sigTpl := imp.ImportPage(out, sigFile, 1, pageBox)
+	sigSize := imp.GetPageSizes()[1][pageBox]
+
+	for i := 1; i <= n; i++ {
+		if i > 1 {
+			tpl = imp.ImportPage(out, pdfFile, i, pageBox)
+		}
+
+		w, h := pages[i][pageBox]["w"], pages[i][pageBox]["h"]
+		if i < n {
+			out.AddPageFormat("P", gofpdf.SizeType{Wd: w, Ht: h})
+		} else {
+			sw, sh := sigSize["w"], sigSize["h"]
+			out.AddPageFormat("P", gofpdf.SizeType{Wd: sw, Ht: sh})
+			imp.UseImportedTemplate(out, sigTpl, 0, 0, sw, sh)
+		}
+		imp.UseImportedTemplate(out, tpl, 0, 0, w, h)
+	}
+
+	return out.OutputFileAndClose(outfile)
+}
@@ -0,0 +1,102 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/jung-kurt/gofpdf/contrib/gofpdi"
+)
+
+func writeTestPDF(t *testing.T, path string, texts ...string) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCompression(false)
+	pdf.SetFont("Arial", "", 12)
+	for _, item := range texts {
+		pdf.AddPage()
+		pdf.Cell(40, 10, item)
+	}
+
+	if err := pdf.OutputFileAndClose(path); err != nil {
+		t.Fatalf("write pdf %s: %v", path, err)
+	}
+}
+
+func countPages(t *testing.T, path string) int {
+	imp := gofpdi.NewImporter()
+	imp.ImportPage(gofpdf.New("P", "pt", "A4", ""), path, 1, pageBox)
+	return len(imp.GetPageSizes())
+}
+
+var streamRe = regexp.MustCompile(`(?s)stream\r?\n(.*?)endstream`)
+
+// pdfContent returns the content of pdf with all the streams decompressed.
+func pdfContent(t *testing.T, path string) []byte {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read pdf %s: %v", path, err)
+	}
+
+	r := bytes.NewBuffer(nil)
+	for _, item := range streamRe.FindAllSubmatch(data, -1) {
+		z, err := zlib.NewReader(bytes.NewReader(item[1]))
+		if err != nil {
+			// not compressed
+			r.Write(item[1])
+			continue
+		}
+		b, _ := ioutil.ReadAll(z)
+		r.Write(b)
+	}
+	return r.Bytes()
+}
+
+func TestMergeSignaturePage(t *testing.T) {
+	dir := t.TempDir()
+	sig := filepath.Join(dir, "sig.pdf")
+	writeTestPDF(t, sig, "org signature")
+
+	cases := [][]string{
+		{"last page"},
+		{"first page", "second page", "last page"},
+	}
+	for _, pages := range cases {
+		in := filepath.Join(dir, "in.pdf")
+		out := filepath.Join(dir, "out.pdf")
+		writeTestPDF(t, in, pages...)
+
+		if err := mergeSignaturePage(in, sig, out); err != nil {
+			t.Fatalf("merge %d pages: %v", len(pages), err)
+		}
+
+		if n := countPages(t, out); n != len(pages) {
+			t.Errorf("merge %d pages: expect %d pages, but got %d", len(pages), len(pages), n)
+		}
+
+		data := pdfContent(t, out)
+		for _, item := range append(pages, "org signature") {
+			if !bytes.Contains(data, []byte("("+item+")")) {
+				t.Errorf("merge %d pages: %q is missing", len(pages), item)
+			}
+		}
+	}
+}
+
+func TestMergeSignaturePageInvalidPDF(t *testing.T) {
+	dir := t.TempDir()
+	sig := filepath.Join(dir, "sig.pdf")
+	if err := ioutil.WriteFile(sig, []byte("not a pdf"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	in := filepath.Join(dir, "in.pdf")
+	writeTestPDF(t, in, "page")
+
+	if err := mergeSignaturePage(in, sig, filepath.Join(dir, "out.pdf")); err == nil {
+		t.Error("merge with invalid signature pdf: expect error, but got nil")
+	}
+}
@@ -101,11 +101,26 @@ func (this *corporationCLAPDF) cla(pdf *gofpdf.Fpdf, content string) {
 	multlines(pdf, this.gh, content)
 }
 
-func (this *corporationCLAPDF) secondPage(pdf *gofpdf.Fpdf) {
+func (this *corporationCLAPDF) secondPage(pdf *gofpdf.Fpdf, signedAt time.Time) {
 	pdf.AddPage()
 
 	signature(pdf, this.gh, "", []string{"", "", ""})
 
-	y, m, d := time.Now().Date()
+	y, m, d := signedAt.Date()
 	addSignatureItem(pdf, this.gh, "Date", fmt.Sprintf("%d-%d-%d", y, m, d))
 }
+
+// signingRecord adds the metadata recorded when signing, as the evidence of it.
+func (this *corporationCLAPDF) signingRecord(pdf *gofpdf.Fpdf, items [][2]string) {
+	pdf.Ln(-1)
+
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(0, this.gh, "Signing Record", "B", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	pdf.SetFont("Arial", "", 8)
+	for _, item := range items {
+		pdf.CellFormat(30, this.gh, fmt.Sprintf("%s:", item[0]), "", 0, "L", false, 0, "")
+		pdf.MultiCell(0, this.gh, item[1], "", "L", false)
+	}
+}
@@ -17,11 +17,10 @@ var generator *pdfGenerator
 type pdfGenerator struct {
 	pdfOutDir    string
 	pdfOrgSigDir string
-	pythonBin    string
 	corporation  *corporationCLAPDF
 }
 
-func InitPDFGenerator(pythonBin, pdfOutDir, pdfOrgSigDir, welcome, declPath string) error {
+func InitPDFGenerator(pdfOutDir, pdfOrgSigDir, welcome, declPath string) error {
 	welTemp, err := newTemplate("wel", welcome)
 	if err != nil {
 		return err
@@ -33,7 +32,6 @@ func InitPDFGenerator(pythonBin, pdfOutDir, pdfOrgSigDir, welcome, declPath stri
 	}
 
 	generator = &pdfGenerator{
-		pythonBin:    pythonBin,
 		pdfOutDir:    pdfOutDir,
 		pdfOrgSigDir: pdfOrgSigDir,
 		corporation: &corporationCLAPDF{
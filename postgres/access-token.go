@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+func (this *client) CreateRefreshToken(opt dbmodels.RefreshToken) error {
+	f := func(tx *sql.Tx) error {
+		// clean up the expired ones
+		if _, err := tx.Exec("DELETE FROM refresh_tokens WHERE expiry < $1", time.Now().Unix()); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(
+			"INSERT INTO refresh_tokens (id, token, user_name, permission, expiry) VALUES ($1, $2, $3, $4, $5)",
+			opt.ID, opt.Token, opt.User, opt.Permission, opt.Expiry,
+		)
+		return err
+	}
+
+	if err := this.doTransaction(f); err != nil {
+		return fmt.Errorf("Failed to create refresh token: %s", err.Error())
+	}
+	return nil
+}
+
+func (this *client) GetRefreshToken(id string) (dbmodels.RefreshToken, error) {
+	r := dbmodels.RefreshToken{ID: id}
+
+	err := this.db.QueryRow(
+		"SELECT token, user_name, permission, expiry FROM refresh_tokens WHERE id = $1", id,
+	).Scan(&r.Token, &r.User, &r.Permission, &r.Expiry)
+	if err == sql.ErrNoRows {
+		return r, fmt.Errorf("the refresh token is not exist")
+	}
+	return r, err
+}
+
+func (this *client) DeleteRefreshToken(id string) error {
+	if _, err := this.db.Exec("DELETE FROM refresh_tokens WHERE id = $1", id); err != nil {
+		return fmt.Errorf("Failed to delete refresh token: %s", err.Error())
+	}
+	return nil
+}
+
+func (this *client) DeleteRefreshTokensOfUser(user string) ([]dbmodels.RefreshToken, error) {
+	rows, err := this.db.Query(
+		"DELETE FROM refresh_tokens WHERE user_name = $1 RETURNING id, token, user_name, permission, expiry", user,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to delete refresh tokens of user: %s", err.Error())
+	}
+	defer rows.Close()
+
+	r := make([]dbmodels.RefreshToken, 0)
+	for rows.Next() {
+		var v dbmodels.RefreshToken
+		if err := rows.Scan(&v.ID, &v.Token, &v.User, &v.Permission, &v.Expiry); err != nil {
+			return nil, err
+		}
+		r = append(r, v)
+	}
+	return r, rows.Err()
+}
+
+func (this *client) RevokeSession(opt dbmodels.RevokedSession) error {
+	f := func(tx *sql.Tx) error {
+		// the access tokens of expired sessions are invalid already
+		if _, err := tx.Exec("DELETE FROM revoked_sessions WHERE expiry < $1", time.Now().Unix()); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(
+			"INSERT INTO revoked_sessions (id, expiry) VALUES ($1, $2) "+
+				"ON CONFLICT (id) DO UPDATE SET expiry = EXCLUDED.expiry",
+			opt.ID, opt.Expiry,
+		)
+		return err
+	}
+
+	if err := this.doTransaction(f); err != nil {
+		return fmt.Errorf("Failed to revoke session: %s", err.Error())
+	}
+	return nil
+}
+
+func (this *client) IsSessionRevoked(id string) (bool, error) {
+	n := 0
+	if err := this.db.QueryRow("SELECT count(*) FROM revoked_sessions WHERE id = $1", id).Scan(&n); err != nil {
+		return false, fmt.Errorf("Failed to check session: %s", err.Error())
+	}
+	return n != 0, nil
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+const auditLogColumns = "id, actor, permission, action, cla_org_id, corporation_id, target, before, after, created_at"
+
+// AddAuditLog appends the log. There is no way to update or delete the logs.
+func (this *client) AddAuditLog(info dbmodels.AuditLog) error {
+	_, err := this.db.Exec(
+		"INSERT INTO audit_logs ("+auditLogColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)",
+		newID(), info.Actor, info.Permission, info.Action, info.CLAOrgID, info.CorporationID,
+		info.Target, info.Before, info.After, info.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("Failed to add audit log: %s", err.Error())
+	}
+	return nil
+}
+
+func (this *client) ListAuditLog(opt dbmodels.AuditLogListOption) (dbmodels.AuditLogPage, error) {
+	r := dbmodels.AuditLogPage{}
+
+	if _, err := opt.Page.SortField(dbmodels.AuditLogSortFields); err != nil {
+		return r, err
+	}
+
+	q := pageSelect{
+		columns: auditLogColumns + ", seq",
+		from:    "audit_logs",
+		where: "($1::text = '' OR cla_org_id = $1) AND ($2::text = '' OR corporation_id = $2) " +
+			"AND ($3::text = '' OR actor = $3) AND ($4::text = '' OR action = $4) " +
+			"AND ($5::bigint <= 0 OR created_at >= $5) AND ($6::bigint <= 0 OR created_at <= $6)",
+		args: []interface{}{opt.CLAOrgID, opt.CorporationID, opt.Actor, opt.Action, opt.From, opt.To},
+		keys: []string{"created_at", "seq"},
+	}
+
+	r.Logs = make([]dbmodels.AuditLog, 0)
+	scan := func(rows *sql.Rows) ([]interface{}, error) {
+		var v dbmodels.AuditLog
+		var seq int64
+
+		err := rows.Scan(
+			&v.ID, &v.Actor, &v.Permission, &v.Action, &v.CLAOrgID, &v.CorporationID,
+			&v.Target, &v.Before, &v.After, &v.CreatedAt, &seq,
+		)
+		if err != nil {
+			return nil, err
+		}
+		r.Logs = append(r.Logs, v)
+		return []interface{}{v.CreatedAt, seq}, nil
+	}
+
+	info, err := this.selectPage(q, opt.Page, scan)
+	if err != nil {
+		return r, fmt.Errorf("Failed to list audit logs: %s", err.Error())
+	}
+	r.PageInfo = info
+	return r, nil
+}
@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// UploadBlankSignature saves the pdf of language only if there is not one.
+func (this *client) UploadBlankSignature(language string, pdf []byte) error {
+	_, err := this.db.Exec(
+		"INSERT INTO blank_signatures (language, pdf) VALUES ($1, $2) ON CONFLICT (language) DO NOTHING",
+		language, pdf,
+	)
+	if err != nil {
+		return fmt.Errorf("Failed to upload blank signature: %s", err.Error())
+	}
+	return nil
+}
+
+func (this *client) DownloadBlankSignature(language string) ([]byte, error) {
+	var r []byte
+
+	err := this.db.QueryRow("SELECT pdf FROM blank_signatures WHERE language = $1", language).Scan(&r)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("the blank signature of %s is not exist", language)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+func (this *client) SetBotToken(claOrgID, token string) error {
+	if err := checkID(claOrgID); err != nil {
+		return err
+	}
+
+	ok, err := checkRowsAffected(this.db.Exec(
+		"UPDATE cla_orgs SET bot_token = $2, updated_at = $3 WHERE id = $1 AND enabled",
+		claOrgID, token, time.Now().Unix(),
+	))
+	if err != nil {
+		return fmt.Errorf("Failed to set bot token: %s", err.Error())
+	}
+	if !ok {
+		return fmt.Errorf("Failed to set bot token: can't find the binding")
+	}
+	return nil
+}
+
+// GetBotToken prefers the token of binding for the repo to the one for the whole org.
+func (this *client) GetBotToken(platform, orgID, repoID string) (string, error) {
+	token := ""
+
+	err := this.db.QueryRow(
+		"SELECT bot_token FROM cla_orgs WHERE enabled AND bot_token <> '' AND platform = $1 AND org_id = $2 "+
+			"AND (repo_id = $3 OR repo_id = '') ORDER BY repo_id DESC LIMIT 1",
+		platform, orgID, repoID,
+	).Scan(&token)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("no bot token is set for %s/%s/%s", platform, orgID, repoID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("Failed to get bot token: %s", err.Error())
+	}
+	return token, nil
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/zengchen1024/cla-server/models"
+)
+
+func (this *client) CreateCLAMetadata(data models.CLAMetadata) (string, error) {
+	uid := newID()
+
+	f := func(tx *sql.Tx) error {
+		n := 0
+		err := tx.QueryRow(
+			"SELECT count(*) FROM cla_metadatas WHERE submitter = $1 AND name = $2",
+			data.Submitter, data.Name,
+		).Scan(&n)
+		if err != nil {
+			return err
+		}
+		if n != 0 {
+			return fmt.Errorf("the cla metadata(%s) is already existing", data.Name)
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO cla_metadatas (id, name, text, language, submitter) VALUES ($1, $2, $3, $4, $5)",
+			uid, data.Name, data.Text, data.Language, data.Submitter,
+		)
+		return err
+	}
+
+	if err := this.doTransaction(f); err != nil {
+		return "", fmt.Errorf("Failed to create cla metadata: %s", err.Error())
+	}
+	return uid, nil
+}
+
+func (this *client) DeleteCLAMetadata(uid string) error {
+	if err := checkID(uid); err != nil {
+		return err
+	}
+
+	_, err := this.db.Exec("DELETE FROM cla_metadatas WHERE id = $1", uid)
+	return err
+}
+
+func (this *client) ListCLAMetadata(belongingTo []string) ([]models.CLAMetadata, error) {
+	rows, err := this.db.Query(
+		"SELECT id, name, text, language, submitter FROM cla_metadatas WHERE submitter = ANY($1) ORDER BY id",
+		pq.Array(belongingTo),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list cla metadata: %s", err.Error())
+	}
+	defer rows.Close()
+
+	r := make([]models.CLAMetadata, 0)
+	for rows.Next() {
+		var v models.CLAMetadata
+		if err := rows.Scan(&v.ID, &v.Name, &v.Text, &v.Language, &v.Submitter); err != nil {
+			return nil, err
+		}
+		r = append(r, v)
+	}
+	return r, rows.Err()
+}
+
+func (this *client) GetCLAMetadata(uid string) (models.CLAMetadata, error) {
+	var v models.CLAMetadata
+
+	if err := checkID(uid); err != nil {
+		return v, err
+	}
+
+	err := this.db.QueryRow(
+		"SELECT id, name, text, language, submitter FROM cla_metadatas WHERE id = $1", uid,
+	).Scan(&v.ID, &v.Name, &v.Text, &v.Language, &v.Submitter)
+	if err == sql.ErrNoRows {
+		return v, fmt.Errorf("the cla metadata(%s) is not exist", uid)
+	}
+	return v, err
+}
@@ -0,0 +1,255 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+const claOrgColumns = "id, platform, org_id, repo_id, cla_id, cla_language, cla_version, apply_to, " +
+	"org_email, enabled, submitter, domain_verification_required"
+
+// corpoCLAsOfRepo is the condition of the enabled bindings of corporation cla for the org/repo
+const corpoCLAsOfRepo = "SELECT id FROM cla_orgs WHERE enabled AND apply_to = '" + models.ApplyToCorporation +
+	"' AND platform = $1 AND org_id = $2 AND repo_id = $3"
+
+func scanCLAOrg(s scanner) (dbmodels.CLAOrg, error) {
+	var v dbmodels.CLAOrg
+
+	err := s.Scan(
+		&v.ID, &v.Platform, &v.OrgID, &v.RepoID, &v.CLAID, &v.CLALanguage, &v.CLAVersion, &v.ApplyTo,
+		&v.OrgEmail, &v.Enabled, &v.Submitter, &v.DomainVerificationRequired,
+	)
+
+	// the binding created before versioning points to version 1
+	if v.CLAVersion == 0 {
+		v.CLAVersion = 1
+	}
+	return v, err
+}
+
+// lockCLAOrg returns the binding whatever it is enabled or not, and locks it
+// until the end of transaction. It returns nil if the binding is not exist.
+func lockCLAOrg(tx *sql.Tx, uid string) (*dbmodels.CLAOrg, error) {
+	if err := checkID(uid); err != nil {
+		return nil, err
+	}
+
+	v, err := scanCLAOrg(tx.QueryRow("SELECT "+claOrgColumns+" FROM cla_orgs WHERE id = $1 FOR UPDATE", uid))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func isCorpoCLA(v *dbmodels.CLAOrg) bool {
+	return v != nil && v.Enabled && v.ApplyTo == models.ApplyToCorporation
+}
+
+func isIndividualCLA(v *dbmodels.CLAOrg) bool {
+	return v != nil && v.Enabled && v.ApplyTo == models.ApplyToIndividual
+}
+
+// isCLAOrgExist checks whether the binding exists whatever it is enabled or not
+func (this *client) isCLAOrgExist(uid string) (bool, error) {
+	if err := checkID(uid); err != nil {
+		return false, err
+	}
+
+	n := 0
+	err := this.db.QueryRow("SELECT count(*) FROM cla_orgs WHERE id = $1", uid).Scan(&n)
+	return n != 0, err
+}
+
+// isCorpoCLAExist checks whether the enabled binding of corporation cla exists
+func (this *client) isCorpoCLAExist(uid string) (bool, error) {
+	if err := checkID(uid); err != nil {
+		return false, err
+	}
+
+	n := 0
+	err := this.db.QueryRow(
+		"SELECT count(*) FROM cla_orgs WHERE id = $1 AND enabled AND apply_to = $2",
+		uid, models.ApplyToCorporation,
+	).Scan(&n)
+	return n != 0, err
+}
+
+func (this *client) listCLAOrgs(where string, args ...interface{}) ([]dbmodels.CLAOrg, error) {
+	rows, err := this.db.Query("SELECT "+claOrgColumns+" FROM cla_orgs WHERE "+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	r := make([]dbmodels.CLAOrg, 0)
+	for rows.Next() {
+		v, err := scanCLAOrg(rows)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, v)
+	}
+	return r, rows.Err()
+}
+
+func (this *client) CreateBindingBetweenCLAAndOrg(info dbmodels.CLAOrg) (string, error) {
+	uid := newID()
+
+	f := func(tx *sql.Tx) error {
+		if err := lockRepo(tx, info.Platform, info.OrgID, info.RepoID); err != nil {
+			return err
+		}
+
+		n := 0
+		err := tx.QueryRow(
+			"SELECT count(*) FROM cla_orgs WHERE enabled AND platform = $1 AND org_id = $2 AND repo_id = $3 "+
+				"AND cla_language = $4 AND apply_to = $5",
+			info.Platform, info.OrgID, info.RepoID, info.CLALanguage, info.ApplyTo,
+		).Scan(&n)
+		if err != nil {
+			return err
+		}
+		if n != 0 {
+			return fmt.Errorf("the org/repo:%s/%s/%s has already been bound a cla with language:%s",
+				info.Platform, info.OrgID, info.RepoID, info.CLALanguage)
+		}
+
+		now := time.Now().Unix()
+		_, err = tx.Exec(
+			"INSERT INTO cla_orgs (id, platform, org_id, repo_id, cla_id, cla_language, cla_version, apply_to, "+
+				"org_email, enabled, submitter, domain_verification_required, created_at, updated_at) "+
+				"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13)",
+			uid, info.Platform, info.OrgID, info.RepoID, info.CLAID, info.CLALanguage, info.CLAVersion, info.ApplyTo,
+			info.OrgEmail, info.Enabled, info.Submitter, info.DomainVerificationRequired, now,
+		)
+		return err
+	}
+
+	if err := this.doTransaction(f); err != nil {
+		return "", fmt.Errorf("Failed to create binding between cla and org: %s", err.Error())
+	}
+	return uid, nil
+}
+
+func (this *client) DeleteBindingBetweenCLAAndOrg(uid string) error {
+	if err := checkID(uid); err != nil {
+		return err
+	}
+
+	_, err := this.db.Exec(
+		"UPDATE cla_orgs SET enabled = FALSE, updated_at = $2 WHERE id = $1", uid, time.Now().Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("Failed to delete binding between cla and org: %s", err.Error())
+	}
+	return nil
+}
+
+func (this *client) GetBindingBetweenCLAAndOrg(uid string) (dbmodels.CLAOrg, error) {
+	if err := checkID(uid); err != nil {
+		return dbmodels.CLAOrg{}, err
+	}
+
+	v, err := scanCLAOrg(this.db.QueryRow("SELECT "+claOrgColumns+" FROM cla_orgs WHERE id = $1", uid))
+	if err == sql.ErrNoRows {
+		return v, fmt.Errorf("the binding(%s) is not exist", uid)
+	}
+	return v, err
+}
+
+func (this *client) ListBindingBetweenCLAAndOrg(opt dbmodels.CLAOrgListOption) (dbmodels.CLAOrgPage, error) {
+	var r dbmodels.CLAOrgPage
+
+	field, err := opt.Page.SortField(dbmodels.CLAOrgSortFields)
+	if err != nil {
+		return r, err
+	}
+
+	q := pageSelect{
+		columns: claOrgColumns,
+		from:    "cla_orgs",
+		where: "enabled AND platform = $1 AND ($2::text = '' OR org_id = $2) AND ($3::text = '' OR apply_to = $3) " +
+			"AND ($4::text[] IS NULL OR org_id = ANY($4))",
+		args: []interface{}{opt.Platform, opt.OrgID, opt.ApplyTo, pq.Array(opt.OrgIDs)},
+		keys: []string{field, "id"},
+	}
+
+	// list the bindings of repo if there are, otherwise the ones of org
+	if opt.RepoID != "" {
+		n := 0
+		err := this.db.QueryRow(
+			"SELECT count(*) FROM cla_orgs WHERE "+q.where+" AND repo_id = $5", append(q.args, opt.RepoID)...,
+		).Scan(&n)
+		if err != nil {
+			return r, fmt.Errorf("Failed to list bindings between cla and org: %s", err.Error())
+		}
+
+		if n != 0 {
+			q.where += " AND repo_id = $5"
+			q.args = append(q.args, opt.RepoID)
+		}
+	}
+
+	r.Bindings = make([]dbmodels.CLAOrg, 0)
+	r.PageInfo, err = this.selectPage(q, opt.Page, func(rows *sql.Rows) ([]interface{}, error) {
+		v, err := scanCLAOrg(rows)
+		if err != nil {
+			return nil, err
+		}
+		r.Bindings = append(r.Bindings, v)
+
+		if field == "cla_language" {
+			return []interface{}{v.CLALanguage, v.ID}, nil
+		}
+		return []interface{}{v.RepoID, v.ID}, nil
+	})
+	if err != nil {
+		return r, fmt.Errorf("Failed to list bindings between cla and org: %s", err.Error())
+	}
+	return r, nil
+}
+
+func (this *client) UpdateBindingCLAVersion(claOrgID string, version int) error {
+	if err := checkID(claOrgID); err != nil {
+		return err
+	}
+
+	ok, err := checkRowsAffected(this.db.Exec(
+		"UPDATE cla_orgs SET cla_version = $2, updated_at = $3 WHERE id = $1 AND enabled",
+		claOrgID, version, time.Now().Unix(),
+	))
+	if err != nil {
+		return fmt.Errorf("Failed to update version of cla: %s", err.Error())
+	}
+	if !ok {
+		return fmt.Errorf("Failed to update version of cla: can't find the binding")
+	}
+	return nil
+}
+
+func (this *client) UpdateBindingDomainVerification(claOrgID string, required bool) error {
+	if err := checkID(claOrgID); err != nil {
+		return err
+	}
+
+	ok, err := checkRowsAffected(this.db.Exec(
+		"UPDATE cla_orgs SET domain_verification_required = $2, updated_at = $3 WHERE id = $1 AND enabled AND apply_to = $4",
+		claOrgID, required, time.Now().Unix(), models.ApplyToCorporation,
+	))
+	if err != nil {
+		return fmt.Errorf("Failed to update domain verification: %s", err.Error())
+	}
+	if !ok {
+		return fmt.Errorf("Failed to update domain verification: can't find the binding of corporation cla")
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+const claColumns = "id, name, text, language, submitter, apply_to, fields, version"
+
+func scanCLA(s scanner) (dbmodels.CLA, error) {
+	var v dbmodels.CLA
+	var fields []byte
+
+	err := s.Scan(&v.ID, &v.Name, &v.Text, &v.Language, &v.Submitter, &v.ApplyTo, &fields, &v.Version)
+	if err != nil {
+		return v, err
+	}
+
+	if len(fields) != 0 {
+		if err := json.Unmarshal(fields, &v.Fields); err != nil {
+			return v, err
+		}
+	}
+
+	// the cla created before versioning is version 1
+	if v.Version == 0 {
+		v.Version = 1
+	}
+	return v, nil
+}
+
+// listCLAVersions returns the versions of clas, key is the id of cla
+func (this *client) listCLAVersions(ids []string) (map[string][]dbmodels.CLAVersion, error) {
+	rows, err := this.db.Query(
+		"SELECT cla_id, version, text, require_resign, published_at FROM cla_versions WHERE cla_id = ANY($1) ORDER BY cla_id, version",
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	r := map[string][]dbmodels.CLAVersion{}
+	for rows.Next() {
+		var id string
+		var v dbmodels.CLAVersion
+		if err := rows.Scan(&id, &v.Version, &v.Text, &v.RequireResign, &v.PublishedAt); err != nil {
+			return nil, err
+		}
+		r[id] = append(r[id], v)
+	}
+	return r, rows.Err()
+}
+
+func (this *client) listCLA(where string, args ...interface{}) ([]dbmodels.CLA, error) {
+	rows, err := this.db.Query("SELECT "+claColumns+" FROM clas WHERE "+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list clas: %s", err.Error())
+	}
+	defer rows.Close()
+
+	r := make([]dbmodels.CLA, 0)
+	for rows.Next() {
+		v, err := scanCLA(rows)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := this.setCLAVersions(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (this *client) setCLAVersions(r []dbmodels.CLA) error {
+	if len(r) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(r))
+	for _, item := range r {
+		ids = append(ids, item.ID)
+	}
+
+	versions, err := this.listCLAVersions(ids)
+	if err != nil {
+		return fmt.Errorf("Failed to list versions of clas: %s", err.Error())
+	}
+
+	for i := range r {
+		r[i].Versions = versions[r[i].ID]
+	}
+	return nil
+}
+
+func (this *client) CreateCLA(info dbmodels.CLA) (string, error) {
+	uid := newID()
+
+	var fields interface{}
+	if info.Fields != nil {
+		b, err := json.Marshal(info.Fields)
+		if err != nil {
+			return "", err
+		}
+		fields = string(b)
+	}
+
+	f := func(tx *sql.Tx) error {
+		n := 0
+		err := tx.QueryRow(
+			"SELECT count(*) FROM clas WHERE submitter = $1 AND name = $2", info.Submitter, info.Name,
+		).Scan(&n)
+		if err != nil {
+			return err
+		}
+		if n != 0 {
+			return fmt.Errorf("the cla(%s) is already existing", info.Name)
+		}
+
+		now := time.Now().Unix()
+		_, err = tx.Exec(
+			"INSERT INTO clas (id, name, text, language, submitter, apply_to, fields, version, created_at, updated_at) "+
+				"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)",
+			uid, info.Name, info.Text, info.Language, info.Submitter, info.ApplyTo, fields, info.Version, now,
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, v := range info.Versions {
+			if err := insertCLAVersion(tx, uid, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := this.doTransaction(f); err != nil {
+		return "", fmt.Errorf("Failed to create cla: %s", err.Error())
+	}
+	return uid, nil
+}
+
+func insertCLAVersion(tx *sql.Tx, claID string, v dbmodels.CLAVersion) error {
+	_, err := tx.Exec(
+		"INSERT INTO cla_versions (cla_id, version, text, require_resign, published_at) VALUES ($1, $2, $3, $4, $5)",
+		claID, v.Version, v.Text, v.RequireResign, v.PublishedAt,
+	)
+	return err
+}
+
+func (this *client) DeleteCLA(uid string) error {
+	if err := checkID(uid); err != nil {
+		return err
+	}
+
+	f := func(tx *sql.Tx) error {
+		n := 0
+		if err := tx.QueryRow("SELECT count(*) FROM cla_orgs WHERE cla_id = $1", uid).Scan(&n); err != nil {
+			return err
+		}
+		if n != 0 {
+			return fmt.Errorf("can't delete the cla which has already been bound to org")
+		}
+
+		_, err := tx.Exec("DELETE FROM clas WHERE id = $1", uid)
+		return err
+	}
+
+	return this.doTransaction(f)
+}
+
+func (this *client) ListCLA(opt dbmodels.CLAListOptions) (dbmodels.CLAPage, error) {
+	var r dbmodels.CLAPage
+
+	field, err := opt.Page.SortField(dbmodels.CLASortFields)
+	if err != nil {
+		return r, err
+	}
+
+	q := pageSelect{
+		columns: claColumns,
+		from:    "clas",
+		where:   "submitter = $1 AND ($2::text = '' OR name = $2) AND ($3::text = '' OR language = $3) AND ($4::text = '' OR apply_to = $4)",
+		args:    []interface{}{opt.Submitter, opt.Name, opt.Language, opt.ApplyTo},
+		keys:    []string{field, "id"},
+	}
+
+	r.CLAs = make([]dbmodels.CLA, 0)
+	r.PageInfo, err = this.selectPage(q, opt.Page, func(rows *sql.Rows) ([]interface{}, error) {
+		v, err := scanCLA(rows)
+		if err != nil {
+			return nil, err
+		}
+		r.CLAs = append(r.CLAs, v)
+
+		if field == "language" {
+			return []interface{}{v.Language, v.ID}, nil
+		}
+		return []interface{}{v.Name, v.ID}, nil
+	})
+	if err != nil {
+		return r, fmt.Errorf("Failed to list clas: %s", err.Error())
+	}
+
+	return r, this.setCLAVersions(r.CLAs)
+}
+
+func (this *client) ListCLAByIDs(ids []string) ([]dbmodels.CLA, error) {
+	if err := checkIDs(ids); err != nil {
+		return nil, err
+	}
+
+	return this.listCLA("id = ANY($1)", pq.Array(ids))
+}
+
+func (this *client) GetCLA(uid string) (dbmodels.CLA, error) {
+	if err := checkID(uid); err != nil {
+		return dbmodels.CLA{}, err
+	}
+
+	v, err := this.listCLA("id = $1", uid)
+	if err != nil {
+		return dbmodels.CLA{}, err
+	}
+	if len(v) == 0 {
+		return dbmodels.CLA{}, fmt.Errorf("the cla(%s) is not exist", uid)
+	}
+	return v[0], nil
+}
+
+func (this *client) AddCLAVersion(claID string, v dbmodels.CLAVersion) error {
+	if err := checkID(claID); err != nil {
+		return err
+	}
+
+	f := func(tx *sql.Tx) error {
+		var text string
+		var latest int
+		var createdAt int64
+
+		err := tx.QueryRow(
+			"SELECT text, version, created_at FROM clas WHERE id = $1 FOR UPDATE", claID,
+		).Scan(&text, &latest, &createdAt)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("the cla(%s) is not exist", claID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if latest == 0 {
+			// keep the text of the cla created before versioning as version 1
+			latest = 1
+			err := insertCLAVersion(tx, claID, dbmodels.CLAVersion{Version: 1, Text: text, PublishedAt: createdAt})
+			if err != nil {
+				return err
+			}
+		}
+
+		if v.Version != latest+1 {
+			return fmt.Errorf("Failed to add version of cla, the version(%d) is not the next one of %d", v.Version, latest)
+		}
+
+		if err := insertCLAVersion(tx, claID, v); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"UPDATE clas SET text = $2, version = $3, updated_at = $4 WHERE id = $1",
+			claID, v.Text, v.Version, time.Now().Unix(),
+		)
+		return err
+	}
+
+	return this.doTransaction(f)
+}
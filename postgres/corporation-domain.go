@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+func (this *client) AddCorporationDomain(claOrgID, adminEmail string, opt dbmodels.CorporationDomain) error {
+	f := func(tx *sql.Tx) error {
+		item, err := lockCLAOrg(tx, claOrgID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("the binding(%s) is not exist", claOrgID)
+		}
+
+		if err := lockRepo(tx, item.Platform, item.OrgID, item.RepoID); err != nil {
+			return err
+		}
+
+		// the domain can't be claimed by more than one corporation of org/repo,
+		// including the domain of administrator's email.
+		owner := ""
+		err = tx.QueryRow(
+			"SELECT c.admin_email FROM corporation_signings c WHERE c.cla_org_id IN ("+corpoCLAsOfRepo+") "+
+				"AND (c.corporation_id = $4 OR EXISTS (SELECT 1 FROM corporation_domains d WHERE "+
+				"d.cla_org_id = c.cla_org_id AND d.corporation_id = c.corporation_id AND d.domain = $5)) LIMIT 1",
+			item.Platform, item.OrgID, item.RepoID, emailToKey(opt.Domain), opt.Domain,
+		).Scan(&owner)
+		if err == nil {
+			if owner == adminEmail {
+				return fmt.Errorf("it has been added")
+			}
+			return fmt.Errorf("it has been claimed by other corporation")
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		corporationID := ""
+		if isCorpoCLA(item) {
+			err := tx.QueryRow(
+				"SELECT corporation_id FROM corporation_signings WHERE cla_org_id = $1 AND admin_email = $2",
+				claOrgID, adminEmail,
+			).Scan(&corporationID)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+		}
+		if corporationID == "" {
+			return fmt.Errorf("the corporation has not signed")
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO corporation_domains (cla_org_id, corporation_id, domain, token, created_at) "+
+				"VALUES ($1, $2, $3, $4, $5)",
+			claOrgID, corporationID, opt.Domain, opt.Token, opt.CreatedAt,
+		)
+		return err
+	}
+
+	if err := this.doTransaction(f); err != nil {
+		return fmt.Errorf("Failed to add domain, %s", err.Error())
+	}
+	return nil
+}
+
+func (this *client) VerifyCorporationDomain(claOrgID, adminEmail, domain string, verifiedAt int64) error {
+	b, err := this.isCorpoCLAExist(claOrgID)
+	if err != nil {
+		return err
+	}
+	if !b {
+		return fmt.Errorf("Failed to verify domain, the binding is not exist")
+	}
+
+	ok, err := checkRowsAffected(this.db.Exec(
+		"UPDATE corporation_domains d SET verified = TRUE, verified_at = $4 FROM corporation_signings c "+
+			"WHERE d.cla_org_id = $1 AND d.domain = $3 AND c.cla_org_id = d.cla_org_id "+
+			"AND c.corporation_id = d.corporation_id AND c.admin_email = $2",
+		claOrgID, adminEmail, domain, verifiedAt,
+	))
+	if err != nil {
+		return fmt.Errorf("Failed to verify domain: %s", err.Error())
+	}
+	if !ok {
+		return fmt.Errorf("Failed to verify domain, the corporation or the domain is not exist")
+	}
+	return nil
+}
+
+func (this *client) ListCorporationDomain(claOrgID, corporationID string) ([]dbmodels.CorporationDomain, error) {
+	b, err := this.isCorpoCLAExist(claOrgID)
+	if err != nil {
+		return nil, err
+	}
+	if !b {
+		return nil, fmt.Errorf("Failed to list domains, the binding is not exist")
+	}
+
+	rows, err := this.db.Query(
+		"SELECT domain, token, verified, verified_at, created_at FROM corporation_domains "+
+			"WHERE cla_org_id = $1 AND corporation_id = $2 ORDER BY created_at, domain",
+		claOrgID, corporationID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list domains: %s", err.Error())
+	}
+	defer rows.Close()
+
+	var r []dbmodels.CorporationDomain
+	for rows.Next() {
+		var v dbmodels.CorporationDomain
+		if err := rows.Scan(&v.Domain, &v.Token, &v.Verified, &v.VerifiedAt, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		r = append(r, v)
+	}
+	return r, rows.Err()
+}
+
+func (this *client) GetCorporationIDByDomain(platform, orgID, repoID, domain string) (string, error) {
+	r := ""
+
+	err := this.db.QueryRow(
+		"SELECT corporation_id FROM corporation_domains WHERE verified AND domain = $4 "+
+			"AND cla_org_id IN ("+corpoCLAsOfRepo+") LIMIT 1",
+		platform, orgID, repoID, domain,
+	).Scan(&r)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("Failed to get corporation by domain: %s", err.Error())
+	}
+	return r, nil
+}
@@ -0,0 +1,261 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+func (this *client) AddCorporationManager(claOrgID string, opt []dbmodels.CorporationManagerCreateOption, managerNumber int) error {
+	if len(opt) == 0 {
+		return nil
+	}
+
+	emails := make([]string, 0, len(opt))
+	for _, m := range opt {
+		emails = append(emails, m.Email)
+	}
+
+	f := func(tx *sql.Tx) error {
+		item, err := lockCLAOrg(tx, claOrgID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("the binding(%s) is not exist", claOrgID)
+		}
+
+		if err := lockRepo(tx, item.Platform, item.OrgID, item.RepoID); err != nil {
+			return err
+		}
+
+		roleCount := 0
+		emailCount := 0
+		err = tx.QueryRow(
+			"SELECT count(*) FILTER (WHERE corporation_id = $4 AND role = $5), count(*) FILTER (WHERE email = ANY($6)) "+
+				"FROM corporation_managers WHERE cla_org_id IN ("+corpoCLAsOfRepo+")",
+			item.Platform, item.OrgID, item.RepoID, opt[0].CorporationID, opt[0].Role, pq.Array(emails),
+		).Scan(&roleCount, &emailCount)
+		if err != nil {
+			return err
+		}
+
+		if roleCount+len(opt) > managerNumber {
+			return fmt.Errorf("it will exceed %d managers allowed", managerNumber)
+		}
+		if emailCount != 0 {
+			return fmt.Errorf("there are already %d same emails", emailCount)
+		}
+
+		for _, m := range opt {
+			_, err := tx.Exec(
+				"INSERT INTO corporation_managers (cla_org_id, email, role, password, corporation_id, must_change_password) "+
+					"VALUES ($1, $2, $3, $4, $5, $6)",
+				claOrgID, m.Email, m.Role, m.Password, m.CorporationID, m.MustChangePassword,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := this.doTransaction(f); err != nil {
+		return fmt.Errorf("Failed to add corporation manager: %s", err.Error())
+	}
+	return nil
+}
+
+func (this *client) listCorporationManagerCheckResult(where string, args ...interface{}) ([]dbmodels.CorporationManagerCheckResult, error) {
+	rows, err := this.db.Query(
+		"SELECT m.email, m.role, m.password, m.must_change_password, o.id, o.platform, o.org_id, o.repo_id "+
+			"FROM corporation_managers m JOIN cla_orgs o ON m.cla_org_id = o.id "+
+			"WHERE o.enabled AND o.apply_to = '"+models.ApplyToCorporation+"'"+where+" ORDER BY o.id, m.email",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	r := make([]dbmodels.CorporationManagerCheckResult, 0)
+	for rows.Next() {
+		var v dbmodels.CorporationManagerCheckResult
+
+		err := rows.Scan(
+			&v.Email, &v.Role, &v.Password, &v.MustChangePassword,
+			&v.CLAOrgID, &v.Platform, &v.OrgID, &v.RepoID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, v)
+	}
+	return r, rows.Err()
+}
+
+func (this *client) CheckCorporationManagerExist(opt dbmodels.CorporationManagerCheckInfo) ([]dbmodels.CorporationManagerCheckResult, error) {
+	// the manager has no name, so only the email is matched
+	r, err := this.listCorporationManagerCheckResult(" AND m.email = $1", opt.User)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to check corporation manager: %s", err.Error())
+	}
+	return r, nil
+}
+
+func (this *client) ListAllCorporationManagers() ([]dbmodels.CorporationManagerCheckResult, error) {
+	r, err := this.listCorporationManagerCheckResult("")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list all corporation managers: %s", err.Error())
+	}
+	return r, nil
+}
+
+func (this *client) ResetCorporationManagerPassword(claOrgID string, opt dbmodels.CorporationManagerResetPassword) error {
+	if err := checkID(claOrgID); err != nil {
+		return err
+	}
+
+	ok, err := checkRowsAffected(this.db.Exec(
+		"UPDATE corporation_managers SET password = $4, must_change_password = $5 "+
+			"WHERE cla_org_id = $1 AND email = $2 AND password = $3 "+
+			"AND (password <> $4 OR must_change_password <> $5) AND cla_org_id IN "+
+			"(SELECT id FROM cla_orgs WHERE id = $1 AND enabled AND apply_to = $6)",
+		claOrgID, opt.Email, opt.OldPassword, opt.NewPassword, opt.MustChangePassword, models.ApplyToCorporation,
+	))
+	if err != nil {
+		return fmt.Errorf("Failed to reset password for corporation manager: %s", err.Error())
+	}
+	if !ok {
+		return fmt.Errorf("Failed to reset password for corporation manager: user name or old password is not correct.")
+	}
+	return nil
+}
+
+func (this *client) listCorporationManager(where string, args ...interface{}) ([]dbmodels.CorporationManagerListResult, error) {
+	rows, err := this.db.Query("SELECT email, role FROM corporation_managers WHERE "+where+" ORDER BY email", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// the manager has no name
+	r := make([]dbmodels.CorporationManagerListResult, 0)
+	for rows.Next() {
+		var v dbmodels.CorporationManagerListResult
+		if err := rows.Scan(&v.Email, &v.Role); err != nil {
+			return nil, err
+		}
+		r = append(r, v)
+	}
+	return r, rows.Err()
+}
+
+func (this *client) ListCorporationManager(claOrgID string, opt dbmodels.CorporationManagerListOption) (dbmodels.CorporationManagerPage, error) {
+	var r dbmodels.CorporationManagerPage
+
+	if err := checkID(claOrgID); err != nil {
+		return r, err
+	}
+
+	field, err := opt.Page.SortField(dbmodels.CorporationManagerSortFields)
+	if err != nil {
+		return r, err
+	}
+
+	q := pageSelect{
+		columns: "email, role",
+		from:    "corporation_managers",
+		where: "cla_org_id = $1 AND role = $2 AND corporation_id = $3 AND cla_org_id IN " +
+			"(SELECT id FROM cla_orgs WHERE id = $1 AND enabled AND apply_to = $4)",
+		args: []interface{}{claOrgID, opt.Role, opt.CorporationID, models.ApplyToCorporation},
+		keys: []string{field},
+	}
+
+	// the manager has no name
+	r.Managers = make([]dbmodels.CorporationManagerListResult, 0)
+	r.PageInfo, err = this.selectPage(q, opt.Page, func(rows *sql.Rows) ([]interface{}, error) {
+		var v dbmodels.CorporationManagerListResult
+		if err := rows.Scan(&v.Email, &v.Role); err != nil {
+			return nil, err
+		}
+		r.Managers = append(r.Managers, v)
+
+		return []interface{}{v.Email}, nil
+	})
+	if err != nil {
+		return r, fmt.Errorf("Failed to list corporation managers: %s", err.Error())
+	}
+	return r, nil
+}
+
+func (this *client) ListManagersWhenEmployeeSigning(claOrgIDs []string, corporID string) ([]dbmodels.CorporationManagerListResult, error) {
+	if err := checkIDs(claOrgIDs); err != nil {
+		return nil, err
+	}
+
+	v, err := this.listCLAOrgs("id = ANY($1)", pq.Array(claOrgIDs))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list corporation managers when employeee signing: %s", err.Error())
+	}
+
+	if len(v) == 0 {
+		return nil, nil
+	}
+	if len(v) != 1 {
+		return nil, fmt.Errorf("Failed to list corporation managers when employeee signing: impossible")
+	}
+
+	r, err := this.listCorporationManager("cla_org_id = $1 AND corporation_id = $2", v[0].ID, corporID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list corporation managers when employeee signing: %s", err.Error())
+	}
+	return r, nil
+}
+
+func (this *client) DeleteCorporationManager(claOrgID string, opt []dbmodels.CorporationManagerCreateOption) error {
+	if len(opt) == 0 {
+		return nil
+	}
+
+	emails := make([]string, 0, len(opt))
+	for _, m := range opt {
+		emails = append(emails, m.Email)
+	}
+
+	f := func(tx *sql.Tx) error {
+		item, err := lockCLAOrg(tx, claOrgID)
+		if err != nil {
+			return err
+		}
+
+		n := 0
+		if isCorpoCLA(item) {
+			err := tx.QueryRow(
+				"SELECT count(*) FROM corporation_managers WHERE cla_org_id = $1 AND role = $2 AND email = ANY($3)",
+				claOrgID, opt[0].Role, pq.Array(emails),
+			).Scan(&n)
+			if err != nil {
+				return err
+			}
+		}
+		if n != len(opt) {
+			return fmt.Errorf("check failed: the managers to be deleted are not all the ones registered")
+		}
+
+		_, err = tx.Exec(
+			"DELETE FROM corporation_managers WHERE cla_org_id = $1 AND email = ANY($2)",
+			claOrgID, pq.Array(emails),
+		)
+		return err
+	}
+
+	if err := this.doTransaction(f); err != nil {
+		return fmt.Errorf("Failed to delete corporation manager: %s", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+func (this *client) SignAsCorporation(claOrgID string, info dbmodels.CorporationSigningInfo) error {
+	signingInfo, err := signingInfoToJSON(info.Info)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := metadataToJSON(info.Metadata)
+	if err != nil {
+		return err
+	}
+
+	f := func(tx *sql.Tx) error {
+		item, err := lockCLAOrg(tx, claOrgID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("the binding(%s) is not exist", claOrgID)
+		}
+
+		if err := lockRepo(tx, item.Platform, item.OrgID, item.RepoID); err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(
+			"SELECT cla_org_id, admin_email, cla_version FROM corporation_signings "+
+				"WHERE corporation_id = $4 AND cla_org_id IN ("+corpoCLAsOfRepo+")",
+			item.Platform, item.OrgID, item.RepoID, info.CorporationID,
+		)
+		if err != nil {
+			return err
+		}
+
+		type signed struct {
+			claOrgID   string
+			adminEmail string
+			claVersion int
+		}
+		var v []signed
+		for rows.Next() {
+			var s signed
+			if err := rows.Scan(&s.claOrgID, &s.adminEmail, &s.claVersion); err != nil {
+				rows.Close()
+				return err
+			}
+			v = append(v, s)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(v) > 0 {
+			// it can sign again only if the version signed is older
+			s := v[0]
+			if len(v) > 1 || s.claOrgID != claOrgID || s.adminEmail != info.AdminEmail ||
+				signedCLAVersion(s.claVersion) >= info.CLAVersion {
+				return fmt.Errorf("Failed to add info when signing as corporation, it has signed")
+			}
+
+			_, err = tx.Exec(
+				"UPDATE corporation_signings SET admin_name = $3, corporation_name = $4, info = $5, "+
+					"cla_version = $6, signed_at = $7, metadata = $8 WHERE cla_org_id = $1 AND corporation_id = $2",
+				claOrgID, info.CorporationID, info.AdminName, info.CorporationName,
+				signingInfo, info.CLAVersion, info.SignedAt, metadata,
+			)
+			return err
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO corporation_signings (cla_org_id, corporation_id, admin_email, admin_name, corporation_name, "+
+				"enabled, info, cla_version, signed_at, metadata) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)",
+			claOrgID, info.CorporationID, info.AdminEmail, info.AdminName, info.CorporationName,
+			info.Enabled, signingInfo, info.CLAVersion, info.SignedAt, metadata,
+		)
+		return err
+	}
+
+	return this.doTransaction(f)
+}
+
+func (this *client) ListCorporationSigning(opt dbmodels.CorporationSigningListOption) (dbmodels.CorporationSigningPage, error) {
+	var r dbmodels.CorporationSigningPage
+
+	field, err := opt.Page.SortField(dbmodels.CorporationSigningSortFields)
+	if err != nil {
+		return r, err
+	}
+
+	q := pageSelect{
+		columns: "c.cla_org_id, c.corporation_id, c.corporation_name, c.admin_email, c.admin_name, c.enabled, " +
+			"c.cla_version, c.signed_at, c.metadata, EXISTS (SELECT 1 FROM corporation_managers m WHERE " +
+			"m.cla_org_id = c.cla_org_id AND m.email = c.admin_email AND m.role = '" + models.RoleAdmin + "')",
+		from:  "corporation_signings c",
+		where: "c.cla_org_id IN (" + corpoCLAsOfRepo + " AND ($4::text = '' OR cla_language = $4))",
+		args:  []interface{}{opt.Platform, opt.OrgID, opt.RepoID, opt.CLALanguage},
+		keys:  []string{"c." + field, "c.cla_org_id", "c.corporation_id"},
+	}
+
+	r.Signings = make([]dbmodels.CorporationSigningDetails, 0)
+	r.PageInfo, err = this.selectPage(q, opt.Page, func(rows *sql.Rows) ([]interface{}, error) {
+		var metadata []byte
+		var v dbmodels.CorporationSigningDetails
+
+		err := rows.Scan(
+			&v.CLAOrgID, &v.CorporationID, &v.CorporationName, &v.AdminEmail, &v.AdminName, &v.Enabled,
+			&v.CLAVersion, &v.SignedAt, &metadata, &v.AdministratorEnabled,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if v.Metadata, err = metadataFromJSON(metadata); err != nil {
+			return nil, err
+		}
+		v.CLAVersion = signedCLAVersion(v.CLAVersion)
+
+		r.Signings = append(r.Signings, v)
+
+		var k interface{}
+		switch field {
+		case "corporation_name":
+			k = v.CorporationName
+		case "admin_email":
+			k = v.AdminEmail
+		default:
+			k = v.SignedAt
+		}
+		return []interface{}{k, v.CLAOrgID, v.CorporationID}, nil
+	})
+	if err != nil {
+		return r, fmt.Errorf("Failed to list corporation signings: %s", err.Error())
+	}
+	return r, nil
+}
+
+func (this *client) UpdateCorporationSigning(claOrgID, adminEmail, corporationName string, opt dbmodels.CorporationSigningUpdateInfo) error {
+	if opt.Enabled == nil {
+		return nil
+	}
+
+	if err := checkID(claOrgID); err != nil {
+		return err
+	}
+
+	ok, err := checkRowsAffected(this.db.Exec(
+		"UPDATE corporation_signings SET enabled = $4 WHERE cla_org_id = $1 AND admin_email = $2 "+
+			"AND corporation_name = $3 AND enabled <> $4 AND cla_org_id IN "+
+			"(SELECT id FROM cla_orgs WHERE id = $1 AND enabled AND apply_to = $5)",
+		claOrgID, adminEmail, corporationName, *opt.Enabled, models.ApplyToCorporation,
+	))
+	if err != nil {
+		return fmt.Errorf("Failed to update corporation signing: %s", err.Error())
+	}
+	if !ok {
+		return fmt.Errorf("Failed to update corporation signing, doesn't match any record")
+	}
+	return nil
+}
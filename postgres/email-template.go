@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+func (this *client) SetEmailTemplate(claOrgID, kind string, opt dbmodels.EmailTemplate) error {
+	if err := checkID(claOrgID); err != nil {
+		return err
+	}
+
+	ok, err := checkRowsAffected(this.db.Exec(
+		"INSERT INTO email_templates (cla_org_id, kind, subject, text, html) "+
+			"SELECT $1::text, $2::text, $3::text, $4::text, $5::text "+
+			"WHERE EXISTS (SELECT 1 FROM cla_orgs WHERE id = $1 AND enabled) "+
+			"ON CONFLICT (cla_org_id, kind) DO UPDATE SET "+
+			"subject = EXCLUDED.subject, text = EXCLUDED.text, html = EXCLUDED.html",
+		claOrgID, kind, opt.Subject, opt.Text, opt.HTML,
+	))
+	if err != nil {
+		return fmt.Errorf("Failed to set email template: %s", err.Error())
+	}
+	if !ok {
+		return fmt.Errorf("Failed to set email template: the binding(%s) doesn't exist", claOrgID)
+	}
+	return nil
+}
+
+func (this *client) DeleteEmailTemplate(claOrgID, kind string) error {
+	if err := checkID(claOrgID); err != nil {
+		return err
+	}
+
+	_, err := this.db.Exec("DELETE FROM email_templates WHERE cla_org_id = $1 AND kind = $2", claOrgID, kind)
+	if err != nil {
+		return fmt.Errorf("Failed to delete email template: %s", err.Error())
+	}
+	return nil
+}
+
+func (this *client) ListEmailTemplate(claOrgID string) (map[string]dbmodels.EmailTemplate, error) {
+	b, err := this.isCLAOrgExist(claOrgID)
+	if err != nil {
+		return nil, err
+	}
+	if !b {
+		return nil, fmt.Errorf("the binding(%s) is not exist", claOrgID)
+	}
+
+	rows, err := this.db.Query(
+		"SELECT kind, subject, text, html FROM email_templates WHERE cla_org_id = $1", claOrgID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list email templates: %s", err.Error())
+	}
+	defer rows.Close()
+
+	r := map[string]dbmodels.EmailTemplate{}
+	for rows.Next() {
+		var kind string
+		var v dbmodels.EmailTemplate
+		if err := rows.Scan(&kind, &v.Subject, &v.Text, &v.HTML); err != nil {
+			return nil, err
+		}
+		r[kind] = v
+	}
+	return r, rows.Err()
+}
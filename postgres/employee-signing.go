@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+func (this *client) SignAsEmployee(claOrgID string, info dbmodels.EmployeeSigningInfo) error {
+	corporationID := corporationIDOfEmployee(info.CorporationID, info.Email)
+
+	signingInfo, err := signingInfoToJSON(info.Info)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := metadataToJSON(info.Metadata)
+	if err != nil {
+		return err
+	}
+
+	f := func(tx *sql.Tx) error {
+		item, err := lockCLAOrg(tx, claOrgID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("the binding(%s) is not exist", claOrgID)
+		}
+
+		if err := lockRepo(tx, item.Platform, item.OrgID, item.RepoID); err != nil {
+			return err
+		}
+
+		// the employee can sign again if the version signed is older
+		n := 0
+		err = tx.QueryRow(
+			"SELECT count(*) FROM employee_signings e JOIN cla_orgs o ON e.cla_org_id = o.id "+
+				"WHERE o.enabled AND o.apply_to = $1 AND o.platform = $2 AND o.org_id = $3 AND o.repo_id = $4 "+
+				"AND e.corporation_id = $5 AND e.email = $6 AND GREATEST(e.cla_version, 1) >= $7",
+			models.ApplyToIndividual, item.Platform, item.OrgID, item.RepoID,
+			corporationID, info.Email, info.CLAVersion,
+		).Scan(&n)
+		if err != nil {
+			return err
+		}
+		if n != 0 {
+			return fmt.Errorf("Failed to sign as employee, it has signed")
+		}
+
+		// resign in place and keep the enabled status
+		_, err = tx.Exec(
+			"INSERT INTO employee_signings (cla_org_id, corporation_id, email, name, enabled, info, "+
+				"signed_at, cla_version, metadata) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) "+
+				"ON CONFLICT (cla_org_id, corporation_id, email) DO UPDATE SET name = EXCLUDED.name, "+
+				"info = EXCLUDED.info, signed_at = EXCLUDED.signed_at, "+
+				"cla_version = EXCLUDED.cla_version, metadata = EXCLUDED.metadata",
+			claOrgID, corporationID, info.Email, info.Name, info.Enabled, signingInfo,
+			info.SignedAt, info.CLAVersion, metadata,
+		)
+		return err
+	}
+
+	return this.doTransaction(f)
+}
+
+func (this *client) ListEmployeeSigning(opt dbmodels.EmployeeSigningListOption) (dbmodels.EmployeeSigningPage, error) {
+	var r dbmodels.EmployeeSigningPage
+
+	if !strings.Contains(opt.CorporationEmail, "@") {
+		return r, fmt.Errorf("invalid corporation email: %s", opt.CorporationEmail)
+	}
+
+	field, err := opt.Page.SortField(dbmodels.EmployeeSigningSortFields)
+	if err != nil {
+		return r, err
+	}
+
+	q := pageSelect{
+		columns: "e.cla_org_id, e.email, e.name, e.enabled, e.signed_at, e.cla_version, e.metadata",
+		from:    "employee_signings e JOIN cla_orgs o ON e.cla_org_id = o.id",
+		where: "o.enabled AND o.apply_to = $1 AND o.platform = $2 AND o.org_id = $3 " +
+			"AND ($4::text = '' OR o.repo_id = $4) AND ($5::text = '' OR o.cla_language = $5) AND e.corporation_id = $6",
+		args: []interface{}{
+			models.ApplyToIndividual, opt.Platform, opt.OrgID, opt.RepoID, opt.CLALanguage,
+			emailSuffixToKey(opt.CorporationEmail),
+		},
+		keys: []string{"e." + field, "e.cla_org_id", "e.email"},
+	}
+
+	r.Signings = make([]dbmodels.EmployeeSigningDetail, 0)
+	r.PageInfo, err = this.selectPage(q, opt.Page, func(rows *sql.Rows) ([]interface{}, error) {
+		var metadata []byte
+		var v dbmodels.EmployeeSigningDetail
+
+		// the info filled by employee is not listed
+		err := rows.Scan(&v.CLAOrgID, &v.Email, &v.Name, &v.Enabled, &v.SignedAt, &v.CLAVersion, &metadata)
+		if err != nil {
+			return nil, err
+		}
+
+		if v.Metadata, err = metadataFromJSON(metadata); err != nil {
+			return nil, err
+		}
+		v.CLAVersion = signedCLAVersion(v.CLAVersion)
+
+		r.Signings = append(r.Signings, v)
+
+		var k interface{}
+		switch field {
+		case "email":
+			k = v.Email
+		case "name":
+			k = v.Name
+		default:
+			k = v.SignedAt
+		}
+		return []interface{}{k, v.CLAOrgID, v.Email}, nil
+	})
+	if err != nil {
+		return r, fmt.Errorf("Failed to list employee signings: %s", err.Error())
+	}
+	return r, nil
+}
+
+func (this *client) UpdateEmployeeSigning(claOrgID, email string, opt dbmodels.EmployeeSigningUpdateInfo) error {
+	if err := checkID(claOrgID); err != nil {
+		return err
+	}
+
+	ok, err := checkRowsAffected(this.db.Exec(
+		"UPDATE employee_signings SET enabled = $4 WHERE cla_org_id = $1 AND corporation_id = $2 "+
+			"AND email = $3 AND enabled <> $4 AND cla_org_id IN "+
+			"(SELECT id FROM cla_orgs WHERE id = $1 AND enabled AND apply_to = $5)",
+		claOrgID, corporationIDOfEmployee(opt.CorporationID, email), email, opt.Enabled, models.ApplyToIndividual,
+	))
+	if err != nil {
+		return fmt.Errorf("Failed to update employee signing: %s", err.Error())
+	}
+	if !ok {
+		return fmt.Errorf("Failed to update employee signing, impossible")
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+func (this *client) SignAsIndividual(claOrgID string, info dbmodels.IndividualSigningInfo) error {
+	signingInfo, err := signingInfoToJSON(info.Info)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := metadataToJSON(info.Metadata)
+	if err != nil {
+		return err
+	}
+
+	f := func(tx *sql.Tx) error {
+		item, err := lockCLAOrg(tx, claOrgID)
+		if err != nil {
+			return err
+		}
+
+		// it can sign again if the version signed is older
+		if item != nil {
+			v := 0
+			err := tx.QueryRow(
+				"SELECT cla_version FROM individual_signings WHERE cla_org_id = $1 AND email = $2",
+				claOrgID, info.Email,
+			).Scan(&v)
+
+			if err == nil && signedCLAVersion(v) >= info.CLAVersion {
+				item = nil
+			} else if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+		}
+		if item == nil {
+			return fmt.Errorf("Failed to add info when signing as individual, maybe he/she has signed")
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO individual_signings (cla_org_id, email, info, signed_at, cla_version, metadata) "+
+				"VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (cla_org_id, email) DO UPDATE SET "+
+				"info = EXCLUDED.info, signed_at = EXCLUDED.signed_at, "+
+				"cla_version = EXCLUDED.cla_version, metadata = EXCLUDED.metadata",
+			claOrgID, info.Email, signingInfo, info.SignedAt, info.CLAVersion, metadata,
+		)
+		return err
+	}
+
+	return this.doTransaction(f)
+}
+
+// bindingsOfRepo returns the bindings of repo if there are, otherwise the ones of org.
+func bindingsOfRepo(v []dbmodels.CLAOrg, repoID string) []dbmodels.CLAOrg {
+	r := make([]dbmodels.CLAOrg, 0, len(v))
+	if repoID != "" {
+		for _, item := range v {
+			if item.RepoID == repoID {
+				r = append(r, item)
+			}
+		}
+		if len(r) != 0 {
+			return r
+		}
+	}
+
+	for _, item := range v {
+		if item.RepoID == "" {
+			r = append(r, item)
+		}
+	}
+	return r
+}
+
+// enabledCorporationSigning returns the cla signed by the corporation if it
+// has signed and been enabled, otherwise nil.
+func (this *client) enabledCorporationSigning(platform, orgID, repoID, corporationID string) (*dbmodels.CorporationSigningCheckResult, error) {
+	var r dbmodels.CorporationSigningCheckResult
+
+	err := this.db.QueryRow(
+		"SELECT o.id, o.cla_id, c.cla_version, o.cla_version FROM corporation_signings c "+
+			"JOIN cla_orgs o ON c.cla_org_id = o.id WHERE c.enabled AND c.corporation_id = $4 "+
+			"AND c.cla_org_id IN ("+corpoCLAsOfRepo+") LIMIT 1",
+		platform, orgID, repoID, corporationID,
+	).Scan(&r.CLAOrgID, &r.CLAID, &r.CLAVersion, &r.CurrentCLAVersion)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.CLAVersion = signedCLAVersion(r.CLAVersion)
+	if r.CurrentCLAVersion == 0 {
+		r.CurrentCLAVersion = 1
+	}
+	return &r, nil
+}
+
+func newSigningCheckResult(t string, item *dbmodels.CLAOrg, signedAt int64, claVersion int) *dbmodels.SigningCheckResult {
+	return &dbmodels.SigningCheckResult{
+		Type:        t,
+		CLAOrgID:    item.ID,
+		CLAID:       item.CLAID,
+		CLALanguage: item.CLALanguage,
+		SignedAt:    signedAt,
+
+		CLAVersion:        signedCLAVersion(claVersion),
+		CurrentCLAVersion: item.CLAVersion,
+	}
+}
+
+type signingOfBinding struct {
+	signedAt   int64
+	claVersion int
+}
+
+// listSigningOfBindings returns the signings found by the query, key is the id of binding
+func (this *client) listSigningOfBindings(query string, args ...interface{}) (map[string]signingOfBinding, error) {
+	rows, err := this.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	r := map[string]signingOfBinding{}
+	for rows.Next() {
+		var id string
+		var v signingOfBinding
+		if err := rows.Scan(&id, &v.signedAt, &v.claVersion); err != nil {
+			return nil, err
+		}
+		r[id] = v
+	}
+	return r, rows.Err()
+}
+
+func (this *client) CheckSigning(opt dbmodels.SigningCheckOption) (*dbmodels.SigningCheckResult, error) {
+	v, err := this.listCLAOrgs(
+		"enabled AND apply_to = $1 AND platform = $2 AND org_id = $3",
+		models.ApplyToIndividual, opt.Platform, opt.OrgID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to check signing: %s", err.Error())
+	}
+
+	v = bindingsOfRepo(v, opt.RepoID)
+	if len(v) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(v))
+	for _, item := range v {
+		ids = append(ids, item.ID)
+	}
+
+	individuals, err := this.listSigningOfBindings(
+		"SELECT cla_org_id, signed_at, cla_version FROM individual_signings WHERE cla_org_id = ANY($1) AND email = $2",
+		pq.Array(ids), opt.Email,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to check signing: %s", err.Error())
+	}
+
+	for i := range v {
+		if s, ok := individuals[v[i].ID]; ok {
+			return newSigningCheckResult(models.ApplyToIndividual, &v[i], s.signedAt, s.claVersion), nil
+		}
+	}
+
+	corporationID := corporationIDOfEmployee(opt.CorporationID, opt.Email)
+
+	employees, err := this.listSigningOfBindings(
+		"SELECT cla_org_id, signed_at, cla_version FROM employee_signings "+
+			"WHERE cla_org_id = ANY($1) AND corporation_id = $2 AND email = $3 AND enabled",
+		pq.Array(ids), corporationID, opt.Email,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to check signing: %s", err.Error())
+	}
+
+	for i := range v {
+		s, ok := employees[v[i].ID]
+		if !ok {
+			continue
+		}
+
+		corp, err := this.enabledCorporationSigning(opt.Platform, opt.OrgID, v[i].RepoID, corporationID)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to check signing: %s", err.Error())
+		}
+		if corp != nil {
+			r := newSigningCheckResult(models.ApplyToCorporation, &v[i], s.signedAt, s.claVersion)
+			r.Corporation = corp
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (this *client) ListIndividualSigning(opt dbmodels.IndividualSigningListOption) (dbmodels.IndividualSigningPage, error) {
+	var r dbmodels.IndividualSigningPage
+
+	field, err := opt.Page.SortField(dbmodels.IndividualSigningSortFields)
+	if err != nil {
+		return r, err
+	}
+
+	q := pageSelect{
+		columns: "s.email, s.info, s.signed_at, s.cla_version, s.metadata, o.id, o.repo_id, o.cla_language",
+		from:    "individual_signings s JOIN cla_orgs o ON s.cla_org_id = o.id",
+		where: "o.enabled AND o.apply_to = $1 AND o.platform = $2 AND o.org_id = $3 " +
+			"AND ($4::text = '' OR o.repo_id = $4) AND ($5::text = '' OR o.cla_language = $5) " +
+			"AND ($6::text = '' OR strpos(lower(s.email), lower($6)) > 0) " +
+			"AND ($7::bigint <= 0 OR s.signed_at >= $7) AND ($8::bigint <= 0 OR s.signed_at <= $8)",
+		args: []interface{}{
+			models.ApplyToIndividual, opt.Platform, opt.OrgID, opt.RepoID, opt.CLALanguage,
+			opt.EmailContains, opt.SignedFrom, opt.SignedTo,
+		},
+		keys: []string{"s." + field, "s.cla_org_id", "s.email"},
+	}
+
+	r.Signings = make([]dbmodels.IndividualSigningDetail, 0)
+	r.PageInfo, err = this.selectPage(q, opt.Page, func(rows *sql.Rows) ([]interface{}, error) {
+		var v dbmodels.IndividualSigningDetail
+		var info, metadata []byte
+
+		err := rows.Scan(
+			&v.Email, &info, &v.SignedAt, &v.CLAVersion, &metadata,
+			&v.CLAOrgID, &v.RepoID, &v.CLALanguage,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if v.Info, err = signingInfoFromJSON(info); err != nil {
+			return nil, err
+		}
+		if v.Metadata, err = metadataFromJSON(metadata); err != nil {
+			return nil, err
+		}
+		v.CLAVersion = signedCLAVersion(v.CLAVersion)
+
+		r.Signings = append(r.Signings, v)
+
+		var k interface{} = v.SignedAt
+		if field == "email" {
+			k = v.Email
+		}
+		return []interface{}{k, v.CLAOrgID, v.Email}, nil
+	})
+	if err != nil {
+		return r, fmt.Errorf("Failed to list individual signings: %s", err.Error())
+	}
+	return r, nil
+}
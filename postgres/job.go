@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+const jobColumns = "id, kind, cla_org_id, corporation_id, email, payload, status, attempts, max_attempts, " +
+	"next_run_at, locked_until, last_error, created_at, updated_at, claim_token"
+
+func scanJob(s scanner) (dbmodels.Job, error) {
+	var v dbmodels.Job
+
+	err := s.Scan(
+		&v.ID, &v.Kind, &v.CLAOrgID, &v.CorporationID, &v.Email, &v.Payload, &v.Status, &v.Attempts,
+		&v.MaxAttempts, &v.NextRunAt, &v.LockedUntil, &v.LastError, &v.CreatedAt, &v.UpdatedAt, &v.ClaimToken,
+	)
+	return v, err
+}
+
+func (this *client) CreateJob(info dbmodels.Job) (string, error) {
+	uid := newID()
+
+	_, err := this.db.Exec(
+		"INSERT INTO jobs ("+jobColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)",
+		uid, info.Kind, info.CLAOrgID, info.CorporationID, info.Email, info.Payload, info.Status, info.Attempts,
+		info.MaxAttempts, info.NextRunAt, info.LockedUntil, info.LastError, info.CreatedAt, info.UpdatedAt, info.ClaimToken,
+	)
+	if err != nil {
+		return "", fmt.Errorf("Failed to create job: %s", err.Error())
+	}
+	return uid, nil
+}
+
+func (this *client) ClaimJob(now, lockedUntil int64, claimToken string) (*dbmodels.Job, error) {
+	// A running job whose lock has expired was left by a crashed worker,
+	// so it can be picked up again.
+	v, err := scanJob(this.db.QueryRow(
+		"UPDATE jobs SET status = $3, locked_until = $2, updated_at = $1, attempts = attempts + 1, claim_token = $5 "+
+			"WHERE id = (SELECT id FROM jobs WHERE (status = $4 AND next_run_at <= $1) "+
+			"OR (status = $3 AND locked_until < $1) ORDER BY next_run_at LIMIT 1 FOR UPDATE SKIP LOCKED) "+
+			"RETURNING "+jobColumns,
+		now, lockedUntil, models.JobStatusRunning, models.JobStatusPending, claimToken,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to claim job: %s", err.Error())
+	}
+	return &v, nil
+}
+
+func (this *client) UpdateJob(uid string, opt dbmodels.JobUpdateInfo) error {
+	if err := checkID(uid); err != nil {
+		return err
+	}
+
+	args := []interface{}{uid}
+	set := []string{}
+	add := func(field string, v interface{}) {
+		args = append(args, v)
+		set = append(set, fmt.Sprintf("%s = $%d", field, len(args)))
+	}
+
+	if opt.Status != nil {
+		add("status", *opt.Status)
+	}
+	if opt.Attempts != nil {
+		add("attempts", *opt.Attempts)
+	}
+	if opt.LastError != nil {
+		add("last_error", *opt.LastError)
+	}
+	if opt.NextRunAt != nil {
+		add("next_run_at", *opt.NextRunAt)
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	add("updated_at", time.Now().Unix())
+
+	where := "id = $1"
+	if len(opt.ExpectedStatus) != 0 {
+		args = append(args, pq.Array(opt.ExpectedStatus))
+		where += fmt.Sprintf(" AND status = ANY($%d)", len(args))
+	}
+	if opt.ClaimToken != "" {
+		args = append(args, opt.ClaimToken)
+		where += fmt.Sprintf(" AND claim_token = $%d", len(args))
+	}
+
+	ok, err := checkRowsAffected(this.db.Exec(
+		"UPDATE jobs SET "+strings.Join(set, ", ")+" WHERE "+where, args...,
+	))
+	if err != nil {
+		return fmt.Errorf("Failed to update job: %s", err.Error())
+	}
+	if !ok {
+		return fmt.Errorf("Failed to update job, the job is not exist, its status is not in %v or it has been claimed by others", opt.ExpectedStatus)
+	}
+	return nil
+}
+
+func (this *client) GetJob(uid string) (dbmodels.Job, error) {
+	if err := checkID(uid); err != nil {
+		return dbmodels.Job{}, err
+	}
+
+	v, err := scanJob(this.db.QueryRow("SELECT "+jobColumns+" FROM jobs WHERE id = $1", uid))
+	if err == sql.ErrNoRows {
+		return v, fmt.Errorf("the job(%s) is not exist", uid)
+	}
+	return v, err
+}
+
+func (this *client) ListJob(opt dbmodels.JobListOption) (dbmodels.JobPage, error) {
+	r := dbmodels.JobPage{}
+
+	if _, err := opt.Page.SortField(dbmodels.JobSortFields); err != nil {
+		return r, err
+	}
+
+	q := pageSelect{
+		columns: jobColumns,
+		from:    "jobs",
+		where:   "cla_org_id = $1 AND ($2::text = '' OR status = $2) AND ($3::text = '' OR corporation_id = $3)",
+		args:    []interface{}{opt.CLAOrgID, opt.Status, opt.CorporationID},
+		keys:    []string{"created_at", "id"},
+	}
+
+	r.Jobs = make([]dbmodels.Job, 0)
+	scan := func(rows *sql.Rows) ([]interface{}, error) {
+		v, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		r.Jobs = append(r.Jobs, v)
+		return []interface{}{v.CreatedAt, v.ID}, nil
+	}
+
+	info, err := this.selectPage(q, opt.Page, scan)
+	if err != nil {
+		return r, fmt.Errorf("Failed to list jobs: %s", err.Error())
+	}
+	r.PageInfo = info
+	return r, nil
+}
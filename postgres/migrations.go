@@ -0,0 +1,300 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type migration struct {
+	version     int
+	description string
+	sql         string
+}
+
+// migrations are applied in order and each one is applied only once.
+// The applied ones must not be changed, append a new one instead.
+var migrations = []migration{
+	{1, "create the initial schema", schemaV1},
+	{2, "record the submitter of org email", schemaV2},
+	{3, "record the token of claiming job", schemaV3},
+}
+
+func (this *client) migrate() error {
+	_, err := this.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version     INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at  BIGINT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create the table of migrations: %s", err.Error())
+	}
+
+	for _, m := range migrations {
+		if err := this.applyMigration(m); err != nil {
+			return fmt.Errorf("failed to apply migration %d(%s): %s", m.version, m.description, err.Error())
+		}
+	}
+	return nil
+}
+
+func (this *client) applyMigration(m migration) error {
+	f := func(tx *sql.Tx) error {
+		// the other instances starting at the same time wait here
+		if _, err := tx.Exec("LOCK TABLE schema_migrations IN EXCLUSIVE MODE"); err != nil {
+			return err
+		}
+
+		n := 0
+		err := tx.QueryRow("SELECT count(*) FROM schema_migrations WHERE version = $1", m.version).Scan(&n)
+		if err != nil || n != 0 {
+			return err
+		}
+
+		if _, err := tx.Exec(m.sql); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO schema_migrations (version, description, applied_at) VALUES ($1, $2, $3)",
+			m.version, m.description, time.Now().Unix(),
+		)
+		return err
+	}
+
+	return this.doTransaction(f)
+}
+
+const schemaV1 = `
+CREATE TABLE cla_metadatas (
+	id        TEXT PRIMARY KEY,
+	name      TEXT NOT NULL,
+	text      TEXT NOT NULL,
+	language  TEXT NOT NULL,
+	submitter TEXT NOT NULL,
+	UNIQUE (submitter, name)
+);
+
+CREATE TABLE clas (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	text       TEXT NOT NULL,
+	language   TEXT NOT NULL,
+	submitter  TEXT NOT NULL,
+	apply_to   TEXT NOT NULL,
+	fields     JSONB,
+	version    INTEGER NOT NULL DEFAULT 0,
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL,
+	UNIQUE (submitter, name)
+);
+
+CREATE TABLE cla_versions (
+	cla_id         TEXT NOT NULL REFERENCES clas (id) ON DELETE CASCADE,
+	version        INTEGER NOT NULL,
+	text           TEXT NOT NULL,
+	require_resign BOOLEAN NOT NULL DEFAULT FALSE,
+	published_at   BIGINT NOT NULL DEFAULT 0,
+	PRIMARY KEY (cla_id, version)
+);
+
+CREATE TABLE cla_orgs (
+	id                           TEXT PRIMARY KEY,
+	platform                     TEXT NOT NULL,
+	org_id                       TEXT NOT NULL,
+	repo_id                      TEXT NOT NULL DEFAULT '',
+	cla_id                       TEXT NOT NULL REFERENCES clas (id),
+	cla_language                 TEXT NOT NULL,
+	cla_version                  INTEGER NOT NULL DEFAULT 0,
+	apply_to                     TEXT NOT NULL,
+	org_email                    TEXT NOT NULL,
+	enabled                      BOOLEAN NOT NULL,
+	submitter                    TEXT NOT NULL,
+	domain_verification_required BOOLEAN NOT NULL DEFAULT FALSE,
+	org_signature                BYTEA,
+	bot_token                    TEXT NOT NULL DEFAULT '',
+	created_at                   BIGINT NOT NULL,
+	updated_at                   BIGINT NOT NULL
+);
+
+-- the org/repo can be bound only one cla of each language and kind
+CREATE UNIQUE INDEX cla_orgs_org_identifier
+	ON cla_orgs (platform, org_id, repo_id, cla_language, apply_to) WHERE enabled;
+
+CREATE TABLE individual_signings (
+	cla_org_id  TEXT NOT NULL REFERENCES cla_orgs (id),
+	email       TEXT NOT NULL,
+	info        JSONB,
+	signed_at   BIGINT NOT NULL DEFAULT 0,
+	cla_version INTEGER NOT NULL DEFAULT 0,
+	metadata    JSONB,
+	PRIMARY KEY (cla_org_id, email)
+);
+
+CREATE INDEX individual_signings_email ON individual_signings (email);
+
+CREATE TABLE corporation_signings (
+	cla_org_id       TEXT NOT NULL REFERENCES cla_orgs (id),
+	corporation_id   TEXT NOT NULL,
+	admin_email      TEXT NOT NULL,
+	admin_name       TEXT NOT NULL,
+	corporation_name TEXT NOT NULL,
+	enabled          BOOLEAN NOT NULL DEFAULT FALSE,
+	info             JSONB,
+	cla_version      INTEGER NOT NULL DEFAULT 0,
+	signed_at        BIGINT NOT NULL DEFAULT 0,
+	metadata         JSONB,
+	PRIMARY KEY (cla_org_id, corporation_id)
+);
+
+CREATE TABLE corporation_managers (
+	cla_org_id           TEXT NOT NULL REFERENCES cla_orgs (id),
+	email                TEXT NOT NULL,
+	role                 TEXT NOT NULL,
+	password             TEXT NOT NULL,
+	corporation_id       TEXT NOT NULL,
+	must_change_password BOOLEAN NOT NULL DEFAULT FALSE,
+	PRIMARY KEY (cla_org_id, email)
+);
+
+CREATE INDEX corporation_managers_email ON corporation_managers (email);
+
+CREATE TABLE employee_signings (
+	cla_org_id     TEXT NOT NULL REFERENCES cla_orgs (id),
+	corporation_id TEXT NOT NULL,
+	email          TEXT NOT NULL,
+	name           TEXT NOT NULL,
+	enabled        BOOLEAN NOT NULL DEFAULT FALSE,
+	info           JSONB,
+	signed_at      BIGINT NOT NULL DEFAULT 0,
+	cla_version    INTEGER NOT NULL DEFAULT 0,
+	metadata       JSONB,
+	PRIMARY KEY (cla_org_id, corporation_id, email)
+);
+
+CREATE INDEX employee_signings_email ON employee_signings (email);
+
+CREATE TABLE revoked_signings (
+	id          BIGSERIAL PRIMARY KEY,
+	cla_org_id  TEXT NOT NULL REFERENCES cla_orgs (id),
+	type        TEXT NOT NULL,
+	email       TEXT NOT NULL,
+	signed_at   BIGINT NOT NULL,
+	cla_version INTEGER NOT NULL,
+	revoked_by  TEXT NOT NULL,
+	reason      TEXT NOT NULL,
+	revoked_at  BIGINT NOT NULL
+);
+
+CREATE INDEX revoked_signings_cla_org_id ON revoked_signings (cla_org_id);
+
+CREATE TABLE corporation_domains (
+	cla_org_id     TEXT NOT NULL,
+	corporation_id TEXT NOT NULL,
+	domain         TEXT NOT NULL,
+	token          TEXT NOT NULL,
+	verified       BOOLEAN NOT NULL DEFAULT FALSE,
+	verified_at    BIGINT NOT NULL DEFAULT 0,
+	created_at     BIGINT NOT NULL DEFAULT 0,
+	PRIMARY KEY (cla_org_id, domain),
+	FOREIGN KEY (cla_org_id, corporation_id) REFERENCES corporation_signings (cla_org_id, corporation_id)
+);
+
+CREATE TABLE email_templates (
+	cla_org_id TEXT NOT NULL REFERENCES cla_orgs (id),
+	kind       TEXT NOT NULL,
+	subject    TEXT NOT NULL,
+	text       TEXT NOT NULL,
+	html       TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (cla_org_id, kind)
+);
+
+CREATE TABLE org_emails (
+	email    TEXT PRIMARY KEY,
+	platform TEXT NOT NULL,
+	token    BYTEA
+);
+
+CREATE TABLE blank_signatures (
+	language TEXT PRIMARY KEY,
+	pdf      BYTEA NOT NULL
+);
+
+CREATE TABLE platform_tokens (
+	platform_user TEXT PRIMARY KEY,
+	token         TEXT NOT NULL
+);
+
+CREATE TABLE verification_codes (
+	id         BIGSERIAL PRIMARY KEY,
+	email      TEXT NOT NULL,
+	code       TEXT NOT NULL,
+	purpose    TEXT NOT NULL,
+	expiry     BIGINT NOT NULL,
+	created_at BIGINT NOT NULL DEFAULT 0,
+	ip         TEXT NOT NULL DEFAULT '',
+	attempts   INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX verification_codes_email ON verification_codes (email, purpose);
+CREATE INDEX verification_codes_ip ON verification_codes (ip, created_at);
+
+CREATE TABLE jobs (
+	id             TEXT PRIMARY KEY,
+	kind           TEXT NOT NULL,
+	cla_org_id     TEXT NOT NULL,
+	corporation_id TEXT NOT NULL DEFAULT '',
+	email          TEXT NOT NULL,
+	payload        TEXT NOT NULL DEFAULT '',
+	status         TEXT NOT NULL,
+	attempts       INTEGER NOT NULL DEFAULT 0,
+	max_attempts   INTEGER NOT NULL,
+	next_run_at    BIGINT NOT NULL DEFAULT 0,
+	locked_until   BIGINT NOT NULL DEFAULT 0,
+	last_error     TEXT NOT NULL DEFAULT '',
+	created_at     BIGINT NOT NULL DEFAULT 0,
+	updated_at     BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX jobs_status ON jobs (status, next_run_at);
+CREATE INDEX jobs_cla_org_id ON jobs (cla_org_id, created_at);
+
+CREATE TABLE refresh_tokens (
+	id         TEXT PRIMARY KEY,
+	token      TEXT NOT NULL,
+	user_name  TEXT NOT NULL,
+	permission TEXT NOT NULL,
+	expiry     BIGINT NOT NULL
+);
+
+CREATE INDEX refresh_tokens_user_name ON refresh_tokens (user_name);
+
+CREATE TABLE revoked_sessions (
+	id     TEXT PRIMARY KEY,
+	expiry BIGINT NOT NULL
+);
+
+CREATE TABLE audit_logs (
+	seq            BIGSERIAL PRIMARY KEY,
+	id             TEXT NOT NULL UNIQUE,
+	actor          TEXT NOT NULL,
+	permission     TEXT NOT NULL DEFAULT '',
+	action         TEXT NOT NULL,
+	cla_org_id     TEXT NOT NULL DEFAULT '',
+	corporation_id TEXT NOT NULL DEFAULT '',
+	target         TEXT NOT NULL DEFAULT '',
+	before         TEXT NOT NULL DEFAULT '',
+	after          TEXT NOT NULL DEFAULT '',
+	created_at     BIGINT NOT NULL
+);
+
+CREATE INDEX audit_logs_created_at ON audit_logs (created_at);
+`
+
+const schemaV2 = `
+ALTER TABLE org_emails ADD COLUMN submitter TEXT NOT NULL DEFAULT '';
+`
+
+const schemaV3 = `
+ALTER TABLE jobs ADD COLUMN claim_token TEXT NOT NULL DEFAULT '';
+`
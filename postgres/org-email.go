@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+// CreateOrgEmail updates the credential if the email has been added by the same submitter
+func (this *client) CreateOrgEmail(opt dbmodels.OrgEmailCreateInfo) error {
+	r, err := this.db.Exec(
+		"INSERT INTO org_emails (email, platform, token, submitter) VALUES ($1, $2, $3, $4) "+
+			"ON CONFLICT (email) DO UPDATE SET platform = EXCLUDED.platform, token = EXCLUDED.token, "+
+			"submitter = EXCLUDED.submitter "+
+			"WHERE COALESCE(NULLIF(org_emails.submitter, ''), org_emails.email) = EXCLUDED.submitter",
+		opt.Email, opt.Platform, opt.Token, opt.Submitter,
+	)
+	if err != nil {
+		return fmt.Errorf("Failed to create org email: %s", err.Error())
+	}
+
+	if n, err := r.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("Failed to create org email: it was added by others")
+	}
+	return nil
+}
+
+func (this *client) GetOrgEmailInfo(email string) (dbmodels.OrgEmailCreateInfo, error) {
+	r := dbmodels.OrgEmailCreateInfo{Email: email}
+
+	err := this.db.QueryRow(
+		"SELECT platform, token, submitter FROM org_emails WHERE email = $1", email,
+	).Scan(&r.Platform, &r.Token, &r.Submitter)
+	if err == sql.ErrNoRows {
+		return r, fmt.Errorf("the org email(%s) is not exist", email)
+	}
+	return r, err
+}
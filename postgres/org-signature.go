@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func (this *client) UploadOrgSignature(claOrgID string, pdf []byte) error {
+	if err := checkID(claOrgID); err != nil {
+		return err
+	}
+
+	_, err := this.db.Exec("UPDATE cla_orgs SET org_signature = $2 WHERE id = $1", claOrgID, pdf)
+	if err != nil {
+		return fmt.Errorf("Failed to upload org signature: %s", err.Error())
+	}
+	return nil
+}
+
+func (this *client) DownloadOrgSignature(claOrgID string) ([]byte, error) {
+	if err := checkID(claOrgID); err != nil {
+		return nil, err
+	}
+
+	var r []byte
+	err := this.db.QueryRow(
+		"SELECT org_signature FROM cla_orgs WHERE id = $1 AND org_signature IS NOT NULL", claOrgID,
+	).Scan(&r)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("the org signature of binding(%s) is not uploaded", claOrgID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+func (this *client) SavePlatformToken(opt dbmodels.PlatformToken) error {
+	_, err := this.db.Exec(
+		"INSERT INTO platform_tokens (platform_user, token) VALUES ($1, $2) "+
+			"ON CONFLICT (platform_user) DO UPDATE SET token = EXCLUDED.token",
+		opt.User, opt.Token,
+	)
+	if err != nil {
+		return fmt.Errorf("Failed to save platform token: %s", err.Error())
+	}
+	return nil
+}
+
+func (this *client) GetPlatformToken(user string) (dbmodels.PlatformToken, error) {
+	r := dbmodels.PlatformToken{User: user}
+
+	err := this.db.QueryRow("SELECT token FROM platform_tokens WHERE platform_user = $1", user).Scan(&r.Token)
+	if err == sql.ErrNoRows {
+		return r, fmt.Errorf("the platform token of %s is not exist", user)
+	}
+	return r, err
+}
@@ -0,0 +1,266 @@
+package postgres
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+var _ models.IDB = (*client)(nil)
+var _ dbmodels.IDB = (*client)(nil)
+
+type client struct {
+	db *sql.DB
+}
+
+// RegisterDatabase connects to the postgres and migrates the schema to the latest version.
+func RegisterDatabase(conn string) (*client, error) {
+	db, err := sql.Open("postgres", conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to postgres: %s", err.Error())
+	}
+
+	cli := &client{db: db}
+	if err := cli.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return cli, nil
+}
+
+func (this *client) Close() error {
+	return this.db.Close()
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (this *client) doTransaction(f func(*sql.Tx) error) error {
+	tx, err := this.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start postgres transaction: %s", err.Error())
+	}
+
+	if err := f(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// lockRepo serializes the transactions which check the signings of all the
+// bindings of org/repo before changing them. The lock is released at the end
+// of transaction.
+func lockRepo(tx *sql.Tx, platform, orgID, repoID string) error {
+	_, err := tx.Exec(
+		"SELECT pg_advisory_xact_lock(hashtext($1))",
+		fmt.Sprintf("%s/%s/%s", platform, orgID, repoID),
+	)
+	return err
+}
+
+// newID generates the id which looks like the one of mongodb, so the
+// ids are the same format whatever the backend is.
+func newID() string {
+	b := make([]byte, 12)
+	binary.BigEndian.PutUint32(b, uint32(time.Now().Unix()))
+	rand.Read(b[4:])
+	return hex.EncodeToString(b)
+}
+
+func checkID(uid string) error {
+	if len(uid) != 24 || strings.Trim(strings.ToLower(uid), "0123456789abcdef") != "" {
+		return fmt.Errorf("the provided hex string is not a valid id")
+	}
+	return nil
+}
+
+func checkIDs(ids []string) error {
+	for _, id := range ids {
+		if err := checkID(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func emailToKey(email string) string {
+	return strings.ReplaceAll(email, ".", "_")
+}
+
+func emailSuffixToKey(email string) string {
+	return emailToKey(strings.Split(email, "@")[1])
+}
+
+// corporationIDOfEmployee returns the corporation which the employee belongs to.
+// It is the domain of email if the corporation is not specified.
+func corporationIDOfEmployee(corporationID, email string) string {
+	if corporationID != "" {
+		return corporationID
+	}
+	return emailSuffixToKey(email)
+}
+
+// signedCLAVersion returns the version of cla signed, and the signing
+// done before versioning is treated as version 1.
+func signedCLAVersion(v int) int {
+	if v == 0 {
+		return 1
+	}
+	return v
+}
+
+// The json is passed as string, because lib/pq sends []byte in binary
+// format which is not accepted by the column of jsonb.
+
+func signingInfoToJSON(info dbmodels.TypeSigningInfo) (interface{}, error) {
+	if info == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func signingInfoFromJSON(b []byte) (dbmodels.TypeSigningInfo, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	var v dbmodels.TypeSigningInfo
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func metadataToJSON(m *dbmodels.SigningMetadata) (interface{}, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func metadataFromJSON(b []byte) (*dbmodels.SigningMetadata, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	var v dbmodels.SigningMetadata
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func checkRowsAffected(r sql.Result, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+
+	n, err := r.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n != 0, nil
+}
+
+// pageSelect is the query of a page of the list sorted by keys. keys are the
+// sort field and the columns which make the row unique in the list.
+type pageSelect struct {
+	columns string
+	from    string
+	where   string
+	args    []interface{}
+	keys    []string
+}
+
+// selectPage queries the rows after the cursor of page in order, and calls
+// scan for each row in the page. scan returns the values of sort keys of the row.
+func (this *client) selectPage(q pageSelect, page dbmodels.PageOption, scan func(*sql.Rows) ([]interface{}, error)) (dbmodels.PageInfo, error) {
+	var info dbmodels.PageInfo
+
+	cursor, err := page.DecodeCursor(len(q.keys))
+	if err != nil {
+		return info, err
+	}
+
+	err = this.db.QueryRow("SELECT count(*) FROM "+q.from+" WHERE "+q.where, q.args...).Scan(&info.Total)
+	if err != nil {
+		return info, err
+	}
+
+	op, dir := ">", ""
+	if page.Desc {
+		op, dir = "<", " DESC"
+	}
+
+	where, args := q.where, q.args
+	if cursor != nil {
+		params := make([]string, 0, len(cursor))
+		for i := range cursor {
+			params = append(params, fmt.Sprintf("$%d", len(q.args)+i+1))
+		}
+
+		where = fmt.Sprintf(
+			"(%s) AND (%s) %s (%s)",
+			q.where, strings.Join(q.keys, ", "), op, strings.Join(params, ", "),
+		)
+		args = append(append([]interface{}{}, q.args...), cursor...)
+	}
+
+	order := make([]string, 0, len(q.keys))
+	for _, k := range q.keys {
+		order = append(order, k+dir)
+	}
+
+	query := "SELECT " + q.columns + " FROM " + q.from + " WHERE " + where + " ORDER BY " + strings.Join(order, ", ")
+	if page.Limit > 0 {
+		// one more row tells whether there is next page
+		query += fmt.Sprintf(" LIMIT %d", page.Limit+1)
+	}
+
+	rows, err := this.db.Query(query, args...)
+	if err != nil {
+		return info, err
+	}
+	defer rows.Close()
+
+	var last []interface{}
+	for n := 0; rows.Next(); n++ {
+		if page.Limit > 0 && n == page.Limit {
+			info.NextCursor = page.EncodeCursor(last...)
+			break
+		}
+
+		if last, err = scan(rows); err != nil {
+			return info, err
+		}
+	}
+	return info, rows.Err()
+}
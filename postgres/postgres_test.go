@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels/conformance"
+)
+
+// The suite runs against a local postgres without docker, for example:
+//
+//	initdb -D /tmp/pgdata -U postgres --auth=trust
+//	pg_ctl -D /tmp/pgdata -o "-p 5433 -k /tmp" -l /tmp/pgdata/log start
+//	CLA_TEST_POSTGRES_CONN=postgres://postgres@localhost:5433/postgres?sslmode=disable go test ./postgres
+//
+// Each case runs in a new database which is dropped at the end.
+func TestConformance(t *testing.T) {
+	conn := os.Getenv("CLA_TEST_POSTGRES_CONN")
+	if conn == "" {
+		t.Skip("CLA_TEST_POSTGRES_CONN is not set")
+	}
+
+	admin, err := sql.Open("postgres", conn)
+	if err != nil {
+		t.Fatalf("connect to postgres: %v", err)
+	}
+	defer admin.Close()
+
+	n := 0
+	conformance.Run(t, func(t *testing.T) conformance.DB {
+		n++
+		name := fmt.Sprintf("cla_conformance_%d_%d", time.Now().Unix(), n)
+
+		if _, err := admin.Exec("CREATE DATABASE " + name); err != nil {
+			t.Fatalf("create database: %v", err)
+		}
+
+		u, err := url.Parse(conn)
+		if err != nil {
+			t.Fatalf("parse %s: %v", conn, err)
+		}
+		u.Path = "/" + name
+
+		c, err := RegisterDatabase(u.String())
+		if err != nil {
+			t.Fatalf("register database: %v", err)
+		}
+
+		t.Cleanup(func() {
+			c.Close()
+			admin.Exec("DROP DATABASE " + name)
+		})
+		return c
+	})
+}
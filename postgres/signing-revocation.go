@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+const (
+	revokedSigningTypeIndividual = "individual"
+	revokedSigningTypeEmployee   = "employee"
+)
+
+// revokeSigning deletes the signing found by the query and keeps a tombstone of it
+func (this *client) revokeSigning(claOrgID, t string, opt dbmodels.SigningRevocation, query string, args ...interface{}) error {
+	if err := checkID(claOrgID); err != nil {
+		return err
+	}
+
+	f := func(tx *sql.Tx) error {
+		var signedAt int64
+		var claVersion int
+
+		err := tx.QueryRow(query, args...).Scan(&signedAt, &claVersion)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("he/she has not signed")
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO revoked_signings (cla_org_id, type, email, signed_at, cla_version, revoked_by, reason, revoked_at) "+
+				"VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+			claOrgID, t, opt.Email, signedAt, signedCLAVersion(claVersion), opt.RevokedBy, opt.Reason, opt.RevokedAt,
+		)
+		return err
+	}
+
+	return this.doTransaction(f)
+}
+
+func (this *client) RevokeIndividualSigning(claOrgID string, opt dbmodels.SigningRevocation) error {
+	err := this.revokeSigning(
+		claOrgID, revokedSigningTypeIndividual, opt,
+		"DELETE FROM individual_signings WHERE cla_org_id = $1 AND email = $2 RETURNING signed_at, cla_version",
+		claOrgID, opt.Email,
+	)
+	if err != nil {
+		return fmt.Errorf("Failed to revoke individual signing, %s", err.Error())
+	}
+	return nil
+}
+
+func (this *client) RevokeEmployeeSigning(claOrgID string, opt dbmodels.SigningRevocation) error {
+	err := this.revokeSigning(
+		claOrgID, revokedSigningTypeEmployee, opt,
+		"DELETE FROM employee_signings WHERE cla_org_id = $1 AND corporation_id = $2 AND email = $3 "+
+			"RETURNING signed_at, cla_version",
+		claOrgID, corporationIDOfEmployee(opt.CorporationID, opt.Email), opt.Email,
+	)
+	if err != nil {
+		return fmt.Errorf("Failed to revoke employee signing, %s", err.Error())
+	}
+	return nil
+}
+
+func (this *client) ListRevokedSigning(claOrgID string) ([]dbmodels.RevokedSigning, error) {
+	b, err := this.isCLAOrgExist(claOrgID)
+	if err != nil {
+		return nil, err
+	}
+	if !b {
+		return nil, fmt.Errorf("the binding(%s) is not exist", claOrgID)
+	}
+
+	rows, err := this.db.Query(
+		"SELECT type, email, signed_at, cla_version, revoked_by, reason, revoked_at "+
+			"FROM revoked_signings WHERE cla_org_id = $1 ORDER BY id",
+		claOrgID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list revoked signings: %s", err.Error())
+	}
+	defer rows.Close()
+
+	r := make([]dbmodels.RevokedSigning, 0)
+	for rows.Next() {
+		var v dbmodels.RevokedSigning
+
+		err := rows.Scan(&v.Type, &v.Email, &v.SignedAt, &v.CLAVersion, &v.RevokedBy, &v.Reason, &v.RevokedAt)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, v)
+	}
+	return r, rows.Err()
+}
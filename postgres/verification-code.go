@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zengchen1024/cla-server/dbmodels"
+)
+
+func (this *client) CreateVerificationCode(opt dbmodels.VerificationCode) error {
+	f := func(tx *sql.Tx) error {
+		// delete the old codes, including unused ones. The attempts of the
+		// unexpired one are kept, so resending can't reset the limit.
+		attempts := 0
+		err := tx.QueryRow(
+			"SELECT COALESCE(MAX(attempts), 0) FROM verification_codes WHERE email = $1 AND purpose = $2 AND expiry >= $3",
+			opt.Email, opt.Purpose, opt.CreatedAt,
+		).Scan(&attempts)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"DELETE FROM verification_codes WHERE email = $1 AND purpose = $2", opt.Email, opt.Purpose,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO verification_codes (email, code, purpose, expiry, created_at, ip, attempts) "+
+				"VALUES ($1, $2, $3, $4, $5, $6, $7)",
+			opt.Email, opt.Code, opt.Purpose, opt.Expiry, opt.CreatedAt, opt.IP, attempts,
+		)
+		return err
+	}
+
+	if err := this.doTransaction(f); err != nil {
+		return fmt.Errorf("Failed to create verification code: %s", err.Error())
+	}
+	return nil
+}
+
+func (this *client) CheckVerificationCode(opt dbmodels.VerificationCode, maxAttempts int) (bool, error) {
+	valid := false
+
+	f := func(tx *sql.Tx) error {
+		var id, expiry int64
+		var code string
+
+		err := tx.QueryRow(
+			"SELECT id, code, expiry FROM verification_codes WHERE email = $1 AND purpose = $2 AND attempts < $3 "+
+				"ORDER BY id LIMIT 1 FOR UPDATE",
+			opt.Email, opt.Purpose, maxAttempts,
+		).Scan(&id, &code, &expiry)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if code != opt.Code || expiry < time.Now().Unix() {
+			// count the attempt, so the guesses can't exceed the limit.
+			_, err := tx.Exec("UPDATE verification_codes SET attempts = attempts + 1 WHERE id = $1", id)
+			return err
+		}
+
+		if _, err := tx.Exec("DELETE FROM verification_codes WHERE id = $1", id); err != nil {
+			return err
+		}
+
+		valid = true
+		return nil
+	}
+
+	if err := this.doTransaction(f); err != nil {
+		return false, fmt.Errorf("Failed to check verification code: %s", err.Error())
+	}
+	return valid, nil
+}
+
+func (this *client) HasVerificationCodeSentSince(email, purpose, ip string, since int64) (bool, error) {
+	n := 0
+
+	err := this.db.QueryRow(
+		"SELECT count(*) FROM verification_codes WHERE created_at >= $4 "+
+			"AND ((email = $1 AND purpose = $2) OR ($3::text <> '' AND ip = $3))",
+		email, purpose, ip, since,
+	).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("Failed to check verification code: %s", err.Error())
+	}
+	return n != 0, nil
+}
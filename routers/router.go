@@ -65,6 +65,36 @@ func init() {
 				&controllers.OrgSignatureController{},
 			),
 		),
+		beego.NSNamespace("/email-template",
+			beego.NSInclude(
+				&controllers.EmailTemplateController{},
+			),
+		),
+		beego.NSNamespace("/signing-check",
+			beego.NSInclude(
+				&controllers.SigningCheckController{},
+			),
+		),
+		beego.NSNamespace("/webhook",
+			beego.NSInclude(
+				&controllers.WebhookController{},
+			),
+		),
+		beego.NSNamespace("/audit",
+			beego.NSInclude(
+				&controllers.AuditLogController{},
+			),
+		),
+		beego.NSNamespace("/corporation-domain",
+			beego.NSInclude(
+				&controllers.CorporationDomainController{},
+			),
+		),
+		beego.NSNamespace("/jobs",
+			beego.NSInclude(
+				&controllers.JobController{},
+			),
+		),
 	)
 	beego.AddNamespace(ns)
 }
@@ -0,0 +1,275 @@
+package webhook
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/zengchen1024/cla-server/code-platform-auth/platforms"
+	"github.com/zengchen1024/cla-server/models"
+)
+
+const (
+	LabelSigned   = "cla/yes"
+	LabelUnsigned = "cla/no"
+
+	// StatusContext is the context of commit status which reports the cla status
+	StatusContext = "license/cla"
+
+	commandCheckCLA = "/check-cla"
+)
+
+type commentPolicy int
+
+const (
+	commentNever commentPolicy = iota
+	commentIfUnsigned
+	commentAlways
+)
+
+var claChecker *CLAChecker
+
+// InitCLAChecker initializes the checker which accesses the code platform
+// with the bot token of binding.
+func InitCLAChecker(signingPageURL string) {
+	claChecker = NewCLAChecker(signingPageURL, isSigned, models.GetBotToken, newPlatform)
+}
+
+func GetCLAChecker() *CLAChecker {
+	return claChecker
+}
+
+// SigningChecker checks whether the contributor has signed the cla for the org/repo
+type SigningChecker func(platform, org, repo, email string) (bool, error)
+
+// BotTokenGetter returns the bot token of binding for the org/repo
+type BotTokenGetter func(platform, org, repo string) (string, error)
+
+// ClientGetter returns the client of platform which accesses it with token
+type ClientGetter func(platform, token string) (platforms.Platform, error)
+
+// CLAChecker reports the cla status of pull request by labels, commit status and comment.
+type CLAChecker struct {
+	signingPageURL string
+	isSigned       SigningChecker
+	botToken       BotTokenGetter
+	newClient      ClientGetter
+}
+
+func NewCLAChecker(signingPageURL string, isSigned SigningChecker, botToken BotTokenGetter, newClient ClientGetter) *CLAChecker {
+	return &CLAChecker{
+		signingPageURL: signingPageURL,
+		isSigned:       isSigned,
+		botToken:       botToken,
+		newClient:      newClient,
+	}
+}
+
+type PullRequest struct {
+	platforms.PullRequest
+
+	Org  string
+	Repo string
+}
+
+// RecheckAuthor checks the open pull requests which contain the commits of author again
+// in the repo or all the repos of org if repo is empty. It is used after the author signed.
+func (this *CLAChecker) RecheckAuthor(platform, org, repo, email string) error {
+	client, err := this.client(platform, org, repo)
+	if err != nil {
+		return err
+	}
+
+	repos := []string{repo}
+	if repo == "" {
+		if repos, err = client.ListRepos(org); err != nil {
+			return fmt.Errorf("Failed to list repos of %s: %s", org, err.Error())
+		}
+	}
+
+	var errs []string
+	for _, r := range repos {
+		if err := this.recheckRepo(client, platform, org, r, email); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (this *CLAChecker) recheckRepo(client platforms.Platform, platform, org, repo, email string) error {
+	prs, err := client.ListOpenPRs(org, repo)
+	if err != nil {
+		return fmt.Errorf("Failed to list pull requests of %s/%s: %s", org, repo, err.Error())
+	}
+
+	for _, item := range prs {
+		pr := PullRequest{PullRequest: item, Org: org, Repo: repo}
+
+		authors, err := client.ListPRCommitAuthors(org, repo, pr.Number)
+		if err != nil {
+			return fmt.Errorf("Failed to list commits of pull request: %s", err.Error())
+		}
+
+		if !hasAuthor(authors, email) {
+			continue
+		}
+
+		if err := this.checkPR(client, platform, &pr, authors, commentNever); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckPR checks whether all the commit authors of pull request have signed the cla
+func (this *CLAChecker) CheckPR(platform string, pr *PullRequest, policy commentPolicy) error {
+	client, err := this.client(platform, pr.Org, pr.Repo)
+	if err != nil {
+		return err
+	}
+
+	authors, err := client.ListPRCommitAuthors(pr.Org, pr.Repo, pr.Number)
+	if err != nil {
+		return fmt.Errorf("Failed to list commits of pull request: %s", err.Error())
+	}
+
+	return this.checkPR(client, platform, pr, authors, policy)
+}
+
+func (this *CLAChecker) client(platform, org, repo string) (platforms.Platform, error) {
+	token, err := this.botToken(platform, org, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return this.newClient(platform, token)
+}
+
+func (this *CLAChecker) checkPR(client platforms.Platform, platform string, pr *PullRequest, authors []string, policy commentPolicy) error {
+	var unsigned []string
+	for _, email := range authors {
+		signed, err := this.isSigned(platform, pr.Org, pr.Repo, email)
+		if err != nil {
+			return err
+		}
+		if !signed {
+			unsigned = append(unsigned, email)
+		}
+	}
+
+	if err := this.setLabel(client, pr, len(unsigned) == 0); err != nil {
+		return err
+	}
+
+	if err := this.setStatus(client, platform, pr, unsigned); err != nil {
+		return err
+	}
+
+	if policy == commentNever || (policy == commentIfUnsigned && len(unsigned) == 0) {
+		return nil
+	}
+
+	comment := this.genComment(platform, pr, unsigned)
+	if err := client.CreatePRComment(pr.Org, pr.Repo, pr.Number, comment); err != nil {
+		return fmt.Errorf("Failed to comment on pull request: %s", err.Error())
+	}
+	return nil
+}
+
+func (this *CLAChecker) setLabel(client platforms.Platform, pr *PullRequest, signed bool) error {
+	add, remove := LabelSigned, LabelUnsigned
+	if !signed {
+		add, remove = LabelUnsigned, LabelSigned
+	}
+
+	labels := make(map[string]bool, len(pr.Labels))
+	for _, l := range pr.Labels {
+		labels[l] = true
+	}
+
+	if !labels[add] {
+		if err := client.AddPRLabel(pr.Org, pr.Repo, pr.Number, add); err != nil {
+			return fmt.Errorf("Failed to add label: %s", err.Error())
+		}
+	}
+
+	if labels[remove] {
+		if err := client.RemovePRLabel(pr.Org, pr.Repo, pr.Number, remove); err != nil {
+			return fmt.Errorf("Failed to remove label: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+func (this *CLAChecker) setStatus(client platforms.Platform, platform string, pr *PullRequest, unsigned []string) error {
+	if pr.HeadSHA == "" {
+		return nil
+	}
+
+	status := platforms.CommitStatus{
+		State:       platforms.CommitStatusSuccess,
+		Context:     StatusContext,
+		Description: "All the authors of commits have signed the CLA",
+	}
+	if len(unsigned) > 0 {
+		status.State = platforms.CommitStatusFailure
+		status.Description = fmt.Sprintf("%d author(s) of commits have not signed the CLA", len(unsigned))
+		status.TargetURL = this.signingPage(platform, pr.Org, pr.Repo)
+	}
+
+	err := client.CreateCommitStatus(pr.Org, pr.Repo, pr.HeadSHA, status)
+	if err != nil && err != platforms.ErrUnsupported {
+		return fmt.Errorf("Failed to create commit status: %s", err.Error())
+	}
+	return nil
+}
+
+func (this *CLAChecker) genComment(platform string, pr *PullRequest, unsigned []string) string {
+	if len(unsigned) == 0 {
+		return "Thanks for your pull request. All the authors of commits have signed the CLA."
+	}
+
+	return fmt.Sprintf(
+		"Thanks for your pull request. The authors of commits with the following emails have not signed the CLA:\n\n"+
+			"%s\n\nPlease sign it at [here](%s), then comment `%s` to check again.",
+		"- "+strings.Join(unsigned, "\n- "), this.signingPage(platform, pr.Org, pr.Repo), commandCheckCLA,
+	)
+}
+
+func (this *CLAChecker) signingPage(platform, org, repo string) string {
+	v := url.Values{}
+	v.Add("platform", platform)
+	v.Add("org_id", org)
+	v.Add("repo_id", repo)
+
+	return this.signingPageURL + "?" + v.Encode()
+}
+
+func hasAuthor(authors []string, email string) bool {
+	for _, item := range authors {
+		if item == email {
+			return true
+		}
+	}
+	return false
+}
+
+func isSigned(platform, org, repo, email string) (bool, error) {
+	opt := models.SigningCheckOption{
+		Platform: platform,
+		OrgID:    org,
+		RepoID:   repo,
+		Email:    email,
+	}
+
+	r, err := opt.Check()
+	return r.Signed, err
+}
+
+func newPlatform(platform, token string) (platforms.Platform, error) {
+	return platforms.NewPlatform(token, "", platform)
+}
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecheckAuthor(t *testing.T) {
+	var status map[string]string
+	var added []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/org/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		if v := r.URL.Query().Get("state"); v != "open" {
+			t.Errorf("expect state: open, got: %s", v)
+		}
+		fmt.Fprint(w, `[{"number":1,"head":{"sha":"sha1"},"labels":[{"name":"cla/no"}]},{"number":2,"head":{"sha":"sha2"}}]`)
+	})
+	mux.HandleFunc("/repos/org/repo/pulls/1/commits", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"commit":{"author":{"email":"a@example.com"}}}]`)
+	})
+	mux.HandleFunc("/repos/org/repo/pulls/2/commits", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"commit":{"author":{"email":"b@example.com"}}}]`)
+	})
+	mux.HandleFunc("/repos/org/repo/issues/1/labels", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(b, &added)
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/repos/org/repo/issues/1/labels/cla/no", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/repos/org/repo/statuses/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/org/repo/statuses/sha1" {
+			t.Errorf("unexpected status of commit: %s", r.URL.Path)
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(b, &status)
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	c := newTestChecker(t, s.URL, map[string]bool{"a@example.com": true})
+	if err := c.RecheckAuthor("github", "org", "repo", "a@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	if status["state"] != "success" || status["context"] != StatusContext {
+		t.Errorf("unexpected commit status: %v", status)
+	}
+	if len(added) != 1 || added[0] != LabelSigned {
+		t.Errorf("expect added labels: [%s], got: %v", LabelSigned, added)
+	}
+}
@@ -0,0 +1,186 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	platformGitee = "gitee"
+
+	giteeEventPR   = "Merge Request Hook"
+	giteeEventNote = "Note Hook"
+
+	// giteeSignatureWindow is how long the signature of webhook is valid since
+	// its timestamp. The signature doesn't cover the payload, so each one is
+	// accepted only once within the window.
+	giteeSignatureWindow = 5 * time.Minute
+)
+
+var giteeWebhook *GiteeWebhook
+
+func GetGiteeWebhook() *GiteeWebhook {
+	return giteeWebhook
+}
+
+// GiteeWebhook handles the events of pull request on Gitee and reports
+// the cla status of pull request by checker.
+type GiteeWebhook struct {
+	// secret is the signing key configured for the webhook on Gitee
+	secret  string
+	checker *CLAChecker
+
+	now func() time.Time
+
+	// tokens is the signatures received within the window and when they expire
+	tokens     map[string]time.Time
+	tokensLock sync.Mutex
+}
+
+func InitGiteeWebhook(secret string, checker *CLAChecker) {
+	giteeWebhook = NewGiteeWebhook(secret, checker)
+}
+
+func NewGiteeWebhook(secret string, checker *CLAChecker) *GiteeWebhook {
+	return &GiteeWebhook{
+		secret:  secret,
+		checker: checker,
+		now:     time.Now,
+		tokens:  map[string]time.Time{},
+	}
+}
+
+type giteeLabel struct {
+	Name string `json:"name"`
+}
+
+type giteeBranch struct {
+	Sha string `json:"sha"`
+}
+
+type giteePullRequest struct {
+	Number int          `json:"number"`
+	State  string       `json:"state"`
+	Head   *giteeBranch `json:"head"`
+	Labels []giteeLabel `json:"labels"`
+}
+
+type giteeRepository struct {
+	Namespace string `json:"namespace"`
+	Path      string `json:"path"`
+}
+
+type giteeComment struct {
+	Body string `json:"body"`
+}
+
+type giteeEvent struct {
+	Action       string            `json:"action"`
+	NoteableType string            `json:"noteable_type"`
+	PullRequest  *giteePullRequest `json:"pull_request"`
+	Repository   *giteeRepository  `json:"repository"`
+	Comment      *giteeComment     `json:"comment"`
+}
+
+func (e *giteeEvent) toPullRequest() *PullRequest {
+	pr := &PullRequest{
+		Org:  e.Repository.Namespace,
+		Repo: e.Repository.Path,
+	}
+	pr.Number = e.PullRequest.Number
+
+	if e.PullRequest.Head != nil {
+		pr.HeadSHA = e.PullRequest.Head.Sha
+	}
+
+	for _, l := range e.PullRequest.Labels {
+		pr.Labels = append(pr.Labels, l.Name)
+	}
+	return pr
+}
+
+// VerifySignature checks the signature of webhook which is the base64 of
+// HmacSHA256(secret, timestamp + "\n" + secret). The timestamp in milliseconds
+// must be within giteeSignatureWindow, and the signature can't be replayed.
+func (this *GiteeWebhook) VerifySignature(header http.Header) error {
+	token := header.Get("X-Gitee-Token")
+	timestamp := header.Get("X-Gitee-Timestamp")
+	if token == "" || timestamp == "" {
+		return fmt.Errorf("missing signature of webhook")
+	}
+
+	mac := hmac.New(sha256.New, []byte(this.secret))
+	mac.Write([]byte(timestamp + "\n" + this.secret))
+	expect := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(token), []byte(expect)) {
+		return fmt.Errorf("invalid signature of webhook")
+	}
+
+	ms, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp of webhook")
+	}
+
+	now := this.now()
+	signedAt := time.Unix(0, ms*int64(time.Millisecond))
+	if d := now.Sub(signedAt); d > giteeSignatureWindow || d < -giteeSignatureWindow {
+		return fmt.Errorf("the signature of webhook is expired")
+	}
+
+	return this.useToken(token, signedAt.Add(giteeSignatureWindow), now)
+}
+
+// useToken records the token until expiry, and fails if it has been used.
+func (this *GiteeWebhook) useToken(token string, expiry, now time.Time) error {
+	this.tokensLock.Lock()
+	defer this.tokensLock.Unlock()
+
+	for k, v := range this.tokens {
+		if v.Before(now) {
+			delete(this.tokens, k)
+		}
+	}
+
+	if _, ok := this.tokens[token]; ok {
+		return fmt.Errorf("the signature of webhook has been used")
+	}
+
+	this.tokens[token] = expiry
+	return nil
+}
+
+// Handle handles the event. It is ignored if it is not the one which may
+// change the cla status of pull request.
+func (this *GiteeWebhook) Handle(eventType string, payload []byte) error {
+	var e giteeEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return fmt.Errorf("Failed to parse the payload of webhook: %s", err.Error())
+	}
+
+	if e.PullRequest == nil || e.Repository == nil {
+		return nil
+	}
+
+	switch eventType {
+	case giteeEventPR:
+		if e.Action == "open" || e.Action == "update" {
+			return this.checker.CheckPR(platformGitee, e.toPullRequest(), commentIfUnsigned)
+		}
+
+	case giteeEventNote:
+		if e.NoteableType == "PullRequest" && e.Comment != nil &&
+			strings.TrimSpace(e.Comment.Body) == commandCheckCLA {
+			return this.checker.CheckPR(platformGitee, e.toPullRequest(), commentAlways)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zengchen1024/cla-server/code-platform-auth/platforms"
+)
+
+// fakeGitee records the operations on the pull request org/repo/1
+type fakeGitee struct {
+	authors  string
+	added    []string
+	removed  []string
+	comments []string
+}
+
+func (f *fakeGitee) serve(t *testing.T) *httptest.Server {
+	prefix := "/v5/repos/org/repo/pulls/1"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(prefix+"/commits", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, f.authors)
+	})
+	mux.HandleFunc(prefix+"/labels", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Body []string `json:"body"`
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(b, &body)
+		f.added = append(f.added, body.Body...)
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc(prefix+"/labels/", func(w http.ResponseWriter, r *http.Request) {
+		f.removed = append(f.removed, strings.TrimPrefix(r.URL.Path, prefix+"/labels/"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc(prefix+"/comments", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Body string `json:"body"`
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(b, &body)
+		f.comments = append(f.comments, body.Body)
+		fmt.Fprint(w, `{"id":1}`)
+	})
+
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestChecker(t *testing.T, endpoint string, signed map[string]bool) *CLAChecker {
+	isSigned := func(platform, org, repo, email string) (bool, error) {
+		if org != "org" || repo != "repo" {
+			t.Errorf("unexpected signing check: %s/%s/%s", platform, org, repo)
+		}
+		return signed[email], nil
+	}
+
+	botToken := func(platform, org, repo string) (string, error) {
+		return "token", nil
+	}
+
+	newClient := func(platform, token string) (platforms.Platform, error) {
+		return platforms.NewPlatformWithEndpoint(token, platform, endpoint)
+	}
+
+	return NewCLAChecker("https://cla.example.com/sign", isSigned, botToken, newClient)
+}
+
+func newTestGiteeWebhook(t *testing.T, f *fakeGitee, signed map[string]bool) *GiteeWebhook {
+	s := f.serve(t)
+
+	return NewGiteeWebhook("secret", newTestChecker(t, s.URL, signed))
+}
+
+func prEvent(action string, labels ...string) []byte {
+	ls := make([]string, 0, len(labels))
+	for _, l := range labels {
+		ls = append(ls, fmt.Sprintf(`{"name":"%s"}`, l))
+	}
+
+	return []byte(fmt.Sprintf(
+		`{"action":"%s","pull_request":{"number":1,"labels":[%s]},"repository":{"namespace":"org","path":"repo"}}`,
+		action, strings.Join(ls, ","),
+	))
+}
+
+func giteeSignatureHeader(timestamp string) http.Header {
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(timestamp + "\nsecret"))
+
+	header := http.Header{}
+	header.Set("X-Gitee-Timestamp", timestamp)
+	header.Set("X-Gitee-Token", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return header
+}
+
+func TestGiteeVerifySignature(t *testing.T) {
+	w := NewGiteeWebhook("secret", nil)
+	now := time.Unix(1600000000, 0)
+	w.now = func() time.Time { return now }
+
+	header := giteeSignatureHeader("1600000000000")
+	if err := w.VerifySignature(header); err != nil {
+		t.Errorf("expect valid signature, got: %v", err)
+	}
+
+	if err := w.VerifySignature(header); err == nil {
+		t.Error("expect the replayed signature to be rejected")
+	}
+
+	header.Set("X-Gitee-Timestamp", "1600000000001")
+	if err := w.VerifySignature(header); err == nil {
+		t.Error("expect invalid signature")
+	}
+
+	if err := w.VerifySignature(giteeSignatureHeader("1599999000000")); err == nil {
+		t.Error("expect the expired signature to be rejected")
+	}
+
+	// the used signature is forgotten after it expires
+	now = now.Add(giteeSignatureWindow + time.Second)
+	if err := w.VerifySignature(giteeSignatureHeader("1600000300000")); err != nil {
+		t.Errorf("expect valid signature, got: %v", err)
+	}
+	if len(w.tokens) != 1 {
+		t.Errorf("expect the expired signatures to be removed, but got %d", len(w.tokens))
+	}
+}
+
+func TestGiteeHandleUnsignedPR(t *testing.T) {
+	f := &fakeGitee{
+		authors: `[{"commit":{"author":{"email":"a@example.com"}}},{"commit":{"author":{"email":"b@example.com"}}}]`,
+	}
+	w := newTestGiteeWebhook(t, f, map[string]bool{"a@example.com": true})
+
+	if err := w.Handle(giteeEventPR, prEvent("open", LabelSigned)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(f.added, []string{LabelUnsigned}) {
+		t.Errorf("expect added labels: [%s], got: %v", LabelUnsigned, f.added)
+	}
+	if !reflect.DeepEqual(f.removed, []string{LabelSigned}) {
+		t.Errorf("expect removed labels: [%s], got: %v", LabelSigned, f.removed)
+	}
+	if len(f.comments) != 1 {
+		t.Fatalf("expect 1 comment, got: %d", len(f.comments))
+	}
+
+	c := f.comments[0]
+	if !strings.Contains(c, "b@example.com") || strings.Contains(c, "a@example.com") {
+		t.Errorf("the comment should only mention the unsigned email:\n%s", c)
+	}
+	if !strings.Contains(c, "https://cla.example.com/sign?org_id=org&platform=gitee&repo_id=repo") {
+		t.Errorf("the link of signing page is not found in comment:\n%s", c)
+	}
+}
+
+func TestGiteeHandleSignedPR(t *testing.T) {
+	f := &fakeGitee{authors: `[{"commit":{"author":{"email":"a@example.com"}}}]`}
+	w := newTestGiteeWebhook(t, f, map[string]bool{"a@example.com": true})
+
+	if err := w.Handle(giteeEventPR, prEvent("update", LabelUnsigned)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(f.added, []string{LabelSigned}) {
+		t.Errorf("expect added labels: [%s], got: %v", LabelSigned, f.added)
+	}
+	if !reflect.DeepEqual(f.removed, []string{LabelUnsigned}) {
+		t.Errorf("expect removed labels: [%s], got: %v", LabelUnsigned, f.removed)
+	}
+	if len(f.comments) != 0 {
+		t.Errorf("expect no comment, got: %v", f.comments)
+	}
+}
+
+func TestGiteeHandleCheckCommand(t *testing.T) {
+	f := &fakeGitee{authors: `[{"commit":{"author":{"email":"a@example.com"}}}]`}
+	w := newTestGiteeWebhook(t, f, map[string]bool{"a@example.com": true})
+
+	payload := []byte(`{"action":"comment","noteable_type":"PullRequest","comment":{"body":" /check-cla "},` +
+		`"pull_request":{"number":1,"labels":[{"name":"cla/yes"}]},"repository":{"namespace":"org","path":"repo"}}`)
+	if err := w.Handle(giteeEventNote, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.added) != 0 || len(f.removed) != 0 {
+		t.Errorf("expect labels unchanged, got added: %v, removed: %v", f.added, f.removed)
+	}
+	if len(f.comments) != 1 {
+		t.Errorf("expect 1 comment, got: %v", f.comments)
+	}
+}
+
+func TestGiteeIgnoreEvent(t *testing.T) {
+	f := &fakeGitee{}
+	w := newTestGiteeWebhook(t, f, nil)
+
+	if err := w.Handle(giteeEventPR, prEvent("merge")); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.added) != 0 || len(f.comments) != 0 {
+		t.Errorf("expect the event ignored, got added: %v, comments: %v", f.added, f.comments)
+	}
+}
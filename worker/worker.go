@@ -1,12 +1,15 @@
 package worker
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"sync"
 	"time"
 
 	"github.com/astaxie/beego"
 
+	"github.com/zengchen1024/cla-server/dbmodels"
 	"github.com/zengchen1024/cla-server/email"
 	"github.com/zengchen1024/cla-server/models"
 	"github.com/zengchen1024/cla-server/pdf"
@@ -16,79 +19,288 @@ import (
 var worker IEmailWorker
 
 type IEmailWorker interface {
-	GenCLAPDFForCorporationAndSendIt(claOrg *models.CLAOrg, signing *models.CorporationSigning, cla *models.CLA, emailCfg *models.OrgEmail)
+	GenCLAPDFForCorporationAndSendIt(claOrg *models.CLAOrg, signing *models.CorporationSigning) error
+	ListJobs(opt models.JobListOption) (dbmodels.JobPage, error)
+	RetryJob(jobID string) error
+	CancelJob(jobID string) error
+	Shutdown()
 }
 
 func GetEmailWorker() IEmailWorker {
 	return worker
 }
 
-func InitEmailWorker(g pdf.IPDFGenerator) {
-	worker = &emailWorker{pdfGenerator: g}
+type Config struct {
+	// PoolSize is the number of jobs which can be handled concurrently
+	PoolSize int
+
+	// MaxAttempts is the max times a job will be tried before it is dead
+	MaxAttempts int
+
+	// PollInterval is the interval to check whether there are jobs to run
+	PollInterval time.Duration
+
+	// Lease is the time a running job is locked by a worker. The job will be
+	// picked up again if it is not finished within the lease.
+	Lease time.Duration
+
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// InitEmailWorker starts the workers which will pick up the pending jobs,
+// including the ones left before the last restart.
+func InitEmailWorker(g pdf.IPDFGenerator, cfg Config) {
+	w := &emailWorker{
+		cfg:          cfg,
+		pdfGenerator: g,
+		stop:         make(chan struct{}),
+		notify:       make(chan struct{}, cfg.PoolSize),
+	}
+
+	for i := 0; i < cfg.PoolSize; i++ {
+		w.wg.Add(1)
+		go w.run()
+	}
+
+	worker = w
 }
 
 type emailWorker struct {
+	cfg          Config
 	pdfGenerator pdf.IPDFGenerator
 	wg           sync.WaitGroup
-	shutdown     bool
+	stop         chan struct{}
+	stopOnce     sync.Once
+
+	// notify is used to wake up an idle worker when a new job is added
+	notify chan struct{}
 }
 
+// Shutdown stops picking up new jobs and waits for the running ones to finish.
+// The remaining jobs will be handled after restart.
 func (this *emailWorker) Shutdown() {
-	this.shutdown = true
+	this.stopOnce.Do(func() {
+		close(this.stop)
+	})
 
-	// Handle remaining requests
 	this.wg.Wait()
 }
 
-func (this *emailWorker) GenCLAPDFForCorporationAndSendIt(claOrg *models.CLAOrg, signing *models.CorporationSigning, cla *models.CLA, emailCfg *models.OrgEmail) {
-	f := func() {
-		defer func() {
-			this.wg.Done()
-		}()
-
-		wait := func() { time.Sleep(time.Minute * time.Duration(1)) }
-
-		file := ""
-		for {
-			if this.shutdown {
-				beego.Info("exit email worker forcedly")
-				break
-			}
-
-			if file == "" || util.IsFileNotExist(file) {
-				file1, err := this.pdfGenerator.GenCLAPDFForCorporation(claOrg, signing, cla)
-				if err != nil {
-					beego.Info(err)
-					wait()
-					continue
-				}
-				file = file1
-			}
-
-			e, err := email.GetEmailClient(emailCfg.Platform)
-			if err != nil {
-				beego.Info(err)
-				wait()
-				continue
-			}
-
-			msg := email.EmailMessage{
-				To:         signing.AdminEmail,
-				Subject:    "pdf signing",
-				Content:    "pdf",
-				Attachment: file,
-			}
-			if err := e.SendEmail(*emailCfg.Token, msg); err != nil {
-				beego.Info(err)
-				wait()
-				continue
-			}
-
-			os.Remove(file)
-			break
+func (this *emailWorker) GenCLAPDFForCorporationAndSendIt(claOrg *models.CLAOrg, signing *models.CorporationSigning) error {
+	payload, err := json.Marshal(signing)
+	if err != nil {
+		return fmt.Errorf("Failed to marshal corporation signing: %s", err.Error())
+	}
+
+	job := models.Job{
+		Kind:          models.JobKindCorporationSigningPDF,
+		CLAOrgID:      claOrg.ID,
+		CorporationID: util.EmailSuffixToKey(signing.AdminEmail),
+		Email:         signing.AdminEmail,
+		Payload:       string(payload),
+		MaxAttempts:   this.cfg.MaxAttempts,
+	}
+	if err := (&job).Create(); err != nil {
+		return fmt.Errorf("Failed to add job of sending pdf: %s", err.Error())
+	}
+
+	this.wakeup()
+	return nil
+}
+
+func (this *emailWorker) ListJobs(opt models.JobListOption) (dbmodels.JobPage, error) {
+	return opt.List()
+}
+
+// RetryJob runs the failed or cancelled job again as a new one.
+func (this *emailWorker) RetryJob(jobID string) error {
+	status := models.JobStatusPending
+	attempts := 0
+	next := time.Now().Unix()
+
+	info := models.JobUpdateInfo{
+		Status:         &status,
+		Attempts:       &attempts,
+		NextRunAt:      &next,
+		ExpectedStatus: []string{models.JobStatusDead, models.JobStatusCancelled},
+	}
+	if err := info.Update(jobID); err != nil {
+		return err
+	}
+
+	this.wakeup()
+	return nil
+}
+
+// CancelJob cancels the job which is waiting to run. A running job can't be cancelled.
+func (this *emailWorker) CancelJob(jobID string) error {
+	status := models.JobStatusCancelled
+
+	info := models.JobUpdateInfo{
+		Status:         &status,
+		ExpectedStatus: []string{models.JobStatusPending, models.JobStatusDead},
+	}
+	return info.Update(jobID)
+}
+
+func (this *emailWorker) wakeup() {
+	select {
+	case this.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (this *emailWorker) isStopped() bool {
+	select {
+	case <-this.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (this *emailWorker) wait() {
+	select {
+	case <-this.stop:
+	case <-this.notify:
+	case <-time.After(this.cfg.PollInterval):
+	}
+}
+
+func (this *emailWorker) run() {
+	defer this.wg.Done()
+
+	for !this.isStopped() {
+		now := time.Now()
+
+		job, err := models.ClaimJob(now.Unix(), now.Add(this.cfg.Lease).Unix())
+		if err != nil {
+			beego.Info(err)
+			this.wait()
+			continue
+		}
+
+		if job == nil {
+			this.wait()
+			continue
+		}
+
+		this.handle(job)
+	}
+}
+
+func (this *emailWorker) handle(job *models.Job) {
+	var err error
+	if job.Attempts > job.MaxAttempts {
+		err = fmt.Errorf("the job has been tried more than %d times", job.MaxAttempts)
+	} else {
+		err = this.do(job)
+	}
+
+	status := models.JobStatusDone
+	info := models.JobUpdateInfo{
+		Status:         &status,
+		ExpectedStatus: []string{models.JobStatusRunning},
+		ClaimToken:     job.ClaimToken,
+	}
+
+	if err != nil {
+		beego.Info(fmt.Sprintf("Failed to handle job(%s): %s", job.ID, err.Error()))
+
+		msg := err.Error()
+		info.LastError = &msg
+
+		if job.Attempts >= job.MaxAttempts {
+			status = models.JobStatusDead
+		} else {
+			status = models.JobStatusPending
+
+			next := time.Now().Add(this.backoff(job.Attempts)).Unix()
+			info.NextRunAt = &next
 		}
 	}
 
-	this.wg.Add(1)
-	go f()
+	if err := info.Update(job.ID); err != nil {
+		beego.Info(fmt.Sprintf("Failed to update job(%s): %s", job.ID, err.Error()))
+	}
+}
+
+// backoff returns the delay before next attempt which grows exponentially
+func (this *emailWorker) backoff(attempts int) time.Duration {
+	d := this.cfg.BackoffBase
+	for i := 1; i < attempts && d < this.cfg.BackoffMax; i++ {
+		d *= 2
+	}
+
+	if d > this.cfg.BackoffMax {
+		return this.cfg.BackoffMax
+	}
+	return d
+}
+
+func (this *emailWorker) do(job *models.Job) error {
+	switch job.Kind {
+	case models.JobKindCorporationSigningPDF:
+		return this.genCLAPDFForCorporationAndSendIt(job)
+	}
+	return fmt.Errorf("unknown job kind: %s", job.Kind)
+}
+
+func (this *emailWorker) genCLAPDFForCorporationAndSendIt(job *models.Job) error {
+	var signing models.CorporationSigning
+	if err := json.Unmarshal([]byte(job.Payload), &signing); err != nil {
+		return fmt.Errorf("Failed to unmarshal corporation signing: %s", err.Error())
+	}
+
+	claOrg := &models.CLAOrg{ID: job.CLAOrgID}
+	if err := claOrg.Get(); err != nil {
+		return err
+	}
+
+	cla := &models.CLA{ID: claOrg.CLAID}
+	if err := cla.Get(); err != nil {
+		return err
+	}
+
+	// the pdf is generated by the version which the corporation signed
+	// instead of the one the binding points to when the job is handled.
+	text, err := cla.TextOfVersion(signing.CLAVersion)
+	if err != nil {
+		return err
+	}
+	cla.Text = text
+	if signing.CLAVersion > 0 {
+		cla.Version = signing.CLAVersion
+	}
+
+	emailCfg := &models.OrgEmail{Email: claOrg.OrgEmail}
+	if err := emailCfg.Get(); err != nil {
+		return err
+	}
+
+	e, err := email.GetEmailClient(emailCfg.Platform)
+	if err != nil {
+		return err
+	}
+
+	file, err := this.pdfGenerator.GenCLAPDFForCorporation(claOrg, &signing, cla)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file)
+
+	data := email.EmailTemplateData{
+		CLAOrg:  claOrg,
+		CLA:     cla,
+		Signing: &signing,
+		Email:   signing.AdminEmail,
+	}
+	msg, err := email.GenEmailMessage(models.EmailTemplateCorporationSigning, &data)
+	if err != nil {
+		return err
+	}
+	msg.Attachment = file
+
+	return e.SendEmail(emailCfg, msg)
 }